@@ -25,6 +25,7 @@ func main() {
 	root.AddCommand(newRestApiCmd())
 	root.AddCommand(newGrpcApiCmd())
 	root.AddCommand(newCmdScheduler())
+	root.AddCommand(newCmdEraseUser())
 
 	err := root.Execute()
 	if err != nil {
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go-bootstrap/internal/app"
+	"go-bootstrap/internal/config"
+	domainuser "go-bootstrap/internal/domain/user"
+	"go-bootstrap/internal/infrastructure"
+	"time"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/confy"
+	"github.com/spf13/cobra"
+)
+
+func newCmdEraseUser() *cobra.Command {
+	var userID string
+	var jobID string
+	var confirm bool
+
+	cmd := &cobra.Command{
+		Use:   "erase-user",
+		Short: "Erase a user for a right-to-be-forgotten request, or show an erasure job",
+		Long: "Erase a user for a right-to-be-forgotten request: the personal data of the user is " +
+			"overwritten for good and its tokens, sessions and other personal rows are deleted. " +
+			"The erasure is recorded as an erasure job and in the audit log. Uses the app_scheduler config.",
+		SilenceUsage: true,
+		Example:      "  go-boostrap erase-user --user-id 42 --yes\n  go-boostrap erase-user --job-id 7",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (userID == "") == (jobID == "") {
+				return errors.New("exactly one of --user-id and --job-id is required")
+			}
+			if userID != "" && !confirm {
+				return errors.New("erasing a user cannot be undone, confirm with --yes")
+			}
+
+			closeLogging := infrastructure.NewLogging("std-out", "std-out")
+			defer func() {
+				_ = closeLogging()
+				_ = config.UnwatchLoader()
+				confy.Close()
+			}()
+
+			eraseUserApp := app.NewEraseUserApp()
+			defer func() {
+				_ = eraseUserApp.Close()
+			}()
+
+			var job domainuser.ErasureJob
+			var err error
+			if userID != "" {
+				job, err = eraseUserApp.Erase(cmd.Context(), userID)
+			} else {
+				job, err = eraseUserApp.GetJob(cmd.Context(), jobID)
+			}
+			if err != nil {
+				return err
+			}
+
+			printErasureJob(cmd, job)
+			if job.Status == domainuser.ErasureJobStatusFailed {
+				return fmt.Errorf("erasure job %s failed: %s", job.ID, job.Error)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&userID, "user-id", "", "id of the user to erase")
+	cmd.Flags().StringVar(&jobID, "job-id", "", "id of the erasure job to show")
+	cmd.Flags().BoolVar(&confirm, "yes", false, "confirm the erasure, it cannot be undone")
+
+	return cmd
+}
+
+func printErasureJob(cmd *cobra.Command, job domainuser.ErasureJob) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "job_id:       %s\n", job.ID)
+	fmt.Fprintf(out, "user_id:      %s\n", job.UserID)
+	fmt.Fprintf(out, "status:       %s\n", job.Status)
+	fmt.Fprintf(out, "created_at:   %s\n", job.CreatedAt.Format(time.RFC3339))
+	if job.StartedAt != nil {
+		fmt.Fprintf(out, "started_at:   %s\n", job.StartedAt.Format(time.RFC3339))
+	}
+	if job.CompletedAt != nil {
+		fmt.Fprintf(out, "completed_at: %s\n", job.CompletedAt.Format(time.RFC3339))
+	}
+	if job.Error != "" {
+		fmt.Fprintf(out, "error:        %s\n", job.Error)
+	}
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"errors"
+	domainuser "go-bootstrap/internal/domain/user"
+	"go-bootstrap/internal/infrastructure"
+)
+
+// eraseUserApp backs the erase-user command: it erases a user right away
+// through the same erasure jobs the REST API queues for the scheduler.
+type eraseUserApp struct {
+	userService domainuser.UserService
+	closeFn     []func() error
+}
+
+func NewEraseUserApp() *eraseUserApp {
+	db, err := infrastructure.NewDB()
+	if err != nil {
+		panic(err)
+	}
+
+	return &eraseUserApp{
+		userService: newJobUserService(db),
+		closeFn:     []func() error{db.Close},
+	}
+}
+
+// Erase queues an erasure job for userID and runs it. A failed erasure is
+// returned as a job with the failed status.
+func (a *eraseUserApp) Erase(ctx context.Context, userID string) (domainuser.ErasureJob, error) {
+	requested, err := a.userService.RequestErasure(ctx, domainuser.RequestErasureInput{
+		UserID: userID,
+	})
+	if err != nil {
+		return domainuser.ErasureJob{}, err
+	}
+
+	output, err := a.userService.RunErasureJob(ctx, domainuser.RunErasureJobInput{
+		JobID: requested.Job.ID,
+	})
+	if err != nil {
+		return requested.Job, err
+	}
+
+	return output.Job, nil
+}
+
+func (a *eraseUserApp) GetJob(ctx context.Context, jobID string) (domainuser.ErasureJob, error) {
+	output, err := a.userService.GetErasureJob(ctx, domainuser.GetErasureJobInput{
+		JobID: jobID,
+	})
+	if err != nil {
+		return domainuser.ErasureJob{}, err
+	}
+
+	return output.Job, nil
+}
+
+func (a *eraseUserApp) Close() error {
+	errs := make([]error, 0, len(a.closeFn))
+	for _, fn := range a.closeFn {
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -5,11 +5,23 @@ import (
 	"errors"
 	"fmt"
 	"go-bootstrap/internal/config"
+	domainpolicy "go-bootstrap/internal/domain/policy"
+	"go-bootstrap/internal/gen/grpcgen/auth"
 	"go-bootstrap/internal/gen/grpcgen/healthcheck"
+	"go-bootstrap/internal/gen/grpcgen/user"
 	"go-bootstrap/internal/infrastructure"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+	authservice "go-bootstrap/internal/module/auth/service"
 	healthcheckrepository "go-bootstrap/internal/module/healthcheck/repository"
 	healthcheckservice "go-bootstrap/internal/module/healthcheck/service"
+	policyrepository "go-bootstrap/internal/module/policy/repository"
+	policyservice "go-bootstrap/internal/module/policy/service"
+	userrepository "go-bootstrap/internal/module/user/repository"
+	userservice "go-bootstrap/internal/module/user/service"
+	transportaudit "go-bootstrap/internal/transport/audit"
+	transportauth "go-bootstrap/internal/transport/auth"
 	transporthealthcheck "go-bootstrap/internal/transport/healthcheck"
+	transportuser "go-bootstrap/internal/transport/user"
 	"log"
 	"log/slog"
 	"net"
@@ -36,16 +48,20 @@ func NewGrpcApiApp(port int) *grpcApiApp {
 		log.Fatalf("gagal listen: %v", err)
 	}
 
-	s := grpc.NewServer()
-
 	grpcApp := &grpcApiApp{
 		port:     port,
 		listener: lis,
-		server:   s,
 		closeFn:  make([]func() error, 0),
 	}
 
-	grpcApp.init()
+	routerGrpc, interceptor := grpcApp.init()
+
+	grpcApp.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(transportaudit.RequestMetaUnary, interceptor.Unary),
+		grpc.ChainStreamInterceptor(transportaudit.RequestMetaStream, interceptor.Stream),
+	)
+	routerGrpc.init(grpcApp.server)
+	reflection.Register(grpcApp.server)
 
 	return grpcApp
 }
@@ -79,28 +95,134 @@ func (r *grpcApiApp) Start() {
 	}
 }
 
-func (r *grpcApiApp) init() {
+func (r *grpcApiApp) init() (routerGrpcApi, *transportauth.AuthGrpcInterceptor) {
 	db, err := infrastructure.NewDB()
 	if err != nil {
 		panic(err)
 	}
 	r.closeFn = append(r.closeFn, db.Close)
 
+	jwtKeySet, err := infrastructure.NewJwtKeySet()
+	if err != nil {
+		panic(err)
+	}
+
+	tokenHasher, err := infrastructure.NewTokenHasher()
+	if err != nil {
+		panic(err)
+	}
+
+	secretCipher, err := infrastructure.NewSecretCipher()
+	if err != nil {
+		panic(err)
+	}
+
+	passwordHasher, err := infrastructure.NewPasswordHasher()
+	if err != nil {
+		panic(err)
+	}
+
+	notifier, err := infrastructure.NewNotifier()
+	if err != nil {
+		panic(err)
+	}
+
 	healthcheckRepo := healthcheckrepository.NewRepository(db)
 	healthcheckService := healthcheckservice.NewService(healthcheckRepo)
 
+	loginAttemptRepo, lockoutPolicy := newLoginLockout(db)
+	dpopReplayRepo, dpopPolicy := newDpop(db, tokenHasher)
+	rolePolicy := infrastructure.NewRolePolicy()
+
+	authService := authservice.NewService(
+		authrepository.NewRepository(db, tokenHasher),
+		authrepository.NewJwtRepository(jwtKeySet),
+		authrepository.NewUserRepository(db),
+		loginAttemptRepo,
+		authrepository.NewMfaRepository(db, tokenHasher, secretCipher),
+		authrepository.NewTokenPolicyRepository(infrastructure.NewTokenPolicy()),
+		authrepository.NewIdentityRepository(db, tokenHasher, secretCipher),
+		newOidcRepository(),
+		authrepository.NewOauthClientRepository(infrastructure.NewOauthClients()),
+		authrepository.NewAuditLogRepository(db),
+		authrepository.NewMagicLinkRepository(db, tokenHasher),
+		authrepository.NewNotifierRepository(notifier),
+		dpopReplayRepo,
+		authrepository.NewRolePolicyRepository(rolePolicy),
+		passwordHasher,
+		lockoutPolicy,
+		newMfaPolicy(),
+		newMagicLinkPolicy(),
+		dpopPolicy,
+		newSessionLimitPolicy(),
+	)
+
+	policyService := policyservice.NewService(
+		policyrepository.NewRepository(rolePolicy),
+	)
+
+	userService := userservice.NewService(
+		userrepository.NewRepository(db),
+		userrepository.NewPasswordResetRepository(db, tokenHasher),
+		userrepository.NewEmailVerificationRepository(db, tokenHasher),
+		userrepository.NewAuthTokenRepository(db),
+		userrepository.NewNotifierRepository(notifier),
+		userrepository.NewAuditLogRepository(db),
+		userrepository.NewErasureRepository(db),
+		passwordHasher,
+		newPasswordResetPolicy(),
+		newEmailVerificationPolicy(),
+		newPasswordPolicy(),
+	)
+
 	routerGrpc := routerGrpcApi{
 		healthcheck: transporthealthcheck.NewGrpcHandler(healthcheckService),
+		auth:        transportauth.NewGrpcHandler(authService),
+		user:        transportuser.NewGrpcHandler(userService),
 	}
 
-	routerGrpc.init(r.server)
-	reflection.Register(r.server)
+	interceptor := transportauth.NewGrpcInterceptor(authService, policyService, grpcMethodPermissions)
+
+	return routerGrpc, interceptor
+}
+
+// grpcMethodPermissions lists the gRPC methods that require a bearer token,
+// keyed by full method name, with the permissions the caller's role needs.
+// Methods that are not listed are public, ApiV1ValidateToken among them since
+// it only answers for the token the caller sends.
+var grpcMethodPermissions = map[string][]domainpolicy.Permission{
+	auth.AuthService_ApiV1Logout_FullMethodName:                  {},
+	auth.AuthService_ApiV1RevokeToken_FullMethodName:             {domainpolicy.PermissionTokensRevoke},
+	auth.AuthService_ApiV1GetListLoginLockout_FullMethodName:     {domainpolicy.PermissionLockoutsList},
+	auth.AuthService_ApiV1ClearLoginLockout_FullMethodName:       {domainpolicy.PermissionLockoutsClear},
+	auth.AuthService_ApiV1Impersonate_FullMethodName:             {domainpolicy.PermissionUsersImpersonate},
+	auth.AuthService_ApiV1EnrollTotp_FullMethodName:              {},
+	auth.AuthService_ApiV1ConfirmTotp_FullMethodName:             {},
+	auth.AuthService_ApiV1DisableTotp_FullMethodName:             {},
+	auth.AuthService_ApiV1RegenerateRecoveryCodes_FullMethodName: {},
+	auth.AuthService_ApiV1GetListSession_FullMethodName:          {},
+	auth.AuthService_ApiV1RevokeSession_FullMethodName:           {},
+	auth.AuthService_ApiV1RevokeOtherSessions_FullMethodName:     {},
+	auth.AuthService_ApiV1RevokeAllSessions_FullMethodName:       {domainpolicy.PermissionSessionsRevoke},
+	auth.AuthService_ApiV1CreateApiKey_FullMethodName:            {domainpolicy.PermissionApiKeysManage},
+	auth.AuthService_ApiV1GetListApiKey_FullMethodName:           {domainpolicy.PermissionApiKeysManage},
+	auth.AuthService_ApiV1RotateApiKey_FullMethodName:            {domainpolicy.PermissionApiKeysManage},
+	auth.AuthService_ApiV1RevokeApiKey_FullMethodName:            {domainpolicy.PermissionApiKeysManage},
+	user.UserService_ApiV1GetProfile_FullMethodName:              {},
+	user.UserService_ApiV1GetListUsers_FullMethodName:            {domainpolicy.PermissionUsersList},
+	user.UserService_ApiV1UpdateProfile_FullMethodName:           {},
+	user.UserService_ApiV1ChangePassword_FullMethodName:          {},
+	user.UserService_ApiV1UpdateStatus_FullMethodName:            {domainpolicy.PermissionUsersUpdateStatus},
 }
 
 type routerGrpcApi struct {
 	healthcheck *transporthealthcheck.HealthCheckGrpcHandler
+	auth        *transportauth.AuthGrpcHandler
+	user        *transportuser.UserGrpcHandler
 }
 
 func (i *routerGrpcApi) init(s *grpc.Server) {
 	healthcheck.RegisterHealthCheckServiceServer(s, i.healthcheck)
+	auth.RegisterAuthServiceServer(s, i.auth)
+	user.RegisterUserServiceServer(s, i.user)
 }
@@ -5,14 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"go-bootstrap/internal/config"
+	domainaudit "go-bootstrap/internal/domain/audit"
 	"go-bootstrap/internal/gen/restapigen"
 	"go-bootstrap/internal/infrastructure"
+	auditrepository "go-bootstrap/internal/module/audit/repository"
+	auditservice "go-bootstrap/internal/module/audit/service"
 	authrepository "go-bootstrap/internal/module/auth/repository"
 	authservice "go-bootstrap/internal/module/auth/service"
 	healthcheckrepository "go-bootstrap/internal/module/healthcheck/repository"
 	healthcheckservice "go-bootstrap/internal/module/healthcheck/service"
+	policyrepository "go-bootstrap/internal/module/policy/repository"
+	policyservice "go-bootstrap/internal/module/policy/service"
 	userrepository "go-bootstrap/internal/module/user/repository"
 	userservice "go-bootstrap/internal/module/user/service"
+	transportaudit "go-bootstrap/internal/transport/audit"
 	transportauth "go-bootstrap/internal/transport/auth"
 	transporthealthcheck "go-bootstrap/internal/transport/healthcheck"
 	transportuser "go-bootstrap/internal/transport/user"
@@ -59,6 +65,13 @@ func NewRestApiApp(port int) *restApiApp {
 		UseOtel: appCfg.Gin.UseOtel,
 	})
 
+	// the client IP keys the login lockout and the API key allowlist, it may
+	// only come from a header set by a proxy we trust
+	err := ginEngine.SetTrustedProxies(appCfg.Gin.TrustedProxies)
+	if err != nil {
+		panic(err)
+	}
+
 	gin.SetMode(appCfg.Gin.Mode)
 	if appCfg.Gin.DisableConsoleColor {
 		gin.DisableConsoleColor()
@@ -70,7 +83,10 @@ func NewRestApiApp(port int) *restApiApp {
 		closeFn:   make([]func() error, 0),
 	}
 
-	restapigen.RegisterHandlers(ginEngine, restapiApp.init())
+	router, middlewares := restapiApp.init()
+	restapigen.RegisterHandlersWithOptions(ginEngine, router, restapigen.GinServerOptions{
+		Middlewares: middlewares,
+	})
 
 	restapiApp.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
@@ -109,35 +125,110 @@ func (r *restApiApp) Start() {
 	}
 }
 
-func (r *restApiApp) init() routerRestApi {
+func (r *restApiApp) init() (routerRestApi, []restapigen.MiddlewareFunc) {
 	db, err := infrastructure.NewDB()
 	if err != nil {
 		panic(err)
 	}
 	r.closeFn = append(r.closeFn, db.Close)
 
+	jwtKeySet, err := infrastructure.NewJwtKeySet()
+	if err != nil {
+		panic(err)
+	}
+
+	tokenHasher, err := infrastructure.NewTokenHasher()
+	if err != nil {
+		panic(err)
+	}
+
+	secretCipher, err := infrastructure.NewSecretCipher()
+	if err != nil {
+		panic(err)
+	}
+
+	passwordHasher, err := infrastructure.NewPasswordHasher()
+	if err != nil {
+		panic(err)
+	}
+
+	notifier, err := infrastructure.NewNotifier()
+	if err != nil {
+		panic(err)
+	}
+
 	ginHelper := ginx.NewGinHelper("message", "errors")
 
 	healthcheckService := healthcheckservice.NewService(
 		healthcheckrepository.NewRepository(db),
 	)
 
+	loginAttemptRepo, lockoutPolicy := newLoginLockout(db)
+	dpopReplayRepo, dpopPolicy := newDpop(db, tokenHasher)
+	rolePolicy := infrastructure.NewRolePolicy()
+
 	authService := authservice.NewService(
-		authrepository.NewRepository(db),
+		authrepository.NewRepository(db, tokenHasher),
+		authrepository.NewJwtRepository(jwtKeySet),
 		authrepository.NewUserRepository(db),
+		loginAttemptRepo,
+		authrepository.NewMfaRepository(db, tokenHasher, secretCipher),
+		authrepository.NewTokenPolicyRepository(infrastructure.NewTokenPolicy()),
+		authrepository.NewIdentityRepository(db, tokenHasher, secretCipher),
+		newOidcRepository(),
+		authrepository.NewOauthClientRepository(infrastructure.NewOauthClients()),
+		authrepository.NewAuditLogRepository(db),
+		authrepository.NewMagicLinkRepository(db, tokenHasher),
+		authrepository.NewNotifierRepository(notifier),
+		dpopReplayRepo,
+		authrepository.NewRolePolicyRepository(rolePolicy),
+		passwordHasher,
+		lockoutPolicy,
+		newMfaPolicy(),
+		newMagicLinkPolicy(),
+		dpopPolicy,
+		newSessionLimitPolicy(),
+	)
+
+	policyService := policyservice.NewService(
+		policyrepository.NewRepository(rolePolicy),
 	)
 
 	userService := userservice.NewService(
 		userrepository.NewRepository(db),
+		userrepository.NewPasswordResetRepository(db, tokenHasher),
+		userrepository.NewEmailVerificationRepository(db, tokenHasher),
+		userrepository.NewAuthTokenRepository(db),
+		userrepository.NewNotifierRepository(notifier),
+		userrepository.NewAuditLogRepository(db),
+		userrepository.NewErasureRepository(db),
+		passwordHasher,
+		newPasswordResetPolicy(),
+		newEmailVerificationPolicy(),
+		newPasswordPolicy(),
+	)
+
+	auditService := auditservice.NewService(
+		auditrepository.NewRepository(db),
+		domainaudit.RetentionPolicy{},
 	)
 
 	router := routerRestApi{
 		HealthCheckRestApiHandler: transporthealthcheck.NewRestApiHandler(healthcheckService),
 		AuthRestAPIHandler:        transportauth.NewRestAPIHandler(authService, ginHelper),
 		UserRestAPIHandler:        transportuser.NewRestAPIHandler(userService, ginHelper),
+		AuditRestAPIHandler:       transportaudit.NewRestAPIHandler(auditService, ginHelper),
+	}
+
+	authMiddleware := transportauth.NewRestAPIMiddleware(authService, policyService, ginHelper)
+	middlewares := []restapigen.MiddlewareFunc{
+		transportaudit.RequestMeta,
+		authMiddleware.OauthClientAuth,
+		authMiddleware.BearerAuth,
+		authMiddleware.RequirePermissions,
 	}
 
-	return router
+	return router, middlewares
 }
 
 type routerRestApi struct {
@@ -145,4 +236,5 @@ type routerRestApi struct {
 	*transporthealthcheck.HealthCheckRestApiHandler
 	*transportauth.AuthRestAPIHandler
 	*transportuser.UserRestAPIHandler
+	*transportaudit.AuditRestAPIHandler
 }
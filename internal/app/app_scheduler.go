@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"go-bootstrap/internal/config"
+	domainaudit "go-bootstrap/internal/domain/audit"
 	"go-bootstrap/internal/infrastructure"
+	auditrepository "go-bootstrap/internal/module/audit/repository"
+	auditservice "go-bootstrap/internal/module/audit/service"
 	healthcheckrepository "go-bootstrap/internal/module/healthcheck/repository"
 	healthcheckservice "go-bootstrap/internal/module/healthcheck/service"
+	workeraudit "go-bootstrap/internal/worker/audit"
 	workerhealthcheck "go-bootstrap/internal/worker/healthcheck"
+	workeruser "go-bootstrap/internal/worker/user"
 	"log/slog"
 	"time"
 
@@ -73,11 +78,21 @@ func (s *schedulerApp) init() {
 	healthcheckService := healthcheckservice.NewService(healthcheckRepo)
 	healthcheckWorker := workerhealthcheck.NewSchedulerHealthCheck(healthcheckService)
 
-	s.registerCronJobs(healthcheckWorker)
+	auditService := auditservice.NewService(
+		auditrepository.NewRepository(db),
+		domainaudit.RetentionPolicy{Retention: config.GetAuditLog().Retention},
+	)
+	auditRetentionWorker := workeraudit.NewSchedulerAuditRetention(auditService)
+
+	userErasureWorker := workeruser.NewSchedulerUserErasure(newJobUserService(db))
+
+	s.registerCronJobs(healthcheckWorker, auditRetentionWorker)
+	s.registerUserErasureJob(userErasureWorker)
 }
 
 func (s *schedulerApp) registerCronJobs(
 	healthcheckWorker *workerhealthcheck.SchedulerHealthCheck,
+	auditRetentionWorker *workeraudit.SchedulerAuditRetention,
 ) {
 	schedulerConfig := config.GetAppScheduler()
 
@@ -94,6 +109,46 @@ func (s *schedulerApp) registerCronJobs(
 	} else {
 		slog.Info("Registered CheckDependencies", "schedule", "every 5 minutes")
 	}
+
+	auditLogConfig := config.GetAuditLog()
+	if auditLogConfig.CleanupInterval == "" {
+		slog.Info("Audit log cleanup_interval is empty, DeleteExpiredAuditLogs not registered")
+		return
+	}
+	_, err = s.cron.AddFunc(auditLogConfig.CleanupInterval, func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Panic recovered in DeleteExpiredAuditLogs", "panic", r)
+			}
+		}()
+		auditRetentionWorker.DeleteExpiredAuditLogs()
+	})
+	if err != nil {
+		slog.Error("Failed to register DeleteExpiredAuditLogs", "error", err)
+	} else {
+		slog.Info("Registered DeleteExpiredAuditLogs", "schedule", auditLogConfig.CleanupInterval)
+	}
+}
+
+func (s *schedulerApp) registerUserErasureJob(userErasureWorker *workeruser.SchedulerUserErasure) {
+	userErasureConfig := config.GetUserErasure()
+	if userErasureConfig.Interval == "" {
+		slog.Info("User erasure interval is empty, RunErasureJobs not registered")
+		return
+	}
+	_, err := s.cron.AddFunc(userErasureConfig.Interval, func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Panic recovered in RunErasureJobs", "panic", r)
+			}
+		}()
+		userErasureWorker.RunErasureJobs()
+	})
+	if err != nil {
+		slog.Error("Failed to register RunErasureJobs", "error", err)
+	} else {
+		slog.Info("Registered RunErasureJobs", "schedule", userErasureConfig.Interval)
+	}
 }
 
 // WaitForNextRun blocks until the next scheduled job runs
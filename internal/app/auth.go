@@ -0,0 +1,107 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"go-bootstrap/internal/config"
+	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/infrastructure"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+)
+
+// newLoginLockout builds the login attempt store and lockout policy from config.GetLoginLockout().
+func newLoginLockout(db infrastructure.DB) (domainauth.AuthRepositoryLoginAttempt, domainauth.LoginLockoutPolicy) {
+	cfg := config.GetLoginLockout()
+
+	policy := domainauth.LoginLockoutPolicy{
+		MaxAttempts:       cfg.MaxAttempts,
+		Window:            cfg.Window,
+		BaseLockout:       cfg.BaseLockout,
+		MaxLockout:        cfg.MaxLockout,
+		LockoutResetAfter: cfg.LockoutResetAfter,
+	}
+
+	switch cfg.Store {
+	case "memory":
+		return authrepository.NewLoginAttemptMemoryRepository(), policy
+	case "", "sql":
+		return authrepository.NewLoginAttemptRepository(db), policy
+	default:
+		panic("unknown login_lockout.store " + cfg.Store)
+	}
+}
+
+// newMfaPolicy builds the TOTP second-step policy from config.GetMfa().
+func newMfaPolicy() domainauth.MfaPolicy {
+	cfg := config.GetMfa()
+
+	return domainauth.MfaPolicy{
+		Issuer:               cfg.Issuer,
+		ChallengeTTL:         cfg.ChallengeTTL,
+		ChallengeMaxAttempts: cfg.ChallengeMaxAttempts,
+	}
+}
+
+// newMagicLinkPolicy builds the passwordless sign-in link policy from config.GetMagicLink().
+func newMagicLinkPolicy() domainauth.MagicLinkPolicy {
+	cfg := config.GetMagicLink()
+
+	return domainauth.MagicLinkPolicy{
+		LinkURL:       cfg.LinkURL,
+		TokenTTL:      cfg.TokenTTL,
+		MaxRequests:   cfg.MaxRequests,
+		RequestWindow: cfg.RequestWindow,
+	}
+}
+
+// newDpop builds the DPoP replay cache and proof policy from config.GetDpop().
+func newDpop(db infrastructure.DB, tokenHasher *infrastructure.TokenHasher) (domainauth.AuthRepositoryDpopReplay, domainauth.DpopPolicy) {
+	cfg := config.GetDpop()
+
+	policy := domainauth.DpopPolicy{
+		ProofMaxAge: cfg.ProofMaxAge,
+		BaseURL:     cfg.BaseURL,
+	}
+
+	switch cfg.ReplayStore {
+	case "memory":
+		return authrepository.NewDpopReplayMemoryRepository(), policy
+	case "", "sql":
+		return authrepository.NewDpopReplayRepository(db, tokenHasher), policy
+	default:
+		panic("unknown dpop.replay_store " + cfg.ReplayStore)
+	}
+}
+
+// newSessionLimitPolicy builds the per-user session caps from config.GetSessionLimit().
+func newSessionLimitPolicy() domainauth.SessionLimitPolicy {
+	cfg := config.GetSessionLimit()
+
+	policy := domainauth.SessionLimitPolicy{
+		MaxSessions:         cfg.MaxSessions,
+		PlatformMaxSessions: make(map[string]int, len(cfg.Platforms)),
+		OnLimit:             domainauth.SessionLimitAction(cfg.OnLimit),
+	}
+	for _, platform := range cfg.Platforms {
+		policy.PlatformMaxSessions[strings.ToLower(platform.Platform)] = platform.MaxSessions
+	}
+
+	switch policy.OnLimit {
+	case "":
+		policy.OnLimit = domainauth.SessionLimitActionEvictOldest
+	case domainauth.SessionLimitActionEvictOldest, domainauth.SessionLimitActionReject:
+	default:
+		panic("unknown session_limit.on_limit " + cfg.OnLimit)
+	}
+
+	return policy
+}
+
+// newOidcRepository builds the OpenID Connect client for the providers in config.GetOidc().
+func newOidcRepository() domainauth.AuthRepositoryOidc {
+	return authrepository.NewOidcRepository(infrastructure.NewOidcProviders(), &http.Client{
+		Timeout: 10 * time.Second,
+	})
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"go-bootstrap/internal/config"
+	domainuser "go-bootstrap/internal/domain/user"
+	"go-bootstrap/internal/infrastructure"
+	userrepository "go-bootstrap/internal/module/user/repository"
+	userservice "go-bootstrap/internal/module/user/service"
+)
+
+// newPasswordResetPolicy builds the reset token policy from config.GetPasswordReset().
+func newPasswordResetPolicy() domainuser.PasswordResetPolicy {
+	cfg := config.GetPasswordReset()
+
+	return domainuser.PasswordResetPolicy{
+		LinkURL:  cfg.LinkURL,
+		TokenTTL: cfg.TokenTTL,
+	}
+}
+
+// newEmailVerificationPolicy builds the verification token policy from config.GetEmailVerification().
+func newEmailVerificationPolicy() domainuser.EmailVerificationPolicy {
+	cfg := config.GetEmailVerification()
+
+	return domainuser.EmailVerificationPolicy{
+		LinkURL:  cfg.LinkURL,
+		TokenTTL: cfg.TokenTTL,
+	}
+}
+
+// newPasswordPolicy builds the password policy from config.GetPasswordPolicy().
+func newPasswordPolicy() domainuser.PasswordPolicy {
+	cfg := config.GetPasswordPolicy()
+
+	return domainuser.PasswordPolicy{
+		MinLength:          cfg.MinLength,
+		MaxLength:          cfg.MaxLength,
+		RequireUpper:       cfg.RequireUpper,
+		RequireLower:       cfg.RequireLower,
+		RequireDigit:       cfg.RequireDigit,
+		RequireSymbol:      cfg.RequireSymbol,
+		MinStrengthScore:   cfg.MinStrengthScore,
+		RejectPersonalInfo: cfg.RejectPersonalInfo,
+		RejectList:         cfg.RejectList,
+	}
+}
+
+// newJobUserService builds the user service for the scheduler and the
+// erase-user command, which only run user jobs. Jobs send no links and check
+// no passwords, so the notifier only logs and the policies are left empty.
+func newJobUserService(db infrastructure.DB) domainuser.UserService {
+	tokenHasher, err := infrastructure.NewTokenHasher()
+	if err != nil {
+		panic(err)
+	}
+
+	passwordHasher, err := infrastructure.NewPasswordHasher()
+	if err != nil {
+		panic(err)
+	}
+
+	notifier, err := infrastructure.NewNotifierFromConfig(config.Notifier{})
+	if err != nil {
+		panic(err)
+	}
+
+	return userservice.NewService(
+		userrepository.NewRepository(db),
+		userrepository.NewPasswordResetRepository(db, tokenHasher),
+		userrepository.NewEmailVerificationRepository(db, tokenHasher),
+		userrepository.NewAuthTokenRepository(db),
+		userrepository.NewNotifierRepository(notifier),
+		userrepository.NewAuditLogRepository(db),
+		userrepository.NewErasureRepository(db),
+		passwordHasher,
+		domainuser.PasswordResetPolicy{},
+		domainuser.EmailVerificationPolicy{},
+		domainuser.PasswordPolicy{},
+	)
+}
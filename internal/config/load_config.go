@@ -13,7 +13,9 @@ var cmdName string
 func LoadConfig(cmd string) {
 	keyDebugMode := ""
 	switch cmd {
-	case "scheduler":
+	case "scheduler", "erase-user":
+		// erase-user runs a scheduler job once, with the scheduler config
+		cmd = "scheduler"
 		keyDebugMode = "app_scheduler.debug_mode"
 	case "restapi":
 		keyDebugMode = "app_rest_api.debug_mode"
@@ -85,6 +87,234 @@ func GetDatabase() Database {
 	}
 }
 
+func GetJwt() Jwt {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.Jwt
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.Jwt
+	default:
+		slog.Error("unknown cmd name for get jwt config")
+		return Jwt{}
+	}
+}
+
+func GetTokenHash() TokenHash {
+	switch cmdName {
+	case "scheduler":
+		return loader.Get().AppScheduler.TokenHash
+	case "restapi":
+		return loader.Get().AppRestApi.TokenHash
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.TokenHash
+	default:
+		slog.Error("unknown cmd name for get token hash config")
+		return TokenHash{}
+	}
+}
+
+func GetAuditLog() AuditLog {
+	switch cmdName {
+	case "scheduler":
+		return loader.Get().AppScheduler.AuditLog
+	default:
+		slog.Error("unknown cmd name for get audit log config")
+		return AuditLog{}
+	}
+}
+
+func GetUserErasure() UserErasure {
+	switch cmdName {
+	case "scheduler":
+		return loader.Get().AppScheduler.UserErasure
+	default:
+		slog.Error("unknown cmd name for get user erasure config")
+		return UserErasure{}
+	}
+}
+
+func GetPolicy() Policy {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.Policy
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.Policy
+	default:
+		slog.Error("unknown cmd name for get policy config")
+		return Policy{}
+	}
+}
+
+func GetLoginLockout() LoginLockout {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.LoginLockout
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.LoginLockout
+	default:
+		slog.Error("unknown cmd name for get login lockout config")
+		return LoginLockout{}
+	}
+}
+
+func GetNotifier() Notifier {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.Notifier
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.Notifier
+	default:
+		slog.Error("unknown cmd name for get notifier config")
+		return Notifier{}
+	}
+}
+
+func GetPasswordReset() PasswordReset {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.PasswordReset
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.PasswordReset
+	default:
+		slog.Error("unknown cmd name for get password reset config")
+		return PasswordReset{}
+	}
+}
+
+func GetPasswordPolicy() PasswordPolicy {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.PasswordPolicy
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.PasswordPolicy
+	default:
+		slog.Error("unknown cmd name for get password policy config")
+		return PasswordPolicy{}
+	}
+}
+
+func GetPasswordHash() PasswordHash {
+	switch cmdName {
+	case "scheduler":
+		return loader.Get().AppScheduler.PasswordHash
+	case "restapi":
+		return loader.Get().AppRestApi.PasswordHash
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.PasswordHash
+	default:
+		slog.Error("unknown cmd name for get password hash config")
+		return PasswordHash{}
+	}
+}
+
+func GetMagicLink() MagicLink {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.MagicLink
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.MagicLink
+	default:
+		slog.Error("unknown cmd name for get magic link config")
+		return MagicLink{}
+	}
+}
+
+func GetDpop() Dpop {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.Dpop
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.Dpop
+	default:
+		slog.Error("unknown cmd name for get dpop config")
+		return Dpop{}
+	}
+}
+
+func GetSessionLimit() SessionLimit {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.SessionLimit
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.SessionLimit
+	default:
+		slog.Error("unknown cmd name for get session limit config")
+		return SessionLimit{}
+	}
+}
+
+func GetEmailVerification() EmailVerification {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.EmailVerification
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.EmailVerification
+	default:
+		slog.Error("unknown cmd name for get email verification config")
+		return EmailVerification{}
+	}
+}
+
+func GetSecretEncryption() SecretEncryption {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.SecretEncryption
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.SecretEncryption
+	default:
+		slog.Error("unknown cmd name for get secret encryption config")
+		return SecretEncryption{}
+	}
+}
+
+func GetMfa() Mfa {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.Mfa
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.Mfa
+	default:
+		slog.Error("unknown cmd name for get mfa config")
+		return Mfa{}
+	}
+}
+
+func GetAuth() Auth {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.Auth
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.Auth
+	default:
+		slog.Error("unknown cmd name for get auth config")
+		return Auth{}
+	}
+}
+
+func GetOidc() Oidc {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.Oidc
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.Oidc
+	default:
+		slog.Error("unknown cmd name for get oidc config")
+		return Oidc{}
+	}
+}
+
+func GetOauth() Oauth {
+	switch cmdName {
+	case "restapi":
+		return loader.Get().AppRestApi.Oauth
+	case "grpcapi":
+		return loader.Get().AppGrpcApi.Oauth
+	default:
+		slog.Error("unknown cmd name for get oauth config")
+		return Oauth{}
+	}
+}
+
 func GetDebugMode() bool {
 	switch cmdName {
 	case "scheduler":
@@ -9,31 +9,69 @@ type root struct {
 }
 
 type AppRestApi struct {
-	Name      string   `env:"name"`
-	Env       string   `env:"env"`
-	DebugMode bool     `env:"debug_mode"`
-	Port      int      `env:"port"`
-	Gin       Gin      `env:"gin"`
-	Pprof     Pprof    `env:"pprof"`
-	Database  Database `env:"database"`
+	Name              string            `env:"name"`
+	Env               string            `env:"env"`
+	DebugMode         bool              `env:"debug_mode"`
+	Port              int               `env:"port"`
+	Gin               Gin               `env:"gin"`
+	Pprof             Pprof             `env:"pprof"`
+	Database          Database          `env:"database"`
+	Jwt               Jwt               `env:"jwt"`
+	TokenHash         TokenHash         `env:"token_hash"`
+	Policy            Policy            `env:"policy"`
+	LoginLockout      LoginLockout      `env:"login_lockout"`
+	Notifier          Notifier          `env:"notifier"`
+	PasswordReset     PasswordReset     `env:"password_reset"`
+	EmailVerification EmailVerification `env:"email_verification"`
+	SecretEncryption  SecretEncryption  `env:"secret_encryption"`
+	Mfa               Mfa               `env:"mfa"`
+	Auth              Auth              `env:"auth"`
+	Oidc              Oidc              `env:"oidc"`
+	Oauth             Oauth             `env:"oauth"`
+	PasswordPolicy    PasswordPolicy    `env:"password_policy"`
+	PasswordHash      PasswordHash      `env:"password_hash"`
+	MagicLink         MagicLink         `env:"magic_link"`
+	Dpop              Dpop              `env:"dpop"`
+	SessionLimit      SessionLimit      `env:"session_limit"`
 }
 
 type AppGrpcApi struct {
-	Name      string   `env:"name"`
-	Env       string   `env:"env"`
-	DebugMode bool     `env:"debug_mode"`
-	Port      int      `env:"port"`
-	Pprof     Pprof    `env:"pprof"`
-	Database  Database `env:"database"`
+	Name              string            `env:"name"`
+	Env               string            `env:"env"`
+	DebugMode         bool              `env:"debug_mode"`
+	Port              int               `env:"port"`
+	Pprof             Pprof             `env:"pprof"`
+	Database          Database          `env:"database"`
+	Jwt               Jwt               `env:"jwt"`
+	TokenHash         TokenHash         `env:"token_hash"`
+	Policy            Policy            `env:"policy"`
+	LoginLockout      LoginLockout      `env:"login_lockout"`
+	Notifier          Notifier          `env:"notifier"`
+	PasswordReset     PasswordReset     `env:"password_reset"`
+	EmailVerification EmailVerification `env:"email_verification"`
+	SecretEncryption  SecretEncryption  `env:"secret_encryption"`
+	Mfa               Mfa               `env:"mfa"`
+	Auth              Auth              `env:"auth"`
+	Oidc              Oidc              `env:"oidc"`
+	Oauth             Oauth             `env:"oauth"`
+	PasswordPolicy    PasswordPolicy    `env:"password_policy"`
+	PasswordHash      PasswordHash      `env:"password_hash"`
+	MagicLink         MagicLink         `env:"magic_link"`
+	Dpop              Dpop              `env:"dpop"`
+	SessionLimit      SessionLimit      `env:"session_limit"`
 }
 
 type AppScheduler struct {
-	Name                string   `env:"name"`
-	Env                 string   `env:"env"`
-	DebugMode           bool     `env:"debug_mode"`
-	HealthCheckInterval string   `env:"healthcheck_interval"`
-	Pprof               Pprof    `env:"pprof"`
-	Database            Database `env:"database"`
+	Name                string       `env:"name"`
+	Env                 string       `env:"env"`
+	DebugMode           bool         `env:"debug_mode"`
+	HealthCheckInterval string       `env:"healthcheck_interval"`
+	Pprof               Pprof        `env:"pprof"`
+	Database            Database     `env:"database"`
+	TokenHash           TokenHash    `env:"token_hash"`
+	PasswordHash        PasswordHash `env:"password_hash"`
+	AuditLog            AuditLog     `env:"audit_log"`
+	UserErasure         UserErasure  `env:"user_erasure"`
 }
 
 type Pprof struct {
@@ -50,6 +88,238 @@ type Database struct {
 	ConnMaxIdleTime time.Duration `env:"conn_max_idle_time"`
 }
 
+// Jwt configures signing and verification of access tokens.
+// The key referenced by ActiveKeyID signs new tokens, every other key
+// stays valid for verification so keys can be rotated without downtime.
+type Jwt struct {
+	Issuer      string   `env:"issuer"`
+	Audience    []string `env:"audience"`
+	ActiveKeyID string   `env:"active_key_id"`
+	Keys        []JwtKey `env:"keys"`
+}
+
+// JwtKey is a single signing key. Algorithm is one of HS256, RS256 or EdDSA.
+// HS256 keys use Secret, RS256 and EdDSA keys use PEM encoded keys; a key
+// with only PublicKey set can verify but never sign.
+type JwtKey struct {
+	ID         string `env:"id"`
+	Algorithm  string `env:"algorithm"`
+	Secret     string `env:"secret"`
+	PrivateKey string `env:"private_key"`
+	PublicKey  string `env:"public_key"`
+}
+
+// TokenHash configures how opaque tokens are stored in auth_tokens.
+// Tokens are kept as HMAC-SHA256(token, Pepper); changing Pepper
+// invalidates every stored token.
+type TokenHash struct {
+	Pepper string `env:"pepper"`
+}
+
+// Policy maps roles to the permissions they are granted, e.g. "users:list".
+// The permission "*" grants everything. A role that is not listed has no
+// permissions.
+type Policy struct {
+	Roles []PolicyRole `env:"roles"`
+}
+
+type PolicyRole struct {
+	Role        string   `env:"role"`
+	Permissions []string `env:"permissions"`
+}
+
+// LoginLockout configures brute-force protection on login. Failed attempts
+// are counted per email and per client IP; MaxAttempts failures within Window
+// lock the key for BaseLockout, doubling on every further lockout up to
+// MaxLockout. The doubling restarts after LockoutResetAfter without failures
+// (0 never restarts it). MaxAttempts 0 disables the lockout.
+// Store is "sql" (default, shared by every replica) or "memory".
+type LoginLockout struct {
+	MaxAttempts       int64         `env:"max_attempts"`
+	Window            time.Duration `env:"window"`
+	BaseLockout       time.Duration `env:"base_lockout"`
+	MaxLockout        time.Duration `env:"max_lockout"`
+	LockoutResetAfter time.Duration `env:"lockout_reset_after"`
+	Store             string        `env:"store"`
+}
+
+// Notifier configures how messages for users (e.g. password reset links) are
+// delivered. Driver is "log" (default, written to the application log) or
+// "file" (appended as JSON lines to FilePath); both are meant for local
+// development until a real delivery channel is plugged in.
+type Notifier struct {
+	Driver   string `env:"driver"`
+	FilePath string `env:"file_path"`
+}
+
+// PasswordReset configures the forgot-password flow. The reset token is
+// appended to LinkURL as the "token" query parameter and expires after TokenTTL.
+type PasswordReset struct {
+	LinkURL  string        `env:"link_url"`
+	TokenTTL time.Duration `env:"token_ttl"`
+}
+
+// MagicLink configures passwordless sign-in links. The token is appended to
+// LinkURL as the "token" query parameter and expires after TokenTTL. A user
+// gets at most MaxRequests links per RequestWindow (0 disables the limit),
+// further requests are dropped without telling the caller.
+type MagicLink struct {
+	LinkURL       string        `env:"link_url"`
+	TokenTTL      time.Duration `env:"token_ttl"`
+	MaxRequests   int64         `env:"max_requests"`
+	RequestWindow time.Duration `env:"request_window"`
+}
+
+// Dpop configures DPoP sender-constrained tokens (RFC 9449). A proof is
+// accepted while its iat is within ProofMaxAge of the server clock (default
+// 1m). BaseURL is the public origin the API is served on, e.g.
+// "https://api.example.com"; set it behind a proxy that terminates TLS or
+// rewrites the host, it replaces scheme and host of the request when checking
+// htu. ReplayStore is "sql" (default, shared by every replica) or "memory".
+type Dpop struct {
+	ProofMaxAge time.Duration `env:"proof_max_age"`
+	BaseURL     string        `env:"base_url"`
+	ReplayStore string        `env:"replay_store"`
+}
+
+// SessionLimit caps the active sessions of a user, checked on every login.
+// MaxSessions counts every session of the user, Platforms caps the sessions
+// per x-platform value; 0 or an unlisted platform means no cap. OnLimit is
+// "evict_oldest" (default) to revoke the oldest sessions over a cap or
+// "reject" to refuse the login.
+type SessionLimit struct {
+	MaxSessions int                    `env:"max_sessions"`
+	OnLimit     string                 `env:"on_limit"`
+	Platforms   []SessionLimitPlatform `env:"platforms"`
+}
+
+type SessionLimitPlatform struct {
+	Platform    string `env:"platform"`
+	MaxSessions int    `env:"max_sessions"`
+}
+
+// PasswordPolicy configures which passwords are accepted on registration,
+// password change and reset. MinLength defaults to 8 and MaxLength 0 only
+// keeps bcrypt's 72 byte limit. MinStrengthScore is the lowest zxcvbn score
+// (0-4, 0 disables the check). RejectPersonalInfo refuses passwords that
+// contain the email or name of the user, RejectList refuses the listed
+// passwords regardless of case.
+type PasswordPolicy struct {
+	MinLength          int      `env:"min_length"`
+	MaxLength          int      `env:"max_length"`
+	RequireUpper       bool     `env:"require_upper"`
+	RequireLower       bool     `env:"require_lower"`
+	RequireDigit       bool     `env:"require_digit"`
+	RequireSymbol      bool     `env:"require_symbol"`
+	MinStrengthScore   int      `env:"min_strength_score"`
+	RejectPersonalInfo bool     `env:"reject_personal_info"`
+	RejectList         []string `env:"reject_list"`
+}
+
+// PasswordHash configures how passwords are stored. Algorithm is "argon2id"
+// (default) or "bcrypt"; hashes of either algorithm are still verified, and a
+// hash with another algorithm or outdated parameters is replaced on the next
+// successful login. BcryptCost defaults to 10.
+type PasswordHash struct {
+	Algorithm  string   `env:"algorithm"`
+	BcryptCost int      `env:"bcrypt_cost"`
+	Argon2id   Argon2id `env:"argon2id"`
+}
+
+// Argon2id holds the argon2id parameters, Memory is in KiB. Zero values fall
+// back to 19456 KiB memory, 2 iterations, parallelism 1, a 16 byte salt and a
+// 32 byte key.
+type Argon2id struct {
+	Memory      uint32 `env:"memory"`
+	Iterations  uint32 `env:"iterations"`
+	Parallelism uint8  `env:"parallelism"`
+	SaltLength  uint32 `env:"salt_length"`
+	KeyLength   uint32 `env:"key_length"`
+}
+
+// EmailVerification configures the link sent after registration. The token
+// is appended to LinkURL as the "token" query parameter and expires after
+// TokenTTL.
+type EmailVerification struct {
+	LinkURL  string        `env:"link_url"`
+	TokenTTL time.Duration `env:"token_ttl"`
+}
+
+// SecretEncryption configures encryption at rest for secrets the
+// application must read back, such as TOTP secrets. Key is a base64 encoded
+// 32 byte AES-256 key; changing it makes every stored secret unreadable.
+type SecretEncryption struct {
+	Key string `env:"key"`
+}
+
+// Mfa configures TOTP two-factor authentication. Issuer is shown by
+// authenticator apps. A login that needs a second factor gets a challenge
+// valid for ChallengeTTL and ChallengeMaxAttempts wrong codes.
+type Mfa struct {
+	Issuer               string        `env:"issuer"`
+	ChallengeTTL         time.Duration `env:"challenge_ttl"`
+	ChallengeMaxAttempts int64         `env:"challenge_max_attempts"`
+}
+
+// Auth configures token lifetimes and session timeouts, it is re-read on
+// every use so changes apply without a restart. A session ends
+// SessionAbsoluteTimeout after login and SessionIdleTimeout after its last
+// refresh; 0 disables either timeout. Expired tokens are deleted by the
+// cleanup worker ExpiredTokenGrace after they expire. Roles override the
+// lifetimes per user role, a zero field keeps the top-level value.
+// ImpersonationTokenTTL is the lifetime of an impersonation token, 15m when
+// zero.
+type Auth struct {
+	AccessTokenTTL         time.Duration `env:"access_token_ttl"`
+	RefreshTokenTTL        time.Duration `env:"refresh_token_ttl"`
+	SessionAbsoluteTimeout time.Duration `env:"session_absolute_timeout"`
+	SessionIdleTimeout     time.Duration `env:"session_idle_timeout"`
+	ExpiredTokenGrace      time.Duration `env:"expired_token_grace"`
+	ImpersonationTokenTTL  time.Duration `env:"impersonation_token_ttl"`
+	Roles                  []AuthRole    `env:"roles"`
+}
+
+type AuthRole struct {
+	Role                   string        `env:"role"`
+	AccessTokenTTL         time.Duration `env:"access_token_ttl"`
+	RefreshTokenTTL        time.Duration `env:"refresh_token_ttl"`
+	SessionAbsoluteTimeout time.Duration `env:"session_absolute_timeout"`
+	SessionIdleTimeout     time.Duration `env:"session_idle_timeout"`
+}
+
+// Oidc configures sign-in through external OpenID Connect providers with
+// the authorization-code flow and PKCE. Endpoints are discovered from
+// Issuer + "/.well-known/openid-configuration".
+type Oidc struct {
+	Providers []OidcProvider `env:"providers"`
+}
+
+// OidcProvider is one registered client at an OpenID Connect provider. Name
+// is used in the login URLs, RedirectURL must point at the callback endpoint
+// of the provider and be registered there. The "openid" scope is always
+// requested, Scopes defaults to "openid", "email" and "profile".
+type OidcProvider struct {
+	Name         string   `env:"name"`
+	Issuer       string   `env:"issuer"`
+	ClientID     string   `env:"client_id"`
+	ClientSecret string   `env:"client_secret"`
+	RedirectURL  string   `env:"redirect_url"`
+	Scopes       []string `env:"scopes"`
+}
+
+// Oauth configures the clients allowed on the token introspection (RFC 7662)
+// and revocation (RFC 7009) endpoints, such as an API gateway. A client
+// authenticates with HTTP Basic, ClientID and ClientSecret, and may
+// introspect and revoke any token.
+type Oauth struct {
+	Clients []OauthClient `env:"clients"`
+}
+
+type OauthClient struct {
+	ClientID     string `env:"client_id"`
+	ClientSecret string `env:"client_secret"`
+}
+
 type Gin struct {
 	Mode                 string  `env:"mode"`
 	DisableConsoleColor  bool    `env:"disable_console_color"`
@@ -57,6 +327,10 @@ type Gin struct {
 	DisableErrorWriter   bool    `env:"disable_error_writer"`
 	Cors                 GinCors `env:"cors"`
 	UseOtel              bool    `env:"use_otel"`
+	// TrustedProxies are the IPs or CIDRs of the proxies whose
+	// X-Forwarded-For and X-Real-IP headers name the client. Empty trusts no
+	// proxy, the client IP is the socket peer.
+	TrustedProxies []string `env:"trusted_proxies"`
 }
 
 type GinCors struct {
@@ -67,3 +341,18 @@ type GinCors struct {
 	ExposeHeaders    []string `env:"expose_headers"`
 	MaxAge           int      `env:"max_age"`
 }
+
+// AuditLog configures the retention of the security audit log. The scheduler
+// deletes entries older than Retention on the CleanupInterval cron schedule;
+// Retention 0 keeps every entry.
+type AuditLog struct {
+	Retention       time.Duration `env:"retention"`
+	CleanupInterval string        `env:"cleanup_interval"`
+}
+
+// UserErasure configures the scheduler job that carries out pending user
+// erasure jobs on the Interval cron schedule; an empty Interval leaves them
+// to the erase-user command.
+type UserErasure struct {
+	Interval string `env:"interval"`
+}
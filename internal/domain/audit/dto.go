@@ -0,0 +1,29 @@
+package domainaudit
+
+import (
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	"time"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
+)
+
+// GetListAuditLogInput filters the audit log, every filter is optional.
+// UserID matches either the actor or the target. From is inclusive, To is
+// exclusive.
+type GetListAuditLogInput struct {
+	Pagination   primitive.PaginationInput
+	Action       *sharedkernel.AuditAction
+	Outcome      *sharedkernel.AuditOutcome
+	UserID       *string
+	ActorUserID  *string
+	TargetUserID *string
+	IPAddress    *string
+	RequestID    *string
+	From         *time.Time
+	To           *time.Time
+}
+
+type GetListAuditLogOutput struct {
+	AuditLogs  []AuditLog
+	Pagination primitive.PaginationOutput
+}
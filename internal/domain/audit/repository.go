@@ -0,0 +1,46 @@
+//go:generate go tool mockgen -source=repository.go -destination=../../gen/mockgen/audit_repository_mock.gen.go -package=mockgen
+
+package domainaudit
+
+import (
+	"context"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	"time"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
+)
+
+// AuditRepositoryDatastore reads and prunes the audit log. Entries are
+// written by the modules that perform the actions.
+type AuditRepositoryDatastore interface {
+	GetListAuditLog(ctx context.Context, filters GetListAuditLogFilters) (GetListAuditLogResult, error)
+
+	DeleteAuditLogs(ctx context.Context, params DeleteAuditLogsParams) (DeleteAuditLogsResult, error)
+}
+
+type GetListAuditLogFilters struct {
+	Pagination   primitive.PaginationInput
+	Action       *sharedkernel.AuditAction
+	Outcome      *sharedkernel.AuditOutcome
+	UserID       *string
+	ActorUserID  *string
+	TargetUserID *string
+	IPAddress    *string
+	RequestID    *string
+	From         *time.Time
+	To           *time.Time
+}
+
+type GetListAuditLogResult struct {
+	AuditLogs  []AuditLog
+	Pagination primitive.PaginationOutput
+}
+
+// DeleteAuditLogsParams deletes the entries created before BeforeDate.
+type DeleteAuditLogsParams struct {
+	BeforeDate time.Time
+}
+
+type DeleteAuditLogsResult struct {
+	DeletedCount int64
+}
@@ -0,0 +1,14 @@
+//go:generate go tool mockgen -source=service.go -destination=../../gen/mockgen/audit_service_mock.gen.go -package=mockgen
+
+package domainaudit
+
+import "context"
+
+type AuditService interface {
+	// GetListAuditLog returns the audit log newest first.
+	GetListAuditLog(ctx context.Context, input GetListAuditLogInput) (GetListAuditLogOutput, error)
+
+	// WorkerDeleteExpiredAuditLogs deletes the entries that are older than
+	// the retention policy allows.
+	WorkerDeleteExpiredAuditLogs(ctx context.Context)
+}
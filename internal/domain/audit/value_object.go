@@ -0,0 +1,18 @@
+package domainaudit
+
+import (
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	"time"
+)
+
+type AuditLog struct {
+	ID string
+	sharedkernel.AuditEntry
+}
+
+// RetentionPolicy controls how long audit log entries are kept. Entries older
+// than Retention are deleted by the retention worker, Retention <= 0 keeps
+// them forever.
+type RetentionPolicy struct {
+	Retention time.Duration
+}
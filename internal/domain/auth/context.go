@@ -0,0 +1,41 @@
+package domainauth
+
+import (
+	"context"
+
+	sharedkernel "go-bootstrap/internal/domain/shared"
+)
+
+type tokenPayloadContextKey struct{}
+
+type oauthClientContextKey struct{}
+
+// WithTokenPayload returns a copy of ctx carrying the authenticated caller.
+// An impersonation token also marks ctx with sharedkernel.WithImpersonation,
+// so modules that do not know the payload can still tell.
+func WithTokenPayload(ctx context.Context, payload TokenPayload) context.Context {
+	if payload.Impersonated() {
+		ctx = sharedkernel.WithImpersonation(ctx, sharedkernel.Impersonation{
+			ActorUserID: payload.ImpersonatorID,
+			UserID:      payload.UserID,
+		})
+	}
+	return context.WithValue(ctx, tokenPayloadContextKey{}, payload)
+}
+
+// TokenPayloadFromContext returns the authenticated caller stored by WithTokenPayload.
+func TokenPayloadFromContext(ctx context.Context) (TokenPayload, bool) {
+	payload, ok := ctx.Value(tokenPayloadContextKey{}).(TokenPayload)
+	return payload, ok
+}
+
+// WithOauthClient returns a copy of ctx carrying the authenticated OAuth client.
+func WithOauthClient(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, oauthClientContextKey{}, clientID)
+}
+
+// OauthClientFromContext returns the client id stored by WithOauthClient.
+func OauthClientFromContext(ctx context.Context) (string, bool) {
+	clientID, ok := ctx.Value(oauthClientContextKey{}).(string)
+	return clientID, ok
+}
@@ -1,21 +1,48 @@
 package domainauth
 
-import "time"
+import (
+	"time"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
+)
 
 type LoginInput struct {
-	Email    string
-	Password string
+	Email     string
+	Password  string
+	IPAddress string
+	UserAgent string
+	Platform  string
+	Dpop      *DpopProof // binds the tokens to the proof key, nil for bearer tokens
 }
 
+// LoginOutput carries either the tokens or, when MfaRequired is set, only
+// the challenge to pass to LoginMfa. PasswordChangeRequired tells that the
+// tokens are only accepted to change the temporary password of the user.
 type LoginOutput struct {
-	AccessToken  string
-	RefreshToken string
-	ExpiresIn    int64 // in seconds
-	TokenType    string
+	AccessToken            string
+	RefreshToken           string
+	ExpiresIn              int64 // in seconds
+	TokenType              string
+	PasswordChangeRequired bool
+
+	MfaRequired           bool
+	MfaChallenge          string
+	MfaChallengeExpiresIn int64 // in seconds
+}
+
+type LoginMfaInput struct {
+	Challenge string
+	Code      string // TOTP code or recovery code
+	IPAddress string
+	UserAgent string
+	Platform  string
+	Dpop      *DpopProof // see LoginInput
 }
 
 type RefreshTokenInput struct {
 	RefreshToken string
+	IPAddress    string
+	Dpop         *DpopProof // required when the refresh token is DPoP-bound
 }
 
 type RefreshTokenOutput struct {
@@ -35,8 +62,13 @@ type LogoutOutput struct {
 	Message string
 }
 
+// ValidateTokenInput takes an access token or an API key. IPAddress is the
+// client IP, checked against the allowlist of an API key.
 type ValidateTokenInput struct {
-	Token string
+	Token     string
+	IPAddress string
+	Operation string     // route or gRPC method the token is used for, audited for impersonation tokens
+	Dpop      *DpopProof // set when the token was sent with the DPoP scheme
 }
 
 type ValidateTokenOutput struct {
@@ -45,11 +77,231 @@ type ValidateTokenOutput struct {
 	ExpiresAt time.Time
 }
 
+// RevokeTokenInput is an RFC 7009 revocation request. TokenTypeHint is
+// "access_token" or "refresh_token"; it is only a hint, every kind of token
+// is recognised.
 type RevokeTokenInput struct {
-	Token string
+	Token         string
+	TokenTypeHint string
 }
 
 type RevokeTokenOutput struct {
 	Success bool
 	Message string
 }
+
+type GetJwksOutput struct {
+	Keys []Jwk
+}
+
+type GetListLoginLockoutInput struct {
+	Pagination primitive.PaginationInput
+}
+
+type GetListLoginLockoutOutput struct {
+	Lockouts   []LoginLockout
+	Pagination primitive.PaginationOutput
+}
+
+type ClearLoginLockoutInput struct {
+	Scope      LoginAttemptScope
+	Identifier string
+}
+
+type ClearLoginLockoutOutput struct {
+	Success bool
+}
+
+type ImpersonateInput struct {
+	ActorUserID  string
+	TargetUserID string
+	Reason       string
+	IPAddress    string
+	UserAgent    string
+	Platform     string
+}
+
+// ImpersonateOutput carries an access token only, it cannot be refreshed.
+type ImpersonateOutput struct {
+	AccessToken string
+	ExpiresIn   int64 // in seconds
+	TokenType   string
+}
+
+type EnrollTotpInput struct {
+	UserID string
+}
+
+type EnrollTotpOutput struct {
+	Secret string // base32
+	URI    string // otpauth://
+}
+
+type ConfirmTotpInput struct {
+	UserID string
+	Code   string
+}
+
+type ConfirmTotpOutput struct {
+	RecoveryCodes []string
+}
+
+// DisableTotpInput needs a current TOTP code or an unused recovery code.
+type DisableTotpInput struct {
+	UserID string
+	Code   string
+}
+
+type DisableTotpOutput struct {
+	Success bool
+}
+
+// RegenerateRecoveryCodesInput needs a current TOTP code or an unused recovery code.
+type RegenerateRecoveryCodesInput struct {
+	UserID string
+	Code   string
+}
+
+type RegenerateRecoveryCodesOutput struct {
+	RecoveryCodes []string
+}
+
+type GetListSessionInput struct {
+	UserID string
+}
+
+type GetListSessionOutput struct {
+	Sessions []Session
+}
+
+type RevokeSessionInput struct {
+	UserID    string
+	SessionID string
+}
+
+type RevokeSessionOutput struct {
+	Success bool
+}
+
+// RevokeOtherSessionsInput keeps CurrentSessionID, the session of the caller.
+type RevokeOtherSessionsInput struct {
+	UserID           string
+	CurrentSessionID string
+}
+
+type RevokeOtherSessionsOutput struct {
+	RevokedCount int64
+}
+
+type RevokeAllSessionsInput struct {
+	UserID string
+}
+
+type RevokeAllSessionsOutput struct {
+	RevokedCount int64
+}
+
+// CreateApiKeyInput creates a key owned by UserID. A nil ExpiresAt never expires.
+type CreateApiKeyInput struct {
+	UserID     string
+	Name       string
+	Scopes     []string
+	AllowedIPs []string
+	ExpiresAt  *time.Time
+}
+
+// CreateApiKeyOutput carries the key, it cannot be read back later.
+type CreateApiKeyOutput struct {
+	Key    string
+	ApiKey ApiKey
+}
+
+type GetListApiKeyInput struct {
+	UserID string
+}
+
+type GetListApiKeyOutput struct {
+	ApiKeys []ApiKey
+}
+
+type RotateApiKeyInput struct {
+	UserID   string
+	ApiKeyID string
+}
+
+type RotateApiKeyOutput struct {
+	Key    string
+	ApiKey ApiKey
+}
+
+type RevokeApiKeyInput struct {
+	UserID   string
+	ApiKeyID string
+}
+
+type RevokeApiKeyOutput struct {
+	Success bool
+}
+
+type StartOidcLoginInput struct {
+	Provider string
+}
+
+// StartOidcLoginOutput is where to send the browser. State comes back on the
+// callback and is valid for ExpiresIn seconds.
+type StartOidcLoginOutput struct {
+	AuthorizationUrl string
+	State            string
+	ExpiresIn        int64 // in seconds
+}
+
+// LoginOidcInput is the callback of the provider after the user signed in.
+type LoginOidcInput struct {
+	Provider  string
+	Code      string
+	State     string
+	IPAddress string
+	UserAgent string
+	Platform  string
+}
+
+type RequestMagicLinkInput struct {
+	Email     string
+	IPAddress string
+}
+
+type RequestMagicLinkOutput struct{}
+
+// LoginMagicLinkInput carries the token of a sign-in link.
+type LoginMagicLinkInput struct {
+	Token     string
+	IPAddress string
+	UserAgent string
+	Platform  string
+	Dpop      *DpopProof // see LoginInput
+}
+
+// IntrospectTokenInput is an RFC 7662 introspection request. IPAddress is
+// the client the token was presented by, checked against the allowlist of an
+// API key.
+type IntrospectTokenInput struct {
+	Token         string
+	TokenTypeHint string
+	IPAddress     string
+}
+
+// IntrospectTokenOutput describes an active token through Payload, whose
+// TokenType tells access tokens, refresh tokens and API keys apart.
+type IntrospectTokenOutput struct {
+	Active  bool
+	Payload *TokenPayload
+}
+
+type AuthenticateOauthClientInput struct {
+	ClientID     string
+	ClientSecret string
+}
+
+type AuthenticateOauthClientOutput struct {
+	ClientID string
+}
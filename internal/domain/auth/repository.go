@@ -6,6 +6,8 @@ import (
 	"context"
 	sharedkernel "go-bootstrap/internal/domain/shared"
 	"time"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
 )
 
 type AuthRepositoryDatastore interface {
@@ -15,11 +17,208 @@ type AuthRepositoryDatastore interface {
 
 	RevokeToken(ctx context.Context, params RevokeTokenParams) (RevokeTokenResult, error)
 
+	RotateToken(ctx context.Context, params RotateTokenParams) (RotateTokenResult, error)
+
+	// RevokeTokenFamily revokes every token of the family and ends its session.
+	RevokeTokenFamily(ctx context.Context, params RevokeTokenFamilyParams) (RevokeTokenFamilyResult, error)
+
 	DeleteExpiredTokens(ctx context.Context, params DeleteExpiredTokensParams) (DeleteExpiredTokensResult, error)
+
+	CreateSession(ctx context.Context, params CreateSessionParams) (CreateSessionResult, error)
+
+	GetDetailSession(ctx context.Context, filters GetDetailSessionFilters) (GetDetailSessionResult, error)
+
+	// GetListSession returns the sessions of a user that are neither revoked
+	// nor expired, most recently used first.
+	GetListSession(ctx context.Context, filters GetListSessionFilters) (GetListSessionResult, error)
+
+	TouchSession(ctx context.Context, params TouchSessionParams) (TouchSessionResult, error)
+
+	// RevokeSessions ends the matching sessions of a user and revokes their
+	// tokens in one transaction.
+	RevokeSessions(ctx context.Context, params RevokeSessionsParams) (RevokeSessionsResult, error)
+
+	DeleteExpiredSessions(ctx context.Context, params DeleteExpiredSessionsParams) (DeleteExpiredSessionsResult, error)
+
+	CreateApiKey(ctx context.Context, params CreateApiKeyParams) (CreateApiKeyResult, error)
+
+	// GetDetailApiKey finds a key by id or by the key itself, revoked and
+	// expired keys included.
+	GetDetailApiKey(ctx context.Context, filters GetDetailApiKeyFilters) (GetDetailApiKeyResult, error)
+
+	// GetListApiKey returns the keys of a user that are not revoked, newest first.
+	GetListApiKey(ctx context.Context, filters GetListApiKeyFilters) (GetListApiKeyResult, error)
+
+	// RotateApiKey replaces the key of a key that is not revoked, the old key
+	// stops working at once.
+	RotateApiKey(ctx context.Context, params RotateApiKeyParams) (RotateApiKeyResult, error)
+
+	RevokeApiKey(ctx context.Context, params RevokeApiKeyParams) (RevokeApiKeyResult, error)
+
+	TouchApiKey(ctx context.Context, params TouchApiKeyParams) (TouchApiKeyResult, error)
+}
+
+type AuthRepositoryJwt interface {
+	CreateAccessToken(ctx context.Context, params CreateAccessTokenParams) (CreateAccessTokenResult, error)
+
+	ParseAccessToken(ctx context.Context, params ParseAccessTokenParams) (ParseAccessTokenResult, error)
+
+	GetJwks(ctx context.Context) (GetJwksResult, error)
+
+	// ParseDpopProof verifies a DPoP proof against the public key in its
+	// header. Only the presence of the claims is checked, not their values.
+	ParseDpopProof(ctx context.Context, params ParseDpopProofParams) (ParseDpopProofResult, error)
+}
+
+// AuthRepositoryDpopReplay remembers the jti of accepted DPoP proofs until
+// they expire, so a proof is accepted only once.
+type AuthRepositoryDpopReplay interface {
+	// UseDpopJti records the jti of a proof key. Success is false when the key
+	// already used the jti, concurrent calls succeed at most once.
+	UseDpopJti(ctx context.Context, params UseDpopJtiParams) (UseDpopJtiResult, error)
+
+	DeleteExpiredDpopJtis(ctx context.Context, params DeleteExpiredDpopJtisParams) (DeleteExpiredDpopJtisResult, error)
+}
+
+// AuthRepositoryLoginAttempt tracks failed logins per LoginAttemptScope.
+// Implementations must update counters atomically, concurrent failures for
+// the same key must never be lost.
+type AuthRepositoryLoginAttempt interface {
+	GetDetailLoginAttempt(ctx context.Context, filters GetDetailLoginAttemptFilters) (GetDetailLoginAttemptResult, error)
+
+	GetListLoginAttempt(ctx context.Context, filters GetListLoginAttemptFilters) (GetListLoginAttemptResult, error)
+
+	// IncrementLoginFailure counts one failed attempt. The count restarts at 1
+	// when the previous failure is older than WindowStart, and LockoutCount
+	// restarts at 0 when it is older than LockoutResetStart.
+	IncrementLoginFailure(ctx context.Context, params IncrementLoginFailureParams) (IncrementLoginFailureResult, error)
+
+	// LockLoginAttempt sets LockedUntil, bumps LockoutCount and clears FailedCount.
+	LockLoginAttempt(ctx context.Context, params LockLoginAttemptParams) (LockLoginAttemptResult, error)
+
+	DeleteLoginAttempt(ctx context.Context, params DeleteLoginAttemptParams) (DeleteLoginAttemptResult, error)
+}
+
+// AuthRepositoryMfa stores TOTP secrets, recovery codes and login
+// challenges. Secrets are encrypted at rest, recovery codes and challenges
+// are stored hashed.
+type AuthRepositoryMfa interface {
+	GetDetailTotp(ctx context.Context, filters GetDetailTotpFilters) (GetDetailTotpResult, error)
+
+	// UpsertTotp stores a new unconfirmed secret, replacing any previous one.
+	UpsertTotp(ctx context.Context, params UpsertTotpParams) (UpsertTotpResult, error)
+
+	ConfirmTotp(ctx context.Context, params ConfirmTotpParams) (ConfirmTotpResult, error)
+
+	// UseTotpStep records step as used. It fails when the same or a later
+	// step was already used, so a code cannot be replayed.
+	UseTotpStep(ctx context.Context, params UseTotpStepParams) (UseTotpStepResult, error)
+
+	// DeleteTotp removes the secret and every recovery code of the user.
+	DeleteTotp(ctx context.Context, params DeleteTotpParams) (DeleteTotpResult, error)
+
+	// ReplaceRecoveryCodes atomically swaps every recovery code of the user for Codes.
+	ReplaceRecoveryCodes(ctx context.Context, params ReplaceRecoveryCodesParams) (ReplaceRecoveryCodesResult, error)
+
+	UseRecoveryCode(ctx context.Context, params UseRecoveryCodeParams) (UseRecoveryCodeResult, error)
+
+	CreateMfaChallenge(ctx context.Context, params CreateMfaChallengeParams) (CreateMfaChallengeResult, error)
+
+	GetDetailMfaChallenge(ctx context.Context, filters GetDetailMfaChallengeFilters) (GetDetailMfaChallengeResult, error)
+
+	IncrementMfaChallengeFailure(ctx context.Context, params IncrementMfaChallengeFailureParams) (IncrementMfaChallengeFailureResult, error)
+
+	// UseMfaChallenge marks an unused, unexpired challenge as used.
+	// Concurrent calls for the same challenge succeed at most once.
+	UseMfaChallenge(ctx context.Context, params UseMfaChallengeParams) (UseMfaChallengeResult, error)
+}
+
+// AuthRepositoryIdentity stores external identities linked to users and the
+// pending OpenID Connect logins.
+type AuthRepositoryIdentity interface {
+	CreateOidcState(ctx context.Context, params CreateOidcStateParams) (CreateOidcStateResult, error)
+
+	// ConsumeOidcState deletes the state and returns it, so a state can be
+	// used only once.
+	ConsumeOidcState(ctx context.Context, params ConsumeOidcStateParams) (ConsumeOidcStateResult, error)
+
+	DeleteExpiredOidcStates(ctx context.Context, params DeleteExpiredOidcStatesParams) (DeleteExpiredOidcStatesResult, error)
+
+	GetDetailIdentity(ctx context.Context, filters GetDetailIdentityFilters) (GetDetailIdentityResult, error)
+
+	// CreateIdentity links the identity to an existing user, see
+	// CreateIdentityParams.ActivatePasswordHash for an unverified one.
+	CreateIdentity(ctx context.Context, params CreateIdentityParams) (CreateIdentityResult, error)
+
+	// CreateUserWithIdentity creates an active user and links the identity
+	// to it in one transaction.
+	CreateUserWithIdentity(ctx context.Context, params CreateUserWithIdentityParams) (CreateUserWithIdentityResult, error)
+}
+
+// AuthRepositoryMagicLink stores passwordless sign-in links, hashed.
+type AuthRepositoryMagicLink interface {
+	CreateMagicLink(ctx context.Context, params CreateMagicLinkParams) (CreateMagicLinkResult, error)
+
+	// CountMagicLink counts the links of a user created after CreatedAfter,
+	// used and expired ones included.
+	CountMagicLink(ctx context.Context, filters CountMagicLinkFilters) (CountMagicLinkResult, error)
+
+	// ConsumeMagicLink marks an unused, unexpired link as used and returns
+	// its user, or fails with databases.ErrNoRowFound. Concurrent calls for
+	// the same link succeed at most once.
+	ConsumeMagicLink(ctx context.Context, params ConsumeMagicLinkParams) (ConsumeMagicLinkResult, error)
+
+	DeleteExpiredMagicLinks(ctx context.Context, params DeleteExpiredMagicLinksParams) (DeleteExpiredMagicLinksResult, error)
+}
+
+// AuthRepositoryNotifier delivers messages to users, implemented by
+// authrepository.NewNotifierRepository.
+type AuthRepositoryNotifier interface {
+	SendMagicLink(ctx context.Context, params SendMagicLinkParams) (SendMagicLinkResult, error)
+}
+
+// AuthRepositoryOidc talks to external OpenID Connect providers. Unknown
+// providers fail with ErrOidcProviderNotFound.
+type AuthRepositoryOidc interface {
+	GetOidcAuthorizationUrl(ctx context.Context, params GetOidcAuthorizationUrlParams) (GetOidcAuthorizationUrlResult, error)
+
+	// ExchangeOidcCode redeems an authorization code and returns the claims
+	// of the verified ID token. A refused code or an ID token that does not
+	// verify fails with ErrOidcLoginRejected.
+	ExchangeOidcCode(ctx context.Context, params ExchangeOidcCodeParams) (ExchangeOidcCodeResult, error)
+}
+
+// AuthRepositoryTokenPolicy serves token lifetimes and session timeouts.
+// They can change at runtime, read them on every use.
+type AuthRepositoryTokenPolicy interface {
+	GetTokenPolicy(ctx context.Context, params GetTokenPolicyParams) (GetTokenPolicyResult, error)
+}
+
+// AuthRepositoryRolePolicy serves the permissions of a role from the policy
+// config. It can change at runtime, read it on every use.
+type AuthRepositoryRolePolicy interface {
+	GetRolePermissions(ctx context.Context, params GetRolePermissionsParams) (GetRolePermissionsResult, error)
+}
+
+// AuthRepositoryOauthClient serves the clients of the introspection and
+// revocation endpoints. They can change at runtime, read them on every use.
+type AuthRepositoryOauthClient interface {
+	GetDetailOauthClient(ctx context.Context, filters GetDetailOauthClientFilters) (GetDetailOauthClientResult, error)
+}
+
+// AuthRepositoryAuditLog writes the audit log, implemented by
+// authrepository.NewAuditLogRepository.
+type AuthRepositoryAuditLog interface {
+	CreateAuditLog(ctx context.Context, params CreateAuditLogParams) (CreateAuditLogResult, error)
 }
 
 type UserRepositoryDatastore interface {
+	// GetDetailUser never returns a soft-deleted user.
 	GetDetailUser(ctx context.Context, filters GetDetailUserFilters) (GetDetailUserResult, error)
+
+	// UpdatePasswordHash replaces the password hash of the user only while it
+	// still equals OldPasswordHash, so a concurrent password change wins.
+	UpdatePasswordHash(ctx context.Context, params UpdatePasswordHashParams) (UpdatePasswordHashResult, error)
 }
 
 type CreateTokenParams struct {
@@ -28,6 +227,9 @@ type CreateTokenParams struct {
 	TokenType    TokenType
 	ExpiresAt    time.Time
 	RefreshToken *string
+	FamilyID     string
+	ParentID     *string // refresh token this one was rotated from
+	DpopJkt      *string // thumbprint of the DPoP key the token is bound to
 }
 
 type CreateTokenResult struct {
@@ -42,15 +244,19 @@ type GetDetailTokenFilters struct {
 	TokenType *TokenType
 }
 
+// GetDetailTokenResult never carries the raw token, only the stored hash.
 type GetDetailTokenResult struct {
-	ID           string
-	UserID       string
-	Token        string
-	TokenType    TokenType
-	Status       TokenStatus
-	ExpiresAt    time.Time
-	CreatedAt    time.Time
-	RefreshToken *string
+	ID               string
+	UserID           string
+	TokenHash        string
+	TokenType        TokenType
+	Status           TokenStatus
+	ExpiresAt        time.Time
+	CreatedAt        time.Time
+	RefreshTokenHash *string
+	FamilyID         string
+	ParentID         *string
+	DpopJkt          *string
 }
 
 type RevokeTokenParams struct {
@@ -63,6 +269,26 @@ type RevokeTokenResult struct {
 	RevokedAt time.Time
 }
 
+type RotateTokenParams struct {
+	TokenID string
+	UserID  string
+}
+
+type RotateTokenResult struct {
+	Success   bool // false when the token was no longer active
+	RotatedAt time.Time
+}
+
+type RevokeTokenFamilyParams struct {
+	FamilyID string
+	UserID   string
+}
+
+type RevokeTokenFamilyResult struct {
+	RevokedCount int64
+	RevokedAt    time.Time
+}
+
 type DeleteExpiredTokensParams struct {
 	BeforeDate time.Time
 }
@@ -71,18 +297,573 @@ type DeleteExpiredTokensResult struct {
 	DeletedCount int64
 }
 
+// CreateSessionParams creates a session. Limit is enforced in the same
+// transaction as the insert, concurrent logins of a user cannot both pass it.
+type CreateSessionParams struct {
+	UserID    string
+	FamilyID  string
+	UserAgent string
+	IPAddress string
+	Platform  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Limit     SessionLimit
+}
+
+// CreateSessionResult has no ID when LimitReached, the session was refused.
+// EvictedSessionIDs are the sessions revoked to make room for it.
+type CreateSessionResult struct {
+	ID                string
+	LimitReached      bool
+	EvictedSessionIDs []string
+}
+
+type GetDetailSessionFilters struct {
+	SessionID *string
+	FamilyID  *string
+	UserID    *string
+}
+
+type GetDetailSessionResult struct {
+	Session
+	FamilyID  string
+	RevokedAt *time.Time
+}
+
+type GetListSessionFilters struct {
+	UserID string
+	Now    time.Time
+}
+
+type GetListSessionResult struct {
+	Sessions []Session
+}
+
+// TouchSessionParams records a refresh. ExpiresAt follows the new refresh token.
+type TouchSessionParams struct {
+	SessionID  string
+	IPAddress  string
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
+
+type TouchSessionResult struct {
+	Success bool
+}
+
+// RevokeSessionsParams selects one session with SessionID, every session but
+// ExceptSessionID, or every session of the user when both are nil. Revoking
+// every session also revokes tokens that have no session row.
+type RevokeSessionsParams struct {
+	UserID          string
+	SessionID       *string
+	ExceptSessionID *string
+}
+
+type RevokeSessionsResult struct {
+	RevokedCount int64
+	RevokedAt    time.Time
+}
+
+type DeleteExpiredSessionsParams struct {
+	BeforeDate time.Time
+}
+
+type DeleteExpiredSessionsResult struct {
+	DeletedCount int64
+}
+
+type CreateApiKeyParams struct {
+	UserID     string
+	Name       string
+	Key        string
+	Prefix     string
+	Scopes     []string
+	AllowedIPs []string
+	ExpiresAt  *time.Time
+	CreatedAt  time.Time
+}
+
+type CreateApiKeyResult struct {
+	ID string
+}
+
+type GetDetailApiKeyFilters struct {
+	ApiKeyID *string
+	UserID   *string
+	Key      *string
+}
+
+type GetDetailApiKeyResult struct {
+	ApiKey
+}
+
+type GetListApiKeyFilters struct {
+	UserID string
+}
+
+type GetListApiKeyResult struct {
+	ApiKeys []ApiKey
+}
+
+type RotateApiKeyParams struct {
+	ApiKeyID  string
+	UserID    string
+	Key       string
+	Prefix    string
+	RotatedAt time.Time
+}
+
+type RotateApiKeyResult struct {
+	Success bool
+}
+
+type RevokeApiKeyParams struct {
+	ApiKeyID  string
+	UserID    string
+	RevokedAt time.Time
+}
+
+type RevokeApiKeyResult struct {
+	Success bool
+}
+
+type TouchApiKeyParams struct {
+	ApiKeyID   string
+	LastUsedAt time.Time
+}
+
+type TouchApiKeyResult struct {
+	Success bool
+}
+
+type CreateAccessTokenParams struct {
+	UserID         string
+	SessionID      string
+	Email          string
+	Role           UserRole
+	IssuedAt       time.Time
+	ExpiresAt      time.Time
+	ImpersonatorID string // set for a token issued by Impersonate
+	DpopJkt        string // set for a DPoP-bound token
+}
+
+type CreateAccessTokenResult struct {
+	Token   string
+	TokenID string
+	KeyID   string
+}
+
+type ParseAccessTokenParams struct {
+	Token string
+}
+
+type ParseAccessTokenResult struct {
+	TokenID string
+	Payload TokenPayload
+}
+
+type GetJwksResult struct {
+	Keys []Jwk
+}
+
+type ParseDpopProofParams struct {
+	Proof string
+}
+
+type ParseDpopProofResult struct {
+	Jkt             string // RFC 7638 thumbprint of the proof key
+	Jti             string
+	Method          string // htm claim
+	Url             string // htu claim
+	IssuedAt        time.Time
+	AccessTokenHash string // ath claim, empty when absent
+}
+
+type UseDpopJtiParams struct {
+	Jkt       string
+	Jti       string
+	UsedAt    time.Time
+	ExpiresAt time.Time // the jti may be used again after this
+}
+
+type UseDpopJtiResult struct {
+	Success bool
+}
+
+type DeleteExpiredDpopJtisParams struct {
+	BeforeDate time.Time
+}
+
+type DeleteExpiredDpopJtisResult struct {
+	DeletedCount int64
+}
+
 type GetDetailUserFilters struct {
 	UserID *string
 	Email  *string
 }
 
+type UpdatePasswordHashParams struct {
+	UserID          string
+	OldPasswordHash string
+	NewPasswordHash string
+}
+
+type UpdatePasswordHashResult struct {
+	Success bool
+}
+
 type GetDetailUserResult struct {
-	ID           string
+	ID                     string
+	Email                  string
+	PasswordHash           string
+	Name                   string
+	Role                   UserRole
+	Status                 sharedkernel.UserStatus
+	PasswordChangeRequired bool // set by an admin with a temporary password
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+}
+
+type GetDetailLoginAttemptFilters struct {
+	Scope      LoginAttemptScope
+	Identifier string
+}
+
+type GetDetailLoginAttemptResult struct {
+	Scope        LoginAttemptScope
+	Identifier   string
+	FailedCount  int64
+	LockoutCount int64
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
+
+type GetListLoginAttemptFilters struct {
+	Pagination  primitive.PaginationInput
+	LockedAfter *time.Time // only rows with locked_until after this time
+}
+
+type GetListLoginAttemptResult struct {
+	LoginAttempts []GetDetailLoginAttemptResult
+	Pagination    primitive.PaginationOutput
+}
+
+type IncrementLoginFailureParams struct {
+	Scope             LoginAttemptScope
+	Identifier        string
+	FailedAt          time.Time
+	WindowStart       time.Time
+	LockoutResetStart time.Time
+}
+
+type IncrementLoginFailureResult struct {
+	FailedCount  int64
+	LockoutCount int64
+}
+
+type LockLoginAttemptParams struct {
+	Scope       LoginAttemptScope
+	Identifier  string
+	LockedUntil time.Time
+}
+
+type LockLoginAttemptResult struct {
+	LockoutCount int64
+}
+
+type DeleteLoginAttemptParams struct {
+	Scope      LoginAttemptScope
+	Identifier string
+}
+
+type DeleteLoginAttemptResult struct {
+	Success bool
+}
+
+type GetDetailTotpFilters struct {
+	UserID string
+}
+
+type GetDetailTotpResult struct {
+	UserID       string
+	Secret       TotpSecret
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+type UpsertTotpParams struct {
+	UserID string
+	Secret TotpSecret
+}
+
+type UpsertTotpResult struct {
+	CreatedAt time.Time
+}
+
+type ConfirmTotpParams struct {
+	UserID      string
+	Step        int64
+	ConfirmedAt time.Time
+}
+
+type ConfirmTotpResult struct {
+	Success bool
+}
+
+type UseTotpStepParams struct {
+	UserID string
+	Step   int64
+}
+
+type UseTotpStepResult struct {
+	Success bool
+}
+
+type DeleteTotpParams struct {
+	UserID string
+}
+
+type DeleteTotpResult struct {
+	Success bool
+}
+
+type ReplaceRecoveryCodesParams struct {
+	UserID string
+	Codes  []string
+}
+
+type ReplaceRecoveryCodesResult struct {
+	CreatedAt time.Time
+}
+
+type UseRecoveryCodeParams struct {
+	UserID string
+	Code   string
+	UsedAt time.Time
+}
+
+type UseRecoveryCodeResult struct {
+	Success bool
+}
+
+type CreateMfaChallengeParams struct {
+	UserID    string
+	Challenge string
+	ExpiresAt time.Time
+}
+
+type CreateMfaChallengeResult struct {
+	ID        string
+	CreatedAt time.Time
+}
+
+type GetDetailMfaChallengeFilters struct {
+	Challenge string
+}
+
+type GetDetailMfaChallengeResult struct {
+	ID             string
+	UserID         string
+	FailedAttempts int64
+	ExpiresAt      time.Time
+	UsedAt         *time.Time
+}
+
+type IncrementMfaChallengeFailureParams struct {
+	ID string
+}
+
+type IncrementMfaChallengeFailureResult struct {
+	FailedAttempts int64
+}
+
+type UseMfaChallengeParams struct {
+	ID     string
+	UsedAt time.Time
+}
+
+type UseMfaChallengeResult struct {
+	Success bool
+}
+
+type GetTokenPolicyParams struct {
+	Role UserRole // selects the per-role override, if any
+}
+
+type GetTokenPolicyResult struct {
+	TokenPolicy           TokenPolicy
+	ExpiredTokenGrace     time.Duration
+	ImpersonationTokenTTL time.Duration
+}
+
+type CreateOidcStateParams struct {
+	Provider     string
+	State        string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+type CreateOidcStateResult struct {
+	ID        string
+	CreatedAt time.Time
+}
+
+type ConsumeOidcStateParams struct {
+	State string
+}
+
+type ConsumeOidcStateResult struct {
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+type DeleteExpiredOidcStatesParams struct {
+	BeforeDate time.Time
+}
+
+type DeleteExpiredOidcStatesResult struct {
+	DeletedCount int64
+}
+
+type GetDetailIdentityFilters struct {
+	Provider string
+	Subject  string
+}
+
+type GetDetailIdentityResult struct {
+	ID        string
+	UserID    string
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+type CreateIdentityParams struct {
+	UserID   string
+	Provider string
+	Subject  string
+	Email    string
+
+	// ActivatePasswordHash, when set, activates a pending_verification user
+	// and replaces its password in the same transaction, so whoever
+	// registered the email without verifying it loses the account.
+	ActivatePasswordHash string
+}
+
+type CreateIdentityResult struct {
+	ID        string
+	CreatedAt time.Time
+}
+
+type CreateUserWithIdentityParams struct {
 	Email        string
-	PasswordHash string
 	Name         string
-	Role         UserRole
-	Status       sharedkernel.UserStatus
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	PasswordHash string
+	Provider     string
+	Subject      string
+}
+
+type CreateUserWithIdentityResult struct {
+	UserID     string
+	IdentityID string
+}
+
+type GetOidcAuthorizationUrlParams struct {
+	Provider      string
+	State         string
+	Nonce         string
+	CodeChallenge string // S256 PKCE challenge
+}
+
+type GetOidcAuthorizationUrlResult struct {
+	Url string
+}
+
+type ExchangeOidcCodeParams struct {
+	Provider     string
+	Code         string
+	CodeVerifier string
+	Nonce        string // must match the nonce claim of the ID token
+}
+
+type ExchangeOidcCodeResult struct {
+	Claims OidcClaims
+}
+
+type GetRolePermissionsParams struct {
+	Role UserRole
+}
+
+type GetRolePermissionsResult struct {
+	Permissions []string // "*" grants every permission
+}
+
+type GetDetailOauthClientFilters struct {
+	ClientID string
+}
+
+type GetDetailOauthClientResult struct {
+	OauthClient OauthClient
+}
+
+type CreateAuditLogParams struct {
+	Entry sharedkernel.AuditEntry
+}
+
+type CreateAuditLogResult struct {
+	ID string
+}
+
+type CreateMagicLinkParams struct {
+	UserID    string
+	Token     string
+	IPAddress string // client that requested the link
+	ExpiresAt time.Time
+}
+
+type CreateMagicLinkResult struct {
+	ID        string
+	CreatedAt time.Time
 }
+
+type CountMagicLinkFilters struct {
+	UserID       string
+	CreatedAfter time.Time
+}
+
+type CountMagicLinkResult struct {
+	Count int64
+}
+
+type ConsumeMagicLinkParams struct {
+	Token  string
+	UsedAt time.Time
+}
+
+type ConsumeMagicLinkResult struct {
+	UserID string
+}
+
+type DeleteExpiredMagicLinksParams struct {
+	BeforeDate time.Time
+}
+
+type DeleteExpiredMagicLinksResult struct {
+	DeletedCount int64
+}
+
+type SendMagicLinkParams struct {
+	Email     string
+	Name      string
+	Link      string
+	ExpiresAt time.Time
+}
+
+type SendMagicLinkResult struct{}
@@ -7,13 +7,86 @@ import "context"
 type AuthService interface {
 	Login(ctx context.Context, input LoginInput) (LoginOutput, error)
 
+	// LoginMfa finishes a login that answered with an MFA challenge.
+	LoginMfa(ctx context.Context, input LoginMfaInput) (LoginOutput, error)
+
 	RefreshToken(ctx context.Context, input RefreshTokenInput) (RefreshTokenOutput, error)
 
 	Logout(ctx context.Context, input LogoutInput) (LogoutOutput, error)
 
 	ValidateToken(ctx context.Context, input ValidateTokenInput) (ValidateTokenOutput, error)
 
+	// RevokeToken revokes an access token, a refresh token together with its
+	// session, or an API key (RFC 7009). An unknown token is not an error.
 	RevokeToken(ctx context.Context, input RevokeTokenInput) (RevokeTokenOutput, error)
 
+	// IntrospectToken reports whether an access token, refresh token or API
+	// key is active and whom it belongs to (RFC 7662).
+	IntrospectToken(ctx context.Context, input IntrospectTokenInput) (IntrospectTokenOutput, error)
+
+	// AuthenticateOauthClient checks the credentials of an introspection and
+	// revocation client and fails with Unauthorized.
+	AuthenticateOauthClient(ctx context.Context, input AuthenticateOauthClientInput) (AuthenticateOauthClientOutput, error)
+
+	GetJwks(ctx context.Context) (GetJwksOutput, error)
+
+	GetListLoginLockout(ctx context.Context, input GetListLoginLockoutInput) (GetListLoginLockoutOutput, error)
+
+	ClearLoginLockout(ctx context.Context, input ClearLoginLockoutInput) (ClearLoginLockoutOutput, error)
+
+	// Impersonate issues a short-lived access token that acts as the target
+	// user and names the admin in TokenPayload.ImpersonatorID. Sensitive
+	// operations refuse it and every request made with it is audited.
+	Impersonate(ctx context.Context, input ImpersonateInput) (ImpersonateOutput, error)
+
+	// EnrollTotp creates a new unconfirmed TOTP secret for the user. It is
+	// not required at login until ConfirmTotp succeeds.
+	EnrollTotp(ctx context.Context, input EnrollTotpInput) (EnrollTotpOutput, error)
+
+	ConfirmTotp(ctx context.Context, input ConfirmTotpInput) (ConfirmTotpOutput, error)
+
+	DisableTotp(ctx context.Context, input DisableTotpInput) (DisableTotpOutput, error)
+
+	RegenerateRecoveryCodes(ctx context.Context, input RegenerateRecoveryCodesInput) (RegenerateRecoveryCodesOutput, error)
+
+	GetListSession(ctx context.Context, input GetListSessionInput) (GetListSessionOutput, error)
+
+	RevokeSession(ctx context.Context, input RevokeSessionInput) (RevokeSessionOutput, error)
+
+	RevokeOtherSessions(ctx context.Context, input RevokeOtherSessionsInput) (RevokeOtherSessionsOutput, error)
+
+	// RevokeAllSessions logs the user out everywhere.
+	RevokeAllSessions(ctx context.Context, input RevokeAllSessionsInput) (RevokeAllSessionsOutput, error)
+
+	// CreateApiKey creates a long-lived key for a machine client. The key is
+	// accepted by ValidateToken in place of an access token.
+	CreateApiKey(ctx context.Context, input CreateApiKeyInput) (CreateApiKeyOutput, error)
+
+	GetListApiKey(ctx context.Context, input GetListApiKeyInput) (GetListApiKeyOutput, error)
+
+	// RotateApiKey issues a new key for an existing one, keeping its name,
+	// scopes, allowlist and expiry.
+	RotateApiKey(ctx context.Context, input RotateApiKeyInput) (RotateApiKeyOutput, error)
+
+	RevokeApiKey(ctx context.Context, input RevokeApiKeyInput) (RevokeApiKeyOutput, error)
+
+	// StartOidcLogin begins an authorization-code flow with PKCE at an
+	// external OpenID Connect provider.
+	StartOidcLogin(ctx context.Context, input StartOidcLoginInput) (StartOidcLoginOutput, error)
+
+	// LoginOidc finishes the flow started by StartOidcLogin. The external
+	// identity is linked to the user with the same verified email, or a new
+	// user is created.
+	LoginOidc(ctx context.Context, input LoginOidcInput) (LoginOutput, error)
+
+	// RequestMagicLink emails a single-use sign-in link to an active user.
+	// It answers the same whether or not the email exists or the user ran
+	// out of links, so it does not reveal accounts.
+	RequestMagicLink(ctx context.Context, input RequestMagicLinkInput) (RequestMagicLinkOutput, error)
+
+	// LoginMagicLink exchanges the token of a sign-in link for tokens, or an
+	// MFA challenge like Login when the user has TOTP enabled.
+	LoginMagicLink(ctx context.Context, input LoginMagicLinkInput) (LoginOutput, error)
+
 	WorkerDeleteExpiredTokens(ctx context.Context)
 }
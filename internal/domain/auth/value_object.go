@@ -1,6 +1,17 @@
 package domainauth
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+)
 
 // Value Objects for Token Types
 type TokenType string
@@ -8,6 +19,8 @@ type TokenType string
 const (
 	TokenTypeAccess  TokenType = "access"
 	TokenTypeRefresh TokenType = "refresh"
+	// TokenTypeApiKey marks a caller authenticated with an API key.
+	TokenTypeApiKey TokenType = "api_key"
 )
 
 // Value Objects for User Role (used in token payload)
@@ -25,14 +38,434 @@ const (
 	TokenStatusActive  TokenStatus = "active"
 	TokenStatusRevoked TokenStatus = "revoked"
 	TokenStatusExpired TokenStatus = "expired"
+	// TokenStatusRotated marks a refresh token that was exchanged for a new pair.
+	// Presenting it again means it leaked, see RefreshToken.
+	TokenStatusRotated TokenStatus = "rotated"
 )
 
-// Token Payload - extracted from JWT
+// Token Payload - extracted from JWT, or built from an API key
 type TokenPayload struct {
-	UserID    string
-	Email     string
-	Role      UserRole
-	TokenType TokenType
-	IssuedAt  time.Time
-	ExpiresAt time.Time
+	UserID         string
+	SessionID      string
+	Email          string
+	Role           UserRole
+	TokenType      TokenType
+	IssuedAt       time.Time
+	ExpiresAt      time.Time
+	ApiKeyID       string   // set when TokenType is TokenTypeApiKey
+	Scopes         []string // permissions an API key is limited to
+	ImpersonatorID string   // admin acting as UserID, set on impersonation tokens
+	DpopJkt        string   // thumbprint of the DPoP key the token is bound to, if any
+	// PasswordChangeRequired is read from the user on every validation, the
+	// transport then only lets the user change the password.
+	PasswordChangeRequired bool
+}
+
+// Impersonated reports whether the token was issued by Impersonate.
+func (p TokenPayload) Impersonated() bool {
+	return p.ImpersonatorID != ""
+}
+
+// HasScopes reports whether the caller may use every permission in scopes.
+// Only API keys are limited by scopes, the role still has to grant them. An
+// operation that needs no permission acts on the owner's own account, so
+// without scopes only a key holding ApiKeyScopeAll may use it.
+func (p TokenPayload) HasScopes(scopes ...string) bool {
+	if p.TokenType != TokenTypeApiKey {
+		return true
+	}
+	if slices.Contains(p.Scopes, ApiKeyScopeAll) {
+		return true
+	}
+	if len(scopes) == 0 {
+		return false
+	}
+	for _, v := range scopes {
+		if !slices.Contains(p.Scopes, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Jwk - public key published on the JWKS endpoint (RFC 7517)
+type Jwk struct {
+	KeyType   string
+	KeyID     string
+	Use       string
+	Algorithm string
+	N         string // RSA modulus
+	E         string // RSA exponent
+	Curve     string // OKP curve
+	X         string // OKP public key
+}
+
+// Session is one login: the token family of an access/refresh pair and the
+// client it was issued to. LastUsedAt moves on every refresh.
+type Session struct {
+	ID         string
+	UserID     string
+	UserAgent  string
+	IPAddress  string
+	Platform   string // x-platform header
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
+
+// SessionLimitAction is what a login that would exceed a session limit does.
+type SessionLimitAction string
+
+const (
+	SessionLimitActionEvictOldest SessionLimitAction = "evict_oldest"
+	SessionLimitActionReject      SessionLimitAction = "reject"
+)
+
+// SessionLimitPolicy caps the active sessions of a user. MaxSessions counts
+// every session, PlatformMaxSessions the sessions per lower case x-platform
+// value; 0 or a missing platform means no cap.
+type SessionLimitPolicy struct {
+	MaxSessions         int
+	PlatformMaxSessions map[string]int
+	OnLimit             SessionLimitAction
+}
+
+// Limit returns the caps a new session on platform has to fit.
+func (p SessionLimitPolicy) Limit(platform string) SessionLimit {
+	return SessionLimit{
+		Max:         p.MaxSessions,
+		PlatformMax: p.PlatformMaxSessions[strings.ToLower(platform)],
+		EvictOldest: p.OnLimit != SessionLimitActionReject,
+	}
+}
+
+// SessionLimit caps the active sessions of a user, counting a new one: Max
+// every session and PlatformMax those on the platform of the new session, 0
+// disables a cap. EvictOldest revokes the oldest sessions over a cap instead
+// of refusing the new session.
+type SessionLimit struct {
+	Max         int
+	PlatformMax int
+	EvictOldest bool
+}
+
+// Enabled reports whether any cap is set.
+func (l SessionLimit) Enabled() bool {
+	return l.Max > 0 || l.PlatformMax > 0
+}
+
+// Overflow returns the sessions of active that have to be revoked for a new
+// session on platform to fit the limit, oldest first. ok is false when the
+// limit is reached and EvictOldest is off.
+func (l SessionLimit) Overflow(active []Session, platform string) (evict []Session, ok bool) {
+	oldestFirst := slices.Clone(active)
+	slices.SortStableFunc(oldestFirst, func(a, b Session) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	evicted := make(map[string]bool)
+	take := func(sessions []Session, over int) {
+		for _, session := range sessions {
+			if over <= 0 {
+				return
+			}
+			if !evicted[session.ID] {
+				evicted[session.ID] = true
+				evict = append(evict, session)
+				over--
+			}
+		}
+	}
+
+	if l.PlatformMax > 0 {
+		var onPlatform []Session
+		for _, session := range oldestFirst {
+			if strings.EqualFold(session.Platform, platform) {
+				onPlatform = append(onPlatform, session)
+			}
+		}
+		take(onPlatform, len(onPlatform)+1-l.PlatformMax)
+	}
+	if l.Max > 0 {
+		take(oldestFirst, len(oldestFirst)-len(evict)+1-l.Max)
+	}
+
+	if len(evict) > 0 && !l.EvictOldest {
+		return nil, false
+	}
+	return evict, true
+}
+
+// LoginAttemptScope is what failed logins are counted against.
+type LoginAttemptScope string
+
+const (
+	LoginAttemptScopeEmail LoginAttemptScope = "email"
+	LoginAttemptScopeIP    LoginAttemptScope = "ip"
+)
+
+// LoginLockout is an email or client IP that is currently locked out.
+type LoginLockout struct {
+	Scope        LoginAttemptScope
+	Identifier   string
+	LockoutCount int64
+	LastFailedAt time.Time
+	LockedUntil  time.Time
+}
+
+// LoginLockoutPolicy controls brute-force protection on Login. After
+// MaxAttempts failures within Window the email or client IP is locked for
+// BaseLockout, doubling on every further lockout up to MaxLockout. The
+// doubling restarts once no failure happened for LockoutResetAfter.
+// MaxAttempts <= 0 disables the lockout.
+type LoginLockoutPolicy struct {
+	MaxAttempts       int64
+	Window            time.Duration
+	BaseLockout       time.Duration
+	MaxLockout        time.Duration
+	LockoutResetAfter time.Duration
+}
+
+// LockoutDuration returns how long the lockout number lockoutCount (starting at 0) lasts.
+func (p LoginLockoutPolicy) LockoutDuration(lockoutCount int64) time.Duration {
+	maxLockout := p.MaxLockout
+	if maxLockout <= 0 {
+		maxLockout = math.MaxInt64
+	}
+
+	duration := p.BaseLockout
+	for i := int64(0); i < lockoutCount && duration < maxLockout; i++ {
+		if duration > maxLockout/2 {
+			return maxLockout
+		}
+		duration *= 2
+	}
+	return min(duration, maxLockout)
+}
+
+// LoginLockedError is returned by Login while the email or client IP is
+// locked out. It unwraps to an apperror.Forbidden so generic handlers still
+// answer 403; transports that know it can answer 429 with Retry-After.
+type LoginLockedError struct {
+	LockedUntil time.Time
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("login locked until %s", e.LockedUntil.Format(time.RFC3339))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return apperror.Forbidden(e.Error(), apperror.WithPublicMessage("too many failed login attempts, try again later"))
+}
+
+// MfaPolicy controls the second login step. A challenge is valid for
+// ChallengeTTL and at most ChallengeMaxAttempts wrong codes.
+type MfaPolicy struct {
+	Issuer               string
+	ChallengeTTL         time.Duration
+	ChallengeMaxAttempts int64
+}
+
+// MagicLinkPolicy configures passwordless sign-in links. LinkURL receives
+// the token as the "token" query parameter. A user gets at most MaxRequests
+// links per RequestWindow, 0 disables the limit.
+type MagicLinkPolicy struct {
+	LinkURL       string
+	TokenTTL      time.Duration
+	MaxRequests   int64
+	RequestWindow time.Duration
+}
+
+// DpopProof is the DPoP header of a request (RFC 9449) with the method and
+// URL the request was received on.
+type DpopProof struct {
+	Proof  string
+	Method string
+	Url    string
+}
+
+// DpopPolicy checks DPoP proofs. A proof is accepted while its iat is within
+// ProofMaxAge of now. BaseURL, when set, replaces the scheme and host of the
+// request URL, e.g. behind a proxy that terminates TLS.
+type DpopPolicy struct {
+	ProofMaxAge time.Duration
+	BaseURL     string
+}
+
+// MatchesTarget reports whether the htu claim of a proof names requestURL.
+// Scheme and host compare case-insensitively, default ports, query and
+// fragment are ignored.
+func (p DpopPolicy) MatchesTarget(htu, requestURL string) bool {
+	target, err := url.Parse(requestURL)
+	if err != nil {
+		return false
+	}
+	if p.BaseURL != "" {
+		base, err := url.Parse(p.BaseURL)
+		if err != nil {
+			return false
+		}
+		target.Scheme = base.Scheme
+		target.Host = base.Host
+		target.Path = strings.TrimSuffix(base.Path, "/") + target.Path
+		target.RawPath = ""
+	}
+
+	claimed, err := url.Parse(htu)
+	if err != nil || claimed.Host == "" {
+		return false
+	}
+	return normalizeDpopUrl(claimed) == normalizeDpopUrl(target)
+}
+
+func normalizeDpopUrl(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return scheme + "://" + host + path
+}
+
+// TokenPolicy controls token lifetimes of a session. A session ends
+// SessionAbsoluteTimeout after login and SessionIdleTimeout after its last
+// refresh, a zero timeout is disabled.
+type TokenPolicy struct {
+	AccessTokenTTL         time.Duration
+	RefreshTokenTTL        time.Duration
+	SessionAbsoluteTimeout time.Duration
+	SessionIdleTimeout     time.Duration
+}
+
+// RefreshTokenExpiry returns when a refresh token issued at now for a session
+// started at sessionStart expires: after RefreshTokenTTL, or earlier when
+// one of the session timeouts is reached first.
+func (p TokenPolicy) RefreshTokenExpiry(sessionStart, now time.Time) time.Time {
+	expiresAt := now.Add(p.RefreshTokenTTL)
+	if p.SessionIdleTimeout > 0 && now.Add(p.SessionIdleTimeout).Before(expiresAt) {
+		expiresAt = now.Add(p.SessionIdleTimeout)
+	}
+	if p.SessionAbsoluteTimeout > 0 && sessionStart.Add(p.SessionAbsoluteTimeout).Before(expiresAt) {
+		expiresAt = sessionStart.Add(p.SessionAbsoluteTimeout)
+	}
+	return expiresAt
+}
+
+// AccessTokenExpiry returns when an access token issued at now expires. It
+// never outlives the refresh token of the same session.
+func (p TokenPolicy) AccessTokenExpiry(refreshTokenExpiry, now time.Time) time.Time {
+	expiresAt := now.Add(p.AccessTokenTTL)
+	if refreshTokenExpiry.Before(expiresAt) {
+		return refreshTokenExpiry
+	}
+	return expiresAt
+}
+
+// SessionExpired reports whether session timed out at now. It uses the
+// current timeouts, so shortening them applies to existing sessions too.
+func (p TokenPolicy) SessionExpired(session Session, now time.Time) bool {
+	if p.SessionAbsoluteTimeout > 0 && !now.Before(session.CreatedAt.Add(p.SessionAbsoluteTimeout)) {
+		return true
+	}
+	if p.SessionIdleTimeout > 0 && !now.Before(session.LastUsedAt.Add(p.SessionIdleTimeout)) {
+		return true
+	}
+	return false
+}
+
+var (
+	// ErrOidcProviderNotFound is returned for a provider name that is not configured.
+	ErrOidcProviderNotFound = errors.New("oidc provider not found")
+	// ErrOidcLoginRejected is returned when the provider refuses the
+	// authorization code or its ID token does not verify.
+	ErrOidcLoginRejected = errors.New("oidc login rejected")
+)
+
+// OauthClient may introspect and revoke any token, see config.Oauth.
+type OauthClient struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// OidcClaims are the verified claims of an ID token.
+type OidcClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+const (
+	// ApiKeyPrefix starts every API key, so it can be told apart from a JWT
+	// in the same Authorization header.
+	ApiKeyPrefix = "gbk_"
+	// ApiKeyScopeAll lets an API key use every permission of its owner.
+	ApiKeyScopeAll = "*"
+)
+
+// ApiKey is a long-lived credential of a machine client. It acts as UserID,
+// limited to Scopes and, when AllowedIPs is not empty, to those client IPs.
+// Prefix is the start of the key, the key itself is never stored.
+type ApiKey struct {
+	ID         string
+	UserID     string
+	Name       string
+	Prefix     string
+	Scopes     []string
+	AllowedIPs []string // addresses or CIDR ranges
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// Usable reports whether the key is neither revoked nor expired at now.
+func (k ApiKey) Usable(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// AllowsIP reports whether a request from ip may use the key.
+func (k ApiKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, v := range k.AllowedIPs {
+		prefix, err := ParseApiKeyAllowedIP(v)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseApiKeyAllowedIP parses one AllowedIPs entry, a single address is a
+// range of one.
+func ParseApiKeyAllowedIP(entry string) (netip.Prefix, error) {
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
 }
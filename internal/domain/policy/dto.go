@@ -0,0 +1,11 @@
+package domainpolicy
+
+type AuthorizeInput struct {
+	Role        string
+	Permissions []Permission
+}
+
+type AuthorizeOutput struct {
+	// Granted is every permission held by the role, not only the requested ones.
+	Granted []Permission
+}
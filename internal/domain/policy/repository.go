@@ -0,0 +1,17 @@
+//go:generate go tool mockgen -source=repository.go -destination=../../gen/mockgen/policy_repository_mock.gen.go -package=mockgen
+
+package domainpolicy
+
+import "context"
+
+type PolicyRepository interface {
+	GetRolePermissions(ctx context.Context, params GetRolePermissionsParams) (GetRolePermissionsResult, error)
+}
+
+type GetRolePermissionsParams struct {
+	Role string
+}
+
+type GetRolePermissionsResult struct {
+	Permissions []Permission
+}
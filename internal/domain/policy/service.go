@@ -0,0 +1,10 @@
+//go:generate go tool mockgen -source=service.go -destination=../../gen/mockgen/policy_service_mock.gen.go -package=mockgen
+
+package domainpolicy
+
+import "context"
+
+type PolicyService interface {
+	// Authorize returns an apperror.Forbidden unless Role is granted every Permissions.
+	Authorize(ctx context.Context, input AuthorizeInput) (AuthorizeOutput, error)
+}
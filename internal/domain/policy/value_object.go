@@ -0,0 +1,24 @@
+package domainpolicy
+
+// Permission is a named action a role can be granted, formatted as <resource>:<action>.
+type Permission string
+
+const (
+	// PermissionAll grants every permission.
+	PermissionAll Permission = "*"
+
+	PermissionUsersList         Permission = "users:list"
+	PermissionUsersUpdateStatus Permission = "users:update_status"
+	PermissionLockoutsList      Permission = "lockouts:list"
+	PermissionLockoutsClear     Permission = "lockouts:clear"
+	PermissionSessionsRevoke    Permission = "sessions:revoke"
+	PermissionApiKeysManage     Permission = "api_keys:manage"
+	PermissionTokensIntrospect  Permission = "tokens:introspect"
+	PermissionTokensRevoke      Permission = "tokens:revoke"
+	PermissionAuditLogsList     Permission = "audit_logs:list"
+	PermissionUsersImpersonate  Permission = "users:impersonate"
+	PermissionUsersCreate       Permission = "users:create"
+	PermissionUsersDelete       Permission = "users:delete"
+	PermissionUsersRestore      Permission = "users:restore"
+	PermissionUsersErase        Permission = "users:erase"
+)
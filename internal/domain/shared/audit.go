@@ -0,0 +1,78 @@
+package sharedkernel
+
+import "time"
+
+// AuditAction names a security relevant event in the audit log.
+type AuditAction string
+
+const (
+	AuditActionLogin               AuditAction = "login"
+	AuditActionLoginMfa            AuditAction = "login_mfa"
+	AuditActionLoginOidc           AuditAction = "login_oidc"
+	AuditActionLoginMagicLink      AuditAction = "login_magic_link"
+	AuditActionMagicLinkRequest    AuditAction = "magic_link_request"
+	AuditActionLogout              AuditAction = "logout"
+	AuditActionTokenRefresh        AuditAction = "token_refresh"
+	AuditActionTokenRevoke         AuditAction = "token_revoke"
+	AuditActionSessionRevoke       AuditAction = "session_revoke"
+	AuditActionApiKeyCreate        AuditAction = "api_key_create"
+	AuditActionApiKeyRotate        AuditAction = "api_key_rotate"
+	AuditActionApiKeyRevoke        AuditAction = "api_key_revoke"
+	AuditActionMfaEnable           AuditAction = "mfa_enable"
+	AuditActionMfaDisable          AuditAction = "mfa_disable"
+	AuditActionLoginLockoutClear   AuditAction = "login_lockout_clear"
+	AuditActionPasswordChange      AuditAction = "password_change"
+	AuditActionPasswordReset       AuditAction = "password_reset"
+	AuditActionUserStatusChange    AuditAction = "user_status_change"
+	AuditActionUserCreate          AuditAction = "user_create"
+	AuditActionUserDelete          AuditAction = "user_delete"
+	AuditActionUserRestore         AuditAction = "user_restore"
+	AuditActionUserErasureRequest  AuditAction = "user_erasure_request"
+	AuditActionUserErasure         AuditAction = "user_erasure"
+	AuditActionImpersonate         AuditAction = "impersonate"
+	AuditActionImpersonatedRequest AuditAction = "impersonated_request"
+)
+
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditActionLogin, AuditActionLoginMfa, AuditActionLoginOidc, AuditActionLoginMagicLink,
+		AuditActionMagicLinkRequest, AuditActionLogout,
+		AuditActionTokenRefresh, AuditActionTokenRevoke, AuditActionSessionRevoke,
+		AuditActionApiKeyCreate, AuditActionApiKeyRotate, AuditActionApiKeyRevoke,
+		AuditActionMfaEnable, AuditActionMfaDisable, AuditActionLoginLockoutClear,
+		AuditActionPasswordChange, AuditActionPasswordReset, AuditActionUserStatusChange,
+		AuditActionUserCreate, AuditActionUserDelete, AuditActionUserRestore,
+		AuditActionUserErasureRequest, AuditActionUserErasure,
+		AuditActionImpersonate, AuditActionImpersonatedRequest:
+		return true
+	default:
+		return false
+	}
+}
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+func (o AuditOutcome) IsValid() bool {
+	return o == AuditOutcomeSuccess || o == AuditOutcomeFailure
+}
+
+// AuditEntry is one event of the audit log. ActorUserID is who performed the
+// action and TargetUserID whom it was performed on; both are empty when
+// unknown, e.g. a login with an unknown email. Reason tells why an action
+// failed, Details carries action specific values such as the session id or
+// the previous status.
+type AuditEntry struct {
+	Action       AuditAction
+	Outcome      AuditOutcome
+	Reason       string
+	ActorUserID  string
+	TargetUserID string
+	Details      map[string]string
+	RequestMeta
+	CreatedAt time.Time
+}
@@ -0,0 +1,42 @@
+package sharedkernel
+
+import (
+	"context"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+)
+
+// ErrImpersonationForbidden is returned by sensitive operations, such as
+// changing the password, when the caller is impersonating the user.
+var ErrImpersonationForbidden = apperror.Forbidden("not allowed while impersonating a user")
+
+// Impersonation marks a request made with an impersonation token:
+// ActorUserID, an admin, acts as UserID. It is set by the transport together
+// with the token payload and read by the audit log.
+type Impersonation struct {
+	ActorUserID string
+	UserID      string
+}
+
+type impersonationContextKey struct{}
+
+// WithImpersonation returns a copy of ctx carrying impersonation.
+func WithImpersonation(ctx context.Context, impersonation Impersonation) context.Context {
+	return context.WithValue(ctx, impersonationContextKey{}, impersonation)
+}
+
+// ImpersonationFromContext returns the impersonation stored by
+// WithImpersonation.
+func ImpersonationFromContext(ctx context.Context) (Impersonation, bool) {
+	impersonation, ok := ctx.Value(impersonationContextKey{}).(Impersonation)
+	return impersonation, ok
+}
+
+// DenyImpersonation returns ErrImpersonationForbidden when ctx belongs to an
+// impersonated request.
+func DenyImpersonation(ctx context.Context) error {
+	if _, ok := ImpersonationFromContext(ctx); ok {
+		return ErrImpersonationForbidden
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package sharedkernel
+
+import "errors"
+
+// ErrPasswordHashUnsupported is returned by PasswordHasher.Verify for a
+// stored hash of an unknown algorithm or format.
+var ErrPasswordHashUnsupported = errors.New("unsupported password hash")
+
+// PasswordHasher hashes user passwords with the configured algorithm and
+// verifies them against stored hashes of any supported algorithm, recognized
+// by the hash prefix.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+
+	// Verify reports whether password matches hash. needsRehash is set when
+	// hash uses another algorithm or outdated parameters, the caller should
+	// then store a fresh Hash of the password.
+	Verify(password, hash string) (match bool, needsRehash bool, err error)
+}
@@ -0,0 +1,26 @@
+package sharedkernel
+
+import "context"
+
+// RequestMeta describes the request being served: the client IP, its
+// User-Agent and the request ID, e.g. from the X-Request-ID header. It is set
+// by the transport and read by the audit log.
+type RequestMeta struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+type requestMetaContextKey struct{}
+
+// WithRequestMeta returns a copy of ctx carrying meta.
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaContextKey{}, meta)
+}
+
+// RequestMetaFromContext returns the meta stored by WithRequestMeta, or the
+// zero value outside of a request.
+func RequestMetaFromContext(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaContextKey{}).(RequestMeta)
+	return meta
+}
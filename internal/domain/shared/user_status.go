@@ -8,12 +8,16 @@ const (
 	UserStatusActive    UserStatus = "active"
 	UserStatusInactive  UserStatus = "inactive"
 	UserStatusSuspended UserStatus = "suspended"
+	// UserStatusPendingVerification is a registered user that has not
+	// confirmed its email address yet.
+	UserStatusPendingVerification UserStatus = "pending_verification"
 )
 
 var (
-	ErrUserInactive  = errors.New("user account is inactive")
-	ErrUserSuspended = errors.New("user account is suspended")
-	ErrUserInvalid   = errors.New("invalid user account status")
+	ErrUserInactive            = errors.New("user account is inactive")
+	ErrUserSuspended           = errors.New("user account is suspended")
+	ErrUserPendingVerification = errors.New("email address is not verified")
+	ErrUserInvalid             = errors.New("invalid user account status")
 )
 
 func (s UserStatus) IsActive() bool {
@@ -28,6 +32,10 @@ func (s UserStatus) IsSuspended() bool {
 	return s == UserStatusSuspended
 }
 
+func (s UserStatus) IsPendingVerification() bool {
+	return s == UserStatusPendingVerification
+}
+
 // CanLogin returns bool + domain error
 func (s UserStatus) CanLogin() error {
 	switch s {
@@ -37,6 +45,8 @@ func (s UserStatus) CanLogin() error {
 		return ErrUserInactive
 	case UserStatusSuspended:
 		return ErrUserSuspended
+	case UserStatusPendingVerification:
+		return ErrUserPendingVerification
 	default:
 		return ErrUserInvalid
 	}
@@ -0,0 +1,32 @@
+package sharedkernel
+
+import (
+	"strings"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+)
+
+// FieldError lists every rule a single input field violates.
+type FieldError struct {
+	Field    string
+	Messages []string
+}
+
+// ValidationError is returned when input fields break business rules, e.g.
+// the password policy. It unwraps to an apperror.BadRequest so generic
+// handlers still answer 400; transports that know it list the fields.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, v := range e.Fields {
+		parts = append(parts, v.Field+": "+strings.Join(v.Messages, ", "))
+	}
+	return "validation error: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return apperror.BadRequest(e.Error(), apperror.WithPublicMessage("Validation error"))
+}
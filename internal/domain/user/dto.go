@@ -19,6 +19,7 @@ type RegisterOutput struct {
 	UserID    string
 	Email     string
 	Name      string
+	Status    sharedkernel.UserStatus
 	CreatedAt time.Time
 }
 
@@ -66,11 +67,109 @@ type ChangePasswordOutput struct {
 }
 
 type UpdateStatusInput struct {
+	// ActorUserID is the admin performing the change.
+	ActorUserID string
+	UserID      string
+	Status      sharedkernel.UserStatus
+}
+
+type UpdateStatusOutput struct {
+	Success   bool
+	UpdatedAt time.Time
+}
+
+type VerifyEmailInput struct {
+	Token string
+}
+
+type VerifyEmailOutput struct {
 	UserID string
 	Status sharedkernel.UserStatus
 }
 
-type UpdateStatusOutput struct {
+type ResendVerificationInput struct {
+	Email string
+}
+
+type ResendVerificationOutput struct{}
+
+type RequestPasswordResetInput struct {
+	Email string
+}
+
+type RequestPasswordResetOutput struct{}
+
+type ResetPasswordInput struct {
+	Token       string
+	NewPassword string
+}
+
+type ResetPasswordOutput struct {
 	Success   bool
 	UpdatedAt time.Time
 }
+
+type CreateUserInput struct {
+	// ActorUserID is the admin creating the user.
+	ActorUserID string
+	Email       string
+	Name        string
+	Role        UserRole
+	Phone       *string
+	Gender      *Gender
+	// TemporaryPassword must be changed at the first login, when nil the
+	// user sets a password through the reset link sent to the email.
+	TemporaryPassword *string
+}
+
+type CreateUserOutput struct {
+	User User
+}
+
+type DeleteUserInput struct {
+	// ActorUserID is the admin performing the deletion.
+	ActorUserID string
+	UserID      string
+}
+
+type DeleteUserOutput struct {
+	Success   bool
+	DeletedAt time.Time
+}
+
+type RestoreUserInput struct {
+	// ActorUserID is the admin performing the restore.
+	ActorUserID string
+	UserID      string
+}
+
+type RestoreUserOutput struct {
+	User User
+}
+
+type RequestErasureInput struct {
+	// ActorUserID is the admin requesting the erasure, empty when it is
+	// requested from the command line.
+	ActorUserID string
+	UserID      string
+}
+
+type RequestErasureOutput struct {
+	Job ErasureJob
+}
+
+type GetErasureJobInput struct {
+	JobID string
+}
+
+type GetErasureJobOutput struct {
+	Job ErasureJob
+}
+
+type RunErasureJobInput struct {
+	JobID string
+}
+
+type RunErasureJobOutput struct {
+	Job ErasureJob
+}
@@ -22,15 +22,94 @@ type UserRepositoryDatastore interface {
 	UpdatePassword(ctx context.Context, params UpdatePasswordParams) (UpdatePasswordResult, error)
 
 	UpdateStatus(ctx context.Context, params UpdateStatusParams) (UpdateStatusResult, error)
+
+	CountUser(ctx context.Context, filters CountUserFilters) (CountUserResult, error)
+
+	// SoftDeleteUser sets deleted_at of a user that is not deleted yet, every
+	// other query skips the user from then on.
+	SoftDeleteUser(ctx context.Context, params SoftDeleteUserParams) (SoftDeleteUserResult, error)
+
+	// RestoreUser clears deleted_at of a deleted user that is not erased.
+	RestoreUser(ctx context.Context, params RestoreUserParams) (RestoreUserResult, error)
+}
+
+// PasswordResetRepositoryDatastore stores password reset tokens. Tokens are
+// persisted hashed, the plaintext only ever leaves through the notifier.
+type PasswordResetRepositoryDatastore interface {
+	CreatePasswordResetToken(ctx context.Context, params CreatePasswordResetTokenParams) (CreatePasswordResetTokenResult, error)
+
+	// GetDetailPasswordResetToken looks a token up without using it, it
+	// returns databases.ErrNoRowFound for an unknown token.
+	GetDetailPasswordResetToken(ctx context.Context, filters GetDetailPasswordResetTokenFilters) (GetDetailPasswordResetTokenResult, error)
+
+	// UsePasswordResetToken marks an unused, unexpired token as used and returns
+	// its owner. Concurrent calls for the same token succeed at most once.
+	UsePasswordResetToken(ctx context.Context, params UsePasswordResetTokenParams) (UsePasswordResetTokenResult, error)
+
+	DeletePasswordResetTokens(ctx context.Context, params DeletePasswordResetTokensParams) (DeletePasswordResetTokensResult, error)
+}
+
+// EmailVerificationRepositoryDatastore stores email verification tokens,
+// hashed like password reset tokens.
+type EmailVerificationRepositoryDatastore interface {
+	CreateEmailVerificationToken(ctx context.Context, params CreateEmailVerificationTokenParams) (CreateEmailVerificationTokenResult, error)
+
+	// UseEmailVerificationToken marks an unused, unexpired token as used and
+	// returns its owner. Concurrent calls for the same token succeed at most once.
+	UseEmailVerificationToken(ctx context.Context, params UseEmailVerificationTokenParams) (UseEmailVerificationTokenResult, error)
+
+	DeleteEmailVerificationTokens(ctx context.Context, params DeleteEmailVerificationTokensParams) (DeleteEmailVerificationTokensResult, error)
+}
+
+// AuthTokenRepositoryDatastore is the part of auth_tokens the user module
+// needs, implemented by userrepository.NewAuthTokenRepository.
+type AuthTokenRepositoryDatastore interface {
+	RevokeUserTokens(ctx context.Context, params RevokeUserTokensParams) (RevokeUserTokensResult, error)
+}
+
+// ErasureRepositoryDatastore stores erasure jobs and erases users,
+// implemented by userrepository.NewErasureRepository.
+type ErasureRepositoryDatastore interface {
+	CreateErasureJob(ctx context.Context, params CreateErasureJobParams) (CreateErasureJobResult, error)
+
+	// GetDetailErasureJob returns databases.ErrNoRowFound when no job matches.
+	GetDetailErasureJob(ctx context.Context, filters GetDetailErasureJobFilters) (GetDetailErasureJobResult, error)
+
+	// ClaimErasureJob moves a pending job to running and returns it, the
+	// given one or else the oldest. Concurrent calls never claim the same job.
+	ClaimErasureJob(ctx context.Context, params ClaimErasureJobParams) (ClaimErasureJobResult, error)
+
+	FinishErasureJob(ctx context.Context, params FinishErasureJobParams) (FinishErasureJobResult, error)
+
+	// EraseUser overwrites the personal data of a user that is not erased
+	// yet and deletes its rows in other tables in one transaction. The users
+	// row and the audit log events are kept, without the IP address, user
+	// agent and email of the user. The last active admin is not erased,
+	// checked under a lock like SoftDeleteUser.
+	EraseUser(ctx context.Context, params EraseUserParams) (EraseUserResult, error)
+}
+
+// AuditLogRepositoryDatastore writes the audit log, implemented by
+// userrepository.NewAuditLogRepository.
+type AuditLogRepositoryDatastore interface {
+	CreateAuditLog(ctx context.Context, params CreateAuditLogParams) (CreateAuditLogResult, error)
+}
+
+type UserRepositoryNotifier interface {
+	SendPasswordReset(ctx context.Context, params SendPasswordResetParams) (SendPasswordResetResult, error)
+
+	SendEmailVerification(ctx context.Context, params SendEmailVerificationParams) (SendEmailVerificationResult, error)
 }
 
 type CreateUserParams struct {
-	Email        string
-	PasswordHash string
-	Name         string
-	Role         UserRole
-	Phone        *string
-	Gender       *Gender
+	Email                  string
+	PasswordHash           string
+	Name                   string
+	Role                   UserRole
+	Status                 sharedkernel.UserStatus
+	Phone                  *string
+	Gender                 *Gender
+	PasswordChangeRequired bool
 }
 
 type CreateUserResult struct {
@@ -45,19 +124,24 @@ type CreateUserResult struct {
 type GetDetailUserFilters struct {
 	UserID *string
 	Email  *string
+	// Deleted looks among deleted users instead, which are skipped otherwise.
+	Deleted bool
 }
 
 type GetDetailUserResult struct {
-	ID           string
-	Email        string
-	PasswordHash string // for authentication
-	Name         string
-	Role         UserRole
-	Status       sharedkernel.UserStatus
-	Phone        *string
-	Gender       *Gender
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID                     string
+	Email                  string
+	PasswordHash           string // for authentication
+	Name                   string
+	Role                   UserRole
+	Status                 sharedkernel.UserStatus
+	Phone                  *string
+	Gender                 *Gender
+	PasswordChangeRequired bool
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+	DeletedAt              *time.Time
+	ErasedAt               *time.Time // only set by GetDetailUser
 }
 
 type GetListUserFilters struct {
@@ -83,6 +167,8 @@ type UpdateUserResult struct {
 	UpdatedAt time.Time
 }
 
+// UpdatePasswordParams sets a new password, which clears
+// password_change_required.
 type UpdatePasswordParams struct {
 	UserID          string
 	NewPasswordHash string
@@ -95,8 +181,201 @@ type UpdatePasswordResult struct {
 type UpdateStatusParams struct {
 	UserID string
 	Status sharedkernel.UserStatus
+	// KeepActiveAdmin leaves the user unchanged when it is the last active
+	// admin, checked under a lock on the admin rows.
+	KeepActiveAdmin bool
 }
 
 type UpdateStatusResult struct {
+	UpdatedAt       time.Time
+	LastActiveAdmin bool // true when KeepActiveAdmin refused the update
+}
+
+type CountUserFilters struct {
+	Status *sharedkernel.UserStatus
+	Role   *UserRole
+}
+
+type CountUserResult struct {
+	Count int64
+}
+
+type SoftDeleteUserParams struct {
+	UserID    string
+	DeletedAt time.Time
+	// KeepActiveAdmin leaves the user undeleted when it is the last active
+	// admin, checked under a lock on the admin rows.
+	KeepActiveAdmin bool
+}
+
+type SoftDeleteUserResult struct {
+	Success         bool // false when the user does not exist or is already deleted
+	LastActiveAdmin bool // true when KeepActiveAdmin refused the delete
+}
+
+type RestoreUserParams struct {
+	UserID string
+}
+
+type RestoreUserResult struct {
+	Success   bool // false when the user does not exist, is not deleted or is erased
 	UpdatedAt time.Time
 }
+
+type CreateErasureJobParams struct {
+	UserID      string
+	RequestedBy string // empty when not requested by a user
+	CreatedAt   time.Time
+}
+
+type CreateErasureJobResult struct {
+	ID string
+}
+
+type GetDetailErasureJobFilters struct {
+	JobID  *string
+	UserID *string
+	// Unfinished only matches pending and running jobs.
+	Unfinished bool
+}
+
+type GetDetailErasureJobResult struct {
+	Job ErasureJob
+}
+
+type ClaimErasureJobParams struct {
+	JobID     *string // nil claims the oldest pending job
+	StartedAt time.Time
+}
+
+type ClaimErasureJobResult struct {
+	Success bool // false when there is no such pending job
+	Job     ErasureJob
+}
+
+type FinishErasureJobParams struct {
+	JobID       string
+	Status      ErasureJobStatus // completed or failed
+	Error       string
+	CompletedAt time.Time
+}
+
+type FinishErasureJobResult struct{}
+
+// EraseUserParams holds the values that replace the personal data of the
+// user, phone and gender are cleared.
+type EraseUserParams struct {
+	UserID       string
+	Email        string
+	Name         string
+	PasswordHash string
+	ErasedAt     time.Time
+}
+
+type EraseUserResult struct {
+	Success         bool // false when the user does not exist or is already erased
+	LastActiveAdmin bool // true when the user is the last active admin
+}
+
+type CreatePasswordResetTokenParams struct {
+	UserID    string
+	Token     string
+	ExpiresAt time.Time
+}
+
+type CreatePasswordResetTokenResult struct {
+	ID        string
+	CreatedAt time.Time
+}
+
+type GetDetailPasswordResetTokenFilters struct {
+	Token string
+}
+
+type GetDetailPasswordResetTokenResult struct {
+	UserID    string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+type UsePasswordResetTokenParams struct {
+	Token  string
+	UsedAt time.Time
+}
+
+type UsePasswordResetTokenResult struct {
+	Success bool
+	UserID  string
+}
+
+type DeletePasswordResetTokensParams struct {
+	UserID string
+}
+
+type DeletePasswordResetTokensResult struct {
+	DeletedCount int64
+}
+
+type CreateEmailVerificationTokenParams struct {
+	UserID    string
+	Token     string
+	ExpiresAt time.Time
+}
+
+type CreateEmailVerificationTokenResult struct {
+	ID        string
+	CreatedAt time.Time
+}
+
+type UseEmailVerificationTokenParams struct {
+	Token  string
+	UsedAt time.Time
+}
+
+type UseEmailVerificationTokenResult struct {
+	Success bool
+	UserID  string
+}
+
+type DeleteEmailVerificationTokensParams struct {
+	UserID string
+}
+
+type DeleteEmailVerificationTokensResult struct {
+	DeletedCount int64
+}
+
+type RevokeUserTokensParams struct {
+	UserID string
+}
+
+type RevokeUserTokensResult struct {
+	RevokedCount int64
+	RevokedAt    time.Time
+}
+
+type CreateAuditLogParams struct {
+	Entry sharedkernel.AuditEntry
+}
+
+type CreateAuditLogResult struct {
+	ID string
+}
+
+type SendPasswordResetParams struct {
+	Email     string
+	Name      string
+	ResetLink string
+	ExpiresAt time.Time
+}
+
+type SendPasswordResetResult struct{}
+
+type SendEmailVerificationParams struct {
+	Email            string
+	Name             string
+	VerificationLink string
+	ExpiresAt        time.Time
+}
+
+type SendEmailVerificationResult struct{}
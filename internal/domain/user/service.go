@@ -14,4 +14,49 @@ type UserService interface {
 	ChangePassword(ctx context.Context, input ChangePasswordInput) (ChangePasswordOutput, error)
 
 	UpdateStatus(ctx context.Context, input UpdateStatusInput) (UpdateStatusOutput, error)
+
+	// VerifyEmail activates a pending_verification user with the token from
+	// the verification link.
+	VerifyEmail(ctx context.Context, input VerifyEmailInput) (VerifyEmailOutput, error)
+
+	// ResendVerification sends a new verification link when the email belongs
+	// to a pending_verification user. It succeeds either way so callers cannot
+	// probe for emails.
+	ResendVerification(ctx context.Context, input ResendVerificationInput) (ResendVerificationOutput, error)
+
+	// RequestPasswordReset sends a reset link when the email belongs to an
+	// active user. It succeeds either way so callers cannot probe for emails.
+	RequestPasswordReset(ctx context.Context, input RequestPasswordResetInput) (RequestPasswordResetOutput, error)
+
+	// ResetPassword sets a new password with a reset token and revokes every
+	// token issued to the user.
+	ResetPassword(ctx context.Context, input ResetPasswordInput) (ResetPasswordOutput, error)
+
+	// CreateUser lets an admin create an active user with any role. With a
+	// temporary password the user has to change it before doing anything
+	// else, without one a password reset link is sent instead.
+	CreateUser(ctx context.Context, input CreateUserInput) (CreateUserOutput, error)
+
+	// DeleteUser soft-deletes a user and revokes every token issued to it.
+	DeleteUser(ctx context.Context, input DeleteUserInput) (DeleteUserOutput, error)
+
+	// RestoreUser undoes DeleteUser. The user logs in again with its old
+	// password.
+	RestoreUser(ctx context.Context, input RestoreUserInput) (RestoreUserOutput, error)
+
+	// RequestErasure queues an erasure job for a right-to-be-forgotten
+	// request, deleted users included. Only one job per user is pending or
+	// running at a time.
+	RequestErasure(ctx context.Context, input RequestErasureInput) (RequestErasureOutput, error)
+
+	GetErasureJob(ctx context.Context, input GetErasureJobInput) (GetErasureJobOutput, error)
+
+	// RunErasureJob carries out a pending erasure job right away instead of
+	// waiting for WorkerRunErasureJobs. A job that fails is returned with
+	// the failed status, not as an error.
+	RunErasureJob(ctx context.Context, input RunErasureJobInput) (RunErasureJobOutput, error)
+
+	// WorkerRunErasureJobs carries out the pending erasure jobs, oldest first,
+	// until none is left or ctx is done.
+	WorkerRunErasureJobs(ctx context.Context)
 }
@@ -13,6 +13,10 @@ const (
 	UserRoleUser  UserRole = "user"
 )
 
+func (r UserRole) IsValid() bool {
+	return r == UserRoleAdmin || r == UserRoleUser
+}
+
 // Gender
 type Gender string
 
@@ -24,13 +28,75 @@ const (
 
 // User Entity - base user information
 type User struct {
-	ID        string
-	Email     string
-	Name      string
-	Role      UserRole
-	Status    sharedkernel.UserStatus
-	Gender    *Gender
-	Phone     *string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                     string
+	Email                  string
+	Name                   string
+	Role                   UserRole
+	Status                 sharedkernel.UserStatus
+	Gender                 *Gender
+	Phone                  *string
+	PasswordChangeRequired bool
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+}
+
+// ErasureJobStatus is where an erasure job is: pending until a worker claims
+// it, running while the user is erased, then completed or failed.
+type ErasureJobStatus string
+
+const (
+	ErasureJobStatusPending   ErasureJobStatus = "pending"
+	ErasureJobStatusRunning   ErasureJobStatus = "running"
+	ErasureJobStatusCompleted ErasureJobStatus = "completed"
+	ErasureJobStatusFailed    ErasureJobStatus = "failed"
+)
+
+// ErasureJob is a right-to-be-forgotten request for UserID. RequestedBy is
+// empty when the erasure was started from the command line, Error tells why
+// a failed job failed.
+type ErasureJob struct {
+	ID          string
+	UserID      string
+	RequestedBy string
+	Status      ErasureJobStatus
+	Error       string
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+}
+
+// PasswordResetPolicy configures reset tokens. LinkURL receives the token as
+// the "token" query parameter.
+type PasswordResetPolicy struct {
+	LinkURL  string
+	TokenTTL time.Duration
+}
+
+// EmailVerificationPolicy configures verification tokens. LinkURL receives the
+// token as the "token" query parameter.
+type EmailVerificationPolicy struct {
+	LinkURL  string
+	TokenTTL time.Duration
+}
+
+// MaxPasswordBytes is the longest password bcrypt hashes in full, longer
+// ones would be silently truncated.
+const MaxPasswordBytes = 72
+
+// PasswordPolicy is checked whenever a password is set. MinLength and
+// MaxLength count characters, a password is never longer than
+// MaxPasswordBytes. MinStrengthScore is the lowest accepted zxcvbn score from
+// 0 (disabled) to 4. RejectPersonalInfo refuses passwords containing the
+// email or name of the user, RejectList refuses common passwords regardless
+// of case.
+type PasswordPolicy struct {
+	MinLength          int
+	MaxLength          int
+	RequireUpper       bool
+	RequireLower       bool
+	RequireDigit       bool
+	RequireSymbol      bool
+	MinStrengthScore   int
+	RejectPersonalInfo bool
+	RejectList         []string
 }
@@ -0,0 +1,227 @@
+package infrastructure
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go-bootstrap/internal/config"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/confy"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrJwtKeyNotFound       = errors.New("jwt key not found")
+	ErrJwtNoActiveKey       = errors.New("jwt active key is not configured")
+	ErrJwtUnsupportedAlg    = errors.New("unsupported jwt algorithm")
+	ErrJwtKeyCannotSign     = errors.New("jwt key has no private material")
+	ErrJwtAlgorithmMismatch = errors.New("jwt algorithm does not match key")
+)
+
+// JwtKey is a parsed signing key from config.Jwt.
+type JwtKey struct {
+	ID        string
+	Algorithm string
+	Method    jwt.SigningMethod
+	SignKey   any
+	VerifyKey any
+}
+
+// CanSign reports whether the key holds private material.
+func (k JwtKey) CanSign() bool {
+	return k.SignKey != nil
+}
+
+// JwtKeySet holds the keys used to sign and verify access tokens.
+// It re-reads config.GetJwt() whenever the config file changes,
+// so keys can be rotated by editing env.json.
+type JwtKeySet struct {
+	mu          sync.RWMutex
+	issuer      string
+	audience    []string
+	activeKeyID string
+	keys        map[string]JwtKey
+}
+
+func NewJwtKeySet() (*JwtKeySet, error) {
+	keySet, err := NewJwtKeySetFromConfig(config.GetJwt())
+	if err != nil {
+		return nil, err
+	}
+
+	_, confySubscriptionSignal := confy.Subscribe()
+	go func() {
+		for range confySubscriptionSignal {
+			if err := keySet.load(config.GetJwt()); err != nil {
+				slog.Error("failed to reload jwt keys, keeping previous keys", "error", err)
+				continue
+			}
+			slog.Info("jwt keys reloaded")
+		}
+	}()
+
+	return keySet, nil
+}
+
+// NewJwtKeySetFromConfig builds a static key set that does not follow config reloads.
+func NewJwtKeySetFromConfig(cfg config.Jwt) (*JwtKeySet, error) {
+	keySet := &JwtKeySet{}
+	if err := keySet.load(cfg); err != nil {
+		return nil, err
+	}
+	return keySet, nil
+}
+
+func (k *JwtKeySet) load(cfg config.Jwt) error {
+	keys := make(map[string]JwtKey, len(cfg.Keys))
+	for _, v := range cfg.Keys {
+		key, err := parseJwtKey(v)
+		if err != nil {
+			return fmt.Errorf("failed to parse jwt key %q: %w", v.ID, err)
+		}
+		keys[key.ID] = key
+	}
+
+	active, ok := keys[cfg.ActiveKeyID]
+	if !ok {
+		return ErrJwtNoActiveKey
+	}
+	if !active.CanSign() {
+		return fmt.Errorf("active key %q: %w", active.ID, ErrJwtKeyCannotSign)
+	}
+
+	k.mu.Lock()
+	k.issuer = cfg.Issuer
+	k.audience = cfg.Audience
+	k.activeKeyID = cfg.ActiveKeyID
+	k.keys = keys
+	k.mu.Unlock()
+
+	return nil
+}
+
+// Issuer returns the configured "iss" claim.
+func (k *JwtKeySet) Issuer() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.issuer
+}
+
+// Audience returns the configured "aud" claim.
+func (k *JwtKeySet) Audience() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.audience
+}
+
+// ActiveKey returns the key that signs new tokens.
+func (k *JwtKeySet) ActiveKey() JwtKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[k.activeKeyID]
+}
+
+// Key returns the key with the given kid.
+func (k *JwtKeySet) Key(kid string) (JwtKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return JwtKey{}, ErrJwtKeyNotFound
+	}
+	return key, nil
+}
+
+// Keys returns every configured key, active and retired.
+func (k *JwtKeySet) Keys() []JwtKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]JwtKey, 0, len(k.keys))
+	for _, v := range k.keys {
+		keys = append(keys, v)
+	}
+	return keys
+}
+
+// Keyfunc resolves the verification key from the token "kid" header.
+// It is meant to be passed to jwt.Parse.
+func (k *JwtKeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := k.Key(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrJwtAlgorithmMismatch
+	}
+
+	return key.VerifyKey, nil
+}
+
+func parseJwtKey(cfg config.JwtKey) (JwtKey, error) {
+	key := JwtKey{
+		ID:        cfg.ID,
+		Algorithm: cfg.Algorithm,
+	}
+
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if cfg.Secret == "" {
+			return JwtKey{}, errors.New("secret is required for HS256")
+		}
+		key.Method = jwt.SigningMethodHS256
+		key.SignKey = []byte(cfg.Secret)
+		key.VerifyKey = []byte(cfg.Secret)
+
+	case jwt.SigningMethodRS256.Alg():
+		key.Method = jwt.SigningMethodRS256
+		if cfg.PrivateKey != "" {
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cfg.PrivateKey))
+			if err != nil {
+				return JwtKey{}, err
+			}
+			key.SignKey = privateKey
+			key.VerifyKey = &privateKey.PublicKey
+		}
+		if key.VerifyKey == nil && cfg.PublicKey != "" {
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.PublicKey))
+			if err != nil {
+				return JwtKey{}, err
+			}
+			key.VerifyKey = publicKey
+		}
+
+	case jwt.SigningMethodEdDSA.Alg():
+		key.Method = jwt.SigningMethodEdDSA
+		if cfg.PrivateKey != "" {
+			privateKey, err := jwt.ParseEdPrivateKeyFromPEM([]byte(cfg.PrivateKey))
+			if err != nil {
+				return JwtKey{}, err
+			}
+			key.SignKey = privateKey
+			key.VerifyKey = privateKey.(crypto.Signer).Public()
+		}
+		if key.VerifyKey == nil && cfg.PublicKey != "" {
+			publicKey, err := jwt.ParseEdPublicKeyFromPEM([]byte(cfg.PublicKey))
+			if err != nil {
+				return JwtKey{}, err
+			}
+			key.VerifyKey = publicKey
+		}
+
+	default:
+		return JwtKey{}, ErrJwtUnsupportedAlg
+	}
+
+	if key.VerifyKey == nil {
+		return JwtKey{}, errors.New("private_key or public_key is required")
+	}
+
+	return key, nil
+}
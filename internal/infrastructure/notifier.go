@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"go-bootstrap/internal/config"
+)
+
+// Notification is a message addressed to a single user.
+type Notification struct {
+	Kind      string    `json:"kind"`
+	To        string    `json:"to"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Notifier delivers notifications to users. Implementations must be safe for
+// concurrent use.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+func NewNotifier() (Notifier, error) {
+	return NewNotifierFromConfig(config.GetNotifier())
+}
+
+func NewNotifierFromConfig(cfg config.Notifier) (Notifier, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return &logNotifier{}, nil
+	case "file":
+		if cfg.FilePath == "" {
+			return nil, fmt.Errorf("notifier file_path is required for driver file")
+		}
+		return &fileNotifier{path: cfg.FilePath}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier driver %q", cfg.Driver)
+	}
+}
+
+// logNotifier writes notifications to the application log.
+type logNotifier struct{}
+
+func (n *logNotifier) Notify(ctx context.Context, notification Notification) error {
+	slog.InfoContext(ctx, "notification",
+		slog.String("kind", notification.Kind),
+		slog.String("to", notification.To),
+		slog.String("subject", notification.Subject),
+		slog.String("body", notification.Body),
+	)
+	return nil
+}
+
+// fileNotifier appends notifications as JSON lines to a file.
+type fileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (n *fileNotifier) Notify(_ context.Context, notification Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open notifier file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package infrastructure
+
+import (
+	"log/slog"
+	"sync"
+
+	"go-bootstrap/internal/config"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/confy"
+)
+
+type OauthClient struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// OauthClients holds the introspection and revocation clients from
+// config.Oauth. It re-reads config.GetOauth() whenever the config file
+// changes, so secrets can be rotated without a restart.
+type OauthClients struct {
+	mu      sync.RWMutex
+	clients map[string]OauthClient
+}
+
+func NewOauthClients() *OauthClients {
+	oauthClients := NewOauthClientsFromConfig(config.GetOauth())
+
+	_, confySubscriptionSignal := confy.Subscribe()
+	go func() {
+		for range confySubscriptionSignal {
+			oauthClients.load(config.GetOauth())
+			slog.Info("oauth clients reloaded")
+		}
+	}()
+
+	return oauthClients
+}
+
+// NewOauthClientsFromConfig builds a static client list that does not follow config reloads.
+func NewOauthClientsFromConfig(cfg config.Oauth) *OauthClients {
+	oauthClients := &OauthClients{}
+	oauthClients.load(cfg)
+	return oauthClients
+}
+
+func (p *OauthClients) load(cfg config.Oauth) {
+	clients := make(map[string]OauthClient, len(cfg.Clients))
+	for _, v := range cfg.Clients {
+		if v.ClientID == "" || v.ClientSecret == "" {
+			slog.Warn("oauth client without client_id or client_secret ignored", "client_id", v.ClientID)
+			continue
+		}
+		clients[v.ClientID] = OauthClient{
+			ClientID:     v.ClientID,
+			ClientSecret: v.ClientSecret,
+		}
+	}
+
+	p.mu.Lock()
+	p.clients = clients
+	p.mu.Unlock()
+}
+
+// Get returns the client registered under clientID.
+func (p *OauthClients) Get(clientID string) (OauthClient, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	client, ok := p.clients[clientID]
+	return client, ok
+}
@@ -0,0 +1,84 @@
+package infrastructure
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+
+	"go-bootstrap/internal/config"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/confy"
+)
+
+var defaultOidcScopes = []string{"openid", "email", "profile"}
+
+type OidcProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OidcProviders holds the OpenID Connect providers from config.Oidc.
+// It re-reads config.GetOidc() whenever the config file changes.
+type OidcProviders struct {
+	mu        sync.RWMutex
+	providers map[string]OidcProvider
+}
+
+func NewOidcProviders() *OidcProviders {
+	oidcProviders := NewOidcProvidersFromConfig(config.GetOidc())
+
+	_, confySubscriptionSignal := confy.Subscribe()
+	go func() {
+		for range confySubscriptionSignal {
+			oidcProviders.load(config.GetOidc())
+			slog.Info("oidc providers reloaded")
+		}
+	}()
+
+	return oidcProviders
+}
+
+// NewOidcProvidersFromConfig builds a static provider list that does not follow config reloads.
+func NewOidcProvidersFromConfig(cfg config.Oidc) *OidcProviders {
+	oidcProviders := &OidcProviders{}
+	oidcProviders.load(cfg)
+	return oidcProviders
+}
+
+func (p *OidcProviders) load(cfg config.Oidc) {
+	providers := make(map[string]OidcProvider, len(cfg.Providers))
+	for _, v := range cfg.Providers {
+		scopes := v.Scopes
+		if len(scopes) == 0 {
+			scopes = defaultOidcScopes
+		}
+		if !slices.Contains(scopes, "openid") {
+			scopes = append([]string{"openid"}, scopes...)
+		}
+		providers[v.Name] = OidcProvider{
+			Name:         v.Name,
+			Issuer:       v.Issuer,
+			ClientID:     v.ClientID,
+			ClientSecret: v.ClientSecret,
+			RedirectURL:  v.RedirectURL,
+			Scopes:       scopes,
+		}
+	}
+
+	p.mu.Lock()
+	p.providers = providers
+	p.mu.Unlock()
+}
+
+// Get returns the provider registered under name.
+func (p *OidcProviders) Get(name string) (OidcProvider, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	provider, ok := p.providers[name]
+	return provider, ok
+}
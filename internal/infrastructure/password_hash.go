@@ -0,0 +1,195 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-bootstrap/internal/config"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PasswordHashAlgorithmArgon2id = "argon2id"
+	PasswordHashAlgorithmBcrypt   = "bcrypt"
+)
+
+// Defaults follow the OWASP password storage recommendation for argon2id.
+const (
+	defaultArgon2idMemory      = 19 * 1024
+	defaultArgon2idIterations  = 2
+	defaultArgon2idParallelism = 1
+	defaultArgon2idSaltLength  = 16
+	defaultArgon2idKeyLength   = 32
+)
+
+var ErrPasswordHashAlgorithmUnknown = errors.New("password hash algorithm must be argon2id or bcrypt")
+
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	saltLength  uint32
+	keyLength   uint32
+}
+
+// PasswordHasher implements sharedkernel.PasswordHasher. New hashes use the
+// configured algorithm; bcrypt hashes ($2a$, $2b$, $2y$) and argon2id hashes
+// in the PHC string format ($argon2id$v=19$m=...,t=...,p=...$salt$key) are
+// both verified, so existing users keep logging in after a switch.
+type PasswordHasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2id   argon2idParams
+}
+
+func NewPasswordHasher() (*PasswordHasher, error) {
+	return NewPasswordHasherFromConfig(config.GetPasswordHash())
+}
+
+func NewPasswordHasherFromConfig(cfg config.PasswordHash) (*PasswordHasher, error) {
+	h := &PasswordHasher{
+		algorithm:  cfg.Algorithm,
+		bcryptCost: cfg.BcryptCost,
+		argon2id: argon2idParams{
+			memory:      cfg.Argon2id.Memory,
+			iterations:  cfg.Argon2id.Iterations,
+			parallelism: cfg.Argon2id.Parallelism,
+			saltLength:  cfg.Argon2id.SaltLength,
+			keyLength:   cfg.Argon2id.KeyLength,
+		},
+	}
+
+	if h.algorithm == "" {
+		h.algorithm = PasswordHashAlgorithmArgon2id
+	}
+	if h.algorithm != PasswordHashAlgorithmArgon2id && h.algorithm != PasswordHashAlgorithmBcrypt {
+		return nil, ErrPasswordHashAlgorithmUnknown
+	}
+	if h.bcryptCost == 0 {
+		h.bcryptCost = bcrypt.DefaultCost
+	}
+	if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if h.argon2id.memory == 0 {
+		h.argon2id.memory = defaultArgon2idMemory
+	}
+	if h.argon2id.iterations == 0 {
+		h.argon2id.iterations = defaultArgon2idIterations
+	}
+	if h.argon2id.parallelism == 0 {
+		h.argon2id.parallelism = defaultArgon2idParallelism
+	}
+	if h.argon2id.saltLength == 0 {
+		h.argon2id.saltLength = defaultArgon2idSaltLength
+	}
+	if h.argon2id.keyLength == 0 {
+		h.argon2id.keyLength = defaultArgon2idKeyLength
+	}
+
+	return h, nil
+}
+
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.algorithm == PasswordHashAlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, h.argon2id.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.argon2id.iterations, h.argon2id.memory, h.argon2id.parallelism, h.argon2id.keyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.argon2id.memory,
+		h.argon2id.iterations,
+		h.argon2id.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *PasswordHasher) Verify(password, hash string) (bool, bool, error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return h.verifyArgon2id(password, hash)
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return h.verifyBcrypt(password, hash)
+	default:
+		return false, false, sharedkernel.ErrPasswordHashUnsupported
+	}
+}
+
+func (h *PasswordHasher) verifyBcrypt(password, hash string) (bool, bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("%w: %v", sharedkernel.ErrPasswordHashUnsupported, err)
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, false, fmt.Errorf("%w: %v", sharedkernel.ErrPasswordHashUnsupported, err)
+	}
+
+	needsRehash := h.algorithm != PasswordHashAlgorithmBcrypt || cost != h.bcryptCost
+	return true, needsRehash, nil
+}
+
+func (h *PasswordHasher) verifyArgon2id(password, hash string) (bool, bool, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false, sharedkernel.ErrPasswordHashUnsupported
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, sharedkernel.ErrPasswordHashUnsupported
+	}
+
+	var params argon2idParams
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism)
+	if err != nil || params.iterations == 0 || params.parallelism == 0 {
+		return false, false, sharedkernel.ErrPasswordHashUnsupported
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, sharedkernel.ErrPasswordHashUnsupported
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false, sharedkernel.ErrPasswordHashUnsupported
+	}
+	params.saltLength = uint32(len(salt))
+	params.keyLength = uint32(len(key))
+
+	candidate := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, params.keyLength)
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return false, false, nil
+	}
+
+	needsRehash := h.algorithm != PasswordHashAlgorithmArgon2id ||
+		params.memory != h.argon2id.memory ||
+		params.iterations != h.argon2id.iterations ||
+		params.parallelism != h.argon2id.parallelism ||
+		params.saltLength != h.argon2id.saltLength ||
+		params.keyLength != h.argon2id.keyLength
+	return true, needsRehash, nil
+}
@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"log/slog"
+	"sync"
+
+	"go-bootstrap/internal/config"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/confy"
+)
+
+// RolePolicy holds the role to permission mapping from config.Policy.
+// It re-reads config.GetPolicy() whenever the config file changes.
+type RolePolicy struct {
+	mu    sync.RWMutex
+	roles map[string][]string
+}
+
+func NewRolePolicy() *RolePolicy {
+	rolePolicy := NewRolePolicyFromConfig(config.GetPolicy())
+
+	_, confySubscriptionSignal := confy.Subscribe()
+	go func() {
+		for range confySubscriptionSignal {
+			rolePolicy.load(config.GetPolicy())
+			slog.Info("role policy reloaded")
+		}
+	}()
+
+	return rolePolicy
+}
+
+// NewRolePolicyFromConfig builds a static policy that does not follow config reloads.
+func NewRolePolicyFromConfig(cfg config.Policy) *RolePolicy {
+	rolePolicy := &RolePolicy{}
+	rolePolicy.load(cfg)
+	return rolePolicy
+}
+
+func (p *RolePolicy) load(cfg config.Policy) {
+	roles := make(map[string][]string, len(cfg.Roles))
+	for _, v := range cfg.Roles {
+		roles[v.Role] = append(roles[v.Role], v.Permissions...)
+	}
+
+	p.mu.Lock()
+	p.roles = roles
+	p.mu.Unlock()
+}
+
+// Permissions returns the permissions granted to role.
+func (p *RolePolicy) Permissions(role string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	permissions := make([]string, len(p.roles[role]))
+	copy(permissions, p.roles[role])
+	return permissions
+}
@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"go-bootstrap/internal/config"
+)
+
+var ErrSecretEncryptionKeyInvalid = errors.New("secret encryption key must be a base64 encoded 32 byte key")
+
+// SecretCipher encrypts secrets that have to be read back, unlike tokens
+// which only need to be compared and are hashed by TokenHasher.
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+func NewSecretCipher() (*SecretCipher, error) {
+	return NewSecretCipherFromConfig(config.GetSecretEncryption())
+}
+
+func NewSecretCipherFromConfig(cfg config.SecretEncryption) (*SecretCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(cfg.Key)
+	if err != nil || len(key) != 32 {
+		return nil, ErrSecretEncryptionKeyInvalid
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretCipher{
+		aead: aead,
+	}, nil
+}
+
+// Encrypt returns base64(nonce || AES-256-GCM ciphertext).
+func (c *SecretCipher) Encrypt(plaintext []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *SecretCipher) Decrypt(ciphertext string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret: %w", err)
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.New("secret ciphertext too short")
+	}
+
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	return plaintext, nil
+}
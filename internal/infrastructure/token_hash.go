@@ -0,0 +1,37 @@
+package infrastructure
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"go-bootstrap/internal/config"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/security/signature"
+)
+
+var ErrTokenHashPepperRequired = errors.New("token hash pepper is not configured")
+
+// TokenHasher turns opaque tokens into the keyed hash stored in the database,
+// so a database dump alone cannot be replayed as bearer tokens.
+type TokenHasher struct {
+	pepper string
+}
+
+func NewTokenHasher() (*TokenHasher, error) {
+	return NewTokenHasherFromConfig(config.GetTokenHash())
+}
+
+func NewTokenHasherFromConfig(cfg config.TokenHash) (*TokenHasher, error) {
+	if cfg.Pepper == "" {
+		return nil, ErrTokenHashPepperRequired
+	}
+
+	return &TokenHasher{
+		pepper: cfg.Pepper,
+	}, nil
+}
+
+// Hash returns the hex encoded HMAC-SHA256 of token.
+func (h *TokenHasher) Hash(token string) (string, error) {
+	return signature.CreateHMAC(token, h.pepper, sha256.New)
+}
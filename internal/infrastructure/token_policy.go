@@ -0,0 +1,113 @@
+package infrastructure
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"go-bootstrap/internal/config"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/confy"
+)
+
+// TokenLifetime is the token lifetime and session timeouts of one role.
+// Zero values are left to the caller's defaults.
+type TokenLifetime struct {
+	AccessTokenTTL         time.Duration
+	RefreshTokenTTL        time.Duration
+	SessionAbsoluteTimeout time.Duration
+	SessionIdleTimeout     time.Duration
+}
+
+// TokenPolicy holds the token lifetimes from config.Auth.
+// It re-reads config.GetAuth() whenever the config file changes.
+type TokenPolicy struct {
+	mu                    sync.RWMutex
+	defaults              TokenLifetime
+	roles                 map[string]TokenLifetime
+	expiredTokenGrace     time.Duration
+	impersonationTokenTTL time.Duration
+}
+
+func NewTokenPolicy() *TokenPolicy {
+	tokenPolicy := NewTokenPolicyFromConfig(config.GetAuth())
+
+	_, confySubscriptionSignal := confy.Subscribe()
+	go func() {
+		for range confySubscriptionSignal {
+			tokenPolicy.load(config.GetAuth())
+			slog.Info("token policy reloaded")
+		}
+	}()
+
+	return tokenPolicy
+}
+
+// NewTokenPolicyFromConfig builds a static policy that does not follow config reloads.
+func NewTokenPolicyFromConfig(cfg config.Auth) *TokenPolicy {
+	tokenPolicy := &TokenPolicy{}
+	tokenPolicy.load(cfg)
+	return tokenPolicy
+}
+
+func (p *TokenPolicy) load(cfg config.Auth) {
+	defaults := TokenLifetime{
+		AccessTokenTTL:         cfg.AccessTokenTTL,
+		RefreshTokenTTL:        cfg.RefreshTokenTTL,
+		SessionAbsoluteTimeout: cfg.SessionAbsoluteTimeout,
+		SessionIdleTimeout:     cfg.SessionIdleTimeout,
+	}
+
+	roles := make(map[string]TokenLifetime, len(cfg.Roles))
+	for _, v := range cfg.Roles {
+		lifetime := defaults
+		if v.AccessTokenTTL != 0 {
+			lifetime.AccessTokenTTL = v.AccessTokenTTL
+		}
+		if v.RefreshTokenTTL != 0 {
+			lifetime.RefreshTokenTTL = v.RefreshTokenTTL
+		}
+		if v.SessionAbsoluteTimeout != 0 {
+			lifetime.SessionAbsoluteTimeout = v.SessionAbsoluteTimeout
+		}
+		if v.SessionIdleTimeout != 0 {
+			lifetime.SessionIdleTimeout = v.SessionIdleTimeout
+		}
+		roles[v.Role] = lifetime
+	}
+
+	p.mu.Lock()
+	p.defaults = defaults
+	p.roles = roles
+	p.expiredTokenGrace = cfg.ExpiredTokenGrace
+	p.impersonationTokenTTL = cfg.ImpersonationTokenTTL
+	p.mu.Unlock()
+}
+
+// Lifetime returns the lifetime of role, or the top-level values when the
+// role has no override.
+func (p *TokenPolicy) Lifetime(role string) TokenLifetime {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if lifetime, ok := p.roles[role]; ok {
+		return lifetime
+	}
+	return p.defaults
+}
+
+// ExpiredTokenGrace returns how long expired tokens are kept before cleanup.
+func (p *TokenPolicy) ExpiredTokenGrace() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.expiredTokenGrace
+}
+
+// ImpersonationTokenTTL returns the lifetime of an impersonation token.
+func (p *TokenPolicy) ImpersonationTokenTTL() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.impersonationTokenTTL
+}
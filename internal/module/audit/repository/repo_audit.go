@@ -0,0 +1,13 @@
+package auditrepository
+
+import "go-bootstrap/internal/infrastructure"
+
+type repository struct {
+	db infrastructure.DB
+}
+
+func NewRepository(db infrastructure.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
@@ -0,0 +1,126 @@
+package auditrepository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	domainaudit "go-bootstrap/internal/domain/audit"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+func (r *repository) GetListAuditLog(ctx context.Context, filters domainaudit.GetListAuditLogFilters) (domainaudit.GetListAuditLogResult, error) {
+	where := sq.And{}
+	if filters.Action != nil {
+		where = append(where, sq.Eq{"action": *filters.Action})
+	}
+	if filters.Outcome != nil {
+		where = append(where, sq.Eq{"outcome": *filters.Outcome})
+	}
+	if filters.UserID != nil {
+		where = append(where, sq.Or{
+			sq.Eq{"actor_user_id": *filters.UserID},
+			sq.Eq{"target_user_id": *filters.UserID},
+		})
+	}
+	if filters.ActorUserID != nil {
+		where = append(where, sq.Eq{"actor_user_id": *filters.ActorUserID})
+	}
+	if filters.TargetUserID != nil {
+		where = append(where, sq.Eq{"target_user_id": *filters.TargetUserID})
+	}
+	if filters.IPAddress != nil {
+		where = append(where, sq.Eq{"ip_address": *filters.IPAddress})
+	}
+	if filters.RequestID != nil {
+		where = append(where, sq.Eq{"request_id": *filters.RequestID})
+	}
+	if filters.From != nil {
+		where = append(where, sq.GtOrEq{"created_at": *filters.From})
+	}
+	if filters.To != nil {
+		where = append(where, sq.Lt{"created_at": *filters.To})
+	}
+
+	countSq := r.db.Sq().Select("COUNT(*)").From("audit_logs").Where(where)
+	selectSq := r.db.Sq().Select(
+		"id",
+		"action",
+		"outcome",
+		"reason",
+		"actor_user_id",
+		"target_user_id",
+		"details",
+		"ip_address",
+		"user_agent",
+		"request_id",
+		"created_at",
+	).From("audit_logs").Where(where).OrderBy("created_at DESC", "id DESC")
+
+	auditLogs := []domainaudit.AuditLog{}
+	pagination, err := r.db.RDBMS().QuerySqPagination(ctx, countSq, selectSq, false, filters.Pagination, func(rows *sql.Rows) error {
+		for rows.Next() {
+			var (
+				auditLog     domainaudit.AuditLog
+				actorUserID  sql.NullString
+				targetUserID sql.NullString
+				details      string
+			)
+			err := rows.Scan(
+				&auditLog.ID,
+				&auditLog.Action,
+				&auditLog.Outcome,
+				&auditLog.Reason,
+				&actorUserID,
+				&targetUserID,
+				&details,
+				&auditLog.IPAddress,
+				&auditLog.UserAgent,
+				&auditLog.RequestID,
+				&auditLog.CreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to scan audit log: %w", err)
+			}
+			auditLog.ActorUserID = actorUserID.String
+			auditLog.TargetUserID = targetUserID.String
+			if err = json.Unmarshal([]byte(details), &auditLog.Details); err != nil {
+				return fmt.Errorf("failed to unmarshal audit log details: %w", err)
+			}
+			auditLogs = append(auditLogs, auditLog)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domainaudit.GetListAuditLogResult{}, err
+	}
+
+	return domainaudit.GetListAuditLogResult{
+		AuditLogs:  auditLogs,
+		Pagination: pagination,
+	}, nil
+}
+
+func (r *repository) DeleteAuditLogs(ctx context.Context, params domainaudit.DeleteAuditLogsParams) (domainaudit.DeleteAuditLogsResult, error) {
+	query := `
+		DELETE FROM audit_logs
+		WHERE created_at < ?
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query, params.BeforeDate)
+	if err != nil {
+		return domainaudit.DeleteAuditLogsResult{}, fmt.Errorf("failed to delete audit logs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainaudit.DeleteAuditLogsResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainaudit.DeleteAuditLogsResult{
+		DeletedCount: rowsAffected,
+	}, nil
+}
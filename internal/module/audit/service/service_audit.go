@@ -0,0 +1,78 @@
+package auditservice
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	domainaudit "go-bootstrap/internal/domain/audit"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+)
+
+type service struct {
+	auditRepo       domainaudit.AuditRepositoryDatastore
+	retentionPolicy domainaudit.RetentionPolicy
+}
+
+func NewService(
+	auditRepo domainaudit.AuditRepositoryDatastore,
+	retentionPolicy domainaudit.RetentionPolicy,
+) *service {
+	return &service{
+		auditRepo:       auditRepo,
+		retentionPolicy: retentionPolicy,
+	}
+}
+
+func (s *service) GetListAuditLog(ctx context.Context, input domainaudit.GetListAuditLogInput) (domainaudit.GetListAuditLogOutput, error) {
+	if input.Pagination.Page <= 0 {
+		input.Pagination.Page = 1
+	}
+	if input.Pagination.PageSize <= 0 {
+		input.Pagination.PageSize = 10
+	}
+	if input.Action != nil && !input.Action.IsValid() {
+		return domainaudit.GetListAuditLogOutput{}, apperror.BadRequest("invalid action")
+	}
+	if input.Outcome != nil && !input.Outcome.IsValid() {
+		return domainaudit.GetListAuditLogOutput{}, apperror.BadRequest("invalid outcome")
+	}
+	if input.From != nil && input.To != nil && !input.From.Before(*input.To) {
+		return domainaudit.GetListAuditLogOutput{}, apperror.BadRequest("from must be before to")
+	}
+
+	result, err := s.auditRepo.GetListAuditLog(ctx, domainaudit.GetListAuditLogFilters(input))
+	if err != nil {
+		return domainaudit.GetListAuditLogOutput{}, apperror.StdUnknown(err)
+	}
+
+	return domainaudit.GetListAuditLogOutput{
+		AuditLogs:  result.AuditLogs,
+		Pagination: result.Pagination,
+	}, nil
+}
+
+func (s *service) WorkerDeleteExpiredAuditLogs(ctx context.Context) {
+	if s.retentionPolicy.Retention <= 0 {
+		slog.Info("Audit log retention is disabled, nothing to delete")
+		return
+	}
+	beforeDate := time.Now().UTC().Add(-s.retentionPolicy.Retention)
+
+	result, err := s.auditRepo.DeleteAuditLogs(ctx, domainaudit.DeleteAuditLogsParams{
+		BeforeDate: beforeDate,
+	})
+	if err != nil {
+		slog.Error("Failed to delete expired audit logs",
+			"error", err,
+			"before_date", beforeDate,
+		)
+		return
+	}
+
+	slog.Info("Expired audit logs deleted",
+		"deleted_count", result.DeletedCount,
+		"before_date", beforeDate,
+	)
+}
@@ -0,0 +1,90 @@
+package auditservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainaudit "go-bootstrap/internal/domain/audit"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	auditservice "go-bootstrap/internal/module/audit/service"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/generic"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuditRepo struct {
+	filters   domainaudit.GetListAuditLogFilters
+	deletions []time.Time
+}
+
+func (f *fakeAuditRepo) GetListAuditLog(ctx context.Context, filters domainaudit.GetListAuditLogFilters) (domainaudit.GetListAuditLogResult, error) {
+	f.filters = filters
+	return domainaudit.GetListAuditLogResult{
+		AuditLogs:  []domainaudit.AuditLog{{ID: "1"}},
+		Pagination: primitive.CreatePaginationOutput(filters.Pagination, 1),
+	}, nil
+}
+
+func (f *fakeAuditRepo) DeleteAuditLogs(ctx context.Context, params domainaudit.DeleteAuditLogsParams) (domainaudit.DeleteAuditLogsResult, error) {
+	f.deletions = append(f.deletions, params.BeforeDate)
+	return domainaudit.DeleteAuditLogsResult{DeletedCount: 3}, nil
+}
+
+func TestService_GetListAuditLog(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("defaults the pagination and passes filters", func(t *testing.T) {
+		repo := &fakeAuditRepo{}
+		svc := auditservice.NewService(repo, domainaudit.RetentionPolicy{})
+
+		action := sharedkernel.AuditActionLogin
+		output, err := svc.GetListAuditLog(ctx, domainaudit.GetListAuditLogInput{
+			Action: &action,
+			UserID: generic.ToPtr("7"),
+		})
+		require.NoError(t, err)
+		assert.Len(t, output.AuditLogs, 1)
+		assert.Equal(t, primitive.PaginationInput{Page: 1, PageSize: 10}, repo.filters.Pagination)
+		assert.Equal(t, &action, repo.filters.Action)
+		assert.Equal(t, "7", *repo.filters.UserID)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		svc := auditservice.NewService(&fakeAuditRepo{}, domainaudit.RetentionPolicy{})
+		now := time.Now()
+
+		for name, input := range map[string]domainaudit.GetListAuditLogInput{
+			"action":  {Action: generic.ToPtr(sharedkernel.AuditAction("login_everywhere"))},
+			"outcome": {Outcome: generic.ToPtr(sharedkernel.AuditOutcome("maybe"))},
+			"range":   {From: &now, To: &now},
+		} {
+			_, err := svc.GetListAuditLog(ctx, input)
+			assert.True(t, apperror.IsBadRequest(err), "%s: expected bad request, got %v", name, err)
+		}
+	})
+}
+
+func TestService_WorkerDeleteExpiredAuditLogs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes entries older than the retention", func(t *testing.T) {
+		repo := &fakeAuditRepo{}
+		svc := auditservice.NewService(repo, domainaudit.RetentionPolicy{Retention: 90 * 24 * time.Hour})
+
+		svc.WorkerDeleteExpiredAuditLogs(ctx)
+		require.Len(t, repo.deletions, 1)
+		assert.WithinDuration(t, time.Now().Add(-90*24*time.Hour), repo.deletions[0], time.Minute)
+	})
+
+	t.Run("no retention keeps everything", func(t *testing.T) {
+		repo := &fakeAuditRepo{}
+		svc := auditservice.NewService(repo, domainaudit.RetentionPolicy{})
+
+		svc.WorkerDeleteExpiredAuditLogs(ctx)
+		assert.Empty(t, repo.deletions)
+	})
+}
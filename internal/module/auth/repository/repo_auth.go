@@ -1,13 +1,195 @@
 package authrepository
 
-import "go-bootstrap/internal/infrastructure"
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/infrastructure"
+)
 
 type repository struct {
-	db infrastructure.DB
+	db          infrastructure.DB
+	tokenHasher *infrastructure.TokenHasher
 }
 
-func NewRepository(db infrastructure.DB) *repository {
+func NewRepository(db infrastructure.DB, tokenHasher *infrastructure.TokenHasher) *repository {
 	return &repository{
+		db:          db,
+		tokenHasher: tokenHasher,
+	}
+}
+
+type jwtRepository struct {
+	keySet *infrastructure.JwtKeySet
+}
+
+func NewJwtRepository(keySet *infrastructure.JwtKeySet) *jwtRepository {
+	return &jwtRepository{
+		keySet: keySet,
+	}
+}
+
+type loginAttemptRepository struct {
+	db infrastructure.DB
+}
+
+func NewLoginAttemptRepository(db infrastructure.DB) *loginAttemptRepository {
+	return &loginAttemptRepository{
+		db: db,
+	}
+}
+
+type mfaRepository struct {
+	db           infrastructure.DB
+	tokenHasher  *infrastructure.TokenHasher
+	secretCipher *infrastructure.SecretCipher
+}
+
+func NewMfaRepository(db infrastructure.DB, tokenHasher *infrastructure.TokenHasher, secretCipher *infrastructure.SecretCipher) *mfaRepository {
+	return &mfaRepository{
+		db:           db,
+		tokenHasher:  tokenHasher,
+		secretCipher: secretCipher,
+	}
+}
+
+type identityRepository struct {
+	db           infrastructure.DB
+	tokenHasher  *infrastructure.TokenHasher
+	secretCipher *infrastructure.SecretCipher
+}
+
+func NewIdentityRepository(db infrastructure.DB, tokenHasher *infrastructure.TokenHasher, secretCipher *infrastructure.SecretCipher) *identityRepository {
+	return &identityRepository{
+		db:           db,
+		tokenHasher:  tokenHasher,
+		secretCipher: secretCipher,
+	}
+}
+
+type oidcRepository struct {
+	providers  *infrastructure.OidcProviders
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery map[string]oidcDiscovery // by issuer
+}
+
+// NewOidcRepository calls the providers with httpClient. Discovery documents
+// are fetched once per issuer, signing keys on every code exchange.
+func NewOidcRepository(providers *infrastructure.OidcProviders, httpClient *http.Client) *oidcRepository {
+	return &oidcRepository{
+		providers:  providers,
+		httpClient: httpClient,
+		discovery:  make(map[string]oidcDiscovery),
+	}
+}
+
+type tokenPolicyRepository struct {
+	tokenPolicy *infrastructure.TokenPolicy
+}
+
+func NewTokenPolicyRepository(tokenPolicy *infrastructure.TokenPolicy) *tokenPolicyRepository {
+	return &tokenPolicyRepository{
+		tokenPolicy: tokenPolicy,
+	}
+}
+
+type rolePolicyRepository struct {
+	rolePolicy *infrastructure.RolePolicy
+}
+
+func NewRolePolicyRepository(rolePolicy *infrastructure.RolePolicy) *rolePolicyRepository {
+	return &rolePolicyRepository{
+		rolePolicy: rolePolicy,
+	}
+}
+
+type oauthClientRepository struct {
+	clients *infrastructure.OauthClients
+}
+
+func NewOauthClientRepository(clients *infrastructure.OauthClients) *oauthClientRepository {
+	return &oauthClientRepository{
+		clients: clients,
+	}
+}
+
+type auditLogRepository struct {
+	db infrastructure.DB
+}
+
+func NewAuditLogRepository(db infrastructure.DB) *auditLogRepository {
+	return &auditLogRepository{
 		db: db,
 	}
 }
+
+type magicLinkRepository struct {
+	db          infrastructure.DB
+	tokenHasher *infrastructure.TokenHasher
+}
+
+func NewMagicLinkRepository(db infrastructure.DB, tokenHasher *infrastructure.TokenHasher) *magicLinkRepository {
+	return &magicLinkRepository{
+		db:          db,
+		tokenHasher: tokenHasher,
+	}
+}
+
+type dpopReplayRepository struct {
+	db          infrastructure.DB
+	tokenHasher *infrastructure.TokenHasher
+}
+
+func NewDpopReplayRepository(db infrastructure.DB, tokenHasher *infrastructure.TokenHasher) *dpopReplayRepository {
+	return &dpopReplayRepository{
+		db:          db,
+		tokenHasher: tokenHasher,
+	}
+}
+
+type notifierRepository struct {
+	notifier infrastructure.Notifier
+}
+
+func NewNotifierRepository(notifier infrastructure.Notifier) *notifierRepository {
+	return &notifierRepository{
+		notifier: notifier,
+	}
+}
+
+type loginAttemptMemoryRepository struct {
+	mu       sync.Mutex
+	attempts map[loginAttemptKey]*domainauth.GetDetailLoginAttemptResult
+}
+
+type loginAttemptKey struct {
+	scope      domainauth.LoginAttemptScope
+	identifier string
+}
+
+// NewLoginAttemptMemoryRepository keeps counters in process memory. Counters
+// are lost on restart and not shared between replicas, use it for single
+// instance deployments and tests.
+func NewLoginAttemptMemoryRepository() *loginAttemptMemoryRepository {
+	return &loginAttemptMemoryRepository{
+		attempts: make(map[loginAttemptKey]*domainauth.GetDetailLoginAttemptResult),
+	}
+}
+
+type dpopReplayMemoryRepository struct {
+	mu   sync.Mutex
+	jtis map[string]time.Time // expiry by jkt and jti
+}
+
+// NewDpopReplayMemoryRepository keeps used proofs in process memory. They are
+// lost on restart and not shared between replicas, use it for single instance
+// deployments and tests.
+func NewDpopReplayMemoryRepository() *dpopReplayMemoryRepository {
+	return &dpopReplayMemoryRepository{
+		jtis: make(map[string]time.Time),
+	}
+}
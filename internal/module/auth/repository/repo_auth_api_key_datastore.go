@@ -0,0 +1,237 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+// scopes and allowed_ips are stored space separated, neither a permission
+// nor an IP range contains a space.
+
+func (r *repository) CreateApiKey(ctx context.Context, params domainauth.CreateApiKeyParams) (domainauth.CreateApiKeyResult, error) {
+	keyHash, err := r.tokenHasher.Hash(params.Key)
+	if err != nil {
+		return domainauth.CreateApiKeyResult{}, fmt.Errorf("failed to hash api key: %w", err)
+	}
+
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, allowed_ips, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		RETURNING id
+	`
+
+	var result domainauth.CreateApiKeyResult
+	err = r.db.RDBMS().QueryRowContext(ctx, query,
+		params.UserID,
+		params.Name,
+		params.Prefix,
+		keyHash,
+		strings.Join(params.Scopes, " "),
+		strings.Join(params.AllowedIPs, " "),
+		params.ExpiresAt,
+		params.CreatedAt,
+	).Scan(&result.ID)
+	if err != nil {
+		return domainauth.CreateApiKeyResult{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *repository) GetDetailApiKey(ctx context.Context, filters domainauth.GetDetailApiKeyFilters) (domainauth.GetDetailApiKeyResult, error) {
+	sq := r.db.Sq().Select(apiKeyColumns...).From("api_keys")
+
+	if filters.ApiKeyID != nil {
+		sq = sq.Where("id = ?", *filters.ApiKeyID)
+	}
+
+	if filters.UserID != nil {
+		sq = sq.Where("user_id = ?", *filters.UserID)
+	}
+
+	if filters.Key != nil {
+		keyHash, err := r.tokenHasher.Hash(*filters.Key)
+		if err != nil {
+			return domainauth.GetDetailApiKeyResult{}, fmt.Errorf("failed to hash api key: %w", err)
+		}
+		sq = sq.Where("key_hash = ?", keyHash)
+	}
+
+	sq = sq.Limit(1)
+
+	row, err := r.db.RDBMS().QueryRowSq(ctx, sq, false)
+	if err != nil {
+		return domainauth.GetDetailApiKeyResult{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	apiKey, err := scanApiKey(row.Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainauth.GetDetailApiKeyResult{}, databases.ErrNoRowFound
+		}
+		return domainauth.GetDetailApiKeyResult{}, fmt.Errorf("failed to scan api key: %w", err)
+	}
+
+	return domainauth.GetDetailApiKeyResult{
+		ApiKey: apiKey,
+	}, nil
+}
+
+func (r *repository) GetListApiKey(ctx context.Context, filters domainauth.GetListApiKeyFilters) (domainauth.GetListApiKeyResult, error) {
+	query := `
+		SELECT ` + strings.Join(apiKeyColumns, ", ") + `
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.RDBMS().QueryContext(ctx, query, filters.UserID)
+	if err != nil {
+		return domainauth.GetListApiKeyResult{}, fmt.Errorf("failed to get api keys: %w", err)
+	}
+	defer rows.Close()
+
+	apiKeys := make([]domainauth.ApiKey, 0)
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows.Scan)
+		if err != nil {
+			return domainauth.GetListApiKeyResult{}, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	if err = rows.Err(); err != nil {
+		return domainauth.GetListApiKeyResult{}, fmt.Errorf("failed to iterate api keys: %w", err)
+	}
+
+	return domainauth.GetListApiKeyResult{
+		ApiKeys: apiKeys,
+	}, nil
+}
+
+func (r *repository) RotateApiKey(ctx context.Context, params domainauth.RotateApiKeyParams) (domainauth.RotateApiKeyResult, error) {
+	keyHash, err := r.tokenHasher.Hash(params.Key)
+	if err != nil {
+		return domainauth.RotateApiKeyResult{}, fmt.Errorf("failed to hash api key: %w", err)
+	}
+
+	query := `
+		UPDATE api_keys
+		SET prefix = $1, key_hash = $2, updated_at = $3
+		WHERE id = $4 AND user_id = $5 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.Prefix,
+		keyHash,
+		params.RotatedAt,
+		params.ApiKeyID,
+		params.UserID,
+	)
+	if err != nil {
+		return domainauth.RotateApiKeyResult{}, fmt.Errorf("failed to rotate api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.RotateApiKeyResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.RotateApiKeyResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
+
+func (r *repository) RevokeApiKey(ctx context.Context, params domainauth.RevokeApiKeyParams) (domainauth.RevokeApiKeyResult, error) {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = $1, updated_at = $1
+		WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.RevokedAt,
+		params.ApiKeyID,
+		params.UserID,
+	)
+	if err != nil {
+		return domainauth.RevokeApiKeyResult{}, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.RevokeApiKeyResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.RevokeApiKeyResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
+
+func (r *repository) TouchApiKey(ctx context.Context, params domainauth.TouchApiKeyParams) (domainauth.TouchApiKeyResult, error) {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = $1
+		WHERE id = $2
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query, params.LastUsedAt, params.ApiKeyID)
+	if err != nil {
+		return domainauth.TouchApiKeyResult{}, fmt.Errorf("failed to touch api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.TouchApiKeyResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.TouchApiKeyResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
+
+var apiKeyColumns = []string{
+	"id",
+	"user_id",
+	"name",
+	"prefix",
+	"scopes",
+	"allowed_ips",
+	"expires_at",
+	"last_used_at",
+	"created_at",
+	"revoked_at",
+}
+
+func scanApiKey(scan func(dest ...any) error) (domainauth.ApiKey, error) {
+	var (
+		apiKey     domainauth.ApiKey
+		scopes     string
+		allowedIPs string
+	)
+	err := scan(
+		&apiKey.ID,
+		&apiKey.UserID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&scopes,
+		&allowedIPs,
+		&apiKey.ExpiresAt,
+		&apiKey.LastUsedAt,
+		&apiKey.CreatedAt,
+		&apiKey.RevokedAt,
+	)
+	if err != nil {
+		return domainauth.ApiKey{}, err
+	}
+
+	apiKey.Scopes = strings.Fields(scopes)
+	apiKey.AllowedIPs = strings.Fields(allowedIPs)
+	return apiKey, nil
+}
@@ -0,0 +1,48 @@
+package authrepository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+)
+
+// CreateAuditLog stores details as a JSON object, an empty actor or target
+// as NULL.
+func (r *auditLogRepository) CreateAuditLog(ctx context.Context, params domainauth.CreateAuditLogParams) (domainauth.CreateAuditLogResult, error) {
+	entry := params.Entry
+
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return domainauth.CreateAuditLogResult{}, fmt.Errorf("failed to marshal audit log details: %w", err)
+	}
+	if entry.Details == nil {
+		details = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO audit_logs (action, outcome, reason, actor_user_id, target_user_id, details, ip_address, user_agent, request_id, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	var result domainauth.CreateAuditLogResult
+	err = r.db.RDBMS().QueryRowContext(ctx, query,
+		entry.Action,
+		entry.Outcome,
+		entry.Reason,
+		entry.ActorUserID,
+		entry.TargetUserID,
+		string(details),
+		entry.IPAddress,
+		entry.UserAgent,
+		entry.RequestID,
+		entry.CreatedAt,
+	).Scan(&result.ID)
+	if err != nil {
+		return domainauth.CreateAuditLogResult{}, fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return result, nil
+}
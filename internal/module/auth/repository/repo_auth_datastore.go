@@ -10,22 +10,40 @@ import (
 	domainauth "go-bootstrap/internal/domain/auth"
 
 	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases/sqlx"
 )
 
 func (r *repository) CreateToken(ctx context.Context, params domainauth.CreateTokenParams) (domainauth.CreateTokenResult, error) {
 	query := `
-		INSERT INTO auth_tokens (user_id, token, token_type, expires_at, refresh_token, status, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO auth_tokens (user_id, token_hash, token_type, expires_at, refresh_token_hash, family_id, parent_id, dpop_jkt, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at
 	`
 
+	tokenHash, err := r.tokenHasher.Hash(params.Token)
+	if err != nil {
+		return domainauth.CreateTokenResult{}, fmt.Errorf("failed to hash token: %w", err)
+	}
+
+	var refreshTokenHash *string
+	if params.RefreshToken != nil {
+		hash, err := r.tokenHasher.Hash(*params.RefreshToken)
+		if err != nil {
+			return domainauth.CreateTokenResult{}, fmt.Errorf("failed to hash refresh token: %w", err)
+		}
+		refreshTokenHash = &hash
+	}
+
 	var result domainauth.CreateTokenResult
-	err := r.db.RDBMS().QueryRowContext(ctx, query,
+	err = r.db.RDBMS().QueryRowContext(ctx, query,
 		params.UserID,
-		params.Token,
+		tokenHash,
 		params.TokenType,
 		params.ExpiresAt,
-		params.RefreshToken,
+		refreshTokenHash,
+		params.FamilyID,
+		params.ParentID,
+		params.DpopJkt,
 		domainauth.TokenStatusActive,
 		time.Now().UTC(),
 	).Scan(&result.ID, &result.CreatedAt)
@@ -41,16 +59,23 @@ func (r *repository) GetDetailToken(ctx context.Context, filters domainauth.GetD
 	sq := r.db.Sq().Select(
 		"id",
 		"user_id",
-		"token",
+		"token_hash",
 		"token_type",
 		"status",
 		"expires_at",
 		"created_at",
-		"refresh_token",
+		"refresh_token_hash",
+		"family_id",
+		"parent_id",
+		"dpop_jkt",
 	).From("auth_tokens")
 
 	if filters.Token != nil {
-		sq = sq.Where("token = ?", *filters.Token)
+		tokenHash, err := r.tokenHasher.Hash(*filters.Token)
+		if err != nil {
+			return domainauth.GetDetailTokenResult{}, fmt.Errorf("failed to hash token: %w", err)
+		}
+		sq = sq.Where("token_hash = ?", tokenHash)
 	}
 
 	if filters.TokenID != nil {
@@ -76,12 +101,15 @@ func (r *repository) GetDetailToken(ctx context.Context, filters domainauth.GetD
 	err = row.Scan(
 		&result.ID,
 		&result.UserID,
-		&result.Token,
+		&result.TokenHash,
 		&result.TokenType,
 		&result.Status,
 		&result.ExpiresAt,
 		&result.CreatedAt,
-		&result.RefreshToken,
+		&result.RefreshTokenHash,
+		&result.FamilyID,
+		&result.ParentID,
+		&result.DpopJkt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -97,14 +125,19 @@ func (r *repository) RevokeToken(ctx context.Context, params domainauth.RevokeTo
 	query := `
 		UPDATE auth_tokens
 		SET status = $1, updated_at = $2
-		WHERE token = $3 AND user_id = $4
+		WHERE token_hash = $3 AND user_id = $4
 	`
 
+	tokenHash, err := r.tokenHasher.Hash(params.Token)
+	if err != nil {
+		return domainauth.RevokeTokenResult{}, fmt.Errorf("failed to hash token: %w", err)
+	}
+
 	revokedAt := time.Now().UTC()
 	result, err := r.db.RDBMS().ExecContext(ctx, query,
 		domainauth.TokenStatusRevoked,
 		revokedAt,
-		params.Token,
+		tokenHash,
 		params.UserID,
 	)
 	if err != nil {
@@ -127,6 +160,88 @@ func (r *repository) RevokeToken(ctx context.Context, params domainauth.RevokeTo
 	}, nil
 }
 
+func (r *repository) RotateToken(ctx context.Context, params domainauth.RotateTokenParams) (domainauth.RotateTokenResult, error) {
+	query := `
+		UPDATE auth_tokens
+		SET status = $1, updated_at = $2
+		WHERE id = $3 AND user_id = $4 AND status = $5
+	`
+
+	rotatedAt := time.Now().UTC()
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		domainauth.TokenStatusRotated,
+		rotatedAt,
+		params.TokenID,
+		params.UserID,
+		domainauth.TokenStatusActive,
+	)
+	if err != nil {
+		return domainauth.RotateTokenResult{}, fmt.Errorf("failed to rotate token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.RotateTokenResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domainauth.RotateTokenResult{
+			Success: false,
+		}, nil
+	}
+
+	return domainauth.RotateTokenResult{
+		Success:   true,
+		RotatedAt: rotatedAt,
+	}, nil
+}
+
+func (r *repository) RevokeTokenFamily(ctx context.Context, params domainauth.RevokeTokenFamilyParams) (domainauth.RevokeTokenFamilyResult, error) {
+	query := `
+		UPDATE auth_tokens
+		SET status = $1, updated_at = $2
+		WHERE family_id = $3 AND user_id = $4 AND status IN ($5, $6)
+	`
+
+	revokedAt := time.Now().UTC()
+	var rowsAffected int64
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		result, err := tx.ExecContext(ctx, query,
+			domainauth.TokenStatusRevoked,
+			revokedAt,
+			params.FamilyID,
+			params.UserID,
+			domainauth.TokenStatusActive,
+			domainauth.TokenStatusRotated,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to revoke token family: %w", err)
+		}
+
+		rowsAffected, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE auth_sessions
+			SET revoked_at = $1
+			WHERE family_id = $2 AND user_id = $3 AND revoked_at IS NULL
+		`, revokedAt, params.FamilyID, params.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domainauth.RevokeTokenFamilyResult{}, err
+	}
+
+	return domainauth.RevokeTokenFamilyResult{
+		RevokedCount: rowsAffected,
+		RevokedAt:    revokedAt,
+	}, nil
+}
+
 func (r *repository) DeleteExpiredTokens(ctx context.Context, params domainauth.DeleteExpiredTokensParams) (domainauth.DeleteExpiredTokensResult, error) {
 	query := `
 		DELETE FROM auth_tokens
@@ -0,0 +1,60 @@
+package authrepository
+
+import (
+	"context"
+	"fmt"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+)
+
+func (r *dpopReplayRepository) UseDpopJti(ctx context.Context, params domainauth.UseDpopJtiParams) (domainauth.UseDpopJtiResult, error) {
+	// an expired row is taken over, any other conflict is a replay
+	query := `
+		INSERT INTO auth_dpop_jtis (jti_hash, expires_at, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti_hash) DO UPDATE SET
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at
+		WHERE auth_dpop_jtis.expires_at <= EXCLUDED.created_at
+	`
+
+	jtiHash, err := r.tokenHasher.Hash(params.Jkt + "." + params.Jti)
+	if err != nil {
+		return domainauth.UseDpopJtiResult{}, fmt.Errorf("failed to hash dpop jti: %w", err)
+	}
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query, jtiHash, params.ExpiresAt, params.UsedAt)
+	if err != nil {
+		return domainauth.UseDpopJtiResult{}, fmt.Errorf("failed to use dpop jti: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.UseDpopJtiResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.UseDpopJtiResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
+
+func (r *dpopReplayRepository) DeleteExpiredDpopJtis(ctx context.Context, params domainauth.DeleteExpiredDpopJtisParams) (domainauth.DeleteExpiredDpopJtisResult, error) {
+	query := `
+		DELETE FROM auth_dpop_jtis
+		WHERE expires_at < $1
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query, params.BeforeDate)
+	if err != nil {
+		return domainauth.DeleteExpiredDpopJtisResult{}, fmt.Errorf("failed to delete expired dpop jtis: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.DeleteExpiredDpopJtisResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.DeleteExpiredDpopJtisResult{
+		DeletedCount: rowsAffected,
+	}, nil
+}
@@ -0,0 +1,37 @@
+package authrepository
+
+import (
+	"context"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+)
+
+func (r *dpopReplayMemoryRepository) UseDpopJti(ctx context.Context, params domainauth.UseDpopJtiParams) (domainauth.UseDpopJtiResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := params.Jkt + "." + params.Jti
+	if expiresAt, ok := r.jtis[key]; ok && expiresAt.After(params.UsedAt) {
+		return domainauth.UseDpopJtiResult{Success: false}, nil
+	}
+	r.jtis[key] = params.ExpiresAt
+
+	return domainauth.UseDpopJtiResult{Success: true}, nil
+}
+
+func (r *dpopReplayMemoryRepository) DeleteExpiredDpopJtis(ctx context.Context, params domainauth.DeleteExpiredDpopJtisParams) (domainauth.DeleteExpiredDpopJtisResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, expiresAt := range r.jtis {
+		if expiresAt.Before(params.BeforeDate) {
+			delete(r.jtis, key)
+			deleted++
+		}
+	}
+
+	return domainauth.DeleteExpiredDpopJtisResult{
+		DeletedCount: deleted,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package authrepository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDpopReplayMemoryRepository_UseDpopJti(t *testing.T) {
+	ctx := context.Background()
+	repo := authrepository.NewDpopReplayMemoryRepository()
+	now := time.Now().UTC()
+
+	use := func(jkt, jti string, usedAt time.Time) bool {
+		result, err := repo.UseDpopJti(ctx, domainauth.UseDpopJtiParams{
+			Jkt:       jkt,
+			Jti:       jti,
+			UsedAt:    usedAt,
+			ExpiresAt: usedAt.Add(time.Minute),
+		})
+		require.NoError(t, err)
+		return result.Success
+	}
+
+	assert.True(t, use("key-1", "jti-1", now))
+	assert.False(t, use("key-1", "jti-1", now.Add(time.Second)), "a jti is accepted once per key")
+	assert.True(t, use("key-2", "jti-1", now), "another key may use the same jti")
+	assert.True(t, use("key-1", "jti-1", now.Add(2*time.Minute)), "an expired jti is forgotten")
+
+	deleted, err := repo.DeleteExpiredDpopJtis(ctx, domainauth.DeleteExpiredDpopJtisParams{
+		BeforeDate: now.Add(2 * time.Minute),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted.DeletedCount)
+}
@@ -0,0 +1,194 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases/sqlx"
+)
+
+func (r *identityRepository) CreateOidcState(ctx context.Context, params domainauth.CreateOidcStateParams) (domainauth.CreateOidcStateResult, error) {
+	query := `
+		INSERT INTO auth_oidc_states (state_hash, provider, nonce, code_verifier_encrypted, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	stateHash, err := r.tokenHasher.Hash(params.State)
+	if err != nil {
+		return domainauth.CreateOidcStateResult{}, fmt.Errorf("failed to hash oidc state: %w", err)
+	}
+
+	codeVerifierEncrypted, err := r.secretCipher.Encrypt([]byte(params.CodeVerifier))
+	if err != nil {
+		return domainauth.CreateOidcStateResult{}, fmt.Errorf("failed to encrypt code verifier: %w", err)
+	}
+
+	var result domainauth.CreateOidcStateResult
+	err = r.db.RDBMS().QueryRowContext(ctx, query,
+		stateHash,
+		params.Provider,
+		params.Nonce,
+		codeVerifierEncrypted,
+		params.ExpiresAt,
+		time.Now().UTC(),
+	).Scan(&result.ID, &result.CreatedAt)
+	if err != nil {
+		return domainauth.CreateOidcStateResult{}, fmt.Errorf("failed to create oidc state: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *identityRepository) ConsumeOidcState(ctx context.Context, params domainauth.ConsumeOidcStateParams) (domainauth.ConsumeOidcStateResult, error) {
+	query := `
+		DELETE FROM auth_oidc_states
+		WHERE state_hash = $1
+		RETURNING provider, nonce, code_verifier_encrypted, expires_at
+	`
+
+	stateHash, err := r.tokenHasher.Hash(params.State)
+	if err != nil {
+		return domainauth.ConsumeOidcStateResult{}, fmt.Errorf("failed to hash oidc state: %w", err)
+	}
+
+	var (
+		result                domainauth.ConsumeOidcStateResult
+		codeVerifierEncrypted string
+	)
+	err = r.db.RDBMS().QueryRowContext(ctx, query, stateHash).Scan(
+		&result.Provider,
+		&result.Nonce,
+		&codeVerifierEncrypted,
+		&result.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainauth.ConsumeOidcStateResult{}, databases.ErrNoRowFound
+		}
+		return domainauth.ConsumeOidcStateResult{}, fmt.Errorf("failed to consume oidc state: %w", err)
+	}
+
+	codeVerifier, err := r.secretCipher.Decrypt(codeVerifierEncrypted)
+	if err != nil {
+		return domainauth.ConsumeOidcStateResult{}, fmt.Errorf("failed to decrypt code verifier: %w", err)
+	}
+	result.CodeVerifier = string(codeVerifier)
+
+	return result, nil
+}
+
+func (r *identityRepository) DeleteExpiredOidcStates(ctx context.Context, params domainauth.DeleteExpiredOidcStatesParams) (domainauth.DeleteExpiredOidcStatesResult, error) {
+	query := `
+		DELETE FROM auth_oidc_states
+		WHERE expires_at < $1
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query, params.BeforeDate)
+	if err != nil {
+		return domainauth.DeleteExpiredOidcStatesResult{}, fmt.Errorf("failed to delete expired oidc states: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.DeleteExpiredOidcStatesResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.DeleteExpiredOidcStatesResult{
+		DeletedCount: rowsAffected,
+	}, nil
+}
+
+func (r *identityRepository) GetDetailIdentity(ctx context.Context, filters domainauth.GetDetailIdentityFilters) (domainauth.GetDetailIdentityResult, error) {
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`
+
+	var result domainauth.GetDetailIdentityResult
+	err := r.db.RDBMS().QueryRowContext(ctx, query, filters.Provider, filters.Subject).Scan(
+		&result.ID,
+		&result.UserID,
+		&result.Provider,
+		&result.Subject,
+		&result.Email,
+		&result.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainauth.GetDetailIdentityResult{}, databases.ErrNoRowFound
+		}
+		return domainauth.GetDetailIdentityResult{}, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *identityRepository) CreateIdentity(ctx context.Context, params domainauth.CreateIdentityParams) (domainauth.CreateIdentityResult, error) {
+	var result domainauth.CreateIdentityResult
+	now := time.Now().UTC()
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		if params.ActivatePasswordHash != "" {
+			_, err := tx.ExecContext(ctx, `
+				UPDATE users
+				SET status = 'active', password_hash = $1, password_change_required = FALSE, updated_at = $2
+				WHERE id = $3 AND status = 'pending_verification'
+			`, params.ActivatePasswordHash, now, params.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to activate user: %w", err)
+			}
+		}
+
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at
+		`, params.UserID, params.Provider, params.Subject, params.Email, now).Scan(&result.ID, &result.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create identity: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domainauth.CreateIdentityResult{}, err
+	}
+
+	return result, nil
+}
+
+func (r *identityRepository) CreateUserWithIdentity(ctx context.Context, params domainauth.CreateUserWithIdentityParams) (domainauth.CreateUserWithIdentityResult, error) {
+	var result domainauth.CreateUserWithIdentityResult
+	now := time.Now().UTC()
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO users (email, password_hash, name, role, status, created_at, updated_at)
+			VALUES ($1, $2, $3, 'user', 'active', $4, $4)
+			RETURNING id
+		`, params.Email, params.PasswordHash, params.Name, now).Scan(&result.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, result.UserID, params.Provider, params.Subject, params.Email, now).Scan(&result.IdentityID)
+		if err != nil {
+			return fmt.Errorf("failed to create identity: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domainauth.CreateUserWithIdentityResult{}, err
+	}
+
+	return result, nil
+}
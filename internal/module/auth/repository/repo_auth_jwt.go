@@ -0,0 +1,179 @@
+package authrepository
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sort"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type accessTokenClaims struct {
+	SessionID string               `json:"sid"`
+	Email     string               `json:"email"`
+	Role      domainauth.UserRole  `json:"role"`
+	TokenType domainauth.TokenType `json:"token_type"`
+	Actor     *actorClaim          `json:"act,omitempty"`
+	Cnf       *cnfClaim            `json:"cnf,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// actorClaim is the "act" claim of RFC 8693, the admin an impersonation
+// token was issued to while the subject is the impersonated user.
+type actorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// cnfClaim is the confirmation claim of RFC 9449, the thumbprint of the DPoP
+// key a token is bound to.
+type cnfClaim struct {
+	Jkt string `json:"jkt"`
+}
+
+func (r *jwtRepository) CreateAccessToken(ctx context.Context, params domainauth.CreateAccessTokenParams) (domainauth.CreateAccessTokenResult, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return domainauth.CreateAccessTokenResult{}, fmt.Errorf("failed to generate token id: %w", err)
+	}
+
+	key := r.keySet.ActiveKey()
+	claims := accessTokenClaims{
+		SessionID: params.SessionID,
+		Email:     params.Email,
+		Role:      params.Role,
+		TokenType: domainauth.TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Subject:   params.UserID,
+			Issuer:    r.keySet.Issuer(),
+			Audience:  r.keySet.Audience(),
+			IssuedAt:  jwt.NewNumericDate(params.IssuedAt),
+			NotBefore: jwt.NewNumericDate(params.IssuedAt),
+			ExpiresAt: jwt.NewNumericDate(params.ExpiresAt),
+		},
+	}
+	if params.ImpersonatorID != "" {
+		claims.Actor = &actorClaim{Subject: params.ImpersonatorID}
+	}
+	if params.DpopJkt != "" {
+		claims.Cnf = &cnfClaim{Jkt: params.DpopJkt}
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	signed, err := token.SignedString(key.SignKey)
+	if err != nil {
+		return domainauth.CreateAccessTokenResult{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return domainauth.CreateAccessTokenResult{
+		Token:   signed,
+		TokenID: tokenID,
+		KeyID:   key.ID,
+	}, nil
+}
+
+func (r *jwtRepository) ParseAccessToken(ctx context.Context, params domainauth.ParseAccessTokenParams) (domainauth.ParseAccessTokenResult, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if issuer := r.keySet.Issuer(); issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	// the token has to name any one of the configured audiences
+	if audience := r.keySet.Audience(); len(audience) > 0 {
+		opts = append(opts, jwt.WithAudience(audience...))
+	}
+
+	var claims accessTokenClaims
+	_, err := jwt.ParseWithClaims(params.Token, &claims, r.keySet.Keyfunc, opts...)
+	if err != nil {
+		return domainauth.ParseAccessTokenResult{}, fmt.Errorf("failed to parse access token: %w", err)
+	}
+
+	if claims.TokenType != domainauth.TokenTypeAccess {
+		return domainauth.ParseAccessTokenResult{}, fmt.Errorf("unexpected token type %q", claims.TokenType)
+	}
+
+	payload := domainauth.TokenPayload{
+		UserID:    claims.Subject,
+		SessionID: claims.SessionID,
+		Email:     claims.Email,
+		Role:      claims.Role,
+		TokenType: claims.TokenType,
+		IssuedAt:  claims.IssuedAt.UTC(),
+		ExpiresAt: claims.ExpiresAt.UTC(),
+	}
+	if claims.Actor != nil {
+		if claims.Actor.Subject == "" {
+			return domainauth.ParseAccessTokenResult{}, fmt.Errorf("empty act claim")
+		}
+		payload.ImpersonatorID = claims.Actor.Subject
+	}
+	if claims.Cnf != nil {
+		if claims.Cnf.Jkt == "" {
+			return domainauth.ParseAccessTokenResult{}, fmt.Errorf("empty cnf claim")
+		}
+		payload.DpopJkt = claims.Cnf.Jkt
+	}
+
+	return domainauth.ParseAccessTokenResult{
+		TokenID: claims.ID,
+		Payload: payload,
+	}, nil
+}
+
+func (r *jwtRepository) GetJwks(ctx context.Context) (domainauth.GetJwksResult, error) {
+	keys := r.keySet.Keys()
+	jwks := make([]domainauth.Jwk, 0, len(keys))
+
+	for _, key := range keys {
+		switch publicKey := key.VerifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, domainauth.Jwk{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				N:         base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, domainauth.Jwk{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		default:
+			// symmetric keys are never published
+		}
+	}
+
+	sort.Slice(jwks, func(i, j int) bool {
+		return jwks[i].KeyID < jwks[j].KeyID
+	})
+
+	return domainauth.GetJwksResult{
+		Keys: jwks,
+	}, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
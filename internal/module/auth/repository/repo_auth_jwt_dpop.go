@@ -0,0 +1,188 @@
+package authrepository
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// dpopProofAlgorithms are the asymmetric algorithms accepted for DPoP proofs,
+// a proof can never be signed with a shared secret.
+var dpopProofAlgorithms = []string{
+	"ES256", "ES384", "ES512",
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"EdDSA",
+}
+
+const dpopMinRsaBits = 2048
+
+type dpopProofClaims struct {
+	Htm string `json:"htm"`
+	Htu string `json:"htu"`
+	Ath string `json:"ath,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// dpopJwk is the public key in the header of a DPoP proof. Members are
+// declared in lexicographic order, RFC 7638 thumbprints depend on it.
+type dpopJwk struct {
+	Crv string `json:"crv,omitempty"`
+	D   string `json:"d,omitempty"`
+	E   string `json:"e,omitempty"`
+	Kty string `json:"kty"`
+	N   string `json:"n,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func (r *jwtRepository) ParseDpopProof(ctx context.Context, params domainauth.ParseDpopProofParams) (domainauth.ParseDpopProofResult, error) {
+	var (
+		claims dpopProofClaims
+		jkt    string
+	)
+	keyfunc := func(token *jwt.Token) (any, error) {
+		if typ, _ := token.Header["typ"].(string); typ != "dpop+jwt" {
+			return nil, fmt.Errorf("unexpected typ %q", typ)
+		}
+
+		raw, err := json.Marshal(token.Header["jwk"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwk header: %w", err)
+		}
+		var key dpopJwk
+		if err = json.Unmarshal(raw, &key); err != nil {
+			return nil, fmt.Errorf("failed to read jwk header: %w", err)
+		}
+
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return nil, err
+		}
+		jkt, err = key.thumbprint()
+		if err != nil {
+			return nil, err
+		}
+		return publicKey, nil
+	}
+
+	_, err := jwt.ParseWithClaims(params.Proof, &claims, keyfunc, jwt.WithValidMethods(dpopProofAlgorithms))
+	if err != nil {
+		return domainauth.ParseDpopProofResult{}, fmt.Errorf("failed to parse dpop proof: %w", err)
+	}
+
+	if claims.ID == "" || claims.IssuedAt == nil || claims.Htm == "" || claims.Htu == "" {
+		return domainauth.ParseDpopProofResult{}, errors.New("dpop proof misses jti, iat, htm or htu")
+	}
+
+	return domainauth.ParseDpopProofResult{
+		Jkt:             jkt,
+		Jti:             claims.ID,
+		Method:          claims.Htm,
+		Url:             claims.Htu,
+		IssuedAt:        claims.IssuedAt.UTC(),
+		AccessTokenHash: claims.Ath,
+	}, nil
+}
+
+func (k dpopJwk) publicKey() (any, error) {
+	if k.D != "" {
+		return nil, errors.New("jwk header holds a private key")
+	}
+
+	switch k.Kty {
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJwkCoordinate(k.X, curve)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJwkCoordinate(k.Y, curve)
+		if err != nil {
+			return nil, err
+		}
+		point := append([]byte{4}, append(x, y...)...)
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwk n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid jwk e")
+		}
+		publicKey := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+		if publicKey.N.BitLen() < dpopMinRsaBits {
+			return nil, fmt.Errorf("rsa key shorter than %d bits", dpopMinRsaBits)
+		}
+		return publicKey, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid jwk x")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// thumbprint returns the base64url SHA-256 thumbprint of RFC 7638, computed
+// over the required members of the key type only.
+func (k dpopJwk) thumbprint() (string, error) {
+	required := dpopJwk{Kty: k.Kty}
+	switch k.Kty {
+	case "EC":
+		required.Crv, required.X, required.Y = k.Crv, k.X, k.Y
+	case "RSA":
+		required.E, required.N = k.E, k.N
+	case "OKP":
+		required.Crv, required.X = k.Crv, k.X
+	default:
+		return "", fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+
+	raw, err := json.Marshal(required)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode jwk: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func decodeJwkCoordinate(value string, curve elliptic.Curve) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) != (curve.Params().BitSize+7)/8 {
+		return nil, errors.New("invalid jwk coordinate")
+	}
+	return b, nil
+}
@@ -0,0 +1,166 @@
+package authrepository_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"go-bootstrap/internal/config"
+	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/infrastructure"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signDpopProof(t *testing.T, method jwt.SigningMethod, key crypto.Signer, jwk map[string]string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = jwk
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func jwkThumbprint(canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestJwtRepository_ParseDpopProof(t *testing.T) {
+	ctx := context.Background()
+	repo := authrepository.NewJwtRepository(nil)
+	b64 := base64.RawURLEncoding.EncodeToString
+	iat := time.Now().UTC().Truncate(time.Second)
+	claims := jwt.MapClaims{
+		"jti": "e1j3V_bKic8-LAEB",
+		"htm": "POST",
+		"htu": "https://api.example.com/api/v1/auth/login",
+		"iat": iat.Unix(),
+		"ath": "fUHyO2r2Z3DZ53EsNrWBb0xWXoaNy59IiKCAqksmQEo",
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecX, ecY := b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32)))
+	ecJwk := map[string]string{"kty": "EC", "crv": "P-256", "x": ecX, "y": ecY}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaN, rsaE := b64(rsaKey.N.Bytes()), b64(big.NewInt(int64(rsaKey.E)).Bytes())
+
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("valid proofs", func(t *testing.T) {
+		cases := []struct {
+			name      string
+			method    jwt.SigningMethod
+			key       crypto.Signer
+			jwk       map[string]string
+			canonical string
+		}{
+			{
+				name: "ES256", method: jwt.SigningMethodES256, key: ecKey, jwk: ecJwk,
+				canonical: `{"crv":"P-256","kty":"EC","x":"` + ecX + `","y":"` + ecY + `"}`,
+			},
+			{
+				name: "RS256", method: jwt.SigningMethodRS256, key: rsaKey,
+				jwk:       map[string]string{"kty": "RSA", "n": rsaN, "e": rsaE, "alg": "RS256"},
+				canonical: `{"e":"` + rsaE + `","kty":"RSA","n":"` + rsaN + `"}`,
+			},
+			{
+				name: "EdDSA", method: jwt.SigningMethodEdDSA, key: edKey,
+				jwk:       map[string]string{"kty": "OKP", "crv": "Ed25519", "x": b64(edPublic)},
+				canonical: `{"crv":"Ed25519","kty":"OKP","x":"` + b64(edPublic) + `"}`,
+			},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				parsed, err := repo.ParseDpopProof(ctx, domainauth.ParseDpopProofParams{
+					Proof: signDpopProof(t, tc.method, tc.key, tc.jwk, claims),
+				})
+				require.NoError(t, err)
+				assert.Equal(t, domainauth.ParseDpopProofResult{
+					Jkt:             jwkThumbprint(tc.canonical),
+					Jti:             "e1j3V_bKic8-LAEB",
+					Method:          "POST",
+					Url:             "https://api.example.com/api/v1/auth/login",
+					IssuedAt:        iat,
+					AccessTokenHash: "fUHyO2r2Z3DZ53EsNrWBb0xWXoaNy59IiKCAqksmQEo",
+				}, parsed)
+			})
+		}
+	})
+
+	t.Run("invalid proofs", func(t *testing.T) {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		wrongTyp := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		wrongTyp.Header["typ"] = "JWT"
+		wrongTyp.Header["jwk"] = ecJwk
+		wrongTypProof, err := wrongTyp.SignedString(ecKey)
+		require.NoError(t, err)
+
+		hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		hmac.Header["typ"] = "dpop+jwt"
+		hmac.Header["jwk"] = map[string]string{"kty": "oct", "k": b64([]byte("secret"))}
+		hmacProof, err := hmac.SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		withPrivateKey := map[string]string{"kty": "EC", "crv": "P-256", "x": ecX, "y": ecY, "d": b64(ecKey.D.Bytes())}
+		withoutJti := jwt.MapClaims{"htm": "POST", "htu": "https://api.example.com/", "iat": iat.Unix()}
+
+		cases := map[string]string{
+			"typ is not dpop+jwt":  wrongTypProof,
+			"symmetric algorithm":  hmacProof,
+			"signed by other key":  signDpopProof(t, jwt.SigningMethodES256, otherKey, ecJwk, claims),
+			"private key in jwk":   signDpopProof(t, jwt.SigningMethodES256, ecKey, withPrivateKey, claims),
+			"jti missing":          signDpopProof(t, jwt.SigningMethodES256, ecKey, ecJwk, withoutJti),
+			"malformed proof":      "not-a-jwt",
+			"curve does not match": signDpopProof(t, jwt.SigningMethodES256, ecKey, map[string]string{"kty": "EC", "crv": "P-384", "x": ecX, "y": ecY}, claims),
+		}
+		for name, proof := range cases {
+			_, err := repo.ParseDpopProof(ctx, domainauth.ParseDpopProofParams{Proof: proof})
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestJwtRepository_AccessTokenCnf(t *testing.T) {
+	ctx := context.Background()
+	keySet, err := infrastructure.NewJwtKeySetFromConfig(config.Jwt{
+		ActiveKeyID: "hs",
+		Keys:        []config.JwtKey{{ID: "hs", Algorithm: "HS256", Secret: "secret"}},
+	})
+	require.NoError(t, err)
+	repo := authrepository.NewJwtRepository(keySet)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	created, err := repo.CreateAccessToken(ctx, domainauth.CreateAccessTokenParams{
+		UserID:    "42",
+		SessionID: "7",
+		Role:      domainauth.UserRoleUser,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(time.Minute),
+		DpopJkt:   "0ZcOCORZNYy-DWpqq30jZyJGHTN0d2HglBV3uiguA4I",
+	})
+	require.NoError(t, err)
+
+	parsed, err := repo.ParseAccessToken(ctx, domainauth.ParseAccessTokenParams{Token: created.Token})
+	require.NoError(t, err)
+	assert.Equal(t, "0ZcOCORZNYy-DWpqq30jZyJGHTN0d2HglBV3uiguA4I", parsed.Payload.DpopJkt)
+}
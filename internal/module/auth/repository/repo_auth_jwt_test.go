@@ -0,0 +1,248 @@
+package authrepository_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"go-bootstrap/internal/config"
+	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/infrastructure"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rsaPrivateKeyPEM(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func ed25519PrivateKeyPEM(t *testing.T) string {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestJwtRepository_CreateAndParseAccessToken(t *testing.T) {
+	ctx := context.Background()
+
+	keys := []config.JwtKey{
+		{ID: "hs", Algorithm: "HS256", Secret: "test-secret-with-enough-entropy"},
+		{ID: "rs", Algorithm: "RS256", PrivateKey: rsaPrivateKeyPEM(t)},
+		{ID: "ed", Algorithm: "EdDSA", PrivateKey: ed25519PrivateKeyPEM(t)},
+	}
+
+	for _, key := range keys {
+		t.Run(key.Algorithm, func(t *testing.T) {
+			keySet, err := infrastructure.NewJwtKeySetFromConfig(config.Jwt{
+				Issuer:      "go-bootstrap",
+				Audience:    []string{"go-bootstrap-api"},
+				ActiveKeyID: key.ID,
+				Keys:        keys,
+			})
+			require.NoError(t, err)
+
+			repo := authrepository.NewJwtRepository(keySet)
+			now := time.Now().UTC().Truncate(time.Second)
+
+			created, err := repo.CreateAccessToken(ctx, domainauth.CreateAccessTokenParams{
+				UserID:    "42",
+				SessionID: "7",
+				Email:     "user@example.com",
+				Role:      domainauth.UserRoleAdmin,
+				IssuedAt:  now,
+				ExpiresAt: now.Add(15 * time.Minute),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, created.KeyID)
+
+			parsed, err := repo.ParseAccessToken(ctx, domainauth.ParseAccessTokenParams{
+				Token: created.Token,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, created.TokenID, parsed.TokenID)
+			assert.Equal(t, domainauth.TokenPayload{
+				UserID:    "42",
+				SessionID: "7",
+				Email:     "user@example.com",
+				Role:      domainauth.UserRoleAdmin,
+				TokenType: domainauth.TokenTypeAccess,
+				IssuedAt:  now,
+				ExpiresAt: now.Add(15 * time.Minute),
+			}, parsed.Payload)
+		})
+	}
+}
+
+func TestJwtRepository_ImpersonationToken(t *testing.T) {
+	ctx := context.Background()
+	keySet, err := infrastructure.NewJwtKeySetFromConfig(config.Jwt{
+		ActiveKeyID: "hs",
+		Keys:        []config.JwtKey{{ID: "hs", Algorithm: "HS256", Secret: "secret"}},
+	})
+	require.NoError(t, err)
+	repo := authrepository.NewJwtRepository(keySet)
+
+	now := time.Now().UTC()
+	created, err := repo.CreateAccessToken(ctx, domainauth.CreateAccessTokenParams{
+		UserID:         "42",
+		SessionID:      "7",
+		Role:           domainauth.UserRoleUser,
+		IssuedAt:       now,
+		ExpiresAt:      now.Add(time.Minute),
+		ImpersonatorID: "1",
+	})
+	require.NoError(t, err)
+
+	parsed, err := repo.ParseAccessToken(ctx, domainauth.ParseAccessTokenParams{
+		Token: created.Token,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "42", parsed.Payload.UserID)
+	assert.Equal(t, "1", parsed.Payload.ImpersonatorID)
+	assert.True(t, parsed.Payload.Impersonated())
+}
+
+func TestJwtRepository_ParseAccessToken_Rotation(t *testing.T) {
+	ctx := context.Background()
+	oldKey := config.JwtKey{ID: "old", Algorithm: "HS256", Secret: "old-secret"}
+	newKey := config.JwtKey{ID: "new", Algorithm: "HS256", Secret: "new-secret"}
+
+	before, err := infrastructure.NewJwtKeySetFromConfig(config.Jwt{
+		ActiveKeyID: oldKey.ID,
+		Keys:        []config.JwtKey{oldKey},
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	created, err := authrepository.NewJwtRepository(before).CreateAccessToken(ctx, domainauth.CreateAccessTokenParams{
+		UserID:    "1",
+		IssuedAt:  now,
+		ExpiresAt: now.Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	rotated, err := infrastructure.NewJwtKeySetFromConfig(config.Jwt{
+		ActiveKeyID: newKey.ID,
+		Keys:        []config.JwtKey{newKey, oldKey},
+	})
+	require.NoError(t, err)
+
+	_, err = authrepository.NewJwtRepository(rotated).ParseAccessToken(ctx, domainauth.ParseAccessTokenParams{
+		Token: created.Token,
+	})
+	assert.NoError(t, err, "token signed by a retired key must still verify")
+
+	removed, err := infrastructure.NewJwtKeySetFromConfig(config.Jwt{
+		ActiveKeyID: newKey.ID,
+		Keys:        []config.JwtKey{newKey},
+	})
+	require.NoError(t, err)
+
+	_, err = authrepository.NewJwtRepository(removed).ParseAccessToken(ctx, domainauth.ParseAccessTokenParams{
+		Token: created.Token,
+	})
+	assert.Error(t, err, "token signed by a removed key must be rejected")
+}
+
+func TestJwtRepository_ParseAccessToken_Audience(t *testing.T) {
+	ctx := context.Background()
+	key := config.JwtKey{ID: "hs", Algorithm: "HS256", Secret: "secret"}
+	tokenFor := func(audience ...string) string {
+		keySet, err := infrastructure.NewJwtKeySetFromConfig(config.Jwt{
+			Audience:    audience,
+			ActiveKeyID: key.ID,
+			Keys:        []config.JwtKey{key},
+		})
+		require.NoError(t, err)
+
+		now := time.Now().UTC()
+		created, err := authrepository.NewJwtRepository(keySet).CreateAccessToken(ctx, domainauth.CreateAccessTokenParams{
+			UserID:    "1",
+			IssuedAt:  now,
+			ExpiresAt: now.Add(time.Minute),
+		})
+		require.NoError(t, err)
+		return created.Token
+	}
+
+	keySet, err := infrastructure.NewJwtKeySetFromConfig(config.Jwt{
+		Audience:    []string{"web-api", "mobile-api"},
+		ActiveKeyID: key.ID,
+		Keys:        []config.JwtKey{key},
+	})
+	require.NoError(t, err)
+	repo := authrepository.NewJwtRepository(keySet)
+
+	for _, audience := range [][]string{{"web-api", "mobile-api"}, {"web-api"}, {"mobile-api"}} {
+		_, err = repo.ParseAccessToken(ctx, domainauth.ParseAccessTokenParams{Token: tokenFor(audience...)})
+		assert.NoError(t, err, "audience %v", audience)
+	}
+
+	_, err = repo.ParseAccessToken(ctx, domainauth.ParseAccessTokenParams{Token: tokenFor("billing-api")})
+	assert.Error(t, err, "a token for another audience must be rejected")
+	_, err = repo.ParseAccessToken(ctx, domainauth.ParseAccessTokenParams{Token: tokenFor()})
+	assert.Error(t, err, "a token without audience must be rejected")
+}
+
+func TestJwtRepository_ParseAccessToken_Expired(t *testing.T) {
+	ctx := context.Background()
+	keySet, err := infrastructure.NewJwtKeySetFromConfig(config.Jwt{
+		ActiveKeyID: "hs",
+		Keys:        []config.JwtKey{{ID: "hs", Algorithm: "HS256", Secret: "secret"}},
+	})
+	require.NoError(t, err)
+
+	repo := authrepository.NewJwtRepository(keySet)
+	issuedAt := time.Now().UTC().Add(-time.Hour)
+	created, err := repo.CreateAccessToken(ctx, domainauth.CreateAccessTokenParams{
+		UserID:    "1",
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = repo.ParseAccessToken(ctx, domainauth.ParseAccessTokenParams{Token: created.Token})
+	assert.Error(t, err)
+}
+
+func TestJwtRepository_GetJwks(t *testing.T) {
+	ctx := context.Background()
+	keySet, err := infrastructure.NewJwtKeySetFromConfig(config.Jwt{
+		ActiveKeyID: "rs",
+		Keys: []config.JwtKey{
+			{ID: "hs", Algorithm: "HS256", Secret: "secret"},
+			{ID: "rs", Algorithm: "RS256", PrivateKey: rsaPrivateKeyPEM(t)},
+			{ID: "ed", Algorithm: "EdDSA", PrivateKey: ed25519PrivateKeyPEM(t)},
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := authrepository.NewJwtRepository(keySet).GetJwks(ctx)
+	require.NoError(t, err)
+	require.Len(t, result.Keys, 2, "symmetric keys must not be published")
+
+	assert.Equal(t, "ed", result.Keys[0].KeyID)
+	assert.Equal(t, "OKP", result.Keys[0].KeyType)
+	assert.Equal(t, "Ed25519", result.Keys[0].Curve)
+	assert.NotEmpty(t, result.Keys[0].X)
+
+	assert.Equal(t, "rs", result.Keys[1].KeyID)
+	assert.Equal(t, "RSA", result.Keys[1].KeyType)
+	assert.Equal(t, "AQAB", result.Keys[1].E)
+	assert.NotEmpty(t, result.Keys[1].N)
+}
@@ -0,0 +1,174 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+func (r *loginAttemptRepository) GetDetailLoginAttempt(ctx context.Context, filters domainauth.GetDetailLoginAttemptFilters) (domainauth.GetDetailLoginAttemptResult, error) {
+	query := `
+		SELECT scope, identifier, failed_count, lockout_count, last_failed_at, locked_until
+		FROM auth_login_attempts
+		WHERE scope = $1 AND identifier = $2
+	`
+
+	var result domainauth.GetDetailLoginAttemptResult
+	err := r.db.RDBMS().QueryRowContext(ctx, query,
+		filters.Scope,
+		filters.Identifier,
+	).Scan(
+		&result.Scope,
+		&result.Identifier,
+		&result.FailedCount,
+		&result.LockoutCount,
+		&result.LastFailedAt,
+		&result.LockedUntil,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainauth.GetDetailLoginAttemptResult{}, databases.ErrNoRowFound
+		}
+		return domainauth.GetDetailLoginAttemptResult{}, fmt.Errorf("failed to get login attempt: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *loginAttemptRepository) GetListLoginAttempt(ctx context.Context, filters domainauth.GetListLoginAttemptFilters) (domainauth.GetListLoginAttemptResult, error) {
+	countSq := r.db.Sq().Select("COUNT(*)").From("auth_login_attempts")
+
+	selectSq := r.db.Sq().Select(
+		"scope",
+		"identifier",
+		"failed_count",
+		"lockout_count",
+		"last_failed_at",
+		"locked_until",
+	).From("auth_login_attempts")
+
+	if filters.LockedAfter != nil {
+		countSq = countSq.Where("locked_until > ?", *filters.LockedAfter)
+		selectSq = selectSq.Where("locked_until > ?", *filters.LockedAfter)
+	}
+
+	selectSq = selectSq.OrderBy("last_failed_at DESC")
+
+	attempts := []domainauth.GetDetailLoginAttemptResult{}
+	pagination, err := r.db.RDBMS().QuerySqPagination(ctx, countSq, selectSq, false, filters.Pagination, func(rows *sql.Rows) error {
+		for rows.Next() {
+			var attempt domainauth.GetDetailLoginAttemptResult
+			err := rows.Scan(
+				&attempt.Scope,
+				&attempt.Identifier,
+				&attempt.FailedCount,
+				&attempt.LockoutCount,
+				&attempt.LastFailedAt,
+				&attempt.LockedUntil,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to scan login attempt: %w", err)
+			}
+			attempts = append(attempts, attempt)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domainauth.GetListLoginAttemptResult{}, err
+	}
+
+	return domainauth.GetListLoginAttemptResult{
+		LoginAttempts: attempts,
+		Pagination:    pagination,
+	}, nil
+}
+
+func (r *loginAttemptRepository) IncrementLoginFailure(ctx context.Context, params domainauth.IncrementLoginFailureParams) (domainauth.IncrementLoginFailureResult, error) {
+	// single statement so concurrent failures on the same key are serialized by the row lock
+	query := `
+		INSERT INTO auth_login_attempts (scope, identifier, failed_count, lockout_count, last_failed_at, created_at, updated_at)
+		VALUES ($1, $2, 1, 0, $3, $3, $3)
+		ON CONFLICT (scope, identifier) DO UPDATE SET
+			failed_count = CASE
+				WHEN auth_login_attempts.last_failed_at < $4 THEN 1
+				ELSE auth_login_attempts.failed_count + 1
+			END,
+			lockout_count = CASE
+				WHEN auth_login_attempts.last_failed_at < $5 THEN 0
+				ELSE auth_login_attempts.lockout_count
+			END,
+			last_failed_at = $3,
+			updated_at = $3
+		RETURNING failed_count, lockout_count
+	`
+
+	var result domainauth.IncrementLoginFailureResult
+	err := r.db.RDBMS().QueryRowContext(ctx, query,
+		params.Scope,
+		params.Identifier,
+		params.FailedAt,
+		params.WindowStart,
+		params.LockoutResetStart,
+	).Scan(&result.FailedCount, &result.LockoutCount)
+	if err != nil {
+		return domainauth.IncrementLoginFailureResult{}, fmt.Errorf("failed to increment login failure: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *loginAttemptRepository) LockLoginAttempt(ctx context.Context, params domainauth.LockLoginAttemptParams) (domainauth.LockLoginAttemptResult, error) {
+	query := `
+		UPDATE auth_login_attempts
+		SET locked_until = $1, lockout_count = lockout_count + 1, failed_count = 0, updated_at = $2
+		WHERE scope = $3 AND identifier = $4
+		RETURNING lockout_count
+	`
+
+	var result domainauth.LockLoginAttemptResult
+	err := r.db.RDBMS().QueryRowContext(ctx, query,
+		params.LockedUntil,
+		time.Now().UTC(),
+		params.Scope,
+		params.Identifier,
+	).Scan(&result.LockoutCount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainauth.LockLoginAttemptResult{}, databases.ErrNoRowFound
+		}
+		return domainauth.LockLoginAttemptResult{}, fmt.Errorf("failed to lock login attempt: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *loginAttemptRepository) DeleteLoginAttempt(ctx context.Context, params domainauth.DeleteLoginAttemptParams) (domainauth.DeleteLoginAttemptResult, error) {
+	query := `
+		DELETE FROM auth_login_attempts
+		WHERE scope = $1 AND identifier = $2
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.Scope,
+		params.Identifier,
+	)
+	if err != nil {
+		return domainauth.DeleteLoginAttemptResult{}, fmt.Errorf("failed to delete login attempt: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.DeleteLoginAttemptResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.DeleteLoginAttemptResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package authrepository
+
+import (
+	"context"
+	"sort"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
+)
+
+func (r *loginAttemptMemoryRepository) GetDetailLoginAttempt(ctx context.Context, filters domainauth.GetDetailLoginAttemptFilters) (domainauth.GetDetailLoginAttemptResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[loginAttemptKey{scope: filters.Scope, identifier: filters.Identifier}]
+	if !ok {
+		return domainauth.GetDetailLoginAttemptResult{}, databases.ErrNoRowFound
+	}
+
+	return copyLoginAttempt(attempt), nil
+}
+
+func (r *loginAttemptMemoryRepository) GetListLoginAttempt(ctx context.Context, filters domainauth.GetListLoginAttemptFilters) (domainauth.GetListLoginAttemptResult, error) {
+	r.mu.Lock()
+	attempts := make([]domainauth.GetDetailLoginAttemptResult, 0, len(r.attempts))
+	for _, v := range r.attempts {
+		if filters.LockedAfter != nil && (v.LockedUntil == nil || !v.LockedUntil.After(*filters.LockedAfter)) {
+			continue
+		}
+		attempts = append(attempts, copyLoginAttempt(v))
+	}
+	r.mu.Unlock()
+
+	sort.Slice(attempts, func(i, j int) bool {
+		return attempts[i].LastFailedAt.After(attempts[j].LastFailedAt)
+	})
+
+	pagination := primitive.CreatePaginationOutput(filters.Pagination, int64(len(attempts)))
+	offset := min(primitive.GetOffsetValue(filters.Pagination.Page, filters.Pagination.PageSize), int64(len(attempts)))
+	end := int64(len(attempts))
+	if filters.Pagination.PageSize > 0 {
+		end = min(offset+filters.Pagination.PageSize, end)
+	}
+
+	return domainauth.GetListLoginAttemptResult{
+		LoginAttempts: attempts[offset:end],
+		Pagination:    pagination,
+	}, nil
+}
+
+func (r *loginAttemptMemoryRepository) IncrementLoginFailure(ctx context.Context, params domainauth.IncrementLoginFailureParams) (domainauth.IncrementLoginFailureResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := loginAttemptKey{scope: params.Scope, identifier: params.Identifier}
+	attempt, ok := r.attempts[key]
+	if !ok {
+		attempt = &domainauth.GetDetailLoginAttemptResult{
+			Scope:      params.Scope,
+			Identifier: params.Identifier,
+		}
+		r.attempts[key] = attempt
+	} else {
+		if attempt.LastFailedAt.Before(params.WindowStart) {
+			attempt.FailedCount = 0
+		}
+		if attempt.LastFailedAt.Before(params.LockoutResetStart) {
+			attempt.LockoutCount = 0
+		}
+	}
+
+	attempt.FailedCount++
+	attempt.LastFailedAt = params.FailedAt
+
+	return domainauth.IncrementLoginFailureResult{
+		FailedCount:  attempt.FailedCount,
+		LockoutCount: attempt.LockoutCount,
+	}, nil
+}
+
+func (r *loginAttemptMemoryRepository) LockLoginAttempt(ctx context.Context, params domainauth.LockLoginAttemptParams) (domainauth.LockLoginAttemptResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[loginAttemptKey{scope: params.Scope, identifier: params.Identifier}]
+	if !ok {
+		return domainauth.LockLoginAttemptResult{}, databases.ErrNoRowFound
+	}
+
+	lockedUntil := params.LockedUntil
+	attempt.LockedUntil = &lockedUntil
+	attempt.LockoutCount++
+	attempt.FailedCount = 0
+
+	return domainauth.LockLoginAttemptResult{
+		LockoutCount: attempt.LockoutCount,
+	}, nil
+}
+
+func (r *loginAttemptMemoryRepository) DeleteLoginAttempt(ctx context.Context, params domainauth.DeleteLoginAttemptParams) (domainauth.DeleteLoginAttemptResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := loginAttemptKey{scope: params.Scope, identifier: params.Identifier}
+	_, ok := r.attempts[key]
+	delete(r.attempts, key)
+
+	return domainauth.DeleteLoginAttemptResult{
+		Success: ok,
+	}, nil
+}
+
+func copyLoginAttempt(attempt *domainauth.GetDetailLoginAttemptResult) domainauth.GetDetailLoginAttemptResult {
+	result := *attempt
+	if attempt.LockedUntil != nil {
+		lockedUntil := *attempt.LockedUntil
+		result.LockedUntil = &lockedUntil
+	}
+	return result
+}
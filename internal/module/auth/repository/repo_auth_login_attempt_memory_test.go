@@ -0,0 +1,63 @@
+package authrepository_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginAttemptMemoryRepository_IncrementLoginFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := authrepository.NewLoginAttemptMemoryRepository()
+	now := time.Now().UTC()
+
+	increment := func(failedAt time.Time) domainauth.IncrementLoginFailureResult {
+		result, err := repo.IncrementLoginFailure(ctx, domainauth.IncrementLoginFailureParams{
+			Scope:             domainauth.LoginAttemptScopeIP,
+			Identifier:        "10.0.0.1",
+			FailedAt:          failedAt,
+			WindowStart:       failedAt.Add(-time.Minute),
+			LockoutResetStart: failedAt.Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			increment(now)
+		}()
+	}
+	wg.Wait()
+
+	attempt, err := repo.GetDetailLoginAttempt(ctx, domainauth.GetDetailLoginAttemptFilters{
+		Scope:      domainauth.LoginAttemptScopeIP,
+		Identifier: "10.0.0.1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), attempt.FailedCount, "concurrent failures must not be lost")
+
+	_, err = repo.LockLoginAttempt(ctx, domainauth.LockLoginAttemptParams{
+		Scope:       domainauth.LoginAttemptScopeIP,
+		Identifier:  "10.0.0.1",
+		LockedUntil: now.Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	result := increment(now.Add(2 * time.Minute))
+	assert.Equal(t, int64(1), result.FailedCount, "count restarts after the window")
+	assert.Equal(t, int64(1), result.LockoutCount)
+
+	result = increment(now.Add(3 * time.Hour))
+	assert.Equal(t, int64(0), result.LockoutCount, "lockout escalation restarts after the reset period")
+}
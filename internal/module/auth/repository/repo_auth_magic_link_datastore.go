@@ -0,0 +1,102 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+func (r *magicLinkRepository) CreateMagicLink(ctx context.Context, params domainauth.CreateMagicLinkParams) (domainauth.CreateMagicLinkResult, error) {
+	query := `
+		INSERT INTO auth_magic_links (user_id, token_hash, ip_address, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	tokenHash, err := r.tokenHasher.Hash(params.Token)
+	if err != nil {
+		return domainauth.CreateMagicLinkResult{}, fmt.Errorf("failed to hash magic link token: %w", err)
+	}
+
+	var result domainauth.CreateMagicLinkResult
+	err = r.db.RDBMS().QueryRowContext(ctx, query,
+		params.UserID,
+		tokenHash,
+		params.IPAddress,
+		params.ExpiresAt,
+		time.Now().UTC(),
+	).Scan(&result.ID, &result.CreatedAt)
+	if err != nil {
+		return domainauth.CreateMagicLinkResult{}, fmt.Errorf("failed to create magic link: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *magicLinkRepository) CountMagicLink(ctx context.Context, filters domainauth.CountMagicLinkFilters) (domainauth.CountMagicLinkResult, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM auth_magic_links
+		WHERE user_id = $1 AND created_at > $2
+	`
+
+	var result domainauth.CountMagicLinkResult
+	err := r.db.RDBMS().QueryRowContext(ctx, query, filters.UserID, filters.CreatedAfter).Scan(&result.Count)
+	if err != nil {
+		return domainauth.CountMagicLinkResult{}, fmt.Errorf("failed to count magic links: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *magicLinkRepository) ConsumeMagicLink(ctx context.Context, params domainauth.ConsumeMagicLinkParams) (domainauth.ConsumeMagicLinkResult, error) {
+	query := `
+		UPDATE auth_magic_links
+		SET used_at = $1
+		WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1
+		RETURNING user_id
+	`
+
+	tokenHash, err := r.tokenHasher.Hash(params.Token)
+	if err != nil {
+		return domainauth.ConsumeMagicLinkResult{}, fmt.Errorf("failed to hash magic link token: %w", err)
+	}
+
+	var result domainauth.ConsumeMagicLinkResult
+	err = r.db.RDBMS().QueryRowContext(ctx, query, params.UsedAt, tokenHash).Scan(&result.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainauth.ConsumeMagicLinkResult{}, databases.ErrNoRowFound
+		}
+		return domainauth.ConsumeMagicLinkResult{}, fmt.Errorf("failed to consume magic link: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *magicLinkRepository) DeleteExpiredMagicLinks(ctx context.Context, params domainauth.DeleteExpiredMagicLinksParams) (domainauth.DeleteExpiredMagicLinksResult, error) {
+	query := `
+		DELETE FROM auth_magic_links
+		WHERE expires_at < $1
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query, params.BeforeDate)
+	if err != nil {
+		return domainauth.DeleteExpiredMagicLinksResult{}, fmt.Errorf("failed to delete expired magic links: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.DeleteExpiredMagicLinksResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.DeleteExpiredMagicLinksResult{
+		DeletedCount: rowsAffected,
+	}, nil
+}
@@ -0,0 +1,328 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases/sqlx"
+)
+
+func (r *mfaRepository) GetDetailTotp(ctx context.Context, filters domainauth.GetDetailTotpFilters) (domainauth.GetDetailTotpResult, error) {
+	query := `
+		SELECT user_id, secret_encrypted, confirmed_at, last_used_step, created_at
+		FROM auth_mfa_totp
+		WHERE user_id = $1
+	`
+
+	var (
+		result          domainauth.GetDetailTotpResult
+		secretEncrypted string
+	)
+	err := r.db.RDBMS().QueryRowContext(ctx, query, filters.UserID).Scan(
+		&result.UserID,
+		&secretEncrypted,
+		&result.ConfirmedAt,
+		&result.LastUsedStep,
+		&result.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainauth.GetDetailTotpResult{}, databases.ErrNoRowFound
+		}
+		return domainauth.GetDetailTotpResult{}, fmt.Errorf("failed to get totp: %w", err)
+	}
+
+	result.Secret, err = r.secretCipher.Decrypt(secretEncrypted)
+	if err != nil {
+		return domainauth.GetDetailTotpResult{}, fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *mfaRepository) UpsertTotp(ctx context.Context, params domainauth.UpsertTotpParams) (domainauth.UpsertTotpResult, error) {
+	query := `
+		INSERT INTO auth_mfa_totp (user_id, secret_encrypted, confirmed_at, last_used_step, created_at, updated_at)
+		VALUES ($1, $2, NULL, 0, $3, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret_encrypted = EXCLUDED.secret_encrypted,
+			confirmed_at = NULL,
+			last_used_step = 0,
+			created_at = EXCLUDED.created_at,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	secretEncrypted, err := r.secretCipher.Encrypt(params.Secret)
+	if err != nil {
+		return domainauth.UpsertTotpResult{}, fmt.Errorf("failed to encrypt totp secret: %w", err)
+	}
+
+	now := time.Now().UTC()
+	_, err = r.db.RDBMS().ExecContext(ctx, query,
+		params.UserID,
+		secretEncrypted,
+		now,
+	)
+	if err != nil {
+		return domainauth.UpsertTotpResult{}, fmt.Errorf("failed to upsert totp: %w", err)
+	}
+
+	return domainauth.UpsertTotpResult{
+		CreatedAt: now,
+	}, nil
+}
+
+func (r *mfaRepository) ConfirmTotp(ctx context.Context, params domainauth.ConfirmTotpParams) (domainauth.ConfirmTotpResult, error) {
+	query := `
+		UPDATE auth_mfa_totp
+		SET confirmed_at = $1, last_used_step = $2, updated_at = $1
+		WHERE user_id = $3 AND confirmed_at IS NULL
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.ConfirmedAt,
+		params.Step,
+		params.UserID,
+	)
+	if err != nil {
+		return domainauth.ConfirmTotpResult{}, fmt.Errorf("failed to confirm totp: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.ConfirmTotpResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.ConfirmTotpResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
+
+func (r *mfaRepository) UseTotpStep(ctx context.Context, params domainauth.UseTotpStepParams) (domainauth.UseTotpStepResult, error) {
+	query := `
+		UPDATE auth_mfa_totp
+		SET last_used_step = $1, updated_at = $2
+		WHERE user_id = $3 AND last_used_step < $1
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.Step,
+		time.Now().UTC(),
+		params.UserID,
+	)
+	if err != nil {
+		return domainauth.UseTotpStepResult{}, fmt.Errorf("failed to use totp step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.UseTotpStepResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.UseTotpStepResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
+
+func (r *mfaRepository) DeleteTotp(ctx context.Context, params domainauth.DeleteTotpParams) (domainauth.DeleteTotpResult, error) {
+	var rowsAffected int64
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM auth_mfa_recovery_codes WHERE user_id = $1`, params.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM auth_mfa_totp WHERE user_id = $1`, params.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to delete totp: %w", err)
+		}
+
+		rowsAffected, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domainauth.DeleteTotpResult{}, err
+	}
+
+	return domainauth.DeleteTotpResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, params domainauth.ReplaceRecoveryCodesParams) (domainauth.ReplaceRecoveryCodesResult, error) {
+	codeHashes := make([]string, 0, len(params.Codes))
+	for _, code := range params.Codes {
+		codeHash, err := r.tokenHasher.Hash(code)
+		if err != nil {
+			return domainauth.ReplaceRecoveryCodesResult{}, fmt.Errorf("failed to hash recovery code: %w", err)
+		}
+		codeHashes = append(codeHashes, codeHash)
+	}
+
+	now := time.Now().UTC()
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM auth_mfa_recovery_codes WHERE user_id = $1`, params.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+
+		for _, codeHash := range codeHashes {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO auth_mfa_recovery_codes (user_id, code_hash, created_at)
+				VALUES ($1, $2, $3)
+			`, params.UserID, codeHash, now)
+			if err != nil {
+				return fmt.Errorf("failed to create recovery code: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domainauth.ReplaceRecoveryCodesResult{}, err
+	}
+
+	return domainauth.ReplaceRecoveryCodesResult{
+		CreatedAt: now,
+	}, nil
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, params domainauth.UseRecoveryCodeParams) (domainauth.UseRecoveryCodeResult, error) {
+	query := `
+		UPDATE auth_mfa_recovery_codes
+		SET used_at = $1
+		WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL
+	`
+
+	codeHash, err := r.tokenHasher.Hash(params.Code)
+	if err != nil {
+		return domainauth.UseRecoveryCodeResult{}, fmt.Errorf("failed to hash recovery code: %w", err)
+	}
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.UsedAt,
+		params.UserID,
+		codeHash,
+	)
+	if err != nil {
+		return domainauth.UseRecoveryCodeResult{}, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.UseRecoveryCodeResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.UseRecoveryCodeResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
+
+func (r *mfaRepository) CreateMfaChallenge(ctx context.Context, params domainauth.CreateMfaChallengeParams) (domainauth.CreateMfaChallengeResult, error) {
+	query := `
+		INSERT INTO auth_mfa_challenges (user_id, challenge_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	challengeHash, err := r.tokenHasher.Hash(params.Challenge)
+	if err != nil {
+		return domainauth.CreateMfaChallengeResult{}, fmt.Errorf("failed to hash mfa challenge: %w", err)
+	}
+
+	var result domainauth.CreateMfaChallengeResult
+	err = r.db.RDBMS().QueryRowContext(ctx, query,
+		params.UserID,
+		challengeHash,
+		params.ExpiresAt,
+		time.Now().UTC(),
+	).Scan(&result.ID, &result.CreatedAt)
+	if err != nil {
+		return domainauth.CreateMfaChallengeResult{}, fmt.Errorf("failed to create mfa challenge: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *mfaRepository) GetDetailMfaChallenge(ctx context.Context, filters domainauth.GetDetailMfaChallengeFilters) (domainauth.GetDetailMfaChallengeResult, error) {
+	query := `
+		SELECT id, user_id, failed_attempts, expires_at, used_at
+		FROM auth_mfa_challenges
+		WHERE challenge_hash = $1
+	`
+
+	challengeHash, err := r.tokenHasher.Hash(filters.Challenge)
+	if err != nil {
+		return domainauth.GetDetailMfaChallengeResult{}, fmt.Errorf("failed to hash mfa challenge: %w", err)
+	}
+
+	var result domainauth.GetDetailMfaChallengeResult
+	err = r.db.RDBMS().QueryRowContext(ctx, query, challengeHash).Scan(
+		&result.ID,
+		&result.UserID,
+		&result.FailedAttempts,
+		&result.ExpiresAt,
+		&result.UsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainauth.GetDetailMfaChallengeResult{}, databases.ErrNoRowFound
+		}
+		return domainauth.GetDetailMfaChallengeResult{}, fmt.Errorf("failed to get mfa challenge: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *mfaRepository) IncrementMfaChallengeFailure(ctx context.Context, params domainauth.IncrementMfaChallengeFailureParams) (domainauth.IncrementMfaChallengeFailureResult, error) {
+	query := `
+		UPDATE auth_mfa_challenges
+		SET failed_attempts = failed_attempts + 1
+		WHERE id = $1
+		RETURNING failed_attempts
+	`
+
+	var result domainauth.IncrementMfaChallengeFailureResult
+	err := r.db.RDBMS().QueryRowContext(ctx, query, params.ID).Scan(&result.FailedAttempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainauth.IncrementMfaChallengeFailureResult{}, databases.ErrNoRowFound
+		}
+		return domainauth.IncrementMfaChallengeFailureResult{}, fmt.Errorf("failed to increment mfa challenge failure: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *mfaRepository) UseMfaChallenge(ctx context.Context, params domainauth.UseMfaChallengeParams) (domainauth.UseMfaChallengeResult, error) {
+	query := `
+		UPDATE auth_mfa_challenges
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL AND expires_at > $1
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.UsedAt,
+		params.ID,
+	)
+	if err != nil {
+		return domainauth.UseMfaChallengeResult{}, fmt.Errorf("failed to use mfa challenge: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.UseMfaChallengeResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.UseMfaChallengeResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
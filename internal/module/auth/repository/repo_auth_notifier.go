@@ -0,0 +1,32 @@
+package authrepository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/infrastructure"
+)
+
+func (r *notifierRepository) SendMagicLink(ctx context.Context, params domainauth.SendMagicLinkParams) (domainauth.SendMagicLinkResult, error) {
+	body := fmt.Sprintf(
+		"Hi %s,\n\nUse the link below to sign in. It can be used once and expires at %s.\n\n%s\n\nIf you did not ask to sign in you can ignore this message.",
+		params.Name,
+		params.ExpiresAt.UTC().Format(time.RFC1123),
+		params.Link,
+	)
+
+	err := r.notifier.Notify(ctx, infrastructure.Notification{
+		Kind:      "magic_link",
+		To:        params.Email,
+		Subject:   "Your sign-in link",
+		Body:      body,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return domainauth.SendMagicLinkResult{}, fmt.Errorf("failed to send magic link: %w", err)
+	}
+
+	return domainauth.SendMagicLinkResult{}, nil
+}
@@ -0,0 +1,44 @@
+package authrepository_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-bootstrap/internal/config"
+	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/infrastructure"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifierRepository_SendMagicLink_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	notifier, err := infrastructure.NewNotifierFromConfig(config.Notifier{
+		Driver:   "file",
+		FilePath: path,
+	})
+	require.NoError(t, err)
+
+	_, err = authrepository.NewNotifierRepository(notifier).SendMagicLink(context.Background(), domainauth.SendMagicLinkParams{
+		Email:     "user@example.com",
+		Name:      "User",
+		Link:      "https://app.example.com/magic-link?token=abc",
+		ExpiresAt: time.Now().Add(15 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var notification infrastructure.Notification
+	require.NoError(t, json.Unmarshal(content, &notification))
+	assert.Equal(t, "magic_link", notification.Kind)
+	assert.Equal(t, "user@example.com", notification.To)
+	assert.Contains(t, notification.Body, "https://app.example.com/magic-link?token=abc")
+}
@@ -0,0 +1,23 @@
+package authrepository
+
+import (
+	"context"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+func (r *oauthClientRepository) GetDetailOauthClient(ctx context.Context, filters domainauth.GetDetailOauthClientFilters) (domainauth.GetDetailOauthClientResult, error) {
+	client, ok := r.clients.Get(filters.ClientID)
+	if !ok {
+		return domainauth.GetDetailOauthClientResult{}, databases.ErrNoRowFound
+	}
+
+	return domainauth.GetDetailOauthClientResult{
+		OauthClient: domainauth.OauthClient{
+			ClientID:     client.ClientID,
+			ClientSecret: client.ClientSecret,
+		},
+	}, nil
+}
@@ -0,0 +1,278 @@
+package authrepository
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/infrastructure"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// maxOidcResponseSize caps what is read from a provider response.
+const maxOidcResponseSize = 1 << 20
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type oidcJwks struct {
+	Keys []struct {
+		KeyType string `json:"kty"`
+		KeyID   string `json:"kid"`
+		N       string `json:"n"`
+		E       string `json:"e"`
+	} `json:"keys"`
+}
+
+type oidcTokenResponse struct {
+	IdToken string `json:"id_token"`
+}
+
+type oidcIdTokenClaims struct {
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified oidcBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// oidcBool accepts true and "true", some providers send email_verified as
+// a string.
+type oidcBool bool
+
+func (b *oidcBool) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*b = oidcBool(v)
+	case string:
+		*b = oidcBool(v == "true")
+	default:
+		*b = false
+	}
+	return nil
+}
+
+func (r *oidcRepository) GetOidcAuthorizationUrl(ctx context.Context, params domainauth.GetOidcAuthorizationUrlParams) (domainauth.GetOidcAuthorizationUrlResult, error) {
+	provider, ok := r.providers.Get(params.Provider)
+	if !ok {
+		return domainauth.GetOidcAuthorizationUrlResult{}, domainauth.ErrOidcProviderNotFound
+	}
+
+	discovery, err := r.discover(ctx, provider)
+	if err != nil {
+		return domainauth.GetOidcAuthorizationUrlResult{}, err
+	}
+
+	authorizationUrl, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return domainauth.GetOidcAuthorizationUrlResult{}, fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	query := authorizationUrl.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.ClientID)
+	query.Set("redirect_uri", provider.RedirectURL)
+	query.Set("scope", strings.Join(provider.Scopes, " "))
+	query.Set("state", params.State)
+	query.Set("nonce", params.Nonce)
+	query.Set("code_challenge", params.CodeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authorizationUrl.RawQuery = query.Encode()
+
+	return domainauth.GetOidcAuthorizationUrlResult{
+		Url: authorizationUrl.String(),
+	}, nil
+}
+
+func (r *oidcRepository) ExchangeOidcCode(ctx context.Context, params domainauth.ExchangeOidcCodeParams) (domainauth.ExchangeOidcCodeResult, error) {
+	provider, ok := r.providers.Get(params.Provider)
+	if !ok {
+		return domainauth.ExchangeOidcCodeResult{}, domainauth.ErrOidcProviderNotFound
+	}
+
+	discovery, err := r.discover(ctx, provider)
+	if err != nil {
+		return domainauth.ExchangeOidcCodeResult{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", params.Code)
+	form.Set("redirect_uri", provider.RedirectURL)
+	form.Set("code_verifier", params.CodeVerifier)
+	form.Set("client_id", provider.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return domainauth.ExchangeOidcCodeResult{}, fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if provider.ClientSecret != "" {
+		// client_secret_basic, RFC 6749 section 2.3.1
+		req.SetBasicAuth(url.QueryEscape(provider.ClientID), url.QueryEscape(provider.ClientSecret))
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return domainauth.ExchangeOidcCodeResult{}, fmt.Errorf("failed to call token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOidcResponseSize))
+	if err != nil {
+		return domainauth.ExchangeOidcCodeResult{}, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return domainauth.ExchangeOidcCodeResult{}, fmt.Errorf("%w: token endpoint answered %d: %s", domainauth.ErrOidcLoginRejected, resp.StatusCode, body)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return domainauth.ExchangeOidcCodeResult{}, fmt.Errorf("token endpoint answered %d", resp.StatusCode)
+	}
+
+	var tokenResponse oidcTokenResponse
+	if err = json.Unmarshal(body, &tokenResponse); err != nil {
+		return domainauth.ExchangeOidcCodeResult{}, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokenResponse.IdToken == "" {
+		return domainauth.ExchangeOidcCodeResult{}, fmt.Errorf("%w: token response has no id_token", domainauth.ErrOidcLoginRejected)
+	}
+
+	keys, err := r.fetchJwks(ctx, discovery.JwksURI)
+	if err != nil {
+		return domainauth.ExchangeOidcCodeResult{}, err
+	}
+
+	var claims oidcIdTokenClaims
+	_, err = jwt.ParseWithClaims(tokenResponse.IdToken, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := keys[kid]; ok {
+			return key, nil
+		}
+		if kid == "" && len(keys) == 1 {
+			for _, key := range keys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	},
+		// RS256 is the algorithm every OpenID provider has to support
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(provider.Issuer),
+		jwt.WithAudience(provider.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return domainauth.ExchangeOidcCodeResult{}, fmt.Errorf("%w: invalid id token: %w", domainauth.ErrOidcLoginRejected, err)
+	}
+	if claims.Nonce != params.Nonce {
+		return domainauth.ExchangeOidcCodeResult{}, fmt.Errorf("%w: id token nonce mismatch", domainauth.ErrOidcLoginRejected)
+	}
+	if claims.Subject == "" {
+		return domainauth.ExchangeOidcCodeResult{}, fmt.Errorf("%w: id token has no subject", domainauth.ErrOidcLoginRejected)
+	}
+
+	return domainauth.ExchangeOidcCodeResult{
+		Claims: domainauth.OidcClaims{
+			Subject:       claims.Subject,
+			Email:         claims.Email,
+			EmailVerified: bool(claims.EmailVerified),
+			Name:          claims.Name,
+		},
+	}, nil
+}
+
+// discover returns the discovery document of provider, fetching it on first use.
+func (r *oidcRepository) discover(ctx context.Context, provider infrastructure.OidcProvider) (oidcDiscovery, error) {
+	r.mu.Lock()
+	discovery, ok := r.discovery[provider.Issuer]
+	r.mu.Unlock()
+	if ok {
+		return discovery, nil
+	}
+
+	discoveryUrl := strings.TrimSuffix(provider.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := r.getJSON(ctx, discoveryUrl, &discovery); err != nil {
+		return oidcDiscovery{}, fmt.Errorf("failed to discover oidc provider %s: %w", provider.Name, err)
+	}
+	if discovery.Issuer != provider.Issuer {
+		return oidcDiscovery{}, fmt.Errorf("oidc provider %s: discovered issuer %q does not match %q", provider.Name, discovery.Issuer, provider.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "" {
+		return oidcDiscovery{}, fmt.Errorf("oidc provider %s: discovery document is incomplete", provider.Name)
+	}
+
+	r.mu.Lock()
+	r.discovery[provider.Issuer] = discovery
+	r.mu.Unlock()
+	return discovery, nil
+}
+
+// fetchJwks returns the RSA signing keys published at jwksUri by key id.
+func (r *oidcRepository) fetchJwks(ctx context.Context, jwksUri string) (map[string]*rsa.PublicKey, error) {
+	var jwks oidcJwks
+	if err := r.getJSON(ctx, jwksUri, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, v := range jwks.Keys {
+		if v.KeyType != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(v.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus of key %q: %w", v.KeyID, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(v.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent of key %q: %w", v.KeyID, err)
+		}
+		keys[v.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no rsa keys")
+	}
+
+	return keys, nil
+}
+
+func (r *oidcRepository) getJSON(ctx context.Context, target string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxOidcResponseSize)).Decode(v)
+}
@@ -0,0 +1,13 @@
+package authrepository
+
+import (
+	"context"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+)
+
+func (r *rolePolicyRepository) GetRolePermissions(ctx context.Context, params domainauth.GetRolePermissionsParams) (domainauth.GetRolePermissionsResult, error) {
+	return domainauth.GetRolePermissionsResult{
+		Permissions: r.rolePolicy.Permissions(string(params.Role)),
+	}, nil
+}
@@ -0,0 +1,343 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases/sqlx"
+)
+
+func (r *repository) CreateSession(ctx context.Context, params domainauth.CreateSessionParams) (domainauth.CreateSessionResult, error) {
+	var result domainauth.CreateSessionResult
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		if params.Limit.Enabled() {
+			evicted, ok, err := r.evictOverLimitSessions(ctx, tx, params)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				result.LimitReached = true
+				return nil
+			}
+			result.EvictedSessionIDs = evicted
+		}
+
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO auth_sessions (user_id, family_id, user_agent, ip_address, platform, created_at, last_used_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6, $7)
+			RETURNING id
+		`,
+			params.UserID,
+			params.FamilyID,
+			params.UserAgent,
+			params.IPAddress,
+			params.Platform,
+			params.CreatedAt,
+			params.ExpiresAt,
+		).Scan(&result.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domainauth.CreateSessionResult{}, err
+	}
+
+	return result, nil
+}
+
+// evictOverLimitSessions revokes the oldest sessions of the user that keep a
+// new one from fitting params.Limit, together with their tokens. The user row
+// stays locked until the transaction ends, so concurrent logins of one user
+// are counted one after another.
+func (r *repository) evictOverLimitSessions(ctx context.Context, tx sqlx.RDBMS, params domainauth.CreateSessionParams) ([]string, bool, error) {
+	var locked int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, params.UserID).Scan(&locked)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, platform, created_at
+		FROM auth_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY created_at, id
+	`, params.UserID, params.CreatedAt)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get active sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var active []domainauth.Session
+	for rows.Next() {
+		var session domainauth.Session
+		if err = rows.Scan(&session.ID, &session.Platform, &session.CreatedAt); err != nil {
+			return nil, false, fmt.Errorf("failed to scan session: %w", err)
+		}
+		active = append(active, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to iterate sessions: %w", err)
+	}
+
+	evict, ok := params.Limit.Overflow(active, params.Platform)
+	if !ok {
+		return nil, false, nil
+	}
+
+	evictedIDs := make([]string, 0, len(evict))
+	for _, session := range evict {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE auth_tokens
+			SET status = $1, updated_at = $2
+			WHERE status IN ($3, $4) AND family_id = (
+				SELECT family_id FROM auth_sessions WHERE id = $5
+			)
+		`,
+			domainauth.TokenStatusRevoked,
+			params.CreatedAt,
+			domainauth.TokenStatusActive,
+			domainauth.TokenStatusRotated,
+			session.ID,
+		)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to revoke session tokens: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE auth_sessions
+			SET revoked_at = $1
+			WHERE id = $2
+		`, params.CreatedAt, session.ID)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		evictedIDs = append(evictedIDs, session.ID)
+	}
+
+	return evictedIDs, true, nil
+}
+
+func (r *repository) GetDetailSession(ctx context.Context, filters domainauth.GetDetailSessionFilters) (domainauth.GetDetailSessionResult, error) {
+	sq := r.db.Sq().Select(
+		"id",
+		"user_id",
+		"family_id",
+		"user_agent",
+		"ip_address",
+		"platform",
+		"created_at",
+		"last_used_at",
+		"expires_at",
+		"revoked_at",
+	).From("auth_sessions")
+
+	if filters.SessionID != nil {
+		sq = sq.Where("id = ?", *filters.SessionID)
+	}
+
+	if filters.FamilyID != nil {
+		sq = sq.Where("family_id = ?", *filters.FamilyID)
+	}
+
+	if filters.UserID != nil {
+		sq = sq.Where("user_id = ?", *filters.UserID)
+	}
+
+	sq = sq.Limit(1)
+
+	row, err := r.db.RDBMS().QueryRowSq(ctx, sq, false)
+	if err != nil {
+		return domainauth.GetDetailSessionResult{}, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	var result domainauth.GetDetailSessionResult
+	err = row.Scan(
+		&result.ID,
+		&result.UserID,
+		&result.FamilyID,
+		&result.UserAgent,
+		&result.IPAddress,
+		&result.Platform,
+		&result.CreatedAt,
+		&result.LastUsedAt,
+		&result.ExpiresAt,
+		&result.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainauth.GetDetailSessionResult{}, databases.ErrNoRowFound
+		}
+		return domainauth.GetDetailSessionResult{}, fmt.Errorf("failed to scan session: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *repository) GetListSession(ctx context.Context, filters domainauth.GetListSessionFilters) (domainauth.GetListSessionResult, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, platform, created_at, last_used_at, expires_at
+		FROM auth_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_used_at DESC
+	`
+
+	rows, err := r.db.RDBMS().QueryContext(ctx, query, filters.UserID, filters.Now)
+	if err != nil {
+		return domainauth.GetListSessionResult{}, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]domainauth.Session, 0)
+	for rows.Next() {
+		var session domainauth.Session
+		err = rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.Platform,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return domainauth.GetListSessionResult{}, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return domainauth.GetListSessionResult{}, fmt.Errorf("failed to iterate sessions: %w", err)
+	}
+
+	return domainauth.GetListSessionResult{
+		Sessions: sessions,
+	}, nil
+}
+
+func (r *repository) TouchSession(ctx context.Context, params domainauth.TouchSessionParams) (domainauth.TouchSessionResult, error) {
+	query := `
+		UPDATE auth_sessions
+		SET last_used_at = $1, ip_address = $2, expires_at = $3
+		WHERE id = $4 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.LastUsedAt,
+		params.IPAddress,
+		params.ExpiresAt,
+		params.SessionID,
+	)
+	if err != nil {
+		return domainauth.TouchSessionResult{}, fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.TouchSessionResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.TouchSessionResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
+
+func (r *repository) RevokeSessions(ctx context.Context, params domainauth.RevokeSessionsParams) (domainauth.RevokeSessionsResult, error) {
+	// sessionFilter narrows "the sessions of user $N that are not revoked",
+	// its argument always comes right after the user id
+	var (
+		sessionFilter string
+		sessionArgs   []any
+	)
+	switch {
+	case params.SessionID != nil:
+		sessionFilter = "AND id = $%d"
+		sessionArgs = []any{*params.SessionID}
+	case params.ExceptSessionID != nil:
+		sessionFilter = "AND id <> $%d"
+		sessionArgs = []any{*params.ExceptSessionID}
+	}
+
+	revokedAt := time.Now().UTC()
+	var rowsAffected int64
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		tokenQuery := `
+			UPDATE auth_tokens
+			SET status = $1, updated_at = $2
+			WHERE user_id = $3 AND status IN ($4, $5)
+		`
+		if sessionFilter != "" {
+			tokenQuery += `AND family_id IN (
+				SELECT family_id FROM auth_sessions
+				WHERE user_id = $3 AND revoked_at IS NULL ` + fmt.Sprintf(sessionFilter, 6) + `
+			)`
+		}
+		tokenArgs := append([]any{
+			domainauth.TokenStatusRevoked,
+			revokedAt,
+			params.UserID,
+			domainauth.TokenStatusActive,
+			domainauth.TokenStatusRotated,
+		}, sessionArgs...)
+
+		_, err := tx.ExecContext(ctx, tokenQuery, tokenArgs...)
+		if err != nil {
+			return fmt.Errorf("failed to revoke session tokens: %w", err)
+		}
+
+		sessionQuery := `
+			UPDATE auth_sessions
+			SET revoked_at = $1
+			WHERE user_id = $2 AND revoked_at IS NULL
+		`
+		if sessionFilter != "" {
+			sessionQuery += fmt.Sprintf(sessionFilter, 3)
+		}
+		result, err := tx.ExecContext(ctx, sessionQuery, append([]any{revokedAt, params.UserID}, sessionArgs...)...)
+		if err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+
+		rowsAffected, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domainauth.RevokeSessionsResult{}, err
+	}
+
+	return domainauth.RevokeSessionsResult{
+		RevokedCount: rowsAffected,
+		RevokedAt:    revokedAt,
+	}, nil
+}
+
+func (r *repository) DeleteExpiredSessions(ctx context.Context, params domainauth.DeleteExpiredSessionsParams) (domainauth.DeleteExpiredSessionsResult, error) {
+	query := `
+		DELETE FROM auth_sessions
+		WHERE expires_at < $1 OR revoked_at < $1
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query, params.BeforeDate)
+	if err != nil {
+		return domainauth.DeleteExpiredSessionsResult{}, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.DeleteExpiredSessionsResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.DeleteExpiredSessionsResult{
+		DeletedCount: rowsAffected,
+	}, nil
+}
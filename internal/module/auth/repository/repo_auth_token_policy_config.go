@@ -0,0 +1,22 @@
+package authrepository
+
+import (
+	"context"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+)
+
+func (r *tokenPolicyRepository) GetTokenPolicy(ctx context.Context, params domainauth.GetTokenPolicyParams) (domainauth.GetTokenPolicyResult, error) {
+	lifetime := r.tokenPolicy.Lifetime(string(params.Role))
+
+	return domainauth.GetTokenPolicyResult{
+		TokenPolicy: domainauth.TokenPolicy{
+			AccessTokenTTL:         lifetime.AccessTokenTTL,
+			RefreshTokenTTL:        lifetime.RefreshTokenTTL,
+			SessionAbsoluteTimeout: lifetime.SessionAbsoluteTimeout,
+			SessionIdleTimeout:     lifetime.SessionIdleTimeout,
+		},
+		ExpiredTokenGrace:     r.tokenPolicy.ExpiredTokenGrace(),
+		ImpersonationTokenTTL: r.tokenPolicy.ImpersonationTokenTTL(),
+	}, nil
+}
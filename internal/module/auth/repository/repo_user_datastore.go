@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
 
@@ -19,9 +20,10 @@ func (r *repository) GetDetailUser(ctx context.Context, filters domainauth.GetDe
 		"name",
 		"role",
 		"status",
+		"password_change_required",
 		"created_at",
 		"updated_at",
-	).From("users")
+	).From("users").Where("deleted_at IS NULL")
 
 	if filters.UserID != nil {
 		sq = sq.Where("id = ?", *filters.UserID)
@@ -46,6 +48,7 @@ func (r *repository) GetDetailUser(ctx context.Context, filters domainauth.GetDe
 		&result.Name,
 		&result.Role,
 		&result.Status,
+		&result.PasswordChangeRequired,
 		&result.CreatedAt,
 		&result.UpdatedAt,
 	)
@@ -58,3 +61,30 @@ func (r *repository) GetDetailUser(ctx context.Context, filters domainauth.GetDe
 
 	return result, nil
 }
+
+func (r *repository) UpdatePasswordHash(ctx context.Context, params domainauth.UpdatePasswordHashParams) (domainauth.UpdatePasswordHashResult, error) {
+	query := `
+		UPDATE users
+		SET password_hash = $1, updated_at = $2
+		WHERE id = $3 AND password_hash = $4 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.NewPasswordHash,
+		time.Now().UTC(),
+		params.UserID,
+		params.OldPasswordHash,
+	)
+	if err != nil {
+		return domainauth.UpdatePasswordHashResult{}, fmt.Errorf("failed to update password hash: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainauth.UpdatePasswordHashResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainauth.UpdatePasswordHashResult{
+		Success: rowsAffected > 0,
+	}, nil
+}
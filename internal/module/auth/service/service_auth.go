@@ -6,66 +6,259 @@ import (
 	"encoding/base64"
 	"errors"
 	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
 	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
 
 	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
 	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
-	"golang.org/x/crypto/bcrypt"
 )
 
+var errInvalidCredentials = apperror.BadRequest("invalid email or password")
+
 type service struct {
-	authRepo domainauth.AuthRepositoryDatastore
-	userRepo domainauth.UserRepositoryDatastore
+	authRepo         domainauth.AuthRepositoryDatastore
+	jwtRepo          domainauth.AuthRepositoryJwt
+	userRepo         domainauth.UserRepositoryDatastore
+	loginAttemptRepo domainauth.AuthRepositoryLoginAttempt
+	mfaRepo          domainauth.AuthRepositoryMfa
+	tokenPolicyRepo  domainauth.AuthRepositoryTokenPolicy
+	identityRepo     domainauth.AuthRepositoryIdentity
+	oidcRepo         domainauth.AuthRepositoryOidc
+	oauthClientRepo  domainauth.AuthRepositoryOauthClient
+	auditLogRepo     domainauth.AuthRepositoryAuditLog
+	magicLinkRepo    domainauth.AuthRepositoryMagicLink
+	notifierRepo     domainauth.AuthRepositoryNotifier
+	dpopReplayRepo   domainauth.AuthRepositoryDpopReplay
+	rolePolicyRepo   domainauth.AuthRepositoryRolePolicy
+	passwordHasher   sharedkernel.PasswordHasher
+	lockoutPolicy    domainauth.LoginLockoutPolicy
+	mfaPolicy        domainauth.MfaPolicy
+	magicLinkPolicy  domainauth.MagicLinkPolicy
+	dpopPolicy       domainauth.DpopPolicy
+	sessionLimit     domainauth.SessionLimitPolicy
 }
 
 func NewService(
 	authRepo domainauth.AuthRepositoryDatastore,
+	jwtRepo domainauth.AuthRepositoryJwt,
 	userRepo domainauth.UserRepositoryDatastore,
+	loginAttemptRepo domainauth.AuthRepositoryLoginAttempt,
+	mfaRepo domainauth.AuthRepositoryMfa,
+	tokenPolicyRepo domainauth.AuthRepositoryTokenPolicy,
+	identityRepo domainauth.AuthRepositoryIdentity,
+	oidcRepo domainauth.AuthRepositoryOidc,
+	oauthClientRepo domainauth.AuthRepositoryOauthClient,
+	auditLogRepo domainauth.AuthRepositoryAuditLog,
+	magicLinkRepo domainauth.AuthRepositoryMagicLink,
+	notifierRepo domainauth.AuthRepositoryNotifier,
+	dpopReplayRepo domainauth.AuthRepositoryDpopReplay,
+	rolePolicyRepo domainauth.AuthRepositoryRolePolicy,
+	passwordHasher sharedkernel.PasswordHasher,
+	lockoutPolicy domainauth.LoginLockoutPolicy,
+	mfaPolicy domainauth.MfaPolicy,
+	magicLinkPolicy domainauth.MagicLinkPolicy,
+	dpopPolicy domainauth.DpopPolicy,
+	sessionLimit domainauth.SessionLimitPolicy,
 ) *service {
 	return &service{
-		authRepo: authRepo,
-		userRepo: userRepo,
+		authRepo:         authRepo,
+		jwtRepo:          jwtRepo,
+		userRepo:         userRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		mfaRepo:          mfaRepo,
+		tokenPolicyRepo:  tokenPolicyRepo,
+		identityRepo:     identityRepo,
+		oidcRepo:         oidcRepo,
+		oauthClientRepo:  oauthClientRepo,
+		auditLogRepo:     auditLogRepo,
+		magicLinkRepo:    magicLinkRepo,
+		notifierRepo:     notifierRepo,
+		dpopReplayRepo:   dpopReplayRepo,
+		rolePolicyRepo:   rolePolicyRepo,
+		passwordHasher:   passwordHasher,
+		lockoutPolicy:    lockoutPolicy,
+		mfaPolicy:        mfaPolicy,
+		magicLinkPolicy:  magicLinkPolicy,
+		dpopPolicy:       dpopPolicy,
+		sessionLimit:     sessionLimit,
 	}
 }
 
 func (s *service) Login(ctx context.Context, input domainauth.LoginInput) (domainauth.LoginOutput, error) {
+	dpopJkt, err := s.dpopJkt(ctx, input.Dpop)
+	if err != nil {
+		return domainauth.LoginOutput{}, err
+	}
+
+	attemptKeys := loginAttemptKeys(input)
+	if err := s.checkLoginLockout(ctx, attemptKeys); err != nil {
+		s.auditLoginFailure(ctx, sharedkernel.AuditActionLogin, "", input.Email, auditReasonLockedOut, err)
+		return domainauth.LoginOutput{}, err
+	}
+
 	user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
 		Email: &input.Email,
 	})
 	if err != nil {
-		return domainauth.LoginOutput{}, apperror.BadRequest("invalid email or password")
+		if errors.Is(err, databases.ErrNoRowFound) {
+			err = s.recordLoginFailure(ctx, attemptKeys, errInvalidCredentials)
+			s.auditLoginFailure(ctx, sharedkernel.AuditActionLogin, "", input.Email, auditReasonUnknownEmail, err)
+			return domainauth.LoginOutput{}, err
+		}
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
 	}
 
 	if err = user.Status.CanLogin(); err != nil {
+		s.auditLoginFailure(ctx, sharedkernel.AuditActionLogin, user.ID, "", "user_"+string(user.Status), err)
 		return domainauth.LoginOutput{}, apperror.BadRequest(err.Error())
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password))
+	match, needsRehash, err := s.passwordHasher.Verify(input.Password, user.PasswordHash)
 	if err != nil {
-		return domainauth.LoginOutput{}, apperror.BadRequest("invalid email or password")
+		slog.WarnContext(ctx, "failed to verify password hash", "user_id", user.ID, "error", err)
+	}
+	if !match {
+		err = s.recordLoginFailure(ctx, attemptKeys, errInvalidCredentials)
+		s.auditLoginFailure(ctx, sharedkernel.AuditActionLogin, user.ID, "", auditReasonInvalidPassword, err)
+		return domainauth.LoginOutput{}, err
+	}
+	if needsRehash {
+		s.rehashPassword(ctx, user, input.Password)
 	}
 
-	accessToken, err := s.generateToken()
+	mfaRequired, err := s.isMfaRequired(ctx, user.ID)
 	if err != nil {
 		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
 	}
+	if mfaRequired {
+		// the password step is recorded, the login itself is recorded by LoginMfa
+		s.audit(ctx, sharedkernel.AuditEntry{
+			Action:       sharedkernel.AuditActionLogin,
+			Outcome:      sharedkernel.AuditOutcomeSuccess,
+			ActorUserID:  user.ID,
+			TargetUserID: user.ID,
+			Details:      map[string]string{"mfa_required": "true"},
+		})
+
+		// the email counter stays until the second factor succeeds, otherwise
+		// every correct password would reset the count of wrong codes
+		return s.createMfaChallenge(ctx, user.ID)
+	}
+
+	// only the email counter is cleared, a client IP that keeps failing on
+	// other accounts must not be unlocked by one valid login
+	_, err = s.loginAttemptRepo.DeleteLoginAttempt(ctx, domainauth.DeleteLoginAttemptParams(attemptKeys[0]))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to clear login attempts", "error", err)
+	}
+
+	output, err := s.issueTokens(ctx, user, sessionClient{
+		UserAgent: input.UserAgent,
+		IPAddress: input.IPAddress,
+		Platform:  input.Platform,
+		DpopJkt:   dpopJkt,
+	})
+	if err != nil {
+		if errors.Is(err, errSessionLimitReached) {
+			s.auditLoginFailure(ctx, sharedkernel.AuditActionLogin, user.ID, "", auditReasonSessionLimit, err)
+		}
+		return domainauth.LoginOutput{}, err
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionLogin,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  user.ID,
+		TargetUserID: user.ID,
+	})
 
+	return output, nil
+}
+
+// rehashPassword stores a hash of password with the current algorithm and
+// parameters. Failing to do so only delays the upgrade to the next login.
+func (s *service) rehashPassword(ctx context.Context, user domainauth.GetDetailUserResult, password string) {
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+
+	_, err = s.userRepo.UpdatePasswordHash(ctx, domainauth.UpdatePasswordHashParams{
+		UserID:          user.ID,
+		OldPasswordHash: user.PasswordHash,
+		NewPasswordHash: passwordHash,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to save rehashed password", "user_id", user.ID, "error", err)
+	}
+}
+
+// issueTokens starts a new session and token family for user.
+func (s *service) issueTokens(ctx context.Context, user domainauth.GetDetailUserResult, client sessionClient) (domainauth.LoginOutput, error) {
 	refreshToken, err := s.generateToken()
 	if err != nil {
 		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
 	}
 
-	accessTokenExpiry := time.Now().UTC().Add(15 * time.Minute)
-	refreshTokenExpiry := time.Now().UTC().Add(7 * 24 * time.Hour)
+	familyID, err := s.generateToken()
+	if err != nil {
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	tokenPolicy, err := s.getTokenPolicy(ctx, user.Role)
+	if err != nil {
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	now := time.Now().UTC()
+	refreshTokenExpiry := tokenPolicy.TokenPolicy.RefreshTokenExpiry(now, now)
+	accessTokenExpiry := tokenPolicy.TokenPolicy.AccessTokenExpiry(refreshTokenExpiry, now)
+
+	platform := sessionPlatform(client.Platform)
+	session, err := s.authRepo.CreateSession(ctx, domainauth.CreateSessionParams{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		UserAgent: sessionUserAgent(client.UserAgent),
+		IPAddress: client.IPAddress,
+		Platform:  platform,
+		CreatedAt: now,
+		ExpiresAt: refreshTokenExpiry,
+		Limit:     s.sessionLimit.Limit(platform),
+	})
+	if err != nil {
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+	if session.LimitReached {
+		return domainauth.LoginOutput{}, errSessionLimitReached
+	}
+	s.auditEvictedSessions(ctx, user.ID, session.EvictedSessionIDs)
+
+	accessToken, err := s.jwtRepo.CreateAccessToken(ctx, domainauth.CreateAccessTokenParams{
+		UserID:    user.ID,
+		SessionID: session.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		IssuedAt:  now,
+		ExpiresAt: accessTokenExpiry,
+		DpopJkt:   client.DpopJkt,
+	})
+	if err != nil {
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
 
 	_, err = s.authRepo.CreateToken(ctx, domainauth.CreateTokenParams{
 		UserID:       user.ID,
-		Token:        accessToken,
+		Token:        accessToken.Token,
 		TokenType:    domainauth.TokenTypeAccess,
 		ExpiresAt:    accessTokenExpiry,
 		RefreshToken: &refreshToken,
+		FamilyID:     familyID,
+		DpopJkt:      dpopBinding(client.DpopJkt),
 	})
 	if err != nil {
 		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
@@ -76,16 +269,19 @@ func (s *service) Login(ctx context.Context, input domainauth.LoginInput) (domai
 		Token:     refreshToken,
 		TokenType: domainauth.TokenTypeRefresh,
 		ExpiresAt: refreshTokenExpiry,
+		FamilyID:  familyID,
+		DpopJkt:   dpopBinding(client.DpopJkt),
 	})
 	if err != nil {
 		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
 	}
 
 	return domainauth.LoginOutput{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    int64(15 * 60),
-		TokenType:    "Bearer",
+		AccessToken:            accessToken.Token,
+		RefreshToken:           refreshToken,
+		ExpiresIn:              int64(accessTokenExpiry.Sub(now).Seconds()),
+		TokenType:              tokenType(client.DpopJkt),
+		PasswordChangeRequired: user.PasswordChangeRequired,
 	}, nil
 }
 
@@ -104,6 +300,10 @@ func (s *service) RefreshToken(ctx context.Context, input domainauth.RefreshToke
 		return domainauth.RefreshTokenOutput{}, apperror.BadRequest("invalid token type")
 	}
 
+	if tokenData.Status == domainauth.TokenStatusRotated {
+		return domainauth.RefreshTokenOutput{}, s.revokeReusedTokenFamily(ctx, tokenData)
+	}
+
 	if tokenData.Status != domainauth.TokenStatusActive {
 		return domainauth.RefreshTokenOutput{}, apperror.BadRequest("token is not active")
 	}
@@ -112,30 +312,94 @@ func (s *service) RefreshToken(ctx context.Context, input domainauth.RefreshToke
 		return domainauth.RefreshTokenOutput{}, apperror.BadRequest("refresh token expired")
 	}
 
-	newAccessToken, err := s.generateToken()
+	// a bound refresh token is useless without the private key, the new
+	// tokens keep the binding
+	dpopJkt, err := s.dpopJkt(ctx, input.Dpop)
+	if err != nil {
+		return domainauth.RefreshTokenOutput{}, err
+	}
+	if !sameDpopKey(tokenData.DpopJkt, dpopJkt) {
+		return domainauth.RefreshTokenOutput{}, errDpopKeyMismatch
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+		UserID: &tokenData.UserID,
+	})
 	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.RefreshTokenOutput{}, apperror.BadRequest("invalid refresh token")
+		}
 		return domainauth.RefreshTokenOutput{}, apperror.StdUnknown(err)
 	}
 
+	if err = user.Status.CanLogin(); err != nil {
+		return domainauth.RefreshTokenOutput{}, apperror.BadRequest(err.Error())
+	}
+
+	session, err := s.authRepo.GetDetailSession(ctx, domainauth.GetDetailSessionFilters{
+		FamilyID: &tokenData.FamilyID,
+		UserID:   &tokenData.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.RefreshTokenOutput{}, apperror.BadRequest("invalid refresh token")
+		}
+		return domainauth.RefreshTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if session.RevokedAt != nil {
+		return domainauth.RefreshTokenOutput{}, apperror.BadRequest("token is not active")
+	}
+
+	tokenPolicy, err := s.getTokenPolicy(ctx, user.Role)
+	if err != nil {
+		return domainauth.RefreshTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if tokenPolicy.TokenPolicy.SessionExpired(session.Session, time.Now().UTC()) {
+		return domainauth.RefreshTokenOutput{}, apperror.BadRequest("session expired")
+	}
+
 	newRefreshToken, err := s.generateToken()
 	if err != nil {
 		return domainauth.RefreshTokenOutput{}, apperror.StdUnknown(err)
 	}
 
-	_, _ = s.authRepo.RevokeToken(ctx, domainauth.RevokeTokenParams{
-		Token:  input.RefreshToken,
-		UserID: tokenData.UserID,
+	rotated, err := s.authRepo.RotateToken(ctx, domainauth.RotateTokenParams{
+		TokenID: tokenData.ID,
+		UserID:  tokenData.UserID,
 	})
+	if err != nil {
+		return domainauth.RefreshTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if !rotated.Success {
+		// another request rotated this token between our read and update
+		return domainauth.RefreshTokenOutput{}, s.revokeReusedTokenFamily(ctx, tokenData)
+	}
 
-	accessTokenExpiry := time.Now().UTC().Add(15 * time.Minute)
-	refreshTokenExpiry := time.Now().UTC().Add(7 * 24 * time.Hour)
+	now := time.Now().UTC()
+	refreshTokenExpiry := tokenPolicy.TokenPolicy.RefreshTokenExpiry(session.CreatedAt, now)
+	accessTokenExpiry := tokenPolicy.TokenPolicy.AccessTokenExpiry(refreshTokenExpiry, now)
+
+	newAccessToken, err := s.jwtRepo.CreateAccessToken(ctx, domainauth.CreateAccessTokenParams{
+		UserID:    user.ID,
+		SessionID: session.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		IssuedAt:  now,
+		ExpiresAt: accessTokenExpiry,
+		DpopJkt:   dpopJkt,
+	})
+	if err != nil {
+		return domainauth.RefreshTokenOutput{}, apperror.StdUnknown(err)
+	}
 
 	_, err = s.authRepo.CreateToken(ctx, domainauth.CreateTokenParams{
 		UserID:       tokenData.UserID,
-		Token:        newAccessToken,
+		Token:        newAccessToken.Token,
 		TokenType:    domainauth.TokenTypeAccess,
 		ExpiresAt:    accessTokenExpiry,
 		RefreshToken: &newRefreshToken,
+		FamilyID:     tokenData.FamilyID,
+		DpopJkt:      tokenData.DpopJkt,
 	})
 	if err != nil {
 		return domainauth.RefreshTokenOutput{}, apperror.StdUnknown(err)
@@ -146,16 +410,37 @@ func (s *service) RefreshToken(ctx context.Context, input domainauth.RefreshToke
 		Token:     newRefreshToken,
 		TokenType: domainauth.TokenTypeRefresh,
 		ExpiresAt: refreshTokenExpiry,
+		FamilyID:  tokenData.FamilyID,
+		ParentID:  &tokenData.ID,
+		DpopJkt:   tokenData.DpopJkt,
 	})
 	if err != nil {
 		return domainauth.RefreshTokenOutput{}, apperror.StdUnknown(err)
 	}
 
+	_, err = s.authRepo.TouchSession(ctx, domainauth.TouchSessionParams{
+		SessionID:  session.ID,
+		IPAddress:  input.IPAddress,
+		LastUsedAt: now,
+		ExpiresAt:  refreshTokenExpiry,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to touch session", "error", err, "session_id", session.ID)
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionTokenRefresh,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  user.ID,
+		TargetUserID: user.ID,
+		Details:      map[string]string{"session_id": session.ID},
+	})
+
 	return domainauth.RefreshTokenOutput{
-		AccessToken:  newAccessToken,
+		AccessToken:  newAccessToken.Token,
 		RefreshToken: newRefreshToken,
-		ExpiresIn:    int64(15 * 60),
-		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessTokenExpiry.Sub(now).Seconds()),
+		TokenType:    tokenType(dpopJkt),
 	}, nil
 }
 
@@ -169,9 +454,11 @@ func (s *service) Logout(ctx context.Context, input domainauth.LogoutInput) (dom
 		}, nil
 	}
 
-	_, err = s.authRepo.RevokeToken(ctx, domainauth.RevokeTokenParams{
-		Token:  input.AccessToken,
-		UserID: tokenData.UserID,
+	// the refresh token belongs to the same family, ending the session
+	// revokes it as well
+	_, err = s.authRepo.RevokeTokenFamily(ctx, domainauth.RevokeTokenFamilyParams{
+		FamilyID: tokenData.FamilyID,
+		UserID:   tokenData.UserID,
 	})
 	if err != nil {
 		return domainauth.LogoutOutput{
@@ -180,12 +467,12 @@ func (s *service) Logout(ctx context.Context, input domainauth.LogoutInput) (dom
 		}, nil
 	}
 
-	if input.RefreshToken != "" {
-		_, _ = s.authRepo.RevokeToken(ctx, domainauth.RevokeTokenParams{
-			Token:  input.RefreshToken,
-			UserID: tokenData.UserID,
-		})
-	}
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionLogout,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  tokenData.UserID,
+		TargetUserID: tokenData.UserID,
+	})
 
 	return domainauth.LogoutOutput{
 		Success: true,
@@ -193,73 +480,104 @@ func (s *service) Logout(ctx context.Context, input domainauth.LogoutInput) (dom
 	}, nil
 }
 
+// ValidateToken accepts a well-signed access token only while its row in
+// auth_tokens is active, so logout and session revocation apply at once. A
+// DPoP-bound token is only accepted with a proof of its key.
 func (s *service) ValidateToken(ctx context.Context, input domainauth.ValidateTokenInput) (domainauth.ValidateTokenOutput, error) {
-	tokenData, err := s.authRepo.GetDetailToken(ctx, domainauth.GetDetailTokenFilters{
-		Token: &input.Token,
+	return s.validateToken(ctx, input, true)
+}
+
+// validateToken skips the DPoP binding when checkDpop is false, for callers
+// that check the proof themselves against cnf.jkt of the payload.
+func (s *service) validateToken(ctx context.Context, input domainauth.ValidateTokenInput, checkDpop bool) (domainauth.ValidateTokenOutput, error) {
+	if strings.HasPrefix(input.Token, domainauth.ApiKeyPrefix) {
+		if input.Dpop != nil {
+			return domainauth.ValidateTokenOutput{Valid: false}, nil
+		}
+		return s.validateApiKey(ctx, input)
+	}
+
+	result, err := s.jwtRepo.ParseAccessToken(ctx, domainauth.ParseAccessTokenParams{
+		Token: input.Token,
 	})
 	if err != nil {
 		return domainauth.ValidateTokenOutput{Valid: false}, nil
 	}
 
-	if tokenData.Status != domainauth.TokenStatusActive {
+	tokenData, err := s.authRepo.GetDetailToken(ctx, domainauth.GetDetailTokenFilters{
+		Token: &input.Token,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.ValidateTokenOutput{Valid: false}, nil
+		}
+		return domainauth.ValidateTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if tokenData.Status != domainauth.TokenStatusActive || result.Payload.SessionID == "" {
 		return domainauth.ValidateTokenOutput{Valid: false}, nil
 	}
 
-	if time.Now().UTC().After(tokenData.ExpiresAt) {
-		return domainauth.ValidateTokenOutput{Valid: false}, nil
+	if checkDpop {
+		ok, err := s.checkDpopBinding(ctx, tokenData.DpopJkt, input)
+		if err != nil {
+			return domainauth.ValidateTokenOutput{}, err
+		}
+		if !ok {
+			return domainauth.ValidateTokenOutput{Valid: false}, nil
+		}
 	}
 
+	// the owner may have been suspended or deactivated since the token was issued
 	user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
-		UserID: &tokenData.UserID,
+		UserID: &result.Payload.UserID,
 	})
 	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.ValidateTokenOutput{Valid: false}, nil
+		}
+		return domainauth.ValidateTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if user.Status.CanLogin() != nil {
 		return domainauth.ValidateTokenOutput{Valid: false}, nil
 	}
+	result.Payload.PasswordChangeRequired = user.PasswordChangeRequired
 
-	payload := &domainauth.TokenPayload{
-		UserID:    user.ID,
-		Email:     user.Email,
-		Role:      domainauth.UserRole(user.Role),
-		TokenType: tokenData.TokenType,
-		IssuedAt:  tokenData.CreatedAt,
-		ExpiresAt: tokenData.ExpiresAt,
+	if result.Payload.Impersonated() {
+		ok, err := s.checkImpersonator(ctx, result.Payload.ImpersonatorID)
+		if err != nil {
+			return domainauth.ValidateTokenOutput{}, apperror.StdUnknown(err)
+		}
+		if !ok {
+			return domainauth.ValidateTokenOutput{Valid: false}, nil
+		}
+		s.auditImpersonatedRequest(ctx, result.Payload, input.Operation)
 	}
 
 	return domainauth.ValidateTokenOutput{
 		Valid:     true,
-		Payload:   payload,
-		ExpiresAt: tokenData.ExpiresAt,
+		Payload:   &result.Payload,
+		ExpiresAt: result.Payload.ExpiresAt,
 	}, nil
 }
 
-func (s *service) RevokeToken(ctx context.Context, input domainauth.RevokeTokenInput) (domainauth.RevokeTokenOutput, error) {
-	tokenData, err := s.authRepo.GetDetailToken(ctx, domainauth.GetDetailTokenFilters{
-		Token: &input.Token,
-	})
+func (s *service) GetJwks(ctx context.Context) (domainauth.GetJwksOutput, error) {
+	result, err := s.jwtRepo.GetJwks(ctx)
 	if err != nil {
-		return domainauth.RevokeTokenOutput{Success: false, Message: "Token not found"}, nil
+		return domainauth.GetJwksOutput{}, apperror.StdUnknown(err)
 	}
 
-	result, err := s.authRepo.RevokeToken(ctx, domainauth.RevokeTokenParams{
-		Token:  input.Token,
-		UserID: tokenData.UserID,
-	})
-	if err != nil {
-		return domainauth.RevokeTokenOutput{Success: false, Message: "Failed to revoke token"}, nil
-	}
-
-	if !result.Success {
-		return domainauth.RevokeTokenOutput{Success: false, Message: "Token already revoked"}, nil
-	}
-
-	return domainauth.RevokeTokenOutput{
-		Success: true,
-		Message: "Token revoked successfully",
+	return domainauth.GetJwksOutput{
+		Keys: result.Keys,
 	}, nil
 }
 
 func (s *service) WorkerDeleteExpiredTokens(ctx context.Context) {
-	beforeDate := time.Now().UTC().Add(-24 * time.Hour)
+	tokenPolicy, err := s.getTokenPolicy(ctx, "")
+	if err != nil {
+		slog.Error("Failed to get token policy", "error", err)
+		return
+	}
+	beforeDate := time.Now().UTC().Add(-tokenPolicy.ExpiredTokenGrace)
 
 	result, err := s.authRepo.DeleteExpiredTokens(ctx, domainauth.DeleteExpiredTokensParams{
 		BeforeDate: beforeDate,
@@ -283,6 +601,98 @@ func (s *service) WorkerDeleteExpiredTokens(ctx context.Context) {
 			"before_date", beforeDate,
 		)
 	}
+
+	sessions, err := s.authRepo.DeleteExpiredSessions(ctx, domainauth.DeleteExpiredSessionsParams{
+		BeforeDate: beforeDate,
+	})
+	if err != nil {
+		slog.Error("Failed to cleanup expired sessions",
+			"error", err,
+			"before_date", beforeDate,
+		)
+		return
+	}
+
+	slog.Info("Expired sessions cleaned up",
+		"deleted_count", sessions.DeletedCount,
+		"before_date", beforeDate,
+	)
+
+	oidcStates, err := s.identityRepo.DeleteExpiredOidcStates(ctx, domainauth.DeleteExpiredOidcStatesParams{
+		BeforeDate: time.Now().UTC(),
+	})
+	if err != nil {
+		slog.Error("Failed to cleanup expired oidc states", "error", err)
+		return
+	}
+
+	slog.Info("Expired oidc states cleaned up",
+		"deleted_count", oidcStates.DeletedCount,
+	)
+
+	magicLinks, err := s.magicLinkRepo.DeleteExpiredMagicLinks(ctx, domainauth.DeleteExpiredMagicLinksParams{
+		BeforeDate: beforeDate,
+	})
+	if err != nil {
+		slog.Error("Failed to cleanup expired magic links", "error", err)
+		return
+	}
+
+	slog.Info("Expired magic links cleaned up",
+		"deleted_count", magicLinks.DeletedCount,
+	)
+
+	dpopJtis, err := s.dpopReplayRepo.DeleteExpiredDpopJtis(ctx, domainauth.DeleteExpiredDpopJtisParams{
+		BeforeDate: time.Now().UTC(),
+	})
+	if err != nil {
+		slog.Error("Failed to cleanup expired dpop jtis", "error", err)
+		return
+	}
+
+	slog.Info("Expired dpop jtis cleaned up",
+		"deleted_count", dpopJtis.DeletedCount,
+	)
+}
+
+// revokeReusedTokenFamily handles a refresh token that was presented after it
+// had already been rotated. Following the OAuth 2.0 Security BCP, the token is
+// assumed stolen and every token issued from the same login is revoked.
+func (s *service) revokeReusedTokenFamily(ctx context.Context, tokenData domainauth.GetDetailTokenResult) error {
+	slog.WarnContext(ctx, "Refresh token reuse detected, revoking token family",
+		"user_id", tokenData.UserID,
+		"token_id", tokenData.ID,
+		"family_id", tokenData.FamilyID,
+	)
+
+	result, err := s.authRepo.RevokeTokenFamily(ctx, domainauth.RevokeTokenFamilyParams{
+		FamilyID: tokenData.FamilyID,
+		UserID:   tokenData.UserID,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to revoke token family",
+			"error", err,
+			"user_id", tokenData.UserID,
+			"family_id", tokenData.FamilyID,
+		)
+		return apperror.StdUnknown(err)
+	}
+
+	slog.WarnContext(ctx, "Token family revoked",
+		"user_id", tokenData.UserID,
+		"family_id", tokenData.FamilyID,
+		"revoked_count", result.RevokedCount,
+	)
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionTokenRefresh,
+		Outcome:      sharedkernel.AuditOutcomeFailure,
+		Reason:       auditReasonRefreshTokenReused,
+		TargetUserID: tokenData.UserID,
+		Details:      map[string]string{"revoked_count": strconv.FormatInt(result.RevokedCount, 10)},
+	})
+
+	return apperror.Unauthorized("refresh token has already been used")
 }
 
 func (s *service) generateToken() (string, error) {
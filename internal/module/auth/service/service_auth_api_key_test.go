@@ -0,0 +1,243 @@
+package authservice_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeApiKeyRepo keeps API keys in memory, by id, next to the plaintext key
+// the real repository only stores hashed.
+type fakeApiKeyRepo struct {
+	domainauth.AuthRepositoryDatastore
+	apiKeys map[string]*domainauth.ApiKey
+	keys    map[string]string // api key id by key
+	touched int
+}
+
+func newFakeApiKeyRepo() *fakeApiKeyRepo {
+	return &fakeApiKeyRepo{
+		apiKeys: map[string]*domainauth.ApiKey{},
+		keys:    map[string]string{},
+	}
+}
+
+func (f *fakeApiKeyRepo) CreateApiKey(ctx context.Context, params domainauth.CreateApiKeyParams) (domainauth.CreateApiKeyResult, error) {
+	id := strconv.Itoa(len(f.apiKeys) + 1)
+	f.apiKeys[id] = &domainauth.ApiKey{
+		ID:         id,
+		UserID:     params.UserID,
+		Name:       params.Name,
+		Prefix:     params.Prefix,
+		Scopes:     params.Scopes,
+		AllowedIPs: params.AllowedIPs,
+		ExpiresAt:  params.ExpiresAt,
+		CreatedAt:  params.CreatedAt,
+	}
+	f.keys[params.Key] = id
+	return domainauth.CreateApiKeyResult{ID: id}, nil
+}
+
+func (f *fakeApiKeyRepo) GetDetailApiKey(ctx context.Context, filters domainauth.GetDetailApiKeyFilters) (domainauth.GetDetailApiKeyResult, error) {
+	id := ""
+	switch {
+	case filters.Key != nil:
+		id = f.keys[*filters.Key]
+	case filters.ApiKeyID != nil:
+		id = *filters.ApiKeyID
+	}
+	apiKey, ok := f.apiKeys[id]
+	if !ok || (filters.UserID != nil && apiKey.UserID != *filters.UserID) {
+		return domainauth.GetDetailApiKeyResult{}, databases.ErrNoRowFound
+	}
+	return domainauth.GetDetailApiKeyResult{ApiKey: *apiKey}, nil
+}
+
+func (f *fakeApiKeyRepo) RotateApiKey(ctx context.Context, params domainauth.RotateApiKeyParams) (domainauth.RotateApiKeyResult, error) {
+	apiKey, ok := f.apiKeys[params.ApiKeyID]
+	if !ok || apiKey.UserID != params.UserID || apiKey.RevokedAt != nil {
+		return domainauth.RotateApiKeyResult{Success: false}, nil
+	}
+	for key, id := range f.keys {
+		if id == params.ApiKeyID {
+			delete(f.keys, key)
+		}
+	}
+	f.keys[params.Key] = params.ApiKeyID
+	apiKey.Prefix = params.Prefix
+	return domainauth.RotateApiKeyResult{Success: true}, nil
+}
+
+func (f *fakeApiKeyRepo) RevokeApiKey(ctx context.Context, params domainauth.RevokeApiKeyParams) (domainauth.RevokeApiKeyResult, error) {
+	apiKey, ok := f.apiKeys[params.ApiKeyID]
+	if !ok || apiKey.UserID != params.UserID || apiKey.RevokedAt != nil {
+		return domainauth.RevokeApiKeyResult{Success: false}, nil
+	}
+	apiKey.RevokedAt = &params.RevokedAt
+	return domainauth.RevokeApiKeyResult{Success: true}, nil
+}
+
+func (f *fakeApiKeyRepo) TouchApiKey(ctx context.Context, params domainauth.TouchApiKeyParams) (domainauth.TouchApiKeyResult, error) {
+	f.touched++
+	f.apiKeys[params.ApiKeyID].LastUsedAt = &params.LastUsedAt
+	return domainauth.TouchApiKeyResult{Success: true}, nil
+}
+
+func TestService_ApiKeys(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(status sharedkernel.UserStatus) (*fakeApiKeyRepo, domainauth.AuthService) {
+		repo := newFakeApiKeyRepo()
+		userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+			ID:     "1",
+			Email:  "service@example.com",
+			Role:   domainauth.UserRoleAdmin,
+			Status: status,
+		}}
+		svc := newTestService(t, withAuthRepo(repo), withUserRepo(userRepo))
+		return repo, svc
+	}
+	validate := func(t *testing.T, svc domainauth.AuthService, key string, ip string) domainauth.ValidateTokenOutput {
+		t.Helper()
+		output, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: key, IPAddress: ip})
+		require.NoError(t, err)
+		return output
+	}
+
+	t.Run("key authenticates as its owner with its scopes", func(t *testing.T) {
+		repo, svc := setup(sharedkernel.UserStatusActive)
+
+		created, err := svc.CreateApiKey(ctx, domainauth.CreateApiKeyInput{
+			UserID: "1",
+			Name:   " billing ",
+			Scopes: []string{"users:list", "users:list"},
+		})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(created.Key, created.ApiKey.Prefix+"_"))
+		assert.True(t, strings.HasPrefix(created.ApiKey.Prefix, domainauth.ApiKeyPrefix))
+		assert.Equal(t, "billing", created.ApiKey.Name)
+		assert.Equal(t, []string{"users:list"}, created.ApiKey.Scopes)
+
+		output := validate(t, svc, created.Key, "198.51.100.1")
+		require.True(t, output.Valid)
+		assert.Equal(t, "1", output.Payload.UserID)
+		assert.Equal(t, domainauth.TokenTypeApiKey, output.Payload.TokenType)
+		assert.Equal(t, created.ApiKey.ID, output.Payload.ApiKeyID)
+		assert.True(t, output.Payload.HasScopes("users:list"))
+		assert.False(t, output.Payload.HasScopes("users:list", "users:update_status"))
+
+		// last_used_at is written once per interval, not on every request
+		validate(t, svc, created.Key, "198.51.100.1")
+		assert.Equal(t, 1, repo.touched)
+		assert.NotNil(t, repo.apiKeys[created.ApiKey.ID].LastUsedAt)
+
+		assert.False(t, validate(t, svc, created.Key+"x", "198.51.100.1").Valid)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, svc := setup(sharedkernel.UserStatusActive)
+		past := time.Now().Add(-time.Hour)
+
+		for name, input := range map[string]domainauth.CreateApiKeyInput{
+			"empty name": {UserID: "1", Name: " "},
+			"scope":      {UserID: "1", Name: "ci", Scopes: []string{"users"}},
+			"allowed ip": {UserID: "1", Name: "ci", AllowedIPs: []string{"10.0.0.300"}},
+			"expiry":     {UserID: "1", Name: "ci", ExpiresAt: &past},
+		} {
+			_, err := svc.CreateApiKey(ctx, input)
+			assert.True(t, apperror.IsBadRequest(err), "%s: expected bad request, got %v", name, err)
+		}
+	})
+
+	t.Run("ip allowlist", func(t *testing.T) {
+		_, svc := setup(sharedkernel.UserStatusActive)
+
+		created, err := svc.CreateApiKey(ctx, domainauth.CreateApiKeyInput{
+			UserID:     "1",
+			Name:       "ci",
+			AllowedIPs: []string{"10.1.2.3/16", "2001:db8::1"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.1.0.0/16", "2001:db8::1/128"}, created.ApiKey.AllowedIPs)
+
+		assert.True(t, validate(t, svc, created.Key, "10.1.200.7").Valid)
+		assert.True(t, validate(t, svc, created.Key, "::ffff:10.1.0.1").Valid)
+		assert.True(t, validate(t, svc, created.Key, "2001:db8::1").Valid)
+		assert.False(t, validate(t, svc, created.Key, "10.2.0.1").Valid)
+		assert.False(t, validate(t, svc, created.Key, "").Valid)
+	})
+
+	t.Run("rotate replaces the key", func(t *testing.T) {
+		_, svc := setup(sharedkernel.UserStatusActive)
+
+		created, err := svc.CreateApiKey(ctx, domainauth.CreateApiKeyInput{UserID: "1", Name: "ci"})
+		require.NoError(t, err)
+
+		rotated, err := svc.RotateApiKey(ctx, domainauth.RotateApiKeyInput{UserID: "1", ApiKeyID: created.ApiKey.ID})
+		require.NoError(t, err)
+		assert.Equal(t, created.ApiKey.ID, rotated.ApiKey.ID)
+		assert.NotEqual(t, created.Key, rotated.Key)
+
+		assert.False(t, validate(t, svc, created.Key, "").Valid)
+		assert.True(t, validate(t, svc, rotated.Key, "").Valid)
+
+		_, err = svc.RotateApiKey(ctx, domainauth.RotateApiKeyInput{UserID: "2", ApiKeyID: created.ApiKey.ID})
+		assert.True(t, apperror.IsNotFound(err), "another user's key, got %v", err)
+	})
+
+	t.Run("revoked and expired keys are rejected", func(t *testing.T) {
+		repo, svc := setup(sharedkernel.UserStatusActive)
+
+		revoked, err := svc.CreateApiKey(ctx, domainauth.CreateApiKeyInput{UserID: "1", Name: "revoked"})
+		require.NoError(t, err)
+		_, err = svc.RevokeApiKey(ctx, domainauth.RevokeApiKeyInput{UserID: "1", ApiKeyID: revoked.ApiKey.ID})
+		require.NoError(t, err)
+		assert.False(t, validate(t, svc, revoked.Key, "").Valid)
+
+		_, err = svc.RevokeApiKey(ctx, domainauth.RevokeApiKeyInput{UserID: "1", ApiKeyID: revoked.ApiKey.ID})
+		assert.True(t, apperror.IsNotFound(err), "expected not found, got %v", err)
+		_, err = svc.RotateApiKey(ctx, domainauth.RotateApiKeyInput{UserID: "1", ApiKeyID: revoked.ApiKey.ID})
+		assert.True(t, apperror.IsNotFound(err), "expected not found, got %v", err)
+
+		expiresAt := time.Now().Add(time.Hour)
+		expired, err := svc.CreateApiKey(ctx, domainauth.CreateApiKeyInput{UserID: "1", Name: "expired", ExpiresAt: &expiresAt})
+		require.NoError(t, err)
+		assert.True(t, validate(t, svc, expired.Key, "").Valid)
+
+		past := time.Now().Add(-time.Second)
+		repo.apiKeys[expired.ApiKey.ID].ExpiresAt = &past
+		assert.False(t, validate(t, svc, expired.Key, "").Valid)
+	})
+
+	t.Run("key of a suspended owner is rejected", func(t *testing.T) {
+		_, svc := setup(sharedkernel.UserStatusSuspended)
+
+		created, err := svc.CreateApiKey(ctx, domainauth.CreateApiKeyInput{UserID: "1", Name: "ci"})
+		require.NoError(t, err)
+		assert.False(t, validate(t, svc, created.Key, "").Valid)
+	})
+}
+
+func TestTokenPayload_HasScopes(t *testing.T) {
+	access := domainauth.TokenPayload{TokenType: domainauth.TokenTypeAccess}
+	assert.True(t, access.HasScopes("users:list"), "access tokens are limited by role only")
+
+	apiKey := domainauth.TokenPayload{TokenType: domainauth.TokenTypeApiKey, Scopes: []string{"users:list"}}
+	assert.True(t, apiKey.HasScopes("users:list"))
+	assert.False(t, apiKey.HasScopes("sessions:revoke"))
+	assert.False(t, apiKey.HasScopes(), "unscoped operations need the * scope")
+
+	apiKey.Scopes = []string{domainauth.ApiKeyScopeAll}
+	assert.True(t, apiKey.HasScopes("users:list", "sessions:revoke"))
+	assert.True(t, apiKey.HasScopes())
+}
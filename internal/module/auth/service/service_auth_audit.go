@@ -0,0 +1,91 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+)
+
+// Reasons of failed logins in the audit log. The caller only ever sees
+// errInvalidCredentials for the first two.
+const (
+	auditReasonUnknownEmail       = "unknown_email"
+	auditReasonInvalidPassword    = "invalid_password"
+	auditReasonLockedOut          = "locked_out"
+	auditReasonInvalidMfaCode     = "invalid_mfa_code"
+	auditReasonRefreshTokenReused = "refresh_token_reused"
+	auditReasonInvalidMagicLink   = "invalid_magic_link"
+	auditReasonRateLimited        = "rate_limited"
+	auditReasonSessionLimit       = "session_limit"
+)
+
+// audit writes entry to the audit log with the request meta of ctx. The
+// actor defaults to the authenticated caller of ctx, an OAuth client or API
+// key the call was made with is added to the details. In an impersonated
+// request the actor is always the admin. A failed write is logged and never
+// fails the audited action.
+func (s *service) audit(ctx context.Context, entry sharedkernel.AuditEntry) {
+	entry.Details = maps.Clone(entry.Details)
+	if entry.Details == nil {
+		entry.Details = map[string]string{}
+	}
+
+	if payload, ok := domainauth.TokenPayloadFromContext(ctx); ok {
+		if entry.ActorUserID == "" {
+			entry.ActorUserID = payload.UserID
+		}
+		if payload.ApiKeyID != "" {
+			entry.Details["caller_api_key_id"] = payload.ApiKeyID
+		}
+	}
+	if clientID, ok := domainauth.OauthClientFromContext(ctx); ok {
+		entry.Details["oauth_client_id"] = clientID
+	}
+	// whatever happens during an impersonated request is done by the admin
+	if impersonation, ok := sharedkernel.ImpersonationFromContext(ctx); ok {
+		entry.ActorUserID = impersonation.ActorUserID
+		entry.Details["impersonated_user_id"] = impersonation.UserID
+	}
+
+	entry.RequestMeta = sharedkernel.RequestMetaFromContext(ctx)
+	entry.CreatedAt = time.Now().UTC()
+
+	_, err := s.auditLogRepo.CreateAuditLog(ctx, domainauth.CreateAuditLogParams{
+		Entry: entry,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to write audit log",
+			"error", err,
+			"action", entry.Action,
+			"outcome", entry.Outcome,
+			"actor_user_id", entry.ActorUserID,
+			"target_user_id", entry.TargetUserID,
+		)
+	}
+}
+
+// auditLoginFailure records a failed login of action for userID, or for the
+// email when no user matched it. A failure that locked the caller out is
+// recorded as such.
+func (s *service) auditLoginFailure(ctx context.Context, action sharedkernel.AuditAction, userID, email, reason string, err error) {
+	var lockedErr *domainauth.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		reason = auditReasonLockedOut
+	}
+
+	entry := sharedkernel.AuditEntry{
+		Action:       action,
+		Outcome:      sharedkernel.AuditOutcomeFailure,
+		Reason:       reason,
+		TargetUserID: userID,
+	}
+	if email != "" {
+		entry.Details = map[string]string{"email": email}
+	}
+	s.audit(ctx, entry)
+}
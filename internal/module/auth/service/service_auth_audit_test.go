@@ -0,0 +1,146 @@
+package authservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type fakeAuditLogRepo struct {
+	entries []sharedkernel.AuditEntry
+}
+
+func newFakeAuditLogRepo() *fakeAuditLogRepo {
+	return &fakeAuditLogRepo{}
+}
+
+func (f *fakeAuditLogRepo) CreateAuditLog(ctx context.Context, params domainauth.CreateAuditLogParams) (domainauth.CreateAuditLogResult, error) {
+	f.entries = append(f.entries, params.Entry)
+	return domainauth.CreateAuditLogResult{ID: "1"}, nil
+}
+
+// fakeUserByEmailRepo only finds its user by the user's own email or id.
+type fakeUserByEmailRepo struct {
+	fakeUserRepo
+}
+
+func (f *fakeUserByEmailRepo) GetDetailUser(ctx context.Context, filters domainauth.GetDetailUserFilters) (domainauth.GetDetailUserResult, error) {
+	if filters.Email != nil && *filters.Email != f.user.Email {
+		return domainauth.GetDetailUserResult{}, databases.ErrNoRowFound
+	}
+	return f.user, nil
+}
+
+// last returns the newest entry of action.
+func (f *fakeAuditLogRepo) last(t *testing.T, action sharedkernel.AuditAction) sharedkernel.AuditEntry {
+	t.Helper()
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		if f.entries[i].Action == action {
+			return f.entries[i]
+		}
+	}
+	require.Failf(t, "audit entry not found", "action %s", action)
+	return sharedkernel.AuditEntry{}
+}
+
+func TestService_Audit(t *testing.T) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	meta := sharedkernel.RequestMeta{IPAddress: "198.51.100.7", UserAgent: "curl/8.0", RequestID: "req-1"}
+	ctx := sharedkernel.WithRequestMeta(context.Background(), meta)
+
+	setup := func() (*fakeAuditLogRepo, domainauth.AuthService) {
+		auditLogRepo := newFakeAuditLogRepo()
+		userRepo := &fakeUserByEmailRepo{fakeUserRepo{user: domainauth.GetDetailUserResult{
+			ID:           "1",
+			Email:        "user@example.com",
+			PasswordHash: string(passwordHash),
+			Role:         domainauth.UserRoleUser,
+			Status:       sharedkernel.UserStatusActive,
+		}}}
+		svc := newTestService(t,
+			withUserRepo(userRepo),
+			withAuditLogRepo(auditLogRepo),
+			withLockoutPolicy(domainauth.LoginLockoutPolicy{
+				MaxAttempts: 2,
+				Window:      time.Minute,
+				BaseLockout: time.Minute,
+			}),
+		)
+		return auditLogRepo, svc
+	}
+
+	t.Run("login failures carry the reason", func(t *testing.T) {
+		auditLogRepo, svc := setup()
+
+		_, err := svc.Login(ctx, domainauth.LoginInput{Email: "nobody@example.com", Password: "x"})
+		require.Error(t, err)
+		entry := auditLogRepo.last(t, sharedkernel.AuditActionLogin)
+		assert.Equal(t, sharedkernel.AuditOutcomeFailure, entry.Outcome)
+		assert.Equal(t, "unknown_email", entry.Reason)
+		assert.Empty(t, entry.TargetUserID)
+		assert.Equal(t, "nobody@example.com", entry.Details["email"])
+		assert.Equal(t, meta, entry.RequestMeta)
+
+		_, err = svc.Login(ctx, domainauth.LoginInput{Email: "user@example.com", Password: "wrong-password"})
+		require.Error(t, err)
+		entry = auditLogRepo.last(t, sharedkernel.AuditActionLogin)
+		assert.Equal(t, "invalid_password", entry.Reason)
+		assert.Equal(t, "1", entry.TargetUserID)
+
+		_, err = svc.Login(ctx, domainauth.LoginInput{Email: "user@example.com", Password: "wrong-password"})
+		require.Error(t, err)
+		assert.Equal(t, "locked_out", auditLogRepo.last(t, sharedkernel.AuditActionLogin).Reason)
+		assert.Len(t, auditLogRepo.entries, 3)
+	})
+
+	t.Run("login, refresh and logout", func(t *testing.T) {
+		auditLogRepo, svc := setup()
+
+		output, err := svc.Login(ctx, domainauth.LoginInput{Email: "user@example.com", Password: "correct-password"})
+		require.NoError(t, err)
+		entry := auditLogRepo.last(t, sharedkernel.AuditActionLogin)
+		assert.Equal(t, sharedkernel.AuditOutcomeSuccess, entry.Outcome)
+		assert.Equal(t, "1", entry.ActorUserID)
+		assert.Equal(t, "req-1", entry.RequestID)
+		assert.False(t, entry.CreatedAt.IsZero())
+
+		refreshed, err := svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: output.RefreshToken})
+		require.NoError(t, err)
+		assert.Equal(t, sharedkernel.AuditOutcomeSuccess, auditLogRepo.last(t, sharedkernel.AuditActionTokenRefresh).Outcome)
+
+		// presenting the rotated token again revokes the family
+		_, err = svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: output.RefreshToken})
+		require.Error(t, err)
+		entry = auditLogRepo.last(t, sharedkernel.AuditActionTokenRefresh)
+		assert.Equal(t, sharedkernel.AuditOutcomeFailure, entry.Outcome)
+		assert.Equal(t, "refresh_token_reused", entry.Reason)
+
+		_, err = svc.Logout(ctx, domainauth.LogoutInput{AccessToken: refreshed.AccessToken})
+		require.NoError(t, err)
+		assert.Equal(t, "1", auditLogRepo.last(t, sharedkernel.AuditActionLogout).TargetUserID)
+	})
+
+	t.Run("the caller of ctx is the actor", func(t *testing.T) {
+		auditLogRepo, svc := setup()
+
+		adminCtx := domainauth.WithTokenPayload(ctx, domainauth.TokenPayload{UserID: "9", ApiKeyID: "4"})
+		_, err := svc.RevokeAllSessions(adminCtx, domainauth.RevokeAllSessionsInput{UserID: "1"})
+		require.NoError(t, err)
+
+		entry := auditLogRepo.last(t, sharedkernel.AuditActionSessionRevoke)
+		assert.Equal(t, "9", entry.ActorUserID)
+		assert.Equal(t, "1", entry.TargetUserID)
+		assert.Equal(t, "all", entry.Details["scope"])
+		assert.Equal(t, "4", entry.Details["caller_api_key_id"])
+	})
+}
@@ -0,0 +1,128 @@
+package authservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+)
+
+const defaultDpopProofMaxAge = time.Minute
+
+var (
+	errInvalidDpopProof = apperror.BadRequest("invalid DPoP proof")
+	errDpopKeyMismatch  = apperror.BadRequest("DPoP proof does not match the token binding")
+)
+
+// dpopJkt verifies the DPoP proof sent to a token endpoint and returns the
+// thumbprint to bind the issued tokens to, empty when the client sent none.
+func (s *service) dpopJkt(ctx context.Context, proof *domainauth.DpopProof) (string, error) {
+	if proof == nil {
+		return "", nil
+	}
+	return s.verifyDpopProof(ctx, *proof, "")
+}
+
+// verifyDpopProof checks the claims of proof against the request it was sent
+// with and records its jti, so a proof is accepted only once. accessToken is
+// set on resource requests, the proof must then carry its hash in ath.
+func (s *service) verifyDpopProof(ctx context.Context, proof domainauth.DpopProof, accessToken string) (string, error) {
+	parsed, err := s.jwtRepo.ParseDpopProof(ctx, domainauth.ParseDpopProofParams{
+		Proof: proof.Proof,
+	})
+	if err != nil {
+		slog.DebugContext(ctx, "rejected dpop proof", "error", err)
+		return "", errInvalidDpopProof
+	}
+
+	if parsed.Method != proof.Method || !s.dpopPolicy.MatchesTarget(parsed.Url, proof.Url) {
+		return "", errInvalidDpopProof
+	}
+
+	maxAge := s.dpopPolicy.ProofMaxAge
+	if maxAge <= 0 {
+		maxAge = defaultDpopProofMaxAge
+	}
+	now := time.Now().UTC()
+	if parsed.IssuedAt.Before(now.Add(-maxAge)) || parsed.IssuedAt.After(now.Add(maxAge)) {
+		return "", errInvalidDpopProof
+	}
+
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		ath := base64.RawURLEncoding.EncodeToString(sum[:])
+		if subtle.ConstantTimeCompare([]byte(ath), []byte(parsed.AccessTokenHash)) != 1 {
+			return "", errInvalidDpopProof
+		}
+	}
+
+	// the jti is remembered as long as the proof would pass the iat check
+	used, err := s.dpopReplayRepo.UseDpopJti(ctx, domainauth.UseDpopJtiParams{
+		Jkt:       parsed.Jkt,
+		Jti:       parsed.Jti,
+		UsedAt:    now,
+		ExpiresAt: parsed.IssuedAt.Add(maxAge),
+	})
+	if err != nil {
+		return "", apperror.StdUnknown(err)
+	}
+	if !used.Success {
+		slog.WarnContext(ctx, "DPoP proof replay detected", "jkt", parsed.Jkt)
+		return "", errInvalidDpopProof
+	}
+
+	return parsed.Jkt, nil
+}
+
+// checkDpopBinding reports whether a resource request fits the binding of
+// its token: a bound token needs a proof of its key, a bearer token must not
+// be sent with the DPoP scheme.
+func (s *service) checkDpopBinding(ctx context.Context, boundJkt *string, input domainauth.ValidateTokenInput) (bool, error) {
+	if boundJkt == nil {
+		return input.Dpop == nil, nil
+	}
+	if input.Dpop == nil {
+		return false, nil
+	}
+
+	jkt, err := s.verifyDpopProof(ctx, *input.Dpop, input.Token)
+	if err != nil {
+		if errors.Is(err, errInvalidDpopProof) {
+			return false, nil
+		}
+		return false, err
+	}
+	return jkt == *boundJkt, nil
+}
+
+// sameDpopKey reports whether jkt, the key of the proof sent to refresh, is
+// the key the refresh token is bound to. Both are empty for bearer tokens.
+func sameDpopKey(boundJkt *string, jkt string) bool {
+	if boundJkt == nil {
+		return jkt == ""
+	}
+	return jkt == *boundJkt
+}
+
+// dpopBinding returns the auth_tokens binding of jkt, nil for bearer tokens.
+func dpopBinding(jkt string) *string {
+	if jkt == "" {
+		return nil
+	}
+	return &jkt
+}
+
+// tokenType is the token_type issued tokens are announced with.
+func tokenType(jkt string) string {
+	if jkt == "" {
+		return "Bearer"
+	}
+	return "DPoP"
+}
@@ -0,0 +1,216 @@
+package authservice_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"testing"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ParseDpopProof verifies real proofs, it needs no signing keys.
+func (f *fakeJwtRepo) ParseDpopProof(ctx context.Context, params domainauth.ParseDpopProofParams) (domainauth.ParseDpopProofResult, error) {
+	return authrepository.NewJwtRepository(nil).ParseDpopProof(ctx, params)
+}
+
+// dpopClient signs DPoP proofs with its own P-256 key.
+type dpopClient struct {
+	key  *ecdsa.PrivateKey
+	jtis int
+}
+
+func newDpopClient(t *testing.T) *dpopClient {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &dpopClient{key: key}
+}
+
+func (c *dpopClient) proof(t *testing.T, method, url, accessToken string, issuedAt time.Time) *domainauth.DpopProof {
+	t.Helper()
+	c.jtis++
+
+	claims := jwt.MapClaims{
+		"jti": "jti-" + strconv.Itoa(c.jtis),
+		"htm": method,
+		"htu": url,
+		"iat": issuedAt.Unix(),
+	}
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		claims["ath"] = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = map[string]string{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(c.key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(c.key.Y.FillBytes(make([]byte, 32))),
+	}
+	signed, err := token.SignedString(c.key)
+	require.NoError(t, err)
+
+	return &domainauth.DpopProof{Proof: signed, Method: method, Url: url}
+}
+
+// sentTo returns proof as received on another request than it was signed for.
+func sentTo(proof *domainauth.DpopProof, method, url string) *domainauth.DpopProof {
+	return &domainauth.DpopProof{Proof: proof.Proof, Method: method, Url: url}
+}
+
+func TestService_Dpop(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	const (
+		loginUrl    = "https://api.example.com/api/v1/auth/login"
+		refreshUrl  = "https://api.example.com/api/v1/auth/refresh"
+		resourceUrl = "https://api.example.com/api/v1/users/me"
+	)
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	setup := func() (*fakeAuthRepo, domainauth.AuthService) {
+		authRepo := newFakeAuthRepo()
+		userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+			ID:           "1",
+			Email:        "user@example.com",
+			PasswordHash: string(passwordHash),
+			Role:         domainauth.UserRoleUser,
+			Status:       sharedkernel.UserStatusActive,
+		}}
+		svc := newTestService(t,
+			withAuthRepo(authRepo),
+			withUserRepo(userRepo),
+			withDpopReplayRepo(authrepository.NewDpopReplayMemoryRepository()),
+			withDpopPolicy(domainauth.DpopPolicy{ProofMaxAge: time.Minute}),
+		)
+		return authRepo, svc
+	}
+
+	login := func(t *testing.T, svc domainauth.AuthService, proof *domainauth.DpopProof) domainauth.LoginOutput {
+		t.Helper()
+		output, err := svc.Login(ctx, domainauth.LoginInput{
+			Email:    "user@example.com",
+			Password: "correct-password",
+			Dpop:     proof,
+		})
+		require.NoError(t, err)
+		return output
+	}
+
+	t.Run("tokens are bound to the proof key", func(t *testing.T) {
+		authRepo, svc := setup()
+		client := newDpopClient(t)
+
+		output := login(t, svc, client.proof(t, "POST", loginUrl, "", now))
+		assert.Equal(t, "DPoP", output.TokenType)
+
+		jkt := authRepo.tokens[output.AccessToken].DpopJkt
+		require.NotNil(t, jkt)
+		assert.Equal(t, jkt, authRepo.tokens[output.RefreshToken].DpopJkt)
+
+		validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{
+			Token: output.AccessToken,
+			Dpop:  client.proof(t, "GET", resourceUrl, output.AccessToken, now),
+		})
+		require.NoError(t, err)
+		require.True(t, validated.Valid)
+		assert.Equal(t, *jkt, validated.Payload.DpopJkt)
+
+		introspected, err := svc.IntrospectToken(ctx, domainauth.IntrospectTokenInput{Token: output.AccessToken})
+		require.NoError(t, err)
+		assert.True(t, introspected.Active, "the resource server checks the proof itself")
+		assert.Equal(t, *jkt, introspected.Payload.DpopJkt)
+	})
+
+	t.Run("a bound token is refused without a valid proof", func(t *testing.T) {
+		_, svc := setup()
+		client := newDpopClient(t)
+		output := login(t, svc, client.proof(t, "POST", loginUrl, "", now))
+
+		replayed := client.proof(t, "GET", resourceUrl, output.AccessToken, now)
+		validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken, Dpop: replayed})
+		require.NoError(t, err)
+		require.True(t, validated.Valid)
+
+		cases := map[string]*domainauth.DpopProof{
+			"bearer scheme":     nil,
+			"replayed proof":    replayed,
+			"other key":         newDpopClient(t).proof(t, "GET", resourceUrl, output.AccessToken, now),
+			"other method":      sentTo(client.proof(t, "GET", resourceUrl, output.AccessToken, now), "DELETE", resourceUrl),
+			"other url":         sentTo(client.proof(t, "GET", resourceUrl, output.AccessToken, now), "GET", refreshUrl),
+			"missing ath":       client.proof(t, "GET", resourceUrl, "", now),
+			"stale iat":         client.proof(t, "GET", resourceUrl, output.AccessToken, now.Add(-2*time.Minute)),
+			"iat in the future": client.proof(t, "GET", resourceUrl, output.AccessToken, now.Add(2*time.Minute)),
+		}
+		for name, proof := range cases {
+			validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken, Dpop: proof})
+			require.NoError(t, err, name)
+			assert.False(t, validated.Valid, name)
+		}
+	})
+
+	t.Run("a bearer token is refused with the DPoP scheme", func(t *testing.T) {
+		_, svc := setup()
+		output := login(t, svc, nil)
+		assert.Equal(t, "Bearer", output.TokenType)
+
+		validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{
+			Token: output.AccessToken,
+			Dpop:  newDpopClient(t).proof(t, "GET", resourceUrl, output.AccessToken, now),
+		})
+		require.NoError(t, err)
+		assert.False(t, validated.Valid)
+	})
+
+	t.Run("an invalid proof fails the login", func(t *testing.T) {
+		_, svc := setup()
+		_, err := svc.Login(ctx, domainauth.LoginInput{
+			Email:    "user@example.com",
+			Password: "correct-password",
+			Dpop:     sentTo(newDpopClient(t).proof(t, "POST", refreshUrl, "", now), "POST", loginUrl),
+		})
+		assert.True(t, apperror.IsBadRequest(err))
+	})
+
+	t.Run("refresh keeps the binding", func(t *testing.T) {
+		authRepo, svc := setup()
+		client := newDpopClient(t)
+		output := login(t, svc, client.proof(t, "POST", loginUrl, "", now))
+
+		_, err := svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: output.RefreshToken})
+		assert.True(t, apperror.IsBadRequest(err), "a bound refresh token needs a proof")
+
+		_, err = svc.RefreshToken(ctx, domainauth.RefreshTokenInput{
+			RefreshToken: output.RefreshToken,
+			Dpop:         newDpopClient(t).proof(t, "POST", refreshUrl, "", now),
+		})
+		assert.True(t, apperror.IsBadRequest(err), "a proof of another key is refused")
+
+		refreshed, err := svc.RefreshToken(ctx, domainauth.RefreshTokenInput{
+			RefreshToken: output.RefreshToken,
+			Dpop:         client.proof(t, "POST", refreshUrl, "", now),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "DPoP", refreshed.TokenType)
+		assert.Equal(t, authRepo.tokens[output.AccessToken].DpopJkt, authRepo.tokens[refreshed.AccessToken].DpopJkt)
+		assert.Equal(t, authRepo.tokens[output.RefreshToken].DpopJkt, authRepo.tokens[refreshed.RefreshToken].DpopJkt)
+	})
+}
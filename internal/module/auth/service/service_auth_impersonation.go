@@ -0,0 +1,186 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	domainpolicy "go-bootstrap/internal/domain/policy"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+// Reasons of refused impersonations in the audit log.
+const (
+	auditReasonUserNotFound = "user_not_found"
+	auditReasonTargetAdmin  = "target_admin"
+)
+
+// Impersonate starts a session of the target user that holds a single access
+// token. There is no refresh token, the admin impersonates again once it
+// expires; logging out with the token ends the impersonation early.
+func (s *service) Impersonate(ctx context.Context, input domainauth.ImpersonateInput) (domainauth.ImpersonateOutput, error) {
+	if err := sharedkernel.DenyImpersonation(ctx); err != nil {
+		return domainauth.ImpersonateOutput{}, err
+	}
+
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return domainauth.ImpersonateOutput{}, apperror.BadRequest("reason is required")
+	}
+	if input.TargetUserID == input.ActorUserID {
+		return domainauth.ImpersonateOutput{}, apperror.BadRequest("cannot impersonate yourself")
+	}
+
+	auditFailure := func(auditReason string) {
+		s.audit(ctx, sharedkernel.AuditEntry{
+			Action:       sharedkernel.AuditActionImpersonate,
+			Outcome:      sharedkernel.AuditOutcomeFailure,
+			Reason:       auditReason,
+			ActorUserID:  input.ActorUserID,
+			TargetUserID: input.TargetUserID,
+			Details:      map[string]string{"reason": reason},
+		})
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+		UserID: &input.TargetUserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			auditFailure(auditReasonUserNotFound)
+			return domainauth.ImpersonateOutput{}, apperror.NotFound("user not found")
+		}
+		return domainauth.ImpersonateOutput{}, apperror.StdUnknown(err)
+	}
+
+	// an admin token would let the impersonator act with another admin's
+	// permissions under their name
+	if user.Role == domainauth.UserRoleAdmin {
+		auditFailure(auditReasonTargetAdmin)
+		return domainauth.ImpersonateOutput{}, apperror.Forbidden("admins cannot be impersonated")
+	}
+	if err = user.Status.CanLogin(); err != nil {
+		auditFailure("user_" + string(user.Status))
+		return domainauth.ImpersonateOutput{}, apperror.BadRequest(err.Error())
+	}
+
+	familyID, err := s.generateToken()
+	if err != nil {
+		return domainauth.ImpersonateOutput{}, apperror.StdUnknown(err)
+	}
+
+	tokenPolicy, err := s.getTokenPolicy(ctx, user.Role)
+	if err != nil {
+		return domainauth.ImpersonateOutput{}, apperror.StdUnknown(err)
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(tokenPolicy.ImpersonationTokenTTL)
+
+	session, err := s.authRepo.CreateSession(ctx, domainauth.CreateSessionParams{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		UserAgent: sessionUserAgent(input.UserAgent),
+		IPAddress: input.IPAddress,
+		Platform:  sessionPlatform(input.Platform),
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return domainauth.ImpersonateOutput{}, apperror.StdUnknown(err)
+	}
+
+	accessToken, err := s.jwtRepo.CreateAccessToken(ctx, domainauth.CreateAccessTokenParams{
+		UserID:         user.ID,
+		SessionID:      session.ID,
+		Email:          user.Email,
+		Role:           user.Role,
+		IssuedAt:       now,
+		ExpiresAt:      expiresAt,
+		ImpersonatorID: input.ActorUserID,
+	})
+	if err != nil {
+		return domainauth.ImpersonateOutput{}, apperror.StdUnknown(err)
+	}
+
+	_, err = s.authRepo.CreateToken(ctx, domainauth.CreateTokenParams{
+		UserID:    user.ID,
+		Token:     accessToken.Token,
+		TokenType: domainauth.TokenTypeAccess,
+		ExpiresAt: expiresAt,
+		FamilyID:  familyID,
+	})
+	if err != nil {
+		return domainauth.ImpersonateOutput{}, apperror.StdUnknown(err)
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionImpersonate,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  input.ActorUserID,
+		TargetUserID: user.ID,
+		Details: map[string]string{
+			"reason":     reason,
+			"session_id": session.ID,
+			"expires_at": expiresAt.Format(time.RFC3339),
+		},
+	})
+
+	return domainauth.ImpersonateOutput{
+		AccessToken: accessToken.Token,
+		ExpiresIn:   int64(expiresAt.Sub(now).Seconds()),
+		TokenType:   "Bearer",
+	}, nil
+}
+
+// checkImpersonator reports whether the admin behind an impersonation token
+// may still log in and impersonate, suspending or demoting the admin ends
+// their impersonations too.
+func (s *service) checkImpersonator(ctx context.Context, impersonatorID string) (bool, error) {
+	impersonator, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+		UserID: &impersonatorID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if impersonator.Status.CanLogin() != nil {
+		return false, nil
+	}
+
+	result, err := s.rolePolicyRepo.GetRolePermissions(ctx, domainauth.GetRolePermissionsParams{
+		Role: impersonator.Role,
+	})
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(result.Permissions, string(domainpolicy.PermissionAll)) ||
+		slices.Contains(result.Permissions, string(domainpolicy.PermissionUsersImpersonate)), nil
+}
+
+// auditImpersonatedRequest records a request made with an impersonation
+// token. Requests without an operation, e.g. introspection, are not audited.
+func (s *service) auditImpersonatedRequest(ctx context.Context, payload domainauth.TokenPayload, operation string) {
+	if operation == "" {
+		return
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionImpersonatedRequest,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  payload.ImpersonatorID,
+		TargetUserID: payload.UserID,
+		Details: map[string]string{
+			"operation":  operation,
+			"session_id": payload.SessionID,
+		},
+	})
+}
@@ -0,0 +1,86 @@
+package authservice_test
+
+import (
+	"context"
+	"testing"
+
+	"go-bootstrap/internal/config"
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	"go-bootstrap/internal/infrastructure"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Impersonate(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserStore(
+		domainauth.GetDetailUserResult{ID: "1", Email: "admin@example.com", Role: domainauth.UserRoleAdmin, Status: sharedkernel.UserStatusActive},
+		domainauth.GetDetailUserResult{ID: "2", Email: "user@example.com", Role: domainauth.UserRoleUser, Status: sharedkernel.UserStatusActive},
+		domainauth.GetDetailUserResult{ID: "3", Email: "other-admin@example.com", Role: domainauth.UserRoleAdmin, Status: sharedkernel.UserStatusActive},
+	)
+	auditLogRepo := newFakeAuditLogRepo()
+	rolePolicy := authrepository.NewRolePolicyRepository(infrastructure.NewRolePolicyFromConfig(config.Policy{Roles: []config.PolicyRole{
+		{Role: "admin", Permissions: []string{"users:impersonate"}},
+		{Role: "support", Permissions: []string{"users:list"}},
+	}}))
+	svc := newTestService(t, withUserRepo(users), withAuditLogRepo(auditLogRepo), withRolePolicyRepo(rolePolicy))
+
+	output, err := svc.Impersonate(ctx, domainauth.ImpersonateInput{ActorUserID: "1", TargetUserID: "2", Reason: "ticket #1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(15*60), output.ExpiresIn)
+
+	entry := auditLogRepo.last(t, sharedkernel.AuditActionImpersonate)
+	assert.Equal(t, sharedkernel.AuditOutcomeSuccess, entry.Outcome)
+	assert.Equal(t, "1", entry.ActorUserID)
+	assert.Equal(t, "2", entry.TargetUserID)
+	assert.Equal(t, "ticket #1", entry.Details["reason"])
+
+	validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken, Operation: "GET /api/v1/users/me"})
+	require.NoError(t, err)
+	require.True(t, validated.Valid)
+	assert.Equal(t, "2", validated.Payload.UserID)
+	assert.Equal(t, "1", validated.Payload.ImpersonatorID)
+
+	entry = auditLogRepo.last(t, sharedkernel.AuditActionImpersonatedRequest)
+	assert.Equal(t, "1", entry.ActorUserID)
+	assert.Equal(t, "2", entry.TargetUserID)
+	assert.Equal(t, "GET /api/v1/users/me", entry.Details["operation"])
+
+	impersonating := domainauth.WithTokenPayload(ctx, *validated.Payload)
+	_, err = svc.CreateApiKey(impersonating, domainauth.CreateApiKeyInput{UserID: "2", Name: "ci"})
+	assert.True(t, apperror.IsForbidden(err), "api keys cannot be created while impersonating")
+	_, err = svc.EnrollTotp(impersonating, domainauth.EnrollTotpInput{UserID: "2"})
+	assert.True(t, apperror.IsForbidden(err), "2fa cannot be changed while impersonating")
+	_, err = svc.Impersonate(impersonating, domainauth.ImpersonateInput{ActorUserID: "2", TargetUserID: "1", Reason: "nested"})
+	assert.True(t, apperror.IsForbidden(err), "impersonation cannot be nested")
+
+	_, err = svc.Impersonate(ctx, domainauth.ImpersonateInput{ActorUserID: "1", TargetUserID: "3", Reason: "ticket #2"})
+	assert.True(t, apperror.IsForbidden(err), "admins cannot be impersonated")
+	assert.Equal(t, "target_admin", auditLogRepo.last(t, sharedkernel.AuditActionImpersonate).Reason)
+
+	_, err = svc.Impersonate(ctx, domainauth.ImpersonateInput{ActorUserID: "1", TargetUserID: "2"})
+	assert.True(t, apperror.IsBadRequest(err), "a reason is required")
+
+	admin := users.users["1"]
+	admin.Role = "support"
+	users.users["1"] = admin
+	validated, err = svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken})
+	require.NoError(t, err)
+	assert.False(t, validated.Valid, "demoting the admin ends the impersonation")
+
+	admin.Role = domainauth.UserRoleAdmin
+	users.users["1"] = admin
+	validated, err = svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken})
+	require.NoError(t, err)
+	require.True(t, validated.Valid)
+
+	admin.Status = sharedkernel.UserStatusSuspended
+	users.users["1"] = admin
+	validated, err = svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken})
+	require.NoError(t, err)
+	assert.False(t, validated.Valid, "suspending the admin ends the impersonation")
+}
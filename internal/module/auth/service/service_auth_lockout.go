@@ -0,0 +1,180 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+// loginAttemptKeys returns the email key first, then the client IP key when known.
+func loginAttemptKeys(input domainauth.LoginInput) []domainauth.GetDetailLoginAttemptFilters {
+	keys := []domainauth.GetDetailLoginAttemptFilters{
+		{Scope: domainauth.LoginAttemptScopeEmail, Identifier: strings.ToLower(strings.TrimSpace(input.Email))},
+	}
+	if input.IPAddress != "" {
+		keys = append(keys, domainauth.GetDetailLoginAttemptFilters{
+			Scope:      domainauth.LoginAttemptScopeIP,
+			Identifier: input.IPAddress,
+		})
+	}
+	return keys
+}
+
+// checkLoginLockout runs before the password is checked so a locked out
+// caller costs neither a user lookup nor a password hash comparison.
+func (s *service) checkLoginLockout(ctx context.Context, keys []domainauth.GetDetailLoginAttemptFilters) error {
+	if s.lockoutPolicy.MaxAttempts <= 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	var lockedUntil time.Time
+	for _, key := range keys {
+		attempt, err := s.loginAttemptRepo.GetDetailLoginAttempt(ctx, key)
+		if err != nil {
+			if errors.Is(err, databases.ErrNoRowFound) {
+				continue
+			}
+			return apperror.StdUnknown(err)
+		}
+
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) && attempt.LockedUntil.After(lockedUntil) {
+			lockedUntil = *attempt.LockedUntil
+		}
+	}
+
+	if !lockedUntil.IsZero() {
+		return &domainauth.LoginLockedError{LockedUntil: lockedUntil}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login against every key and locks the
+// keys that reached the threshold. It returns failure, or a LoginLockedError
+// when a key got locked.
+func (s *service) recordLoginFailure(ctx context.Context, keys []domainauth.GetDetailLoginAttemptFilters, failure error) error {
+	if s.lockoutPolicy.MaxAttempts <= 0 {
+		return failure
+	}
+
+	now := time.Now().UTC()
+	var lockoutResetStart time.Time
+	if s.lockoutPolicy.LockoutResetAfter > 0 {
+		lockoutResetStart = now.Add(-s.lockoutPolicy.LockoutResetAfter)
+	}
+
+	var lockedUntil time.Time
+	for _, key := range keys {
+		result, err := s.loginAttemptRepo.IncrementLoginFailure(ctx, domainauth.IncrementLoginFailureParams{
+			Scope:             key.Scope,
+			Identifier:        key.Identifier,
+			FailedAt:          now,
+			WindowStart:       now.Add(-s.lockoutPolicy.Window),
+			LockoutResetStart: lockoutResetStart,
+		})
+		if err != nil {
+			return apperror.StdUnknown(err)
+		}
+
+		if result.FailedCount < s.lockoutPolicy.MaxAttempts {
+			continue
+		}
+
+		until := now.Add(s.lockoutPolicy.LockoutDuration(result.LockoutCount))
+		_, err = s.loginAttemptRepo.LockLoginAttempt(ctx, domainauth.LockLoginAttemptParams{
+			Scope:       key.Scope,
+			Identifier:  key.Identifier,
+			LockedUntil: until,
+		})
+		if err != nil {
+			return apperror.StdUnknown(err)
+		}
+
+		slog.WarnContext(ctx, "login locked out after repeated failures",
+			"scope", key.Scope,
+			"lockout_count", result.LockoutCount+1,
+			"locked_until", until,
+		)
+		if until.After(lockedUntil) {
+			lockedUntil = until
+		}
+	}
+
+	if !lockedUntil.IsZero() {
+		return &domainauth.LoginLockedError{LockedUntil: lockedUntil}
+	}
+	return failure
+}
+
+func (s *service) GetListLoginLockout(ctx context.Context, input domainauth.GetListLoginLockoutInput) (domainauth.GetListLoginLockoutOutput, error) {
+	now := time.Now().UTC()
+	result, err := s.loginAttemptRepo.GetListLoginAttempt(ctx, domainauth.GetListLoginAttemptFilters{
+		Pagination:  input.Pagination,
+		LockedAfter: &now,
+	})
+	if err != nil {
+		return domainauth.GetListLoginLockoutOutput{}, apperror.StdUnknown(err)
+	}
+
+	lockouts := make([]domainauth.LoginLockout, 0, len(result.LoginAttempts))
+	for _, v := range result.LoginAttempts {
+		if v.LockedUntil == nil {
+			continue
+		}
+		lockouts = append(lockouts, domainauth.LoginLockout{
+			Scope:        v.Scope,
+			Identifier:   v.Identifier,
+			LockoutCount: v.LockoutCount,
+			LastFailedAt: v.LastFailedAt,
+			LockedUntil:  *v.LockedUntil,
+		})
+	}
+
+	return domainauth.GetListLoginLockoutOutput{
+		Lockouts:   lockouts,
+		Pagination: result.Pagination,
+	}, nil
+}
+
+func (s *service) ClearLoginLockout(ctx context.Context, input domainauth.ClearLoginLockoutInput) (domainauth.ClearLoginLockoutOutput, error) {
+	identifier := input.Identifier
+	switch input.Scope {
+	case domainauth.LoginAttemptScopeEmail:
+		identifier = strings.ToLower(strings.TrimSpace(identifier))
+	case domainauth.LoginAttemptScopeIP:
+	default:
+		return domainauth.ClearLoginLockoutOutput{}, apperror.BadRequest("invalid lockout scope")
+	}
+
+	result, err := s.loginAttemptRepo.DeleteLoginAttempt(ctx, domainauth.DeleteLoginAttemptParams{
+		Scope:      input.Scope,
+		Identifier: identifier,
+	})
+	if err != nil {
+		return domainauth.ClearLoginLockoutOutput{}, apperror.StdUnknown(err)
+	}
+	if !result.Success {
+		return domainauth.ClearLoginLockoutOutput{}, apperror.NotFound("lockout not found")
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:  sharedkernel.AuditActionLoginLockoutClear,
+		Outcome: sharedkernel.AuditOutcomeSuccess,
+		Details: map[string]string{
+			"scope":      string(input.Scope),
+			"identifier": identifier,
+		},
+	})
+
+	return domainauth.ClearLoginLockoutOutput{
+		Success: true,
+	}, nil
+}
@@ -0,0 +1,209 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+const (
+	defaultMagicLinkTTL           = 15 * time.Minute
+	defaultMagicLinkRequestWindow = time.Hour
+)
+
+var errInvalidMagicLink = apperror.BadRequest("invalid or expired sign-in link")
+
+func (s *service) RequestMagicLink(ctx context.Context, input domainauth.RequestMagicLinkInput) (domainauth.RequestMagicLinkOutput, error) {
+	user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+		Email: &input.Email,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.RequestMagicLinkOutput{}, nil
+		}
+		return domainauth.RequestMagicLinkOutput{}, apperror.StdUnknown(err)
+	}
+
+	if err = user.Status.CanLogin(); err != nil {
+		slog.InfoContext(ctx, "magic link requested for user that cannot log in", "user_id", user.ID, "status", user.Status)
+		return domainauth.RequestMagicLinkOutput{}, nil
+	}
+
+	now := time.Now().UTC()
+	limited, err := s.magicLinkRateLimited(ctx, user.ID, now)
+	if err != nil {
+		return domainauth.RequestMagicLinkOutput{}, apperror.StdUnknown(err)
+	}
+	if limited {
+		s.audit(ctx, sharedkernel.AuditEntry{
+			Action:       sharedkernel.AuditActionMagicLinkRequest,
+			Outcome:      sharedkernel.AuditOutcomeFailure,
+			Reason:       auditReasonRateLimited,
+			TargetUserID: user.ID,
+		})
+		return domainauth.RequestMagicLinkOutput{}, nil
+	}
+
+	token, err := s.generateToken()
+	if err != nil {
+		return domainauth.RequestMagicLinkOutput{}, apperror.StdUnknown(err)
+	}
+
+	link, err := tokenLink(s.magicLinkPolicy.LinkURL, token)
+	if err != nil {
+		return domainauth.RequestMagicLinkOutput{}, apperror.StdUnknown(err)
+	}
+
+	ttl := s.magicLinkPolicy.TokenTTL
+	if ttl <= 0 {
+		ttl = defaultMagicLinkTTL
+	}
+	expiresAt := now.Add(ttl)
+
+	_, err = s.magicLinkRepo.CreateMagicLink(ctx, domainauth.CreateMagicLinkParams{
+		UserID:    user.ID,
+		Token:     token,
+		IPAddress: input.IPAddress,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return domainauth.RequestMagicLinkOutput{}, apperror.StdUnknown(err)
+	}
+
+	_, err = s.notifierRepo.SendMagicLink(ctx, domainauth.SendMagicLinkParams{
+		Email:     user.Email,
+		Name:      user.Name,
+		Link:      link,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return domainauth.RequestMagicLinkOutput{}, apperror.StdUnknown(err)
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionMagicLinkRequest,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		TargetUserID: user.ID,
+	})
+
+	return domainauth.RequestMagicLinkOutput{}, nil
+}
+
+// magicLinkRateLimited reports whether the user already got the allowed
+// number of links in the current window. Concurrent requests may each pass
+// the check, the limit is meant against mail flooding, not as a hard cap.
+func (s *service) magicLinkRateLimited(ctx context.Context, userID string, now time.Time) (bool, error) {
+	if s.magicLinkPolicy.MaxRequests <= 0 {
+		return false, nil
+	}
+
+	window := s.magicLinkPolicy.RequestWindow
+	if window <= 0 {
+		window = defaultMagicLinkRequestWindow
+	}
+
+	result, err := s.magicLinkRepo.CountMagicLink(ctx, domainauth.CountMagicLinkFilters{
+		UserID:       userID,
+		CreatedAfter: now.Add(-window),
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.Count >= s.magicLinkPolicy.MaxRequests, nil
+}
+
+func (s *service) LoginMagicLink(ctx context.Context, input domainauth.LoginMagicLinkInput) (domainauth.LoginOutput, error) {
+	if input.Token == "" {
+		return domainauth.LoginOutput{}, errInvalidMagicLink
+	}
+
+	dpopJkt, err := s.dpopJkt(ctx, input.Dpop)
+	if err != nil {
+		return domainauth.LoginOutput{}, err
+	}
+
+	consumed, err := s.magicLinkRepo.ConsumeMagicLink(ctx, domainauth.ConsumeMagicLinkParams{
+		Token:  input.Token,
+		UsedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			s.auditLoginFailure(ctx, sharedkernel.AuditActionLoginMagicLink, "", "", auditReasonInvalidMagicLink, errInvalidMagicLink)
+			return domainauth.LoginOutput{}, errInvalidMagicLink
+		}
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+		UserID: &consumed.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.LoginOutput{}, errInvalidMagicLink
+		}
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	if err = user.Status.CanLogin(); err != nil {
+		s.auditLoginFailure(ctx, sharedkernel.AuditActionLoginMagicLink, user.ID, "", "user_"+string(user.Status), err)
+		return domainauth.LoginOutput{}, apperror.BadRequest(err.Error())
+	}
+
+	// the link only proves access to the mailbox, a second factor is still
+	// required like after a password
+	mfaRequired, err := s.isMfaRequired(ctx, user.ID)
+	if err != nil {
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	entry := sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionLoginMagicLink,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  user.ID,
+		TargetUserID: user.ID,
+	}
+	if mfaRequired {
+		entry.Details = map[string]string{"mfa_required": "true"}
+		s.audit(ctx, entry)
+		return s.createMfaChallenge(ctx, user.ID)
+	}
+
+	output, err := s.issueTokens(ctx, user, sessionClient{
+		UserAgent: input.UserAgent,
+		IPAddress: input.IPAddress,
+		Platform:  input.Platform,
+		DpopJkt:   dpopJkt,
+	})
+	if err != nil {
+		if errors.Is(err, errSessionLimitReached) {
+			s.auditLoginFailure(ctx, entry.Action, user.ID, "", auditReasonSessionLimit, err)
+		}
+		return domainauth.LoginOutput{}, err
+	}
+
+	s.audit(ctx, entry)
+
+	return output, nil
+}
+
+// tokenLink adds token as the "token" query parameter of linkURL.
+func tokenLink(linkURL string, token string) (string, error) {
+	link, err := url.Parse(linkURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
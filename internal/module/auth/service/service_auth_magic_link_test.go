@@ -0,0 +1,146 @@
+package authservice_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMagicLink struct {
+	userID    string
+	expiresAt time.Time
+	usedAt    *time.Time
+	createdAt time.Time
+}
+
+// fakeMagicLinkRepo keys links by the plain token.
+type fakeMagicLinkRepo struct {
+	links map[string]*fakeMagicLink
+}
+
+func newFakeMagicLinkRepo() *fakeMagicLinkRepo {
+	return &fakeMagicLinkRepo{links: map[string]*fakeMagicLink{}}
+}
+
+func (f *fakeMagicLinkRepo) CreateMagicLink(ctx context.Context, params domainauth.CreateMagicLinkParams) (domainauth.CreateMagicLinkResult, error) {
+	now := time.Now().UTC()
+	f.links[params.Token] = &fakeMagicLink{userID: params.UserID, expiresAt: params.ExpiresAt, createdAt: now}
+	return domainauth.CreateMagicLinkResult{ID: params.Token, CreatedAt: now}, nil
+}
+
+func (f *fakeMagicLinkRepo) CountMagicLink(ctx context.Context, filters domainauth.CountMagicLinkFilters) (domainauth.CountMagicLinkResult, error) {
+	var count int64
+	for _, link := range f.links {
+		if link.userID == filters.UserID && link.createdAt.After(filters.CreatedAfter) {
+			count++
+		}
+	}
+	return domainauth.CountMagicLinkResult{Count: count}, nil
+}
+
+func (f *fakeMagicLinkRepo) ConsumeMagicLink(ctx context.Context, params domainauth.ConsumeMagicLinkParams) (domainauth.ConsumeMagicLinkResult, error) {
+	link, ok := f.links[params.Token]
+	if !ok || link.usedAt != nil || !link.expiresAt.After(params.UsedAt) {
+		return domainauth.ConsumeMagicLinkResult{}, databases.ErrNoRowFound
+	}
+	link.usedAt = &params.UsedAt
+	return domainauth.ConsumeMagicLinkResult{UserID: link.userID}, nil
+}
+
+func (f *fakeMagicLinkRepo) DeleteExpiredMagicLinks(ctx context.Context, params domainauth.DeleteExpiredMagicLinksParams) (domainauth.DeleteExpiredMagicLinksResult, error) {
+	return domainauth.DeleteExpiredMagicLinksResult{}, nil
+}
+
+type fakeNotifierRepo struct {
+	magicLinks []domainauth.SendMagicLinkParams
+}
+
+func (f *fakeNotifierRepo) SendMagicLink(ctx context.Context, params domainauth.SendMagicLinkParams) (domainauth.SendMagicLinkResult, error) {
+	f.magicLinks = append(f.magicLinks, params)
+	return domainauth.SendMagicLinkResult{}, nil
+}
+
+// token returns the token of the newest link sent.
+func (f *fakeNotifierRepo) token(t *testing.T) string {
+	t.Helper()
+	require.NotEmpty(t, f.magicLinks)
+	link, err := url.Parse(f.magicLinks[len(f.magicLinks)-1].Link)
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestService_MagicLink(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &fakeUserByEmailRepo{fakeUserRepo{user: domainauth.GetDetailUserResult{
+		ID:     "1",
+		Email:  "user@example.com",
+		Name:   "User",
+		Role:   domainauth.UserRoleUser,
+		Status: sharedkernel.UserStatusActive,
+	}}}
+	magicLinkRepo := newFakeMagicLinkRepo()
+	notifierRepo := &fakeNotifierRepo{}
+	auditLogRepo := newFakeAuditLogRepo()
+	svc := newTestService(t,
+		withUserRepo(userRepo),
+		withAuditLogRepo(auditLogRepo),
+		withMagicLinkRepo(magicLinkRepo),
+		withNotifierRepo(notifierRepo),
+		withMagicLinkPolicy(domainauth.MagicLinkPolicy{
+			LinkURL:       "https://app.example.com/magic-link",
+			TokenTTL:      time.Minute,
+			MaxRequests:   2,
+			RequestWindow: time.Hour,
+		}),
+	)
+
+	_, err := svc.RequestMagicLink(ctx, domainauth.RequestMagicLinkInput{Email: "unknown@example.com"})
+	require.NoError(t, err, "an unknown email is not revealed")
+	assert.Empty(t, notifierRepo.magicLinks)
+
+	_, err = svc.RequestMagicLink(ctx, domainauth.RequestMagicLinkInput{Email: "user@example.com", IPAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	require.Len(t, notifierRepo.magicLinks, 1)
+	assert.Equal(t, "user@example.com", notifierRepo.magicLinks[0].Email)
+	assert.Contains(t, notifierRepo.magicLinks[0].Link, "https://app.example.com/magic-link?token=")
+	token := notifierRepo.token(t)
+	assert.Contains(t, magicLinkRepo.links, token)
+
+	output, err := svc.LoginMagicLink(ctx, domainauth.LoginMagicLinkInput{Token: token, IPAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.AccessToken)
+	assert.NotEmpty(t, output.RefreshToken)
+	assert.Equal(t, "Bearer", output.TokenType)
+	assert.Equal(t, sharedkernel.AuditOutcomeSuccess, auditLogRepo.last(t, sharedkernel.AuditActionLoginMagicLink).Outcome)
+
+	validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken})
+	require.NoError(t, err)
+	assert.True(t, validated.Valid)
+
+	_, err = svc.LoginMagicLink(ctx, domainauth.LoginMagicLinkInput{Token: token})
+	assert.True(t, apperror.IsBadRequest(err), "a link is single use")
+	assert.Equal(t, "invalid_magic_link", auditLogRepo.last(t, sharedkernel.AuditActionLoginMagicLink).Reason)
+
+	_, err = svc.RequestMagicLink(ctx, domainauth.RequestMagicLinkInput{Email: "user@example.com"})
+	require.NoError(t, err)
+	require.Len(t, notifierRepo.magicLinks, 2)
+
+	_, err = svc.RequestMagicLink(ctx, domainauth.RequestMagicLinkInput{Email: "user@example.com"})
+	require.NoError(t, err, "a rate limited request answers like any other")
+	assert.Len(t, notifierRepo.magicLinks, 2, "no link is sent over the limit")
+	assert.Equal(t, "rate_limited", auditLogRepo.last(t, sharedkernel.AuditActionMagicLinkRequest).Reason)
+
+	expired := notifierRepo.token(t)
+	magicLinkRepo.links[expired].expiresAt = time.Now().UTC().Add(-time.Second)
+	_, err = svc.LoginMagicLink(ctx, domainauth.LoginMagicLinkInput{Token: expired})
+	assert.True(t, apperror.IsBadRequest(err), "an expired link is refused")
+}
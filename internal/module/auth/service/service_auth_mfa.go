@@ -0,0 +1,434 @@
+package authservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+const (
+	defaultMfaChallengeTTL         = 5 * time.Minute
+	defaultMfaChallengeMaxAttempts = 5
+	defaultMfaIssuer               = "go-bootstrap"
+
+	totpSecretSize     = 20 // 160 bits, the RFC 4226 recommendation
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var (
+	errInvalidMfaChallenge = apperror.BadRequest("invalid or expired mfa challenge")
+	errInvalidMfaCode      = apperror.BadRequest("invalid mfa code")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// isMfaRequired reports whether the user has confirmed TOTP and must pass
+// a second factor before tokens are issued.
+func (s *service) isMfaRequired(ctx context.Context, userID string) (bool, error) {
+	totp, err := s.mfaRepo.GetDetailTotp(ctx, domainauth.GetDetailTotpFilters{
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return totp.ConfirmedAt != nil, nil
+}
+
+// createMfaChallenge answers a login whose password was correct but whose
+// user has TOTP enabled.
+func (s *service) createMfaChallenge(ctx context.Context, userID string) (domainauth.LoginOutput, error) {
+	challenge, err := s.generateToken()
+	if err != nil {
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	ttl := s.mfaPolicy.ChallengeTTL
+	if ttl <= 0 {
+		ttl = defaultMfaChallengeTTL
+	}
+
+	_, err = s.mfaRepo.CreateMfaChallenge(ctx, domainauth.CreateMfaChallengeParams{
+		UserID:    userID,
+		Challenge: challenge,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	})
+	if err != nil {
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	return domainauth.LoginOutput{
+		MfaRequired:           true,
+		MfaChallenge:          challenge,
+		MfaChallengeExpiresIn: int64(ttl.Seconds()),
+	}, nil
+}
+
+func (s *service) LoginMfa(ctx context.Context, input domainauth.LoginMfaInput) (domainauth.LoginOutput, error) {
+	dpopJkt, err := s.dpopJkt(ctx, input.Dpop)
+	if err != nil {
+		return domainauth.LoginOutput{}, err
+	}
+
+	challenge, err := s.mfaRepo.GetDetailMfaChallenge(ctx, domainauth.GetDetailMfaChallengeFilters{
+		Challenge: input.Challenge,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.LoginOutput{}, errInvalidMfaChallenge
+		}
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	maxAttempts := s.mfaPolicy.ChallengeMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMfaChallengeMaxAttempts
+	}
+	now := time.Now().UTC()
+	if challenge.UsedAt != nil || !challenge.ExpiresAt.After(now) || challenge.FailedAttempts >= maxAttempts {
+		return domainauth.LoginOutput{}, errInvalidMfaChallenge
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+		UserID: &challenge.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.LoginOutput{}, errInvalidMfaChallenge
+		}
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	if err = user.Status.CanLogin(); err != nil {
+		s.auditLoginFailure(ctx, sharedkernel.AuditActionLoginMfa, user.ID, "", "user_"+string(user.Status), err)
+		return domainauth.LoginOutput{}, apperror.BadRequest(err.Error())
+	}
+
+	// wrong codes count against the same keys as wrong passwords
+	attemptKeys := loginAttemptKeys(domainauth.LoginInput{
+		Email:     user.Email,
+		IPAddress: input.IPAddress,
+	})
+	if err = s.checkLoginLockout(ctx, attemptKeys); err != nil {
+		s.auditLoginFailure(ctx, sharedkernel.AuditActionLoginMfa, user.ID, "", auditReasonLockedOut, err)
+		return domainauth.LoginOutput{}, err
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user.ID, input.Code)
+	if err != nil {
+		return domainauth.LoginOutput{}, err
+	}
+	if !ok {
+		_, err = s.mfaRepo.IncrementMfaChallengeFailure(ctx, domainauth.IncrementMfaChallengeFailureParams{
+			ID: challenge.ID,
+		})
+		if err != nil {
+			return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+		}
+		err = s.recordLoginFailure(ctx, attemptKeys, errInvalidMfaCode)
+		s.auditLoginFailure(ctx, sharedkernel.AuditActionLoginMfa, user.ID, "", auditReasonInvalidMfaCode, err)
+		return domainauth.LoginOutput{}, err
+	}
+
+	used, err := s.mfaRepo.UseMfaChallenge(ctx, domainauth.UseMfaChallengeParams{
+		ID:     challenge.ID,
+		UsedAt: now,
+	})
+	if err != nil {
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+	if !used.Success {
+		return domainauth.LoginOutput{}, errInvalidMfaChallenge
+	}
+
+	_, err = s.loginAttemptRepo.DeleteLoginAttempt(ctx, domainauth.DeleteLoginAttemptParams(attemptKeys[0]))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to clear login attempts", "error", err)
+	}
+
+	output, err := s.issueTokens(ctx, user, sessionClient{
+		UserAgent: input.UserAgent,
+		IPAddress: input.IPAddress,
+		Platform:  input.Platform,
+		DpopJkt:   dpopJkt,
+	})
+	if err != nil {
+		if errors.Is(err, errSessionLimitReached) {
+			s.auditLoginFailure(ctx, sharedkernel.AuditActionLoginMfa, user.ID, "", auditReasonSessionLimit, err)
+		}
+		return domainauth.LoginOutput{}, err
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionLoginMfa,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  user.ID,
+		TargetUserID: user.ID,
+	})
+
+	return output, nil
+}
+
+func (s *service) EnrollTotp(ctx context.Context, input domainauth.EnrollTotpInput) (domainauth.EnrollTotpOutput, error) {
+	if err := sharedkernel.DenyImpersonation(ctx); err != nil {
+		return domainauth.EnrollTotpOutput{}, err
+	}
+
+	totp, err := s.mfaRepo.GetDetailTotp(ctx, domainauth.GetDetailTotpFilters{
+		UserID: input.UserID,
+	})
+	if err != nil && !errors.Is(err, databases.ErrNoRowFound) {
+		return domainauth.EnrollTotpOutput{}, apperror.StdUnknown(err)
+	}
+	if err == nil && totp.ConfirmedAt != nil {
+		return domainauth.EnrollTotpOutput{}, apperror.Conflict("two-factor authentication is already enabled")
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+		UserID: &input.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.EnrollTotpOutput{}, apperror.NotFound("user not found")
+		}
+		return domainauth.EnrollTotpOutput{}, apperror.StdUnknown(err)
+	}
+
+	secret := make(domainauth.TotpSecret, totpSecretSize)
+	if _, err = rand.Read(secret); err != nil {
+		return domainauth.EnrollTotpOutput{}, apperror.StdUnknown(err)
+	}
+
+	_, err = s.mfaRepo.UpsertTotp(ctx, domainauth.UpsertTotpParams{
+		UserID: user.ID,
+		Secret: secret,
+	})
+	if err != nil {
+		return domainauth.EnrollTotpOutput{}, apperror.StdUnknown(err)
+	}
+
+	issuer := s.mfaPolicy.Issuer
+	if issuer == "" {
+		issuer = defaultMfaIssuer
+	}
+
+	return domainauth.EnrollTotpOutput{
+		Secret: secret.Base32(),
+		URI:    secret.URI(issuer, user.Email),
+	}, nil
+}
+
+func (s *service) ConfirmTotp(ctx context.Context, input domainauth.ConfirmTotpInput) (domainauth.ConfirmTotpOutput, error) {
+	if err := sharedkernel.DenyImpersonation(ctx); err != nil {
+		return domainauth.ConfirmTotpOutput{}, err
+	}
+
+	totp, err := s.mfaRepo.GetDetailTotp(ctx, domainauth.GetDetailTotpFilters{
+		UserID: input.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.ConfirmTotpOutput{}, apperror.BadRequest("two-factor authentication is not enrolled")
+		}
+		return domainauth.ConfirmTotpOutput{}, apperror.StdUnknown(err)
+	}
+	if totp.ConfirmedAt != nil {
+		return domainauth.ConfirmTotpOutput{}, apperror.Conflict("two-factor authentication is already enabled")
+	}
+
+	now := time.Now().UTC()
+	step, ok := totp.Secret.Verify(strings.TrimSpace(input.Code), now)
+	if !ok {
+		return domainauth.ConfirmTotpOutput{}, errInvalidMfaCode
+	}
+
+	confirmed, err := s.mfaRepo.ConfirmTotp(ctx, domainauth.ConfirmTotpParams{
+		UserID:      input.UserID,
+		Step:        step,
+		ConfirmedAt: now,
+	})
+	if err != nil {
+		return domainauth.ConfirmTotpOutput{}, apperror.StdUnknown(err)
+	}
+	if !confirmed.Success {
+		return domainauth.ConfirmTotpOutput{}, apperror.Conflict("two-factor authentication is already enabled")
+	}
+
+	recoveryCodes, err := s.replaceRecoveryCodes(ctx, input.UserID)
+	if err != nil {
+		return domainauth.ConfirmTotpOutput{}, err
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionMfaEnable,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		TargetUserID: input.UserID,
+	})
+
+	return domainauth.ConfirmTotpOutput{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (s *service) DisableTotp(ctx context.Context, input domainauth.DisableTotpInput) (domainauth.DisableTotpOutput, error) {
+	if err := sharedkernel.DenyImpersonation(ctx); err != nil {
+		return domainauth.DisableTotpOutput{}, err
+	}
+
+	totp, err := s.mfaRepo.GetDetailTotp(ctx, domainauth.GetDetailTotpFilters{
+		UserID: input.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.DisableTotpOutput{}, apperror.NotFound("two-factor authentication is not enabled")
+		}
+		return domainauth.DisableTotpOutput{}, apperror.StdUnknown(err)
+	}
+
+	// an unconfirmed enrollment never protected the account, dropping it
+	// does not need a code
+	if totp.ConfirmedAt != nil {
+		ok, err := s.verifySecondFactor(ctx, input.UserID, input.Code)
+		if err != nil {
+			return domainauth.DisableTotpOutput{}, err
+		}
+		if !ok {
+			return domainauth.DisableTotpOutput{}, errInvalidMfaCode
+		}
+	}
+
+	_, err = s.mfaRepo.DeleteTotp(ctx, domainauth.DeleteTotpParams{
+		UserID: input.UserID,
+	})
+	if err != nil {
+		return domainauth.DisableTotpOutput{}, apperror.StdUnknown(err)
+	}
+
+	if totp.ConfirmedAt != nil {
+		s.audit(ctx, sharedkernel.AuditEntry{
+			Action:       sharedkernel.AuditActionMfaDisable,
+			Outcome:      sharedkernel.AuditOutcomeSuccess,
+			TargetUserID: input.UserID,
+		})
+	}
+
+	return domainauth.DisableTotpOutput{
+		Success: true,
+	}, nil
+}
+
+func (s *service) RegenerateRecoveryCodes(ctx context.Context, input domainauth.RegenerateRecoveryCodesInput) (domainauth.RegenerateRecoveryCodesOutput, error) {
+	if err := sharedkernel.DenyImpersonation(ctx); err != nil {
+		return domainauth.RegenerateRecoveryCodesOutput{}, err
+	}
+
+	ok, err := s.verifySecondFactor(ctx, input.UserID, input.Code)
+	if err != nil {
+		return domainauth.RegenerateRecoveryCodesOutput{}, err
+	}
+	if !ok {
+		return domainauth.RegenerateRecoveryCodesOutput{}, errInvalidMfaCode
+	}
+
+	recoveryCodes, err := s.replaceRecoveryCodes(ctx, input.UserID)
+	if err != nil {
+		return domainauth.RegenerateRecoveryCodesOutput{}, err
+	}
+
+	return domainauth.RegenerateRecoveryCodesOutput{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// verifySecondFactor accepts a TOTP code of a confirmed secret, each time
+// step once, or an unused recovery code, which is consumed.
+func (s *service) verifySecondFactor(ctx context.Context, userID, code string) (bool, error) {
+	totp, err := s.mfaRepo.GetDetailTotp(ctx, domainauth.GetDetailTotpFilters{
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return false, nil
+		}
+		return false, apperror.StdUnknown(err)
+	}
+	if totp.ConfirmedAt == nil {
+		return false, nil
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == domainauth.TotpDigits {
+		step, ok := totp.Secret.Verify(code, time.Now().UTC())
+		if !ok {
+			return false, nil
+		}
+
+		used, err := s.mfaRepo.UseTotpStep(ctx, domainauth.UseTotpStepParams{
+			UserID: userID,
+			Step:   step,
+		})
+		if err != nil {
+			return false, apperror.StdUnknown(err)
+		}
+		return used.Success, nil
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, domainauth.UseRecoveryCodeParams{
+		UserID: userID,
+		Code:   normalizeRecoveryCode(code),
+		UsedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return false, apperror.StdUnknown(err)
+	}
+	if used.Success {
+		slog.InfoContext(ctx, "mfa recovery code used", "user_id", userID)
+	}
+	return used.Success, nil
+}
+
+// replaceRecoveryCodes generates a new set of recovery codes, formatted as
+// "xxxxx-xxxxx", invalidating the previous ones.
+func (s *service) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	recoveryCodes := make([]string, 0, recoveryCodeCount)
+	normalized := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, apperror.StdUnknown(err)
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:recoveryCodeLength]
+		recoveryCodes = append(recoveryCodes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		normalized = append(normalized, code)
+	}
+
+	_, err := s.mfaRepo.ReplaceRecoveryCodes(ctx, domainauth.ReplaceRecoveryCodesParams{
+		UserID: userID,
+		Codes:  normalized,
+	})
+	if err != nil {
+		return nil, apperror.StdUnknown(err)
+	}
+
+	return recoveryCodes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
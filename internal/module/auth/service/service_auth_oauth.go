@@ -0,0 +1,255 @@
+package authservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+var errInvalidOauthClient = apperror.Unauthorized("invalid client credentials")
+
+func (s *service) AuthenticateOauthClient(ctx context.Context, input domainauth.AuthenticateOauthClientInput) (domainauth.AuthenticateOauthClientOutput, error) {
+	client, err := s.oauthClientRepo.GetDetailOauthClient(ctx, domainauth.GetDetailOauthClientFilters{
+		ClientID: input.ClientID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.AuthenticateOauthClientOutput{}, errInvalidOauthClient
+		}
+		return domainauth.AuthenticateOauthClientOutput{}, apperror.StdUnknown(err)
+	}
+
+	// compare digests so neither the content nor the length of the secret
+	// leaks through timing
+	given := sha256.Sum256([]byte(input.ClientSecret))
+	expected := sha256.Sum256([]byte(client.OauthClient.ClientSecret))
+	if subtle.ConstantTimeCompare(given[:], expected[:]) != 1 {
+		return domainauth.AuthenticateOauthClientOutput{}, errInvalidOauthClient
+	}
+
+	return domainauth.AuthenticateOauthClientOutput{
+		ClientID: client.OauthClient.ClientID,
+	}, nil
+}
+
+// IntrospectToken ignores the type hint: access tokens and API keys are
+// recognised by their format, anything else is looked up as refresh token.
+func (s *service) IntrospectToken(ctx context.Context, input domainauth.IntrospectTokenInput) (domainauth.IntrospectTokenOutput, error) {
+	// the resource server asking checks the DPoP proof itself against cnf.jkt
+	validated, err := s.validateToken(ctx, domainauth.ValidateTokenInput{
+		Token:     input.Token,
+		IPAddress: input.IPAddress,
+	}, false)
+	if err != nil {
+		return domainauth.IntrospectTokenOutput{}, err
+	}
+	if validated.Valid {
+		return domainauth.IntrospectTokenOutput{
+			Active:  true,
+			Payload: validated.Payload,
+		}, nil
+	}
+	if strings.HasPrefix(input.Token, domainauth.ApiKeyPrefix) {
+		return domainauth.IntrospectTokenOutput{Active: false}, nil
+	}
+
+	return s.introspectRefreshToken(ctx, input.Token)
+}
+
+// introspectRefreshToken applies the checks of RefreshToken without
+// rotating the token.
+func (s *service) introspectRefreshToken(ctx context.Context, token string) (domainauth.IntrospectTokenOutput, error) {
+	now := time.Now().UTC()
+	inactive := domainauth.IntrospectTokenOutput{Active: false}
+
+	tokenData, err := s.authRepo.GetDetailToken(ctx, domainauth.GetDetailTokenFilters{
+		Token: &token,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return inactive, nil
+		}
+		return domainauth.IntrospectTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if tokenData.TokenType != domainauth.TokenTypeRefresh ||
+		tokenData.Status != domainauth.TokenStatusActive ||
+		now.After(tokenData.ExpiresAt) {
+		return inactive, nil
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+		UserID: &tokenData.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return inactive, nil
+		}
+		return domainauth.IntrospectTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if user.Status.CanLogin() != nil {
+		return inactive, nil
+	}
+
+	session, err := s.authRepo.GetDetailSession(ctx, domainauth.GetDetailSessionFilters{
+		FamilyID: &tokenData.FamilyID,
+		UserID:   &tokenData.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return inactive, nil
+		}
+		return domainauth.IntrospectTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if session.RevokedAt != nil {
+		return inactive, nil
+	}
+
+	tokenPolicy, err := s.getTokenPolicy(ctx, user.Role)
+	if err != nil {
+		return domainauth.IntrospectTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if tokenPolicy.TokenPolicy.SessionExpired(session.Session, now) {
+		return inactive, nil
+	}
+
+	payload := domainauth.TokenPayload{
+		UserID:    user.ID,
+		SessionID: session.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		TokenType: domainauth.TokenTypeRefresh,
+		IssuedAt:  tokenData.CreatedAt,
+		ExpiresAt: tokenData.ExpiresAt,
+	}
+	if tokenData.DpopJkt != nil {
+		payload.DpopJkt = *tokenData.DpopJkt
+	}
+
+	return domainauth.IntrospectTokenOutput{
+		Active:  true,
+		Payload: &payload,
+	}, nil
+}
+
+func (s *service) RevokeToken(ctx context.Context, input domainauth.RevokeTokenInput) (domainauth.RevokeTokenOutput, error) {
+	if strings.HasPrefix(input.Token, domainauth.ApiKeyPrefix) {
+		return s.revokeApiKeyToken(ctx, input.Token)
+	}
+
+	tokenData, err := s.authRepo.GetDetailToken(ctx, domainauth.GetDetailTokenFilters{
+		Token: &input.Token,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.RevokeTokenOutput{Success: false, Message: "Token not found"}, nil
+		}
+		return domainauth.RevokeTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if tokenData.Status != domainauth.TokenStatusActive {
+		return domainauth.RevokeTokenOutput{Success: false, Message: "Token already revoked"}, nil
+	}
+
+	// RFC 7009 section 2.1: revoking a refresh token also invalidates the
+	// access tokens of the same grant, which is the whole session here
+	if tokenData.TokenType == domainauth.TokenTypeRefresh {
+		result, err := s.authRepo.RevokeTokenFamily(ctx, domainauth.RevokeTokenFamilyParams{
+			FamilyID: tokenData.FamilyID,
+			UserID:   tokenData.UserID,
+		})
+		if err != nil {
+			return domainauth.RevokeTokenOutput{}, apperror.StdUnknown(err)
+		}
+
+		slog.InfoContext(ctx, "Refresh token revoked, session ended",
+			"user_id", tokenData.UserID,
+			"revoked_count", result.RevokedCount,
+		)
+		s.audit(ctx, sharedkernel.AuditEntry{
+			Action:       sharedkernel.AuditActionTokenRevoke,
+			Outcome:      sharedkernel.AuditOutcomeSuccess,
+			TargetUserID: tokenData.UserID,
+			Details:      map[string]string{"token_type": string(tokenData.TokenType)},
+		})
+
+		return domainauth.RevokeTokenOutput{
+			Success: true,
+			Message: "Token revoked successfully",
+		}, nil
+	}
+
+	result, err := s.authRepo.RevokeToken(ctx, domainauth.RevokeTokenParams{
+		Token:  input.Token,
+		UserID: tokenData.UserID,
+	})
+	if err != nil {
+		return domainauth.RevokeTokenOutput{}, apperror.StdUnknown(err)
+	}
+
+	if !result.Success {
+		return domainauth.RevokeTokenOutput{Success: false, Message: "Token already revoked"}, nil
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionTokenRevoke,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		TargetUserID: tokenData.UserID,
+		Details:      map[string]string{"token_type": string(tokenData.TokenType)},
+	})
+
+	return domainauth.RevokeTokenOutput{
+		Success: true,
+		Message: "Token revoked successfully",
+	}, nil
+}
+
+// revokeApiKeyToken is RevokeToken for a token starting with
+// domainauth.ApiKeyPrefix, it revokes the key on behalf of its owner.
+func (s *service) revokeApiKeyToken(ctx context.Context, key string) (domainauth.RevokeTokenOutput, error) {
+	apiKey, err := s.authRepo.GetDetailApiKey(ctx, domainauth.GetDetailApiKeyFilters{
+		Key: &key,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.RevokeTokenOutput{Success: false, Message: "Token not found"}, nil
+		}
+		return domainauth.RevokeTokenOutput{}, apperror.StdUnknown(err)
+	}
+
+	result, err := s.authRepo.RevokeApiKey(ctx, domainauth.RevokeApiKeyParams{
+		ApiKeyID:  apiKey.ID,
+		UserID:    apiKey.UserID,
+		RevokedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return domainauth.RevokeTokenOutput{}, apperror.StdUnknown(err)
+	}
+	if !result.Success {
+		return domainauth.RevokeTokenOutput{Success: false, Message: "Token already revoked"}, nil
+	}
+
+	slog.InfoContext(ctx, "API key revoked",
+		"user_id", apiKey.UserID,
+		"api_key_id", apiKey.ID,
+	)
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionApiKeyRevoke,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		TargetUserID: apiKey.UserID,
+		Details:      map[string]string{"api_key_id": apiKey.ID},
+	})
+
+	return domainauth.RevokeTokenOutput{
+		Success: true,
+		Message: "Token revoked successfully",
+	}, nil
+}
@@ -0,0 +1,178 @@
+package authservice_test
+
+import (
+	"context"
+	"testing"
+
+	"go-bootstrap/internal/config"
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	"go-bootstrap/internal/infrastructure"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestService_AuthenticateOauthClient(t *testing.T) {
+	ctx := context.Background()
+	oauthClientRepo := authrepository.NewOauthClientRepository(infrastructure.NewOauthClientsFromConfig(config.Oauth{
+		Clients: []config.OauthClient{
+			{ClientID: "gateway", ClientSecret: "gateway-secret"},
+			{ClientID: "no-secret"},
+		},
+	}))
+	svc := newTestService(t, withOauthClientRepo(oauthClientRepo))
+
+	output, err := svc.AuthenticateOauthClient(ctx, domainauth.AuthenticateOauthClientInput{ClientID: "gateway", ClientSecret: "gateway-secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "gateway", output.ClientID)
+
+	for name, input := range map[string]domainauth.AuthenticateOauthClientInput{
+		"wrong secret":      {ClientID: "gateway", ClientSecret: "gateway-secre"},
+		"unknown client":    {ClientID: "other", ClientSecret: "gateway-secret"},
+		"client w/o secret": {ClientID: "no-secret"},
+		"empty credentials": {},
+	} {
+		_, err = svc.AuthenticateOauthClient(ctx, input)
+		assert.True(t, apperror.IsUnauthorized(err), "%s: expected unauthorized, got %v", name, err)
+	}
+}
+
+func TestService_IntrospectToken(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	setup := func() (*fakeUserRepo, domainauth.AuthService) {
+		userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+			ID:           "1",
+			Email:        "user@example.com",
+			PasswordHash: string(passwordHash),
+			Role:         domainauth.UserRoleUser,
+			Status:       sharedkernel.UserStatusActive,
+		}}
+		svc := newTestService(t, withUserRepo(userRepo))
+		return userRepo, svc
+	}
+	login := func(t *testing.T, svc domainauth.AuthService) domainauth.LoginOutput {
+		t.Helper()
+		output, err := svc.Login(ctx, domainauth.LoginInput{Email: "user@example.com", Password: "correct-password"})
+		require.NoError(t, err)
+		return output
+	}
+	introspect := func(t *testing.T, svc domainauth.AuthService, token string) domainauth.IntrospectTokenOutput {
+		t.Helper()
+		output, err := svc.IntrospectToken(ctx, domainauth.IntrospectTokenInput{Token: token})
+		require.NoError(t, err)
+		return output
+	}
+
+	t.Run("access and refresh tokens describe their session", func(t *testing.T) {
+		_, svc := setup()
+		tokens := login(t, svc)
+
+		access := introspect(t, svc, tokens.AccessToken)
+		require.True(t, access.Active)
+		assert.Equal(t, domainauth.TokenTypeAccess, access.Payload.TokenType)
+		assert.Equal(t, "1", access.Payload.UserID)
+		assert.Equal(t, "user@example.com", access.Payload.Email)
+
+		refresh := introspect(t, svc, tokens.RefreshToken)
+		require.True(t, refresh.Active)
+		assert.Equal(t, domainauth.TokenTypeRefresh, refresh.Payload.TokenType)
+		assert.Equal(t, access.Payload.SessionID, refresh.Payload.SessionID)
+		assert.False(t, refresh.Payload.ExpiresAt.IsZero())
+
+		assert.False(t, introspect(t, svc, "unknown").Active)
+		assert.Nil(t, introspect(t, svc, "unknown").Payload)
+	})
+
+	t.Run("rotated refresh token is inactive", func(t *testing.T) {
+		_, svc := setup()
+		tokens := login(t, svc)
+
+		_, err := svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: tokens.RefreshToken})
+		require.NoError(t, err)
+		assert.False(t, introspect(t, svc, tokens.RefreshToken).Active)
+	})
+
+	t.Run("suspended owner makes every token inactive", func(t *testing.T) {
+		userRepo, svc := setup()
+		tokens := login(t, svc)
+
+		userRepo.user.Status = sharedkernel.UserStatusSuspended
+		assert.False(t, introspect(t, svc, tokens.AccessToken).Active)
+		assert.False(t, introspect(t, svc, tokens.RefreshToken).Active)
+	})
+
+	t.Run("revoking a refresh token ends the session", func(t *testing.T) {
+		_, svc := setup()
+		tokens := login(t, svc)
+
+		revoked, err := svc.RevokeToken(ctx, domainauth.RevokeTokenInput{Token: tokens.RefreshToken, TokenTypeHint: "refresh_token"})
+		require.NoError(t, err)
+		assert.True(t, revoked.Success)
+		assert.False(t, introspect(t, svc, tokens.RefreshToken).Active)
+		assert.False(t, introspect(t, svc, tokens.AccessToken).Active, "the access token of the same session is revoked too")
+
+		again, err := svc.RevokeToken(ctx, domainauth.RevokeTokenInput{Token: tokens.RefreshToken})
+		require.NoError(t, err)
+		assert.False(t, again.Success)
+
+		unknown, err := svc.RevokeToken(ctx, domainauth.RevokeTokenInput{Token: "unknown"})
+		require.NoError(t, err, "an unknown token is not an error")
+		assert.False(t, unknown.Success)
+	})
+
+	t.Run("revoking an access token keeps the refresh token", func(t *testing.T) {
+		_, svc := setup()
+		tokens := login(t, svc)
+
+		revoked, err := svc.RevokeToken(ctx, domainauth.RevokeTokenInput{Token: tokens.AccessToken})
+		require.NoError(t, err)
+		assert.True(t, revoked.Success)
+		assert.False(t, introspect(t, svc, tokens.AccessToken).Active)
+		assert.True(t, introspect(t, svc, tokens.RefreshToken).Active)
+	})
+}
+
+func TestService_IntrospectToken_ApiKey(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+		ID:     "1",
+		Email:  "service@example.com",
+		Role:   domainauth.UserRoleAdmin,
+		Status: sharedkernel.UserStatusActive,
+	}}
+	svc := newTestService(t, withAuthRepo(newFakeApiKeyRepo()), withUserRepo(userRepo))
+
+	created, err := svc.CreateApiKey(ctx, domainauth.CreateApiKeyInput{
+		UserID:     "1",
+		Name:       "gateway",
+		Scopes:     []string{"users:list"},
+		AllowedIPs: []string{"10.0.0.0/8"},
+	})
+	require.NoError(t, err)
+
+	output, err := svc.IntrospectToken(ctx, domainauth.IntrospectTokenInput{Token: created.Key, IPAddress: "10.1.2.3"})
+	require.NoError(t, err)
+	require.True(t, output.Active)
+	assert.Equal(t, domainauth.TokenTypeApiKey, output.Payload.TokenType)
+	assert.Equal(t, created.ApiKey.ID, output.Payload.ApiKeyID)
+	assert.Equal(t, []string{"users:list"}, output.Payload.Scopes)
+
+	output, err = svc.IntrospectToken(ctx, domainauth.IntrospectTokenInput{Token: created.Key})
+	require.NoError(t, err)
+	assert.False(t, output.Active, "a key with an allowlist needs the client ip")
+
+	revoked, err := svc.RevokeToken(ctx, domainauth.RevokeTokenInput{Token: created.Key})
+	require.NoError(t, err)
+	assert.True(t, revoked.Success)
+
+	output, err = svc.IntrospectToken(ctx, domainauth.IntrospectTokenInput{Token: created.Key, IPAddress: "10.1.2.3"})
+	require.NoError(t, err)
+	assert.False(t, output.Active)
+}
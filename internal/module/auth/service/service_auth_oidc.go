@@ -0,0 +1,270 @@
+package authservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+// oidcStateTTL is how long the user may take at the provider.
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	errOidcProviderNotFound = apperror.NotFound("oidc provider not found")
+	errInvalidOidcState     = apperror.BadRequest("invalid or expired oidc state")
+	errOidcLoginRejected    = apperror.BadRequest("oidc login failed")
+)
+
+func (s *service) StartOidcLogin(ctx context.Context, input domainauth.StartOidcLoginInput) (domainauth.StartOidcLoginOutput, error) {
+	state, err := s.generateToken()
+	if err != nil {
+		return domainauth.StartOidcLoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	nonce, err := s.generateToken()
+	if err != nil {
+		return domainauth.StartOidcLoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	codeVerifier, codeChallenge, err := generatePkce()
+	if err != nil {
+		return domainauth.StartOidcLoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	authorizationUrl, err := s.oidcRepo.GetOidcAuthorizationUrl(ctx, domainauth.GetOidcAuthorizationUrlParams{
+		Provider:      input.Provider,
+		State:         state,
+		Nonce:         nonce,
+		CodeChallenge: codeChallenge,
+	})
+	if err != nil {
+		if errors.Is(err, domainauth.ErrOidcProviderNotFound) {
+			return domainauth.StartOidcLoginOutput{}, errOidcProviderNotFound
+		}
+		return domainauth.StartOidcLoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	_, err = s.identityRepo.CreateOidcState(ctx, domainauth.CreateOidcStateParams{
+		Provider:     input.Provider,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().UTC().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return domainauth.StartOidcLoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	return domainauth.StartOidcLoginOutput{
+		AuthorizationUrl: authorizationUrl.Url,
+		State:            state,
+		ExpiresIn:        int64(oidcStateTTL.Seconds()),
+	}, nil
+}
+
+func (s *service) LoginOidc(ctx context.Context, input domainauth.LoginOidcInput) (domainauth.LoginOutput, error) {
+	state, err := s.identityRepo.ConsumeOidcState(ctx, domainauth.ConsumeOidcStateParams{
+		State: input.State,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.LoginOutput{}, errInvalidOidcState
+		}
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+	if state.Provider != input.Provider || !state.ExpiresAt.After(time.Now().UTC()) {
+		return domainauth.LoginOutput{}, errInvalidOidcState
+	}
+
+	exchanged, err := s.oidcRepo.ExchangeOidcCode(ctx, domainauth.ExchangeOidcCodeParams{
+		Provider:     input.Provider,
+		Code:         input.Code,
+		CodeVerifier: state.CodeVerifier,
+		Nonce:        state.Nonce,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domainauth.ErrOidcLoginRejected):
+			slog.WarnContext(ctx, "oidc login rejected", "provider", input.Provider, "error", err)
+			return domainauth.LoginOutput{}, errOidcLoginRejected
+		case errors.Is(err, domainauth.ErrOidcProviderNotFound):
+			return domainauth.LoginOutput{}, errOidcProviderNotFound
+		default:
+			return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+		}
+	}
+
+	user, err := s.resolveOidcUser(ctx, input.Provider, exchanged.Claims)
+	if err != nil {
+		return domainauth.LoginOutput{}, err
+	}
+
+	if err = user.Status.CanLogin(); err != nil {
+		s.auditLoginFailure(ctx, sharedkernel.AuditActionLoginOidc, user.ID, "", "user_"+string(user.Status), err)
+		return domainauth.LoginOutput{}, apperror.BadRequest(err.Error())
+	}
+
+	mfaRequired, err := s.isMfaRequired(ctx, user.ID)
+	if err != nil {
+		return domainauth.LoginOutput{}, apperror.StdUnknown(err)
+	}
+
+	entry := sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionLoginOidc,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  user.ID,
+		TargetUserID: user.ID,
+		Details:      map[string]string{"provider": input.Provider},
+	}
+	if mfaRequired {
+		entry.Details["mfa_required"] = "true"
+		s.audit(ctx, entry)
+		return s.createMfaChallenge(ctx, user.ID)
+	}
+
+	output, err := s.issueTokens(ctx, user, sessionClient{
+		UserAgent: input.UserAgent,
+		IPAddress: input.IPAddress,
+		Platform:  input.Platform,
+	})
+	if err != nil {
+		if errors.Is(err, errSessionLimitReached) {
+			s.auditLoginFailure(ctx, entry.Action, user.ID, "", auditReasonSessionLimit, err)
+		}
+		return domainauth.LoginOutput{}, err
+	}
+
+	s.audit(ctx, entry)
+
+	return output, nil
+}
+
+// resolveOidcUser returns the user linked to the external identity. An
+// identity seen for the first time is linked to the user with the same
+// email, or a new user is created; both require the provider to have
+// verified the email. Linking a user that never verified its email
+// activates it with a random password, the provider proved the email
+// belongs to the one signing in, not necessarily to who registered it.
+func (s *service) resolveOidcUser(ctx context.Context, provider string, claims domainauth.OidcClaims) (domainauth.GetDetailUserResult, error) {
+	identity, err := s.identityRepo.GetDetailIdentity(ctx, domainauth.GetDetailIdentityFilters{
+		Provider: provider,
+		Subject:  claims.Subject,
+	})
+	if err == nil {
+		user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+			UserID: &identity.UserID,
+		})
+		if err != nil {
+			// the identity outlives a soft-deleted user so a restore keeps the link
+			if errors.Is(err, databases.ErrNoRowFound) {
+				return domainauth.GetDetailUserResult{}, apperror.Forbidden("the linked account has been deleted")
+			}
+			return domainauth.GetDetailUserResult{}, apperror.StdUnknown(err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, databases.ErrNoRowFound) {
+		return domainauth.GetDetailUserResult{}, apperror.StdUnknown(err)
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return domainauth.GetDetailUserResult{}, apperror.BadRequest("the provider did not return a verified email address")
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+		Email: &claims.Email,
+	})
+	if err == nil {
+		params := domainauth.CreateIdentityParams{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    claims.Email,
+		}
+		if user.Status.IsPendingVerification() {
+			params.ActivatePasswordHash, err = s.randomPasswordHash()
+			if err != nil {
+				return domainauth.GetDetailUserResult{}, apperror.StdUnknown(err)
+			}
+		}
+
+		_, err = s.identityRepo.CreateIdentity(ctx, params)
+		if err != nil {
+			return domainauth.GetDetailUserResult{}, apperror.StdUnknown(err)
+		}
+		slog.InfoContext(ctx, "oidc identity linked to existing user", "provider", provider, "user_id", user.ID)
+
+		if params.ActivatePasswordHash != "" {
+			user.Status = sharedkernel.UserStatusActive
+			user.PasswordHash = params.ActivatePasswordHash
+		}
+		return user, nil
+	}
+	if !errors.Is(err, databases.ErrNoRowFound) {
+		return domainauth.GetDetailUserResult{}, apperror.StdUnknown(err)
+	}
+
+	passwordHash, err := s.randomPasswordHash()
+	if err != nil {
+		return domainauth.GetDetailUserResult{}, apperror.StdUnknown(err)
+	}
+
+	name := claims.Name
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	created, err := s.identityRepo.CreateUserWithIdentity(ctx, domainauth.CreateUserWithIdentityParams{
+		Email:        claims.Email,
+		Name:         name,
+		PasswordHash: passwordHash,
+		Provider:     provider,
+		Subject:      claims.Subject,
+	})
+	if err != nil {
+		return domainauth.GetDetailUserResult{}, apperror.StdUnknown(err)
+	}
+	slog.InfoContext(ctx, "user created from oidc identity", "provider", provider, "user_id", created.UserID)
+
+	user, err = s.userRepo.GetDetailUser(ctx, domainauth.GetDetailUserFilters{
+		UserID: &created.UserID,
+	})
+	if err != nil {
+		return domainauth.GetDetailUserResult{}, apperror.StdUnknown(err)
+	}
+	return user, nil
+}
+
+// randomPasswordHash hashes a random password for a user that signs in
+// through the provider only, it fills the column until they set one with
+// forgot-password.
+func (s *service) randomPasswordHash() (string, error) {
+	password, err := s.generateToken()
+	if err != nil {
+		return "", err
+	}
+	return s.passwordHasher.Hash(password)
+}
+
+// generatePkce returns an RFC 7636 code verifier and its S256 challenge.
+func generatePkce() (verifier string, challenge string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	verifier = base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
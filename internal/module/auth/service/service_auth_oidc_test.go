@@ -0,0 +1,374 @@
+package authservice_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-bootstrap/internal/config"
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	"go-bootstrap/internal/infrastructure"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubIdp is a minimal OpenID provider: discovery, JWKS and a token
+// endpoint that checks the PKCE verifier of the code it handed out.
+type stubIdp struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	grants map[string]stubIdpGrant // by code
+
+	subject       string
+	email         string
+	emailVerified bool
+	nonce         string // overrides the nonce of the ID token when set
+}
+
+type stubIdpGrant struct {
+	codeChallenge string
+	nonce         string
+	redirectURI   string
+}
+
+func newStubIdp(t *testing.T) *stubIdp {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &stubIdp{key: key, grants: map[string]stubIdpGrant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "stub-key",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("POST /token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// signIn plays the user at the authorization endpoint and returns the
+// code and state the provider redirects back with.
+func (idp *stubIdp) signIn(t *testing.T, authorizationUrl string) (code string, state string) {
+	t.Helper()
+	parsed, err := url.Parse(authorizationUrl)
+	require.NoError(t, err)
+	query := parsed.Query()
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+	require.Equal(t, "client-1", query.Get("client_id"))
+
+	code = "code-" + strconv.Itoa(len(idp.grants)+1)
+	idp.grants[code] = stubIdpGrant{
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		redirectURI:   query.Get("redirect_uri"),
+	}
+	return code, query.Get("state")
+}
+
+func (idp *stubIdp) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, _ := r.BasicAuth()
+	grant, ok := idp.grants[r.PostFormValue("code")]
+	delete(idp.grants, r.PostFormValue("code"))
+	verifierSum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || clientID != "client-1" || clientSecret != "secret-1" ||
+		r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("redirect_uri") != grant.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(verifierSum[:]) != grant.codeChallenge {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	nonce := grant.nonce
+	if idp.nonce != "" {
+		nonce = idp.nonce
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            "client-1",
+		"sub":            idp.subject,
+		"email":          idp.email,
+		"email_verified": idp.emailVerified,
+		"name":           "External User",
+		"nonce":          nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "stub-key"
+	idToken, err := token.SignedString(idp.key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+// fakeUserStore backs the user and the identity repositories so users
+// created from an identity can be read back.
+type fakeUserStore struct {
+	domainauth.UserRepositoryDatastore
+	domainauth.AuthRepositoryIdentity
+	users      map[string]domainauth.GetDetailUserResult
+	identities map[string]domainauth.GetDetailIdentityResult // by provider and subject
+	states     map[string]domainauth.ConsumeOidcStateResult
+}
+
+func newFakeUserStore(users ...domainauth.GetDetailUserResult) *fakeUserStore {
+	store := &fakeUserStore{
+		users:      map[string]domainauth.GetDetailUserResult{},
+		identities: map[string]domainauth.GetDetailIdentityResult{},
+		states:     map[string]domainauth.ConsumeOidcStateResult{},
+	}
+	for _, v := range users {
+		store.users[v.ID] = v
+	}
+	return store
+}
+
+func (f *fakeUserStore) GetDetailUser(ctx context.Context, filters domainauth.GetDetailUserFilters) (domainauth.GetDetailUserResult, error) {
+	for _, v := range f.users {
+		if (filters.UserID == nil || v.ID == *filters.UserID) && (filters.Email == nil || v.Email == *filters.Email) {
+			return v, nil
+		}
+	}
+	return domainauth.GetDetailUserResult{}, databases.ErrNoRowFound
+}
+
+func (f *fakeUserStore) CreateOidcState(ctx context.Context, params domainauth.CreateOidcStateParams) (domainauth.CreateOidcStateResult, error) {
+	f.states[params.State] = domainauth.ConsumeOidcStateResult{
+		Provider:     params.Provider,
+		Nonce:        params.Nonce,
+		CodeVerifier: params.CodeVerifier,
+		ExpiresAt:    params.ExpiresAt,
+	}
+	return domainauth.CreateOidcStateResult{ID: strconv.Itoa(len(f.states))}, nil
+}
+
+func (f *fakeUserStore) ConsumeOidcState(ctx context.Context, params domainauth.ConsumeOidcStateParams) (domainauth.ConsumeOidcStateResult, error) {
+	state, ok := f.states[params.State]
+	if !ok {
+		return domainauth.ConsumeOidcStateResult{}, databases.ErrNoRowFound
+	}
+	delete(f.states, params.State)
+	return state, nil
+}
+
+func (f *fakeUserStore) GetDetailIdentity(ctx context.Context, filters domainauth.GetDetailIdentityFilters) (domainauth.GetDetailIdentityResult, error) {
+	identity, ok := f.identities[filters.Provider+"/"+filters.Subject]
+	if !ok {
+		return domainauth.GetDetailIdentityResult{}, databases.ErrNoRowFound
+	}
+	return identity, nil
+}
+
+func (f *fakeUserStore) CreateIdentity(ctx context.Context, params domainauth.CreateIdentityParams) (domainauth.CreateIdentityResult, error) {
+	if user, ok := f.users[params.UserID]; ok && params.ActivatePasswordHash != "" && user.Status.IsPendingVerification() {
+		user.Status = sharedkernel.UserStatusActive
+		user.PasswordHash = params.ActivatePasswordHash
+		f.users[params.UserID] = user
+	}
+	id := strconv.Itoa(len(f.identities) + 1)
+	f.identities[params.Provider+"/"+params.Subject] = domainauth.GetDetailIdentityResult{
+		ID:       id,
+		UserID:   params.UserID,
+		Provider: params.Provider,
+		Subject:  params.Subject,
+		Email:    params.Email,
+	}
+	return domainauth.CreateIdentityResult{ID: id}, nil
+}
+
+func (f *fakeUserStore) CreateUserWithIdentity(ctx context.Context, params domainauth.CreateUserWithIdentityParams) (domainauth.CreateUserWithIdentityResult, error) {
+	userID := strconv.Itoa(len(f.users) + 100)
+	f.users[userID] = domainauth.GetDetailUserResult{
+		ID:           userID,
+		Email:        params.Email,
+		PasswordHash: params.PasswordHash,
+		Name:         params.Name,
+		Role:         domainauth.UserRoleUser,
+		Status:       sharedkernel.UserStatusActive,
+	}
+	identity, err := f.CreateIdentity(ctx, domainauth.CreateIdentityParams{
+		UserID:   userID,
+		Provider: params.Provider,
+		Subject:  params.Subject,
+		Email:    params.Email,
+	})
+	if err != nil {
+		return domainauth.CreateUserWithIdentityResult{}, err
+	}
+	return domainauth.CreateUserWithIdentityResult{UserID: userID, IdentityID: identity.ID}, nil
+}
+
+func TestService_LoginOidc(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, users ...domainauth.GetDetailUserResult) (*stubIdp, *fakeUserStore, domainauth.AuthService) {
+		t.Helper()
+		idp := newStubIdp(t)
+		idp.subject = "external-1"
+		idp.email = "external@example.com"
+		idp.emailVerified = true
+
+		store := newFakeUserStore(users...)
+		oidcRepo := authrepository.NewOidcRepository(infrastructure.NewOidcProvidersFromConfig(config.Oidc{
+			Providers: []config.OidcProvider{{
+				Name:         "stub",
+				Issuer:       idp.server.URL,
+				ClientID:     "client-1",
+				ClientSecret: "secret-1",
+				RedirectURL:  "https://app.example.com/api/v1/auth/oidc/stub/callback",
+			}},
+		}), idp.server.Client())
+		svc := newTestService(t, withUserRepo(store), withIdentityRepo(store), withOidcRepo(oidcRepo))
+		return idp, store, svc
+	}
+	login := func(t *testing.T, idp *stubIdp, svc domainauth.AuthService) (domainauth.LoginOutput, error) {
+		t.Helper()
+		started, err := svc.StartOidcLogin(ctx, domainauth.StartOidcLoginInput{Provider: "stub"})
+		require.NoError(t, err)
+		code, state := idp.signIn(t, started.AuthorizationUrl)
+		assert.Equal(t, started.State, state)
+		return svc.LoginOidc(ctx, domainauth.LoginOidcInput{Provider: "stub", Code: code, State: state})
+	}
+
+	t.Run("first login creates a user, the next one reuses it", func(t *testing.T) {
+		idp, store, svc := setup(t)
+
+		output, err := login(t, idp, svc)
+		require.NoError(t, err)
+		assert.NotEmpty(t, output.AccessToken)
+		require.Len(t, store.users, 1)
+		require.Len(t, store.identities, 1)
+
+		_, err = login(t, idp, svc)
+		require.NoError(t, err)
+		assert.Len(t, store.users, 1)
+	})
+
+	t.Run("identity is linked to the user with the same verified email", func(t *testing.T) {
+		idp, store, svc := setup(t, domainauth.GetDetailUserResult{
+			ID:     "1",
+			Email:  "external@example.com",
+			Role:   domainauth.UserRoleUser,
+			Status: sharedkernel.UserStatusActive,
+		})
+
+		_, err := login(t, idp, svc)
+		require.NoError(t, err)
+		assert.Len(t, store.users, 1)
+		assert.Equal(t, "1", store.identities["stub/external-1"].UserID)
+	})
+
+	t.Run("linking an unverified user activates it with a new password", func(t *testing.T) {
+		idp, store, svc := setup(t, domainauth.GetDetailUserResult{
+			ID:           "1",
+			Email:        "external@example.com",
+			PasswordHash: "hash-chosen-by-whoever-registered",
+			Role:         domainauth.UserRoleUser,
+			Status:       sharedkernel.UserStatusPendingVerification,
+		})
+
+		output, err := login(t, idp, svc)
+		require.NoError(t, err)
+		assert.NotEmpty(t, output.AccessToken)
+		assert.Equal(t, "1", store.identities["stub/external-1"].UserID)
+		assert.Equal(t, sharedkernel.UserStatusActive, store.users["1"].Status)
+		assert.NotEqual(t, "hash-chosen-by-whoever-registered", store.users["1"].PasswordHash)
+	})
+
+	t.Run("linking a verified user keeps its password", func(t *testing.T) {
+		idp, store, svc := setup(t, domainauth.GetDetailUserResult{
+			ID:           "1",
+			Email:        "external@example.com",
+			PasswordHash: "hash",
+			Role:         domainauth.UserRoleUser,
+			Status:       sharedkernel.UserStatusActive,
+		})
+
+		_, err := login(t, idp, svc)
+		require.NoError(t, err)
+		assert.Equal(t, "hash", store.users["1"].PasswordHash)
+	})
+
+	t.Run("unverified email is neither linked nor registered", func(t *testing.T) {
+		idp, store, svc := setup(t, domainauth.GetDetailUserResult{ID: "1", Email: "external@example.com"})
+		idp.emailVerified = false
+
+		_, err := login(t, idp, svc)
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+		assert.Empty(t, store.identities)
+	})
+
+	t.Run("state is single use and bound to the provider", func(t *testing.T) {
+		idp, _, svc := setup(t)
+
+		started, err := svc.StartOidcLogin(ctx, domainauth.StartOidcLoginInput{Provider: "stub"})
+		require.NoError(t, err)
+		code, state := idp.signIn(t, started.AuthorizationUrl)
+
+		_, err = svc.LoginOidc(ctx, domainauth.LoginOidcInput{Provider: "other", Code: code, State: state})
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+		_, err = svc.LoginOidc(ctx, domainauth.LoginOidcInput{Provider: "stub", Code: code, State: state})
+		assert.True(t, apperror.IsBadRequest(err), "a consumed state must be rejected, got %v", err)
+	})
+
+	t.Run("id token with another nonce is rejected", func(t *testing.T) {
+		idp, store, svc := setup(t)
+		idp.nonce = "replayed-nonce"
+
+		_, err := login(t, idp, svc)
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+		assert.Empty(t, store.users)
+	})
+
+	t.Run("suspended user cannot sign in", func(t *testing.T) {
+		idp, _, svc := setup(t, domainauth.GetDetailUserResult{
+			ID:     "1",
+			Email:  "external@example.com",
+			Status: sharedkernel.UserStatusSuspended,
+		})
+
+		_, err := login(t, idp, svc)
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, _, svc := setup(t)
+
+		_, err := svc.StartOidcLogin(ctx, domainauth.StartOidcLoginInput{Provider: "unknown"})
+		assert.True(t, apperror.IsNotFound(err), "expected not found, got %v", err)
+	})
+}
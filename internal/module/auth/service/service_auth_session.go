@@ -0,0 +1,173 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+var errSessionLimitReached = apperror.Forbidden("too many active sessions, log out on another device first")
+
+// sessionClient describes the client a session is issued to.
+type sessionClient struct {
+	UserAgent string
+	IPAddress string
+	Platform  string
+	DpopJkt   string // binds the tokens to a DPoP key, empty for bearer tokens
+}
+
+// Lengths of the auth_sessions columns the client headers are stored in.
+const (
+	maxSessionUserAgentLength = 512
+	maxSessionPlatformLength  = 50
+)
+
+// sessionUserAgent and sessionPlatform fit the User-Agent and x-platform
+// headers, which the client chooses freely, into their auth_sessions columns.
+func sessionUserAgent(userAgent string) string {
+	return truncateChars(userAgent, maxSessionUserAgentLength)
+}
+
+func sessionPlatform(platform string) string {
+	return truncateChars(strings.TrimSpace(platform), maxSessionPlatformLength)
+}
+
+// truncateChars cuts s to at most n characters, dropping invalid UTF-8 that
+// the database would refuse anyway.
+func truncateChars(s string, n int) string {
+	s = strings.ToValidUTF8(s, "")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+func (s *service) GetListSession(ctx context.Context, input domainauth.GetListSessionInput) (domainauth.GetListSessionOutput, error) {
+	result, err := s.authRepo.GetListSession(ctx, domainauth.GetListSessionFilters{
+		UserID: input.UserID,
+		Now:    time.Now().UTC(),
+	})
+	if err != nil {
+		return domainauth.GetListSessionOutput{}, apperror.StdUnknown(err)
+	}
+
+	return domainauth.GetListSessionOutput{
+		Sessions: result.Sessions,
+	}, nil
+}
+
+func (s *service) RevokeSession(ctx context.Context, input domainauth.RevokeSessionInput) (domainauth.RevokeSessionOutput, error) {
+	session, err := s.authRepo.GetDetailSession(ctx, domainauth.GetDetailSessionFilters{
+		SessionID: &input.SessionID,
+		UserID:    &input.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainauth.RevokeSessionOutput{}, apperror.NotFound("session not found")
+		}
+		return domainauth.RevokeSessionOutput{}, apperror.StdUnknown(err)
+	}
+	if session.RevokedAt != nil {
+		return domainauth.RevokeSessionOutput{}, apperror.NotFound("session not found")
+	}
+
+	result, err := s.authRepo.RevokeSessions(ctx, domainauth.RevokeSessionsParams{
+		UserID:    input.UserID,
+		SessionID: &session.ID,
+	})
+	if err != nil {
+		return domainauth.RevokeSessionOutput{}, apperror.StdUnknown(err)
+	}
+
+	if result.RevokedCount > 0 {
+		s.audit(ctx, sharedkernel.AuditEntry{
+			Action:       sharedkernel.AuditActionSessionRevoke,
+			Outcome:      sharedkernel.AuditOutcomeSuccess,
+			TargetUserID: input.UserID,
+			Details:      map[string]string{"session_id": session.ID},
+		})
+	}
+
+	return domainauth.RevokeSessionOutput{
+		Success: result.RevokedCount > 0,
+	}, nil
+}
+
+func (s *service) RevokeOtherSessions(ctx context.Context, input domainauth.RevokeOtherSessionsInput) (domainauth.RevokeOtherSessionsOutput, error) {
+	if err := sharedkernel.DenyImpersonation(ctx); err != nil {
+		return domainauth.RevokeOtherSessionsOutput{}, err
+	}
+
+	if input.CurrentSessionID == "" {
+		return domainauth.RevokeOtherSessionsOutput{}, apperror.BadRequest("current session is unknown")
+	}
+
+	result, err := s.authRepo.RevokeSessions(ctx, domainauth.RevokeSessionsParams{
+		UserID:          input.UserID,
+		ExceptSessionID: &input.CurrentSessionID,
+	})
+	if err != nil {
+		return domainauth.RevokeOtherSessionsOutput{}, apperror.StdUnknown(err)
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionSessionRevoke,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		TargetUserID: input.UserID,
+		Details: map[string]string{
+			"scope":         "others",
+			"revoked_count": strconv.FormatInt(result.RevokedCount, 10),
+		},
+	})
+
+	return domainauth.RevokeOtherSessionsOutput{
+		RevokedCount: result.RevokedCount,
+	}, nil
+}
+
+func (s *service) RevokeAllSessions(ctx context.Context, input domainauth.RevokeAllSessionsInput) (domainauth.RevokeAllSessionsOutput, error) {
+	result, err := s.authRepo.RevokeSessions(ctx, domainauth.RevokeSessionsParams{
+		UserID: input.UserID,
+	})
+	if err != nil {
+		return domainauth.RevokeAllSessionsOutput{}, apperror.StdUnknown(err)
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionSessionRevoke,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		TargetUserID: input.UserID,
+		Details: map[string]string{
+			"scope":         "all",
+			"revoked_count": strconv.FormatInt(result.RevokedCount, 10),
+		},
+	})
+
+	return domainauth.RevokeAllSessionsOutput{
+		RevokedCount: result.RevokedCount,
+	}, nil
+}
+
+// auditEvictedSessions records the sessions of userID a login revoked to stay
+// within the session limit.
+func (s *service) auditEvictedSessions(ctx context.Context, userID string, sessionIDs []string) {
+	for _, sessionID := range sessionIDs {
+		s.audit(ctx, sharedkernel.AuditEntry{
+			Action:       sharedkernel.AuditActionSessionRevoke,
+			Outcome:      sharedkernel.AuditOutcomeSuccess,
+			Reason:       auditReasonSessionLimit,
+			ActorUserID:  userID,
+			TargetUserID: userID,
+			Details:      map[string]string{"session_id": sessionID},
+		})
+	}
+}
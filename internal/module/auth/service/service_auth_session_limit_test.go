@@ -0,0 +1,134 @@
+package authservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSessionLimit_Overflow(t *testing.T) {
+	now := time.Now()
+	active := []domainauth.Session{
+		{ID: "3", Platform: "ios", CreatedAt: now.Add(-1 * time.Hour)},
+		{ID: "1", Platform: "web", CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: "2", Platform: "IOS", CreatedAt: now.Add(-2 * time.Hour)},
+	}
+	ids := func(sessions []domainauth.Session) []string {
+		var ids []string
+		for _, session := range sessions {
+			ids = append(ids, session.ID)
+		}
+		return ids
+	}
+
+	cases := []struct {
+		name     string
+		limit    domainauth.SessionLimit
+		platform string
+		evict    []string
+		ok       bool
+	}{
+		{name: "under both caps", limit: domainauth.SessionLimit{Max: 4, PlatformMax: 3, EvictOldest: true}, platform: "ios", ok: true},
+		{name: "total cap evicts the oldest", limit: domainauth.SessionLimit{Max: 3, EvictOldest: true}, platform: "android", evict: []string{"1"}, ok: true},
+		{name: "platform cap evicts the oldest on the platform", limit: domainauth.SessionLimit{PlatformMax: 2, EvictOldest: true}, platform: "ios", evict: []string{"2"}, ok: true},
+		{name: "both caps count an eviction once", limit: domainauth.SessionLimit{Max: 3, PlatformMax: 2, EvictOldest: true}, platform: "ios", evict: []string{"2"}, ok: true},
+		{name: "both caps evict from each", limit: domainauth.SessionLimit{Max: 2, PlatformMax: 2, EvictOldest: true}, platform: "ios", evict: []string{"2", "1"}, ok: true},
+		{name: "other platform is not capped", limit: domainauth.SessionLimit{PlatformMax: 1, EvictOldest: true}, platform: "android", ok: true},
+		{name: "reject", limit: domainauth.SessionLimit{Max: 3}, platform: "web", ok: false},
+	}
+	for _, tc := range cases {
+		evict, ok := tc.limit.Overflow(active, tc.platform)
+		assert.Equal(t, tc.ok, ok, tc.name)
+		assert.Equal(t, tc.evict, ids(evict), tc.name)
+	}
+}
+
+func TestService_SessionLimit(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	setup := func(policy domainauth.SessionLimitPolicy) (*fakeAuditLogRepo, domainauth.AuthService) {
+		userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+			ID:           "1",
+			Email:        "user@example.com",
+			PasswordHash: string(passwordHash),
+			Role:         domainauth.UserRoleUser,
+			Status:       sharedkernel.UserStatusActive,
+		}}
+		auditLogRepo := newFakeAuditLogRepo()
+		svc := newTestService(t, withUserRepo(userRepo), withAuditLogRepo(auditLogRepo), withSessionLimit(policy))
+		return auditLogRepo, svc
+	}
+	login := func(svc domainauth.AuthService, platform string) (domainauth.LoginOutput, error) {
+		return svc.Login(ctx, domainauth.LoginInput{
+			Email:    "user@example.com",
+			Password: "correct-password",
+			Platform: platform,
+		})
+	}
+	valid := func(svc domainauth.AuthService, accessToken string) bool {
+		validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: accessToken})
+		require.NoError(t, err)
+		return validated.Valid
+	}
+
+	t.Run("the oldest session on the platform is evicted", func(t *testing.T) {
+		auditLogRepo, svc := setup(domainauth.SessionLimitPolicy{
+			MaxSessions:         3,
+			PlatformMaxSessions: map[string]int{"ios": 1},
+			OnLimit:             domainauth.SessionLimitActionEvictOldest,
+		})
+
+		web, err := login(svc, "web")
+		require.NoError(t, err)
+		firstIos, err := login(svc, "ios")
+		require.NoError(t, err)
+		secondIos, err := login(svc, "iOS")
+		require.NoError(t, err)
+
+		assert.True(t, valid(svc, web.AccessToken))
+		assert.False(t, valid(svc, firstIos.AccessToken), "the older ios session is evicted")
+		assert.True(t, valid(svc, secondIos.AccessToken))
+		_, err = svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: firstIos.RefreshToken})
+		assert.True(t, apperror.IsBadRequest(err), "the refresh token of an evicted session is revoked too")
+
+		last := auditLogRepo.entries[len(auditLogRepo.entries)-2]
+		assert.Equal(t, sharedkernel.AuditActionSessionRevoke, last.Action)
+		assert.Equal(t, "session_limit", last.Reason)
+
+		_, err = login(svc, "android")
+		require.NoError(t, err)
+		_, err = login(svc, "android")
+		require.NoError(t, err)
+		assert.False(t, valid(svc, web.AccessToken), "the total cap evicts the oldest session of any platform")
+	})
+
+	t.Run("a login over the limit is rejected", func(t *testing.T) {
+		auditLogRepo, svc := setup(domainauth.SessionLimitPolicy{
+			PlatformMaxSessions: map[string]int{"web": 1},
+			OnLimit:             domainauth.SessionLimitActionReject,
+		})
+
+		web, err := login(svc, "web")
+		require.NoError(t, err)
+		_, err = login(svc, "web")
+		assert.True(t, apperror.IsForbidden(err))
+		assert.True(t, valid(svc, web.AccessToken), "a rejected login leaves the other sessions alone")
+
+		last := auditLogRepo.entries[len(auditLogRepo.entries)-1]
+		assert.Equal(t, sharedkernel.AuditOutcomeFailure, last.Outcome)
+		assert.Equal(t, "session_limit", last.Reason)
+
+		_, err = login(svc, "ios")
+		require.NoError(t, err, "other platforms are not capped")
+	})
+}
@@ -2,33 +2,1104 @@ package authservice_test
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"go-bootstrap/internal/config"
 	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	"go-bootstrap/internal/infrastructure"
+	authrepository "go-bootstrap/internal/module/auth/repository"
+	authservice "go-bootstrap/internal/module/auth/service"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
-func TestService_Login(t *testing.T) {
-	t.Skip("TODO: Implement with mocks")
+// fakeAuthRepo keeps tokens in memory. Methods a test does not exercise fall
+// through to the embedded nil interface and panic.
+type fakeAuthRepo struct {
+	domainauth.AuthRepositoryDatastore
+	tokens   map[string]*domainauth.GetDetailTokenResult
+	sessions map[string]*domainauth.GetDetailSessionResult
+	nextID   int
+}
+
+func newFakeAuthRepo() *fakeAuthRepo {
+	return &fakeAuthRepo{
+		tokens:   map[string]*domainauth.GetDetailTokenResult{},
+		sessions: map[string]*domainauth.GetDetailSessionResult{},
+	}
+}
+
+func (f *fakeAuthRepo) CreateToken(ctx context.Context, params domainauth.CreateTokenParams) (domainauth.CreateTokenResult, error) {
+	f.nextID++
+	id := strconv.Itoa(f.nextID)
+	f.tokens[params.Token] = &domainauth.GetDetailTokenResult{
+		ID:               id,
+		UserID:           params.UserID,
+		TokenHash:        params.Token,
+		TokenType:        params.TokenType,
+		Status:           domainauth.TokenStatusActive,
+		ExpiresAt:        params.ExpiresAt,
+		CreatedAt:        time.Now().UTC(),
+		RefreshTokenHash: params.RefreshToken,
+		FamilyID:         params.FamilyID,
+		ParentID:         params.ParentID,
+		DpopJkt:          params.DpopJkt,
+	}
+	return domainauth.CreateTokenResult{ID: id}, nil
+}
+
+func (f *fakeAuthRepo) GetDetailToken(ctx context.Context, filters domainauth.GetDetailTokenFilters) (domainauth.GetDetailTokenResult, error) {
+	token, ok := f.tokens[*filters.Token]
+	if !ok {
+		return domainauth.GetDetailTokenResult{}, databases.ErrNoRowFound
+	}
+	return *token, nil
+}
+
+func (f *fakeAuthRepo) RotateToken(ctx context.Context, params domainauth.RotateTokenParams) (domainauth.RotateTokenResult, error) {
+	for _, token := range f.tokens {
+		if token.ID == params.TokenID && token.Status == domainauth.TokenStatusActive {
+			token.Status = domainauth.TokenStatusRotated
+			return domainauth.RotateTokenResult{Success: true}, nil
+		}
+	}
+	return domainauth.RotateTokenResult{Success: false}, nil
+}
+
+func (f *fakeAuthRepo) RevokeToken(ctx context.Context, params domainauth.RevokeTokenParams) (domainauth.RevokeTokenResult, error) {
+	token, ok := f.tokens[params.Token]
+	if !ok || token.UserID != params.UserID || token.Status != domainauth.TokenStatusActive {
+		return domainauth.RevokeTokenResult{Success: false}, nil
+	}
+	token.Status = domainauth.TokenStatusRevoked
+	return domainauth.RevokeTokenResult{Success: true, RevokedAt: time.Now().UTC()}, nil
+}
+
+func (f *fakeAuthRepo) RevokeTokenFamily(ctx context.Context, params domainauth.RevokeTokenFamilyParams) (domainauth.RevokeTokenFamilyResult, error) {
+	var count int64
+	for _, token := range f.tokens {
+		if token.FamilyID == params.FamilyID && token.Status != domainauth.TokenStatusRevoked {
+			token.Status = domainauth.TokenStatusRevoked
+			count++
+		}
+	}
+	now := time.Now().UTC()
+	for _, session := range f.sessions {
+		if session.FamilyID == params.FamilyID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+	return domainauth.RevokeTokenFamilyResult{RevokedCount: count}, nil
+}
+
+func (f *fakeAuthRepo) CreateSession(ctx context.Context, params domainauth.CreateSessionParams) (domainauth.CreateSessionResult, error) {
+	var evictedIDs []string
+	if params.Limit.Enabled() {
+		var active []domainauth.Session
+		for _, session := range f.sessions {
+			if session.UserID == params.UserID && session.RevokedAt == nil && session.ExpiresAt.After(params.CreatedAt) {
+				active = append(active, session.Session)
+			}
+		}
+		evict, ok := params.Limit.Overflow(active, params.Platform)
+		if !ok {
+			return domainauth.CreateSessionResult{LimitReached: true}, nil
+		}
+		for _, session := range evict {
+			_, _ = f.RevokeTokenFamily(ctx, domainauth.RevokeTokenFamilyParams{FamilyID: f.sessions[session.ID].FamilyID, UserID: session.UserID})
+			evictedIDs = append(evictedIDs, session.ID)
+		}
+	}
+
+	f.nextID++
+	id := strconv.Itoa(f.nextID)
+	f.sessions[id] = &domainauth.GetDetailSessionResult{
+		Session: domainauth.Session{
+			ID:         id,
+			UserID:     params.UserID,
+			UserAgent:  params.UserAgent,
+			IPAddress:  params.IPAddress,
+			Platform:   params.Platform,
+			CreatedAt:  params.CreatedAt,
+			LastUsedAt: params.CreatedAt,
+			ExpiresAt:  params.ExpiresAt,
+		},
+		FamilyID: params.FamilyID,
+	}
+	return domainauth.CreateSessionResult{ID: id, EvictedSessionIDs: evictedIDs}, nil
+}
+
+func (f *fakeAuthRepo) GetDetailSession(ctx context.Context, filters domainauth.GetDetailSessionFilters) (domainauth.GetDetailSessionResult, error) {
+	for _, session := range f.sessions {
+		if (filters.SessionID == nil || session.ID == *filters.SessionID) &&
+			(filters.FamilyID == nil || session.FamilyID == *filters.FamilyID) &&
+			(filters.UserID == nil || session.UserID == *filters.UserID) {
+			return *session, nil
+		}
+	}
+	return domainauth.GetDetailSessionResult{}, databases.ErrNoRowFound
+}
+
+func (f *fakeAuthRepo) GetListSession(ctx context.Context, filters domainauth.GetListSessionFilters) (domainauth.GetListSessionResult, error) {
+	var sessions []domainauth.Session
+	for _, session := range f.sessions {
+		if session.UserID == filters.UserID && session.RevokedAt == nil && session.ExpiresAt.After(filters.Now) {
+			sessions = append(sessions, session.Session)
+		}
+	}
+	return domainauth.GetListSessionResult{Sessions: sessions}, nil
+}
+
+func (f *fakeAuthRepo) TouchSession(ctx context.Context, params domainauth.TouchSessionParams) (domainauth.TouchSessionResult, error) {
+	session, ok := f.sessions[params.SessionID]
+	if !ok || session.RevokedAt != nil {
+		return domainauth.TouchSessionResult{Success: false}, nil
+	}
+	session.LastUsedAt = params.LastUsedAt
+	session.IPAddress = params.IPAddress
+	session.ExpiresAt = params.ExpiresAt
+	return domainauth.TouchSessionResult{Success: true}, nil
+}
+
+func (f *fakeAuthRepo) RevokeSessions(ctx context.Context, params domainauth.RevokeSessionsParams) (domainauth.RevokeSessionsResult, error) {
+	var count int64
+	for _, session := range f.sessions {
+		if session.UserID != params.UserID || session.RevokedAt != nil ||
+			(params.SessionID != nil && session.ID != *params.SessionID) ||
+			(params.ExceptSessionID != nil && session.ID == *params.ExceptSessionID) {
+			continue
+		}
+		_, _ = f.RevokeTokenFamily(ctx, domainauth.RevokeTokenFamilyParams{FamilyID: session.FamilyID, UserID: session.UserID})
+		count++
+	}
+	return domainauth.RevokeSessionsResult{RevokedCount: count}, nil
+}
+
+type fakeJwtRepo struct {
+	domainauth.AuthRepositoryJwt
+	issued   int
+	payloads map[string]domainauth.TokenPayload
+}
+
+func (f *fakeJwtRepo) CreateAccessToken(ctx context.Context, params domainauth.CreateAccessTokenParams) (domainauth.CreateAccessTokenResult, error) {
+	f.issued++
+	token := "access-" + strconv.Itoa(f.issued)
+	if f.payloads == nil {
+		f.payloads = map[string]domainauth.TokenPayload{}
+	}
+	f.payloads[token] = domainauth.TokenPayload{
+		UserID:    params.UserID,
+		SessionID: params.SessionID,
+		Email:     params.Email,
+		Role:      params.Role,
+		TokenType: domainauth.TokenTypeAccess,
+		IssuedAt:  params.IssuedAt,
+		ExpiresAt: params.ExpiresAt,
+
+		ImpersonatorID: params.ImpersonatorID,
+		DpopJkt:        params.DpopJkt,
+	}
+	return domainauth.CreateAccessTokenResult{Token: token}, nil
+}
+
+func (f *fakeJwtRepo) ParseAccessToken(ctx context.Context, params domainauth.ParseAccessTokenParams) (domainauth.ParseAccessTokenResult, error) {
+	payload, ok := f.payloads[params.Token]
+	if !ok {
+		return domainauth.ParseAccessTokenResult{}, assert.AnError
+	}
+	return domainauth.ParseAccessTokenResult{Payload: payload}, nil
+}
+
+type fakeUserRepo struct {
+	domainauth.UserRepositoryDatastore
+	user           domainauth.GetDetailUserResult
+	passwordHashes []string // every hash saved by UpdatePasswordHash
+}
+
+func (f *fakeUserRepo) GetDetailUser(ctx context.Context, filters domainauth.GetDetailUserFilters) (domainauth.GetDetailUserResult, error) {
+	return f.user, nil
+}
+
+func (f *fakeUserRepo) UpdatePasswordHash(ctx context.Context, params domainauth.UpdatePasswordHashParams) (domainauth.UpdatePasswordHashResult, error) {
+	if f.user.ID != params.UserID || f.user.PasswordHash != params.OldPasswordHash {
+		return domainauth.UpdatePasswordHashResult{}, nil
+	}
+	f.user.PasswordHash = params.NewPasswordHash
+	f.passwordHashes = append(f.passwordHashes, params.NewPasswordHash)
+	return domainauth.UpdatePasswordHashResult{Success: true}, nil
+}
+
+// fakeMfaRepo keeps the TOTP secret, recovery codes and challenges of a
+// single user in memory.
+type fakeMfaRepo struct {
+	domainauth.AuthRepositoryMfa
+	totp          *domainauth.GetDetailTotpResult
+	recoveryCodes map[string]bool
+	challenges    map[string]*domainauth.GetDetailMfaChallengeResult
+}
+
+func newTokenPolicyRepo(cfg config.Auth) domainauth.AuthRepositoryTokenPolicy {
+	return authrepository.NewTokenPolicyRepository(infrastructure.NewTokenPolicyFromConfig(cfg))
+}
+
+// newPasswordHasher hashes with bcrypt at its minimum cost, the cost the
+// tests store, so logins do not trigger a rehash.
+func newPasswordHasher() sharedkernel.PasswordHasher {
+	hasher, err := infrastructure.NewPasswordHasherFromConfig(config.PasswordHash{
+		Algorithm:  infrastructure.PasswordHashAlgorithmBcrypt,
+		BcryptCost: bcrypt.MinCost,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return hasher
+}
+
+// testServiceDeps holds the dependencies newTestService passes to
+// authservice.NewService. The optional repositories are nil, a test that
+// reaches one without setting it panics.
+type testServiceDeps struct {
+	authRepo         domainauth.AuthRepositoryDatastore
+	userRepo         domainauth.UserRepositoryDatastore
+	loginAttemptRepo domainauth.AuthRepositoryLoginAttempt
+	mfaRepo          domainauth.AuthRepositoryMfa
+	tokenPolicyRepo  domainauth.AuthRepositoryTokenPolicy
+	identityRepo     domainauth.AuthRepositoryIdentity
+	oidcRepo         domainauth.AuthRepositoryOidc
+	oauthClientRepo  domainauth.AuthRepositoryOauthClient
+	auditLogRepo     domainauth.AuthRepositoryAuditLog
+	magicLinkRepo    domainauth.AuthRepositoryMagicLink
+	notifierRepo     domainauth.AuthRepositoryNotifier
+	dpopReplayRepo   domainauth.AuthRepositoryDpopReplay
+	rolePolicyRepo   domainauth.AuthRepositoryRolePolicy
+	passwordHasher   sharedkernel.PasswordHasher
+	lockoutPolicy    domainauth.LoginLockoutPolicy
+	mfaPolicy        domainauth.MfaPolicy
+	magicLinkPolicy  domainauth.MagicLinkPolicy
+	dpopPolicy       domainauth.DpopPolicy
+	sessionLimit     domainauth.SessionLimitPolicy
+}
+
+type testServiceOption func(*testServiceDeps)
+
+func withAuthRepo(v domainauth.AuthRepositoryDatastore) testServiceOption {
+	return func(d *testServiceDeps) { d.authRepo = v }
+}
+
+func withUserRepo(v domainauth.UserRepositoryDatastore) testServiceOption {
+	return func(d *testServiceDeps) { d.userRepo = v }
+}
+
+func withLoginAttemptRepo(v domainauth.AuthRepositoryLoginAttempt) testServiceOption {
+	return func(d *testServiceDeps) { d.loginAttemptRepo = v }
+}
+
+func withMfaRepo(v domainauth.AuthRepositoryMfa) testServiceOption {
+	return func(d *testServiceDeps) { d.mfaRepo = v }
+}
+
+func withTokenPolicyRepo(v domainauth.AuthRepositoryTokenPolicy) testServiceOption {
+	return func(d *testServiceDeps) { d.tokenPolicyRepo = v }
+}
+
+func withIdentityRepo(v domainauth.AuthRepositoryIdentity) testServiceOption {
+	return func(d *testServiceDeps) { d.identityRepo = v }
+}
+
+func withOidcRepo(v domainauth.AuthRepositoryOidc) testServiceOption {
+	return func(d *testServiceDeps) { d.oidcRepo = v }
+}
+
+func withOauthClientRepo(v domainauth.AuthRepositoryOauthClient) testServiceOption {
+	return func(d *testServiceDeps) { d.oauthClientRepo = v }
+}
+
+func withAuditLogRepo(v domainauth.AuthRepositoryAuditLog) testServiceOption {
+	return func(d *testServiceDeps) { d.auditLogRepo = v }
+}
+
+func withMagicLinkRepo(v domainauth.AuthRepositoryMagicLink) testServiceOption {
+	return func(d *testServiceDeps) { d.magicLinkRepo = v }
+}
+
+func withNotifierRepo(v domainauth.AuthRepositoryNotifier) testServiceOption {
+	return func(d *testServiceDeps) { d.notifierRepo = v }
+}
+
+func withDpopReplayRepo(v domainauth.AuthRepositoryDpopReplay) testServiceOption {
+	return func(d *testServiceDeps) { d.dpopReplayRepo = v }
+}
+
+func withRolePolicyRepo(v domainauth.AuthRepositoryRolePolicy) testServiceOption {
+	return func(d *testServiceDeps) { d.rolePolicyRepo = v }
+}
+
+func withPasswordHasher(v sharedkernel.PasswordHasher) testServiceOption {
+	return func(d *testServiceDeps) { d.passwordHasher = v }
+}
+
+func withLockoutPolicy(v domainauth.LoginLockoutPolicy) testServiceOption {
+	return func(d *testServiceDeps) { d.lockoutPolicy = v }
+}
+
+func withMfaPolicy(v domainauth.MfaPolicy) testServiceOption {
+	return func(d *testServiceDeps) { d.mfaPolicy = v }
+}
+
+func withMagicLinkPolicy(v domainauth.MagicLinkPolicy) testServiceOption {
+	return func(d *testServiceDeps) { d.magicLinkPolicy = v }
+}
+
+func withDpopPolicy(v domainauth.DpopPolicy) testServiceOption {
+	return func(d *testServiceDeps) { d.dpopPolicy = v }
+}
+
+func withSessionLimit(v domainauth.SessionLimitPolicy) testServiceOption {
+	return func(d *testServiceDeps) { d.sessionLimit = v }
+}
+
+// newTestService builds the service on in-memory fakes, opts replace the
+// ones a test needs to seed or inspect.
+func newTestService(t *testing.T, opts ...testServiceOption) domainauth.AuthService {
+	t.Helper()
+	deps := testServiceDeps{
+		authRepo:         newFakeAuthRepo(),
+		userRepo:         &fakeUserRepo{},
+		loginAttemptRepo: authrepository.NewLoginAttemptMemoryRepository(),
+		mfaRepo:          newFakeMfaRepo(),
+		tokenPolicyRepo:  newTokenPolicyRepo(config.Auth{}),
+		auditLogRepo:     newFakeAuditLogRepo(),
+		passwordHasher:   newPasswordHasher(),
+	}
+	for _, opt := range opts {
+		opt(&deps)
+	}
+
+	return authservice.NewService(deps.authRepo, &fakeJwtRepo{}, deps.userRepo, deps.loginAttemptRepo,
+		deps.mfaRepo, deps.tokenPolicyRepo, deps.identityRepo, deps.oidcRepo, deps.oauthClientRepo,
+		deps.auditLogRepo, deps.magicLinkRepo, deps.notifierRepo, deps.dpopReplayRepo, deps.rolePolicyRepo,
+		deps.passwordHasher, deps.lockoutPolicy, deps.mfaPolicy, deps.magicLinkPolicy, deps.dpopPolicy,
+		deps.sessionLimit)
+}
+
+func newFakeMfaRepo() *fakeMfaRepo {
+	return &fakeMfaRepo{
+		recoveryCodes: map[string]bool{},
+		challenges:    map[string]*domainauth.GetDetailMfaChallengeResult{},
+	}
+}
+
+func (f *fakeMfaRepo) GetDetailTotp(ctx context.Context, filters domainauth.GetDetailTotpFilters) (domainauth.GetDetailTotpResult, error) {
+	if f.totp == nil || f.totp.UserID != filters.UserID {
+		return domainauth.GetDetailTotpResult{}, databases.ErrNoRowFound
+	}
+	return *f.totp, nil
+}
+
+func (f *fakeMfaRepo) UpsertTotp(ctx context.Context, params domainauth.UpsertTotpParams) (domainauth.UpsertTotpResult, error) {
+	f.totp = &domainauth.GetDetailTotpResult{UserID: params.UserID, Secret: params.Secret, CreatedAt: time.Now().UTC()}
+	return domainauth.UpsertTotpResult{}, nil
+}
+
+func (f *fakeMfaRepo) ConfirmTotp(ctx context.Context, params domainauth.ConfirmTotpParams) (domainauth.ConfirmTotpResult, error) {
+	if f.totp == nil || f.totp.ConfirmedAt != nil {
+		return domainauth.ConfirmTotpResult{Success: false}, nil
+	}
+	f.totp.ConfirmedAt = &params.ConfirmedAt
+	f.totp.LastUsedStep = params.Step
+	return domainauth.ConfirmTotpResult{Success: true}, nil
+}
+
+func (f *fakeMfaRepo) UseTotpStep(ctx context.Context, params domainauth.UseTotpStepParams) (domainauth.UseTotpStepResult, error) {
+	if f.totp == nil || params.Step <= f.totp.LastUsedStep {
+		return domainauth.UseTotpStepResult{Success: false}, nil
+	}
+	f.totp.LastUsedStep = params.Step
+	return domainauth.UseTotpStepResult{Success: true}, nil
+}
+
+func (f *fakeMfaRepo) DeleteTotp(ctx context.Context, params domainauth.DeleteTotpParams) (domainauth.DeleteTotpResult, error) {
+	f.totp = nil
+	f.recoveryCodes = map[string]bool{}
+	return domainauth.DeleteTotpResult{Success: true}, nil
+}
+
+func (f *fakeMfaRepo) ReplaceRecoveryCodes(ctx context.Context, params domainauth.ReplaceRecoveryCodesParams) (domainauth.ReplaceRecoveryCodesResult, error) {
+	f.recoveryCodes = map[string]bool{}
+	for _, code := range params.Codes {
+		f.recoveryCodes[code] = false
+	}
+	return domainauth.ReplaceRecoveryCodesResult{}, nil
+}
+
+func (f *fakeMfaRepo) UseRecoveryCode(ctx context.Context, params domainauth.UseRecoveryCodeParams) (domainauth.UseRecoveryCodeResult, error) {
+	used, ok := f.recoveryCodes[params.Code]
+	if !ok || used {
+		return domainauth.UseRecoveryCodeResult{Success: false}, nil
+	}
+	f.recoveryCodes[params.Code] = true
+	return domainauth.UseRecoveryCodeResult{Success: true}, nil
+}
+
+func (f *fakeMfaRepo) CreateMfaChallenge(ctx context.Context, params domainauth.CreateMfaChallengeParams) (domainauth.CreateMfaChallengeResult, error) {
+	id := strconv.Itoa(len(f.challenges) + 1)
+	f.challenges[params.Challenge] = &domainauth.GetDetailMfaChallengeResult{
+		ID:        id,
+		UserID:    params.UserID,
+		ExpiresAt: params.ExpiresAt,
+	}
+	return domainauth.CreateMfaChallengeResult{ID: id, CreatedAt: time.Now().UTC()}, nil
+}
+
+func (f *fakeMfaRepo) GetDetailMfaChallenge(ctx context.Context, filters domainauth.GetDetailMfaChallengeFilters) (domainauth.GetDetailMfaChallengeResult, error) {
+	challenge, ok := f.challenges[filters.Challenge]
+	if !ok {
+		return domainauth.GetDetailMfaChallengeResult{}, databases.ErrNoRowFound
+	}
+	return *challenge, nil
+}
+
+func (f *fakeMfaRepo) challengeByID(id string) *domainauth.GetDetailMfaChallengeResult {
+	for _, challenge := range f.challenges {
+		if challenge.ID == id {
+			return challenge
+		}
+	}
+	return nil
+}
+
+func (f *fakeMfaRepo) IncrementMfaChallengeFailure(ctx context.Context, params domainauth.IncrementMfaChallengeFailureParams) (domainauth.IncrementMfaChallengeFailureResult, error) {
+	challenge := f.challengeByID(params.ID)
+	challenge.FailedAttempts++
+	return domainauth.IncrementMfaChallengeFailureResult{FailedAttempts: challenge.FailedAttempts}, nil
+}
+
+func (f *fakeMfaRepo) UseMfaChallenge(ctx context.Context, params domainauth.UseMfaChallengeParams) (domainauth.UseMfaChallengeResult, error) {
+	challenge := f.challengeByID(params.ID)
+	if challenge.UsedAt != nil {
+		return domainauth.UseMfaChallengeResult{Success: false}, nil
+	}
+	challenge.UsedAt = &params.UsedAt
+	return domainauth.UseMfaChallengeResult{Success: true}, nil
+}
+
+func TestService_Login_Lockout(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+		ID:           "1",
+		Email:        "user@example.com",
+		PasswordHash: string(passwordHash),
+		Role:         domainauth.UserRoleUser,
+		Status:       sharedkernel.UserStatusActive,
+	}}
+	loginAttemptRepo := authrepository.NewLoginAttemptMemoryRepository()
+	svc := newTestService(t,
+		withUserRepo(userRepo),
+		withLoginAttemptRepo(loginAttemptRepo),
+		withLockoutPolicy(domainauth.LoginLockoutPolicy{
+			MaxAttempts:       3,
+			Window:            time.Minute,
+			BaseLockout:       time.Minute,
+			MaxLockout:        time.Hour,
+			LockoutResetAfter: 24 * time.Hour,
+		}),
+	)
+
+	login := func(password, ip string) error {
+		_, err := svc.Login(ctx, domainauth.LoginInput{
+			Email:     "User@Example.com",
+			Password:  password,
+			IPAddress: ip,
+		})
+		return err
+	}
+
+	for range 2 {
+		err = login("wrong-password", "10.0.0.1")
+		assert.True(t, apperror.IsBadRequest(err), "expected invalid credentials, got %v", err)
+	}
+
+	err = login("wrong-password", "10.0.0.1")
+	var lockedErr *domainauth.LoginLockedError
+	require.ErrorAs(t, err, &lockedErr, "third failure must lock")
+	assert.True(t, apperror.IsForbidden(err))
+	assert.WithinDuration(t, time.Now().Add(time.Minute), lockedErr.LockedUntil, 5*time.Second)
+
+	err = login("correct-password", "10.0.0.2")
+	require.ErrorAs(t, err, &lockedErr, "a locked email must reject even the right password")
+
+	output, err := svc.GetListLoginLockout(ctx, domainauth.GetListLoginLockoutInput{
+		Pagination: primitive.PaginationInput{Page: 1, PageSize: 10},
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Lockouts, 2)
+
+	_, err = svc.ClearLoginLockout(ctx, domainauth.ClearLoginLockoutInput{
+		Scope:      domainauth.LoginAttemptScopeEmail,
+		Identifier: "user@example.com",
+	})
+	require.NoError(t, err)
+
+	err = login("correct-password", "10.0.0.1")
+	require.ErrorAs(t, err, &lockedErr, "the client IP stays locked until it is cleared as well")
+
+	err = login("correct-password", "10.0.0.2")
+	assert.NoError(t, err)
+}
+
+func TestLoginLockoutPolicy_LockoutDuration(t *testing.T) {
+	policy := domainauth.LoginLockoutPolicy{BaseLockout: time.Minute, MaxLockout: 10 * time.Minute}
+
+	assert.Equal(t, time.Minute, policy.LockoutDuration(0))
+	assert.Equal(t, 2*time.Minute, policy.LockoutDuration(1))
+	assert.Equal(t, 8*time.Minute, policy.LockoutDuration(3))
+	assert.Equal(t, 10*time.Minute, policy.LockoutDuration(4))
+	assert.Equal(t, 10*time.Minute, policy.LockoutDuration(64))
+}
+
+func TestService_Login_RehashesPassword(t *testing.T) {
+	ctx := context.Background()
+	argon2idConfig := config.PasswordHash{
+		Algorithm: infrastructure.PasswordHashAlgorithmArgon2id,
+		Argon2id:  config.Argon2id{Memory: 64, Iterations: 1, Parallelism: 1},
+	}
+
+	newHash := func(t *testing.T, cfg config.PasswordHash) string {
+		t.Helper()
+		hasher, err := infrastructure.NewPasswordHasherFromConfig(cfg)
+		require.NoError(t, err)
+		hash, err := hasher.Hash("secret-password")
+		require.NoError(t, err)
+		return hash
+	}
+
+	setup := func(t *testing.T, passwordHash string) (*fakeUserRepo, domainauth.AuthService) {
+		t.Helper()
+		hasher, err := infrastructure.NewPasswordHasherFromConfig(argon2idConfig)
+		require.NoError(t, err)
+		userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+			ID:           "1",
+			Email:        "user@example.com",
+			PasswordHash: passwordHash,
+			Role:         domainauth.UserRoleUser,
+			Status:       sharedkernel.UserStatusActive,
+		}}
+		svc := newTestService(t, withUserRepo(userRepo), withPasswordHasher(hasher))
+		return userRepo, svc
+	}
+
+	login := func(svc domainauth.AuthService, password string) error {
+		_, err := svc.Login(ctx, domainauth.LoginInput{Email: "user@example.com", Password: password})
+		return err
+	}
+
+	t.Run("bcrypt hash is replaced by argon2id", func(t *testing.T) {
+		userRepo, svc := setup(t, newHash(t, config.PasswordHash{
+			Algorithm:  infrastructure.PasswordHashAlgorithmBcrypt,
+			BcryptCost: bcrypt.MinCost,
+		}))
+
+		require.NoError(t, login(svc, "secret-password"))
+		require.Len(t, userRepo.passwordHashes, 1)
+		assert.True(t, strings.HasPrefix(userRepo.user.PasswordHash, "$argon2id$v=19$m=64,t=1,p=1$"))
+
+		// the new hash is current, the next login keeps it
+		require.NoError(t, login(svc, "secret-password"))
+		assert.Len(t, userRepo.passwordHashes, 1)
+	})
+
+	t.Run("argon2id hash with outdated parameters is replaced", func(t *testing.T) {
+		outdated := argon2idConfig
+		outdated.Argon2id.Iterations = 2
+		userRepo, svc := setup(t, newHash(t, outdated))
+
+		require.NoError(t, login(svc, "secret-password"))
+		require.Len(t, userRepo.passwordHashes, 1)
+		assert.True(t, strings.HasPrefix(userRepo.user.PasswordHash, "$argon2id$v=19$m=64,t=1,p=1$"))
+	})
+
+	t.Run("wrong password keeps the hash", func(t *testing.T) {
+		userRepo, svc := setup(t, newHash(t, config.PasswordHash{
+			Algorithm:  infrastructure.PasswordHashAlgorithmBcrypt,
+			BcryptCost: bcrypt.MinCost,
+		}))
+
+		err := login(svc, "wrong-password")
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+		assert.Empty(t, userRepo.passwordHashes)
+	})
+
+	t.Run("unknown hash format is refused", func(t *testing.T) {
+		userRepo, svc := setup(t, "plain-text")
+
+		err := login(svc, "plain-text")
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+		assert.Empty(t, userRepo.passwordHashes)
+	})
 }
 
 func TestService_RefreshToken(t *testing.T) {
-	t.Skip("TODO: Implement with mocks")
+	ctx := context.Background()
+	authRepo := newFakeAuthRepo()
+	userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+		ID:     "1",
+		Email:  "user@example.com",
+		Role:   domainauth.UserRoleUser,
+		Status: sharedkernel.UserStatusActive,
+	}}
+	svc := newTestService(t, withAuthRepo(authRepo), withUserRepo(userRepo))
+
+	_, err := authRepo.CreateSession(ctx, domainauth.CreateSessionParams{
+		UserID:    "1",
+		FamilyID:  "family-1",
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = authRepo.CreateToken(ctx, domainauth.CreateTokenParams{
+		UserID:    "1",
+		Token:     "refresh-0",
+		TokenType: domainauth.TokenTypeRefresh,
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+		FamilyID:  "family-1",
+	})
+	require.NoError(t, err)
+
+	first, err := svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: "refresh-0"})
+	require.NoError(t, err)
+
+	rotated := authRepo.tokens[first.RefreshToken]
+	assert.Equal(t, "family-1", rotated.FamilyID)
+	assert.Equal(t, authRepo.tokens["refresh-0"].ID, *rotated.ParentID)
+	assert.Equal(t, domainauth.TokenStatusRotated, authRepo.tokens["refresh-0"].Status)
+
+	t.Run("reuse of a rotated token revokes the family", func(t *testing.T) {
+		_, err := svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: "refresh-0"})
+		assert.True(t, apperror.IsUnauthorized(err))
+
+		for _, token := range authRepo.tokens {
+			assert.Equal(t, domainauth.TokenStatusRevoked, token.Status, "token %s", token.TokenHash)
+		}
+
+		_, err = svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: first.RefreshToken})
+		assert.True(t, apperror.IsBadRequest(err), "descendant token must be unusable after reuse")
+	})
+}
+
+func TestTokenPolicy(t *testing.T) {
+	login := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := domainauth.TokenPolicy{
+		AccessTokenTTL:         15 * time.Minute,
+		RefreshTokenTTL:        7 * 24 * time.Hour,
+		SessionAbsoluteTimeout: 24 * time.Hour,
+		SessionIdleTimeout:     time.Hour,
+	}
+
+	refreshExpiry := policy.RefreshTokenExpiry(login, login)
+	assert.Equal(t, login.Add(time.Hour), refreshExpiry, "idle timeout shortens the refresh token")
+	assert.Equal(t, login.Add(15*time.Minute), policy.AccessTokenExpiry(refreshExpiry, login))
+
+	late := login.Add(23*time.Hour + 30*time.Minute)
+	refreshExpiry = policy.RefreshTokenExpiry(login, late)
+	assert.Equal(t, login.Add(24*time.Hour), refreshExpiry, "absolute timeout caps the session")
+	assert.Equal(t, refreshExpiry, policy.AccessTokenExpiry(refreshExpiry, login.Add(23*time.Hour+50*time.Minute)),
+		"access token never outlives the refresh token")
+
+	session := domainauth.Session{CreatedAt: login, LastUsedAt: login.Add(20 * time.Hour)}
+	assert.False(t, policy.SessionExpired(session, login.Add(20*time.Hour+30*time.Minute)))
+	assert.True(t, policy.SessionExpired(session, login.Add(21*time.Hour)), "idle timeout")
+	session.LastUsedAt = login.Add(23*time.Hour + 30*time.Minute)
+	assert.True(t, policy.SessionExpired(session, login.Add(24*time.Hour)), "absolute timeout")
+	assert.False(t, domainauth.TokenPolicy{}.SessionExpired(session, login.Add(1000*time.Hour)), "zero timeouts are disabled")
+}
+
+func TestService_TokenPolicy(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	setup := func(role domainauth.UserRole) (*fakeAuthRepo, domainauth.AuthService) {
+		authRepo := newFakeAuthRepo()
+		userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+			ID:           "1",
+			Email:        "user@example.com",
+			PasswordHash: string(passwordHash),
+			Role:         role,
+			Status:       sharedkernel.UserStatusActive,
+		}}
+		tokenPolicyRepo := newTokenPolicyRepo(config.Auth{
+			SessionIdleTimeout: time.Hour,
+			Roles: []config.AuthRole{
+				{Role: "admin", AccessTokenTTL: 5 * time.Minute, SessionAbsoluteTimeout: 8 * time.Hour},
+			},
+		})
+		svc := newTestService(t, withAuthRepo(authRepo), withUserRepo(userRepo), withTokenPolicyRepo(tokenPolicyRepo))
+		return authRepo, svc
+	}
+	login := func(t *testing.T, svc domainauth.AuthService) domainauth.LoginOutput {
+		t.Helper()
+		output, err := svc.Login(ctx, domainauth.LoginInput{Email: "user@example.com", Password: "correct-password"})
+		require.NoError(t, err)
+		return output
+	}
+
+	t.Run("defaults apply to roles without override", func(t *testing.T) {
+		authRepo, svc := setup(domainauth.UserRoleUser)
+		output := login(t, svc)
+		assert.Equal(t, int64(15*60), output.ExpiresIn)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), authRepo.tokens[output.RefreshToken].ExpiresAt, time.Minute,
+			"idle timeout shortens the refresh token")
+	})
+
+	t.Run("role override", func(t *testing.T) {
+		_, svc := setup(domainauth.UserRoleAdmin)
+		output := login(t, svc)
+		assert.Equal(t, int64(5*60), output.ExpiresIn)
+	})
+
+	t.Run("idle session cannot be refreshed", func(t *testing.T) {
+		authRepo, svc := setup(domainauth.UserRoleUser)
+		output := login(t, svc)
+		for _, session := range authRepo.sessions {
+			session.LastUsedAt = session.LastUsedAt.Add(-2 * time.Hour)
+		}
+
+		_, err := svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: output.RefreshToken})
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+	})
+
+	t.Run("absolute timeout ends an active session", func(t *testing.T) {
+		authRepo, svc := setup(domainauth.UserRoleAdmin)
+		output := login(t, svc)
+		for _, session := range authRepo.sessions {
+			session.CreatedAt = session.CreatedAt.Add(-9 * time.Hour)
+		}
+
+		_, err := svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: output.RefreshToken})
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+	})
 }
 
-func TestService_ValidateToken(t *testing.T) {
+func TestTotpSecret_Code(t *testing.T) {
+	// RFC 6238 appendix B, SHA1, truncated to six digits
+	secret := domainauth.TotpSecret("12345678901234567890")
+
+	assert.Equal(t, "287082", secret.Code(domainauth.TotpStep(time.Unix(59, 0))))
+	assert.Equal(t, "081804", secret.Code(domainauth.TotpStep(time.Unix(1111111109, 0))))
+	assert.Equal(t, "005924", secret.Code(domainauth.TotpStep(time.Unix(1234567890, 0))))
+
+	now := time.Unix(1111111109, 0)
+	step, ok := secret.Verify("081804", now.Add(domainauth.TotpPeriod))
+	assert.True(t, ok, "the previous step is accepted for clock drift")
+	assert.Equal(t, domainauth.TotpStep(now), step)
+
+	_, ok = secret.Verify("081804", now.Add(3*domainauth.TotpPeriod))
+	assert.False(t, ok)
+}
+
+func TestService_LoginMfa(t *testing.T) {
 	ctx := context.Background()
-	input := domainauth.ValidateTokenInput{
-		Token: "test-token",
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+		ID:           "1",
+		Email:        "user@example.com",
+		PasswordHash: string(passwordHash),
+		Role:         domainauth.UserRoleUser,
+		Status:       sharedkernel.UserStatusActive,
+	}}
+	mfaRepo := newFakeMfaRepo()
+	svc := newTestService(t,
+		withUserRepo(userRepo),
+		withMfaRepo(mfaRepo),
+		withMfaPolicy(domainauth.MfaPolicy{
+			Issuer:               "go-bootstrap",
+			ChallengeTTL:         time.Minute,
+			ChallengeMaxAttempts: 3,
+		}),
+	)
+
+	login := func() domainauth.LoginOutput {
+		output, err := svc.Login(ctx, domainauth.LoginInput{Email: "user@example.com", Password: "correct-password"})
+		require.NoError(t, err)
+		return output
 	}
 
-	_ = ctx
-	_ = input
+	enrolled, err := svc.EnrollTotp(ctx, domainauth.EnrollTotpInput{UserID: "1"})
+	require.NoError(t, err)
+	assert.Contains(t, enrolled.URI, "otpauth://totp/go-bootstrap:user@example.com?")
+	assert.Equal(t, mfaRepo.totp.Secret.Base32(), enrolled.Secret)
+
+	output := login()
+	assert.False(t, output.MfaRequired, "an unconfirmed enrollment does not protect the login")
+	assert.NotEmpty(t, output.AccessToken)
+
+	// confirm with the previous step so the current one is still free for login
+	secret := mfaRepo.totp.Secret
+	current := domainauth.TotpStep(time.Now())
+	confirmed, err := svc.ConfirmTotp(ctx, domainauth.ConfirmTotpInput{UserID: "1", Code: secret.Code(current - 1)})
+	require.NoError(t, err)
+	require.Len(t, confirmed.RecoveryCodes, 10)
+
+	output = login()
+	require.True(t, output.MfaRequired)
+	assert.Empty(t, output.AccessToken)
+	assert.Equal(t, int64(60), output.MfaChallengeExpiresIn)
+
+	_, err = svc.LoginMfa(ctx, domainauth.LoginMfaInput{Challenge: output.MfaChallenge, Code: "000000x"})
+	assert.True(t, apperror.IsBadRequest(err))
+
+	_, err = svc.LoginMfa(ctx, domainauth.LoginMfaInput{Challenge: output.MfaChallenge, Code: secret.Code(current - 1)})
+	assert.True(t, apperror.IsBadRequest(err), "a used step must not be accepted again")
 
-	t.Skip("TODO: Setup mocks and test validation")
+	tokens, err := svc.LoginMfa(ctx, domainauth.LoginMfaInput{Challenge: output.MfaChallenge, Code: secret.Code(current)})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	_, err = svc.LoginMfa(ctx, domainauth.LoginMfaInput{Challenge: output.MfaChallenge, Code: confirmed.RecoveryCodes[0]})
+	assert.True(t, apperror.IsBadRequest(err), "a challenge is single-use")
+
+	t.Run("recovery codes are single-use", func(t *testing.T) {
+		output := login()
+		_, err := svc.LoginMfa(ctx, domainauth.LoginMfaInput{Challenge: output.MfaChallenge, Code: strings.ToUpper(confirmed.RecoveryCodes[0])})
+		require.NoError(t, err)
+
+		output = login()
+		_, err = svc.LoginMfa(ctx, domainauth.LoginMfaInput{Challenge: output.MfaChallenge, Code: confirmed.RecoveryCodes[0]})
+		assert.True(t, apperror.IsBadRequest(err))
+	})
+
+	t.Run("challenge is dropped after too many wrong codes", func(t *testing.T) {
+		output := login()
+		for range 3 {
+			_, err := svc.LoginMfa(ctx, domainauth.LoginMfaInput{Challenge: output.MfaChallenge, Code: "wrong-code"})
+			assert.True(t, apperror.IsBadRequest(err))
+		}
+		_, err := svc.LoginMfa(ctx, domainauth.LoginMfaInput{Challenge: output.MfaChallenge, Code: confirmed.RecoveryCodes[1]})
+		assert.True(t, apperror.IsBadRequest(err))
+		assert.False(t, mfaRepo.recoveryCodes[strings.ReplaceAll(confirmed.RecoveryCodes[1], "-", "")], "the code must not be consumed")
+	})
+
+	t.Run("disable requires a second factor", func(t *testing.T) {
+		_, err := svc.DisableTotp(ctx, domainauth.DisableTotpInput{UserID: "1", Code: "wrong-code"})
+		assert.True(t, apperror.IsBadRequest(err))
+
+		_, err = svc.DisableTotp(ctx, domainauth.DisableTotpInput{UserID: "1", Code: confirmed.RecoveryCodes[2]})
+		require.NoError(t, err)
+		assert.False(t, login().MfaRequired)
+	})
+}
+
+func TestService_Sessions(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	authRepo := newFakeAuthRepo()
+	userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+		ID:           "1",
+		Email:        "user@example.com",
+		PasswordHash: string(passwordHash),
+		Role:         domainauth.UserRoleUser,
+		Status:       sharedkernel.UserStatusActive,
+	}}
+	svc := newTestService(t, withAuthRepo(authRepo), withUserRepo(userRepo))
+
+	login := func(platform string) (domainauth.LoginOutput, domainauth.TokenPayload) {
+		output, err := svc.Login(ctx, domainauth.LoginInput{
+			Email:     "user@example.com",
+			Password:  "correct-password",
+			IPAddress: "10.0.0.1",
+			UserAgent: "test-agent",
+			Platform:  platform,
+		})
+		require.NoError(t, err)
+
+		validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken})
+		require.NoError(t, err)
+		require.True(t, validated.Valid)
+		return output, *validated.Payload
+	}
+	valid := func(accessToken string) bool {
+		validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: accessToken})
+		require.NoError(t, err)
+		return validated.Valid
+	}
+
+	web, webPayload := login("web")
+	ios, iosPayload := login("ios")
+	android, _ := login("android")
+	assert.NotEqual(t, webPayload.SessionID, iosPayload.SessionID)
+
+	listed, err := svc.GetListSession(ctx, domainauth.GetListSessionInput{UserID: "1"})
+	require.NoError(t, err)
+	require.Len(t, listed.Sessions, 3)
+	assert.Equal(t, "test-agent", listed.Sessions[0].UserAgent)
+
+	_, err = svc.RevokeSession(ctx, domainauth.RevokeSessionInput{UserID: "2", SessionID: webPayload.SessionID})
+	assert.True(t, apperror.IsNotFound(err), "a session of another user must not be revocable")
+
+	_, err = svc.RevokeSession(ctx, domainauth.RevokeSessionInput{UserID: "1", SessionID: webPayload.SessionID})
+	require.NoError(t, err)
+	assert.False(t, valid(web.AccessToken), "the access token of a revoked session is rejected at once")
+	_, err = svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: web.RefreshToken})
+	assert.True(t, apperror.IsBadRequest(err))
+
+	revoked, err := svc.RevokeOtherSessions(ctx, domainauth.RevokeOtherSessionsInput{UserID: "1", CurrentSessionID: iosPayload.SessionID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), revoked.RevokedCount)
+	assert.True(t, valid(ios.AccessToken))
+	assert.False(t, valid(android.AccessToken))
+
+	refreshed, err := svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: ios.RefreshToken, IPAddress: "10.0.0.9"})
+	require.NoError(t, err)
+	assert.True(t, valid(refreshed.AccessToken))
+	listed, err = svc.GetListSession(ctx, domainauth.GetListSessionInput{UserID: "1"})
+	require.NoError(t, err)
+	require.Len(t, listed.Sessions, 1)
+	assert.Equal(t, "10.0.0.9", listed.Sessions[0].IPAddress)
+	assert.Equal(t, "ios", listed.Sessions[0].Platform)
+
+	all, err := svc.RevokeAllSessions(ctx, domainauth.RevokeAllSessionsInput{UserID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), all.RevokedCount)
+	assert.False(t, valid(refreshed.AccessToken))
+}
+
+func TestService_Login_OversizedClientHeaders(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	authRepo := newFakeAuthRepo()
+	userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+		ID:           "1",
+		Email:        "user@example.com",
+		PasswordHash: string(passwordHash),
+		Role:         domainauth.UserRoleUser,
+		Status:       sharedkernel.UserStatusActive,
+	}}
+	svc := newTestService(t, withAuthRepo(authRepo), withUserRepo(userRepo))
+
+	_, err = svc.Login(ctx, domainauth.LoginInput{
+		Email:     "user@example.com",
+		Password:  "correct-password",
+		UserAgent: strings.Repeat("é", 4096),
+		Platform:  " " + strings.Repeat("p", 200),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, authRepo.sessions, 1)
+	for _, session := range authRepo.sessions {
+		// the columns are VARCHAR(512) and VARCHAR(50)
+		assert.Equal(t, strings.Repeat("é", 512), session.UserAgent)
+		assert.Equal(t, strings.Repeat("p", 50), session.Platform)
+	}
+}
+
+func TestService_ValidateToken_UserStatus(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+		ID:           "1",
+		Email:        "user@example.com",
+		PasswordHash: string(passwordHash),
+		Role:         domainauth.UserRoleUser,
+		Status:       sharedkernel.UserStatusActive,
+	}}
+	svc := newTestService(t, withUserRepo(userRepo))
+
+	output, err := svc.Login(ctx, domainauth.LoginInput{Email: "user@example.com", Password: "correct-password"})
+	require.NoError(t, err)
+
+	validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken})
+	require.NoError(t, err)
+	assert.True(t, validated.Valid)
+
+	userRepo.user.Status = sharedkernel.UserStatusSuspended
+	validated, err = svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken})
+	require.NoError(t, err)
+	assert.False(t, validated.Valid, "tokens of a suspended user are rejected")
+}
+
+func TestService_ValidateToken_PasswordChangeRequired(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("temporary-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+		ID:                     "1",
+		Email:                  "user@example.com",
+		PasswordHash:           string(passwordHash),
+		Role:                   domainauth.UserRoleUser,
+		Status:                 sharedkernel.UserStatusActive,
+		PasswordChangeRequired: true,
+	}}
+	svc := newTestService(t, withUserRepo(userRepo))
+
+	output, err := svc.Login(ctx, domainauth.LoginInput{Email: "user@example.com", Password: "temporary-password"})
+	require.NoError(t, err)
+	assert.True(t, output.PasswordChangeRequired)
+
+	validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken})
+	require.NoError(t, err)
+	require.True(t, validated.Valid)
+	assert.True(t, validated.Payload.PasswordChangeRequired)
+
+	userRepo.user.PasswordChangeRequired = false
+	validated, err = svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken})
+	require.NoError(t, err)
+	require.True(t, validated.Valid)
+	assert.False(t, validated.Payload.PasswordChangeRequired, "the flag is read from the user on every validation")
 }
 
 func TestService_Logout(t *testing.T) {
-	t.Skip("TODO: Implement with mocks")
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	userRepo := &fakeUserRepo{user: domainauth.GetDetailUserResult{
+		ID:           "1",
+		Email:        "user@example.com",
+		PasswordHash: string(passwordHash),
+		Role:         domainauth.UserRoleUser,
+		Status:       sharedkernel.UserStatusActive,
+	}}
+	svc := newTestService(t, withUserRepo(userRepo))
+
+	output, err := svc.Login(ctx, domainauth.LoginInput{Email: "user@example.com", Password: "correct-password"})
+	require.NoError(t, err)
+
+	loggedOut, err := svc.Logout(ctx, domainauth.LogoutInput{AccessToken: output.AccessToken})
+	require.NoError(t, err)
+	assert.True(t, loggedOut.Success)
+
+	validated, err := svc.ValidateToken(ctx, domainauth.ValidateTokenInput{Token: output.AccessToken})
+	require.NoError(t, err)
+	assert.False(t, validated.Valid)
+
+	_, err = svc.RefreshToken(ctx, domainauth.RefreshTokenInput{RefreshToken: output.RefreshToken})
+	assert.Error(t, err, "logout ends the whole session")
+
+	listed, err := svc.GetListSession(ctx, domainauth.GetListSessionInput{UserID: "1"})
+	require.NoError(t, err)
+	assert.Empty(t, listed.Sessions)
 }
 
 func TestService_RevokeToken(t *testing.T) {
@@ -0,0 +1,40 @@
+package authservice
+
+import (
+	"context"
+	"time"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+)
+
+const (
+	defaultAccessTokenTTL        = 15 * time.Minute
+	defaultRefreshTokenTTL       = 7 * 24 * time.Hour
+	defaultExpiredTokenGrace     = 24 * time.Hour
+	defaultImpersonationTokenTTL = 15 * time.Minute
+)
+
+// getTokenPolicy returns the current token policy of role with defaults
+// for lifetimes that are not configured.
+func (s *service) getTokenPolicy(ctx context.Context, role domainauth.UserRole) (domainauth.GetTokenPolicyResult, error) {
+	result, err := s.tokenPolicyRepo.GetTokenPolicy(ctx, domainauth.GetTokenPolicyParams{
+		Role: role,
+	})
+	if err != nil {
+		return domainauth.GetTokenPolicyResult{}, err
+	}
+
+	if result.TokenPolicy.AccessTokenTTL <= 0 {
+		result.TokenPolicy.AccessTokenTTL = defaultAccessTokenTTL
+	}
+	if result.TokenPolicy.RefreshTokenTTL <= 0 {
+		result.TokenPolicy.RefreshTokenTTL = defaultRefreshTokenTTL
+	}
+	if result.ExpiredTokenGrace <= 0 {
+		result.ExpiredTokenGrace = defaultExpiredTokenGrace
+	}
+	if result.ImpersonationTokenTTL <= 0 {
+		result.ImpersonationTokenTTL = defaultImpersonationTokenTTL
+	}
+	return result, nil
+}
@@ -0,0 +1,13 @@
+package policyrepository
+
+import "go-bootstrap/internal/infrastructure"
+
+type repository struct {
+	rolePolicy *infrastructure.RolePolicy
+}
+
+func NewRepository(rolePolicy *infrastructure.RolePolicy) *repository {
+	return &repository{
+		rolePolicy: rolePolicy,
+	}
+}
@@ -0,0 +1,20 @@
+package policyrepository
+
+import (
+	"context"
+
+	domainpolicy "go-bootstrap/internal/domain/policy"
+)
+
+func (r *repository) GetRolePermissions(ctx context.Context, params domainpolicy.GetRolePermissionsParams) (domainpolicy.GetRolePermissionsResult, error) {
+	permissions := r.rolePolicy.Permissions(params.Role)
+
+	result := domainpolicy.GetRolePermissionsResult{
+		Permissions: make([]domainpolicy.Permission, 0, len(permissions)),
+	}
+	for _, v := range permissions {
+		result.Permissions = append(result.Permissions, domainpolicy.Permission(v))
+	}
+
+	return result, nil
+}
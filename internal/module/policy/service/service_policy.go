@@ -0,0 +1,47 @@
+package policyservice
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	domainpolicy "go-bootstrap/internal/domain/policy"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+)
+
+type service struct {
+	policyRepo domainpolicy.PolicyRepository
+}
+
+func NewService(
+	policyRepo domainpolicy.PolicyRepository,
+) *service {
+	return &service{
+		policyRepo: policyRepo,
+	}
+}
+
+func (s *service) Authorize(ctx context.Context, input domainpolicy.AuthorizeInput) (domainpolicy.AuthorizeOutput, error) {
+	result, err := s.policyRepo.GetRolePermissions(ctx, domainpolicy.GetRolePermissionsParams{
+		Role: input.Role,
+	})
+	if err != nil {
+		return domainpolicy.AuthorizeOutput{}, apperror.StdUnknown(err)
+	}
+
+	if !slices.Contains(result.Permissions, domainpolicy.PermissionAll) {
+		for _, v := range input.Permissions {
+			if !slices.Contains(result.Permissions, v) {
+				return domainpolicy.AuthorizeOutput{}, apperror.Forbidden(
+					fmt.Sprintf("role %q lacks permission %q", input.Role, v),
+					apperror.WithPublicMessage("you do not have permission to perform this action"),
+				)
+			}
+		}
+	}
+
+	return domainpolicy.AuthorizeOutput{
+		Granted: result.Permissions,
+	}, nil
+}
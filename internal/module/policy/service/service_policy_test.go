@@ -0,0 +1,54 @@
+package policyservice_test
+
+import (
+	"context"
+	"testing"
+
+	"go-bootstrap/internal/config"
+	domainpolicy "go-bootstrap/internal/domain/policy"
+	"go-bootstrap/internal/infrastructure"
+	policyrepository "go-bootstrap/internal/module/policy/repository"
+	policyservice "go-bootstrap/internal/module/policy/service"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Authorize(t *testing.T) {
+	rolePolicy := infrastructure.NewRolePolicyFromConfig(config.Policy{
+		Roles: []config.PolicyRole{
+			{Role: "admin", Permissions: []string{"*"}},
+			{Role: "support", Permissions: []string{"users:list"}},
+			{Role: "user"},
+		},
+	})
+	svc := policyservice.NewService(policyrepository.NewRepository(rolePolicy))
+
+	tests := []struct {
+		name        string
+		role        string
+		permissions []domainpolicy.Permission
+		allowed     bool
+	}{
+		{"wildcard grants everything", "admin", []domainpolicy.Permission{domainpolicy.PermissionUsersList, domainpolicy.PermissionUsersUpdateStatus}, true},
+		{"granted permission", "support", []domainpolicy.Permission{domainpolicy.PermissionUsersList}, true},
+		{"missing one of several", "support", []domainpolicy.Permission{domainpolicy.PermissionUsersList, domainpolicy.PermissionUsersUpdateStatus}, false},
+		{"role without permissions", "user", []domainpolicy.Permission{domainpolicy.PermissionUsersList}, false},
+		{"unknown role", "guest", []domainpolicy.Permission{domainpolicy.PermissionUsersList}, false},
+		{"nothing requested", "user", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Authorize(context.Background(), domainpolicy.AuthorizeInput{
+				Role:        tt.role,
+				Permissions: tt.permissions,
+			})
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, apperror.IsForbidden(err), "expected forbidden, got %v", err)
+		})
+	}
+}
@@ -11,3 +11,67 @@ func NewRepository(db infrastructure.DB) *repository {
 		db: db,
 	}
 }
+
+type passwordResetRepository struct {
+	db          infrastructure.DB
+	tokenHasher *infrastructure.TokenHasher
+}
+
+func NewPasswordResetRepository(db infrastructure.DB, tokenHasher *infrastructure.TokenHasher) *passwordResetRepository {
+	return &passwordResetRepository{
+		db:          db,
+		tokenHasher: tokenHasher,
+	}
+}
+
+type emailVerificationRepository struct {
+	db          infrastructure.DB
+	tokenHasher *infrastructure.TokenHasher
+}
+
+func NewEmailVerificationRepository(db infrastructure.DB, tokenHasher *infrastructure.TokenHasher) *emailVerificationRepository {
+	return &emailVerificationRepository{
+		db:          db,
+		tokenHasher: tokenHasher,
+	}
+}
+
+type authTokenRepository struct {
+	db infrastructure.DB
+}
+
+func NewAuthTokenRepository(db infrastructure.DB) *authTokenRepository {
+	return &authTokenRepository{
+		db: db,
+	}
+}
+
+type auditLogRepository struct {
+	db infrastructure.DB
+}
+
+func NewAuditLogRepository(db infrastructure.DB) *auditLogRepository {
+	return &auditLogRepository{
+		db: db,
+	}
+}
+
+type notifierRepository struct {
+	notifier infrastructure.Notifier
+}
+
+func NewNotifierRepository(notifier infrastructure.Notifier) *notifierRepository {
+	return &notifierRepository{
+		notifier: notifier,
+	}
+}
+
+type erasureRepository struct {
+	db infrastructure.DB
+}
+
+func NewErasureRepository(db infrastructure.DB) *erasureRepository {
+	return &erasureRepository{
+		db: db,
+	}
+}
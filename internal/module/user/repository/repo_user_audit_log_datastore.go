@@ -0,0 +1,53 @@
+package userrepository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	domainuser "go-bootstrap/internal/domain/user"
+)
+
+// CreateAuditLog stores details as a JSON object, an empty actor or target
+// as NULL.
+func (r *auditLogRepository) CreateAuditLog(ctx context.Context, params domainuser.CreateAuditLogParams) (domainuser.CreateAuditLogResult, error) {
+	entry := params.Entry
+
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return domainuser.CreateAuditLogResult{}, fmt.Errorf("failed to marshal audit log details: %w", err)
+	}
+	if entry.Details == nil {
+		details = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO audit_logs (action, outcome, reason, actor_user_id, target_user_id, details, ip_address, user_agent, request_id, created_at)
+		VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		entry.Action,
+		entry.Outcome,
+		entry.Reason,
+		entry.ActorUserID,
+		entry.TargetUserID,
+		string(details),
+		entry.IPAddress,
+		entry.UserAgent,
+		entry.RequestID,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return domainuser.CreateAuditLogResult{}, fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return domainuser.CreateAuditLogResult{}, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return domainuser.CreateAuditLogResult{
+		ID: fmt.Sprintf("%d", id),
+	}, nil
+}
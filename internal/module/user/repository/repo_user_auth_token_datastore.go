@@ -0,0 +1,57 @@
+package userrepository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainuser "go-bootstrap/internal/domain/user"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases/sqlx"
+)
+
+// RevokeUserTokens revokes every active or rotated token of the user, across
+// all token families, and ends their sessions, so every session has to log in
+// again.
+func (r *authTokenRepository) RevokeUserTokens(ctx context.Context, params domainuser.RevokeUserTokensParams) (domainuser.RevokeUserTokensResult, error) {
+	query := `
+		UPDATE auth_tokens
+		SET status = 'revoked', updated_at = ?
+		WHERE user_id = ? AND status IN ('active', 'rotated')
+	`
+
+	revokedAt := time.Now().UTC()
+	var rowsAffected int64
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		result, err := tx.ExecContext(ctx, query,
+			revokedAt,
+			params.UserID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to revoke user tokens: %w", err)
+		}
+
+		rowsAffected, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE auth_sessions
+			SET revoked_at = ?
+			WHERE user_id = ? AND revoked_at IS NULL
+		`, revokedAt, params.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke user sessions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domainuser.RevokeUserTokensResult{}, err
+	}
+
+	return domainuser.RevokeUserTokensResult{
+		RevokedCount: rowsAffected,
+		RevokedAt:    revokedAt,
+	}, nil
+}
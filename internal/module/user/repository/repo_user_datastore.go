@@ -9,15 +9,15 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases/sqlx"
 
-	sharedkernel "go-bootstrap/internal/domain/shared"
 	domainuser "go-bootstrap/internal/domain/user"
 )
 
 func (r *repository) CreateUser(ctx context.Context, params domainuser.CreateUserParams) (domainuser.CreateUserResult, error) {
 	query := `
-		INSERT INTO users (email, password_hash, name, role, status, phone, gender, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO users (email, password_hash, name, role, status, phone, gender, password_change_required, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now().UTC()
@@ -26,9 +26,10 @@ func (r *repository) CreateUser(ctx context.Context, params domainuser.CreateUse
 		params.PasswordHash,
 		params.Name,
 		params.Role,
-		sharedkernel.UserStatusActive,
+		params.Status,
 		params.Phone,
 		params.Gender,
+		params.PasswordChangeRequired,
 		now,
 		now,
 	)
@@ -47,7 +48,7 @@ func (r *repository) CreateUser(ctx context.Context, params domainuser.CreateUse
 		Email:     params.Email,
 		Name:      params.Name,
 		Role:      params.Role,
-		Status:    sharedkernel.UserStatusActive,
+		Status:    params.Status,
 		CreatedAt: now,
 	}, nil
 }
@@ -62,8 +63,11 @@ func (r *repository) GetDetailUser(ctx context.Context, filters domainuser.GetDe
 		"status",
 		"phone",
 		"gender",
+		"password_change_required",
 		"created_at",
 		"updated_at",
+		"deleted_at",
+		"erased_at",
 	).From("users")
 
 	if filters.UserID != nil {
@@ -74,6 +78,12 @@ func (r *repository) GetDetailUser(ctx context.Context, filters domainuser.GetDe
 		sq = sq.Where("email = ?", *filters.Email)
 	}
 
+	if filters.Deleted {
+		sq = sq.Where("deleted_at IS NOT NULL")
+	} else {
+		sq = sq.Where("deleted_at IS NULL")
+	}
+
 	sq = sq.Limit(1)
 
 	var result domainuser.GetDetailUserResult
@@ -91,8 +101,11 @@ func (r *repository) GetDetailUser(ctx context.Context, filters domainuser.GetDe
 		&result.Status,
 		&result.Phone,
 		&result.Gender,
+		&result.PasswordChangeRequired,
 		&result.CreatedAt,
 		&result.UpdatedAt,
+		&result.DeletedAt,
+		&result.ErasedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -105,7 +118,7 @@ func (r *repository) GetDetailUser(ctx context.Context, filters domainuser.GetDe
 }
 
 func (r *repository) GetListUser(ctx context.Context, filters domainuser.GetListUserFilters) (domainuser.GetListUserResult, error) {
-	countSq := r.db.Sq().Select("COUNT(*)").From("users")
+	countSq := r.db.Sq().Select("COUNT(*)").From("users").Where("deleted_at IS NULL")
 
 	selectSq := r.db.Sq().Select(
 		"id",
@@ -116,9 +129,11 @@ func (r *repository) GetListUser(ctx context.Context, filters domainuser.GetList
 		"status",
 		"phone",
 		"gender",
+		"password_change_required",
 		"created_at",
 		"updated_at",
-	).From("users")
+		"deleted_at",
+	).From("users").Where("deleted_at IS NULL")
 
 	if filters.Search != nil {
 		searchPattern := "%" + *filters.Search + "%"
@@ -155,8 +170,10 @@ func (r *repository) GetListUser(ctx context.Context, filters domainuser.GetList
 				&user.Status,
 				&user.Phone,
 				&user.Gender,
+				&user.PasswordChangeRequired,
 				&user.CreatedAt,
 				&user.UpdatedAt,
+				&user.DeletedAt,
 			)
 			if err != nil {
 				return fmt.Errorf("failed to scan user: %w", err)
@@ -193,7 +210,7 @@ func (r *repository) UpdateUser(ctx context.Context, params domainuser.UpdateUse
 		updateSq = updateSq.Set("gender", *params.Gender)
 	}
 
-	updateSq = updateSq.Where("id = ?", params.UserID)
+	updateSq = updateSq.Where("id = ? AND deleted_at IS NULL", params.UserID)
 
 	_, err := r.db.RDBMS().ExecSq(ctx, updateSq, false)
 	if err != nil {
@@ -208,8 +225,8 @@ func (r *repository) UpdateUser(ctx context.Context, params domainuser.UpdateUse
 func (r *repository) UpdatePassword(ctx context.Context, params domainuser.UpdatePasswordParams) (domainuser.UpdatePasswordResult, error) {
 	query := `
 		UPDATE users
-		SET password_hash = ?, updated_at = ?
-		WHERE id = ?
+		SET password_hash = ?, password_change_required = FALSE, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	updatedAt := time.Now().UTC()
@@ -232,21 +249,154 @@ func (r *repository) UpdateStatus(ctx context.Context, params domainuser.UpdateS
 	query := `
 		UPDATE users
 		SET status = ?, updated_at = ?
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	result := domainuser.UpdateStatusResult{
+		UpdatedAt: time.Now().UTC(),
+	}
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		if params.KeepActiveAdmin {
+			lastAdmin, err := lockLastActiveAdmin(ctx, tx, params.UserID)
+			if err != nil {
+				return err
+			}
+			if lastAdmin {
+				result.LastActiveAdmin = true
+				return nil
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, query,
+			params.Status,
+			result.UpdatedAt,
+			params.UserID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update status: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domainuser.UpdateStatusResult{}, err
+	}
+
+	return result, nil
+}
+
+// lockLastActiveAdmin locks the rows of the active admins that are not
+// deleted until tx ends and tells whether userID is the only one of them.
+// Two admins taking each other out wait for one another here, the second
+// one sees the first change and is refused.
+func lockLastActiveAdmin(ctx context.Context, tx sqlx.RDBMS, userID string) (bool, error) {
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE id = ?)
+		FROM (
+			SELECT id FROM users
+			WHERE role = 'admin' AND status = 'active' AND deleted_at IS NULL
+			FOR UPDATE
+		) admins
+	`
+
+	var count, self int64
+	err := tx.QueryRowContext(ctx, query, userID).Scan(&count, &self)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock active admins: %w", err)
+	}
+
+	return self > 0 && count <= 1, nil
+}
+
+func (r *repository) CountUser(ctx context.Context, filters domainuser.CountUserFilters) (domainuser.CountUserResult, error) {
+	countSq := r.db.Sq().Select("COUNT(*)").From("users").Where("deleted_at IS NULL")
+
+	if filters.Status != nil {
+		countSq = countSq.Where("status = ?", *filters.Status)
+	}
+
+	if filters.Role != nil {
+		countSq = countSq.Where("role = ?", *filters.Role)
+	}
+
+	row, err := r.db.RDBMS().QueryRowSq(ctx, countSq, false)
+	if err != nil {
+		return domainuser.CountUserResult{}, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	var result domainuser.CountUserResult
+	if err = row.Scan(&result.Count); err != nil {
+		return domainuser.CountUserResult{}, fmt.Errorf("failed to scan user count: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *repository) SoftDeleteUser(ctx context.Context, params domainuser.SoftDeleteUserParams) (domainuser.SoftDeleteUserResult, error) {
+	query := `
+		UPDATE users
+		SET deleted_at = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	var result domainuser.SoftDeleteUserResult
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		if params.KeepActiveAdmin {
+			lastAdmin, err := lockLastActiveAdmin(ctx, tx, params.UserID)
+			if err != nil {
+				return err
+			}
+			if lastAdmin {
+				result.LastActiveAdmin = true
+				return nil
+			}
+		}
+
+		res, err := tx.ExecContext(ctx, query,
+			params.DeletedAt,
+			params.DeletedAt,
+			params.UserID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to soft delete user: %w", err)
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		result.Success = rowsAffected > 0
+		return nil
+	})
+	if err != nil {
+		return domainuser.SoftDeleteUserResult{}, err
+	}
+
+	return result, nil
+}
+
+func (r *repository) RestoreUser(ctx context.Context, params domainuser.RestoreUserParams) (domainuser.RestoreUserResult, error) {
+	query := `
+		UPDATE users
+		SET deleted_at = NULL, updated_at = ?
+		WHERE id = ? AND deleted_at IS NOT NULL AND erased_at IS NULL
 	`
 
 	updatedAt := time.Now().UTC()
-	_, err := r.db.RDBMS().ExecContext(ctx, query,
-		params.Status,
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
 		updatedAt,
 		params.UserID,
 	)
+	if err != nil {
+		return domainuser.RestoreUserResult{}, fmt.Errorf("failed to restore user: %w", err)
+	}
 
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return domainuser.UpdateStatusResult{}, fmt.Errorf("failed to update status: %w", err)
+		return domainuser.RestoreUserResult{}, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
-	return domainuser.UpdateStatusResult{
+	return domainuser.RestoreUserResult{
+		Success:   rowsAffected > 0,
 		UpdatedAt: updatedAt,
 	}, nil
 }
@@ -0,0 +1,116 @@
+package userrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	domainuser "go-bootstrap/internal/domain/user"
+)
+
+func (r *emailVerificationRepository) CreateEmailVerificationToken(ctx context.Context, params domainuser.CreateEmailVerificationTokenParams) (domainuser.CreateEmailVerificationTokenResult, error) {
+	query := `
+		INSERT INTO email_verification_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	tokenHash, err := r.tokenHasher.Hash(params.Token)
+	if err != nil {
+		return domainuser.CreateEmailVerificationTokenResult{}, fmt.Errorf("failed to hash email verification token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.UserID,
+		tokenHash,
+		params.ExpiresAt,
+		now,
+	)
+	if err != nil {
+		return domainuser.CreateEmailVerificationTokenResult{}, fmt.Errorf("failed to create email verification token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return domainuser.CreateEmailVerificationTokenResult{}, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return domainuser.CreateEmailVerificationTokenResult{
+		ID:        fmt.Sprintf("%d", id),
+		CreatedAt: now,
+	}, nil
+}
+
+func (r *emailVerificationRepository) UseEmailVerificationToken(ctx context.Context, params domainuser.UseEmailVerificationTokenParams) (domainuser.UseEmailVerificationTokenResult, error) {
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = ?
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+	`
+
+	tokenHash, err := r.tokenHasher.Hash(params.Token)
+	if err != nil {
+		return domainuser.UseEmailVerificationTokenResult{}, fmt.Errorf("failed to hash email verification token: %w", err)
+	}
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.UsedAt,
+		tokenHash,
+		params.UsedAt,
+	)
+	if err != nil {
+		return domainuser.UseEmailVerificationTokenResult{}, fmt.Errorf("failed to use email verification token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainuser.UseEmailVerificationTokenResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domainuser.UseEmailVerificationTokenResult{
+			Success: false,
+		}, nil
+	}
+
+	var userID string
+	err = r.db.RDBMS().QueryRowContext(ctx,
+		`SELECT user_id FROM email_verification_tokens WHERE token_hash = ?`,
+		tokenHash,
+	).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainuser.UseEmailVerificationTokenResult{
+				Success: false,
+			}, nil
+		}
+		return domainuser.UseEmailVerificationTokenResult{}, fmt.Errorf("failed to get email verification token owner: %w", err)
+	}
+
+	return domainuser.UseEmailVerificationTokenResult{
+		Success: true,
+		UserID:  userID,
+	}, nil
+}
+
+func (r *emailVerificationRepository) DeleteEmailVerificationTokens(ctx context.Context, params domainuser.DeleteEmailVerificationTokensParams) (domainuser.DeleteEmailVerificationTokensResult, error) {
+	query := `
+		DELETE FROM email_verification_tokens
+		WHERE user_id = ? AND used_at IS NULL
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query, params.UserID)
+	if err != nil {
+		return domainuser.DeleteEmailVerificationTokensResult{}, fmt.Errorf("failed to delete email verification tokens: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainuser.DeleteEmailVerificationTokensResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainuser.DeleteEmailVerificationTokensResult{
+		DeletedCount: rowsAffected,
+	}, nil
+}
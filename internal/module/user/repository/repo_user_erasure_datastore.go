@@ -0,0 +1,254 @@
+package userrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainuser "go-bootstrap/internal/domain/user"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases/sqlx"
+)
+
+const erasureJobColumns = `id, user_id, requested_by, status, error, created_at, started_at, completed_at`
+
+func (r *erasureRepository) CreateErasureJob(ctx context.Context, params domainuser.CreateErasureJobParams) (domainuser.CreateErasureJobResult, error) {
+	query := `
+		INSERT INTO user_erasure_jobs (user_id, requested_by, status, created_at)
+		VALUES ($1, NULLIF($2, '')::BIGINT, 'pending', $3)
+		RETURNING id
+	`
+
+	var result domainuser.CreateErasureJobResult
+	err := r.db.RDBMS().QueryRowContext(ctx, query,
+		params.UserID,
+		params.RequestedBy,
+		params.CreatedAt,
+	).Scan(&result.ID)
+	if err != nil {
+		return domainuser.CreateErasureJobResult{}, fmt.Errorf("failed to create erasure job: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *erasureRepository) GetDetailErasureJob(ctx context.Context, filters domainuser.GetDetailErasureJobFilters) (domainuser.GetDetailErasureJobResult, error) {
+	sq := r.db.Sq().Select(
+		"id",
+		"user_id",
+		"requested_by",
+		"status",
+		"error",
+		"created_at",
+		"started_at",
+		"completed_at",
+	).From("user_erasure_jobs")
+
+	if filters.JobID != nil {
+		sq = sq.Where("id = ?", *filters.JobID)
+	}
+
+	if filters.UserID != nil {
+		sq = sq.Where("user_id = ?", *filters.UserID)
+	}
+
+	if filters.Unfinished {
+		sq = sq.Where("status IN ('pending', 'running')")
+	}
+
+	sq = sq.OrderBy("id DESC").Limit(1)
+
+	row, err := r.db.RDBMS().QueryRowSq(ctx, sq, false)
+	if err != nil {
+		return domainuser.GetDetailErasureJobResult{}, fmt.Errorf("failed to get erasure job: %w", err)
+	}
+
+	job, err := scanErasureJob(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainuser.GetDetailErasureJobResult{}, databases.ErrNoRowFound
+		}
+		return domainuser.GetDetailErasureJobResult{}, fmt.Errorf("failed to scan erasure job: %w", err)
+	}
+
+	return domainuser.GetDetailErasureJobResult{
+		Job: job,
+	}, nil
+}
+
+// ClaimErasureJob locks the pending job with SKIP LOCKED, so a job claimed by
+// another worker is passed over instead of waited for.
+func (r *erasureRepository) ClaimErasureJob(ctx context.Context, params domainuser.ClaimErasureJobParams) (domainuser.ClaimErasureJobResult, error) {
+	query := `
+		UPDATE user_erasure_jobs
+		SET status = 'running', started_at = $1
+		WHERE id = (
+			SELECT id FROM user_erasure_jobs
+			WHERE status = 'pending' AND ($2::BIGINT IS NULL OR id = $2::BIGINT)
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + erasureJobColumns
+
+	job, err := scanErasureJob(r.db.RDBMS().QueryRowContext(ctx, query,
+		params.StartedAt,
+		params.JobID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainuser.ClaimErasureJobResult{}, nil
+		}
+		return domainuser.ClaimErasureJobResult{}, fmt.Errorf("failed to claim erasure job: %w", err)
+	}
+
+	return domainuser.ClaimErasureJobResult{
+		Success: true,
+		Job:     job,
+	}, nil
+}
+
+func (r *erasureRepository) FinishErasureJob(ctx context.Context, params domainuser.FinishErasureJobParams) (domainuser.FinishErasureJobResult, error) {
+	query := `
+		UPDATE user_erasure_jobs
+		SET status = $1, error = $2, completed_at = $3
+		WHERE id = $4 AND status = 'running'
+	`
+
+	_, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.Status,
+		params.Error,
+		params.CompletedAt,
+		params.JobID,
+	)
+	if err != nil {
+		return domainuser.FinishErasureJobResult{}, fmt.Errorf("failed to finish erasure job: %w", err)
+	}
+
+	return domainuser.FinishErasureJobResult{}, nil
+}
+
+// erasedUserQueries delete the rows of the user that hold personal data or
+// credentials. $1 is the user id, or the email the user had when byEmail is
+// set: lockout counters are keyed by the lower-cased email, failed logins and
+// lockout clears keep it in the audit log details.
+var erasedUserQueries = []struct {
+	name    string
+	byEmail bool
+	query   string
+}{
+	{"auth tokens", false, `DELETE FROM auth_tokens WHERE user_id = $1`},
+	{"auth sessions", false, `DELETE FROM auth_sessions WHERE user_id = $1`},
+	{"api keys", false, `DELETE FROM api_keys WHERE user_id = $1`},
+	{"user identities", false, `DELETE FROM user_identities WHERE user_id = $1`},
+	{"mfa totp", false, `DELETE FROM auth_mfa_totp WHERE user_id = $1`},
+	{"mfa recovery codes", false, `DELETE FROM auth_mfa_recovery_codes WHERE user_id = $1`},
+	{"mfa challenges", false, `DELETE FROM auth_mfa_challenges WHERE user_id = $1`},
+	{"magic links", false, `DELETE FROM auth_magic_links WHERE user_id = $1`},
+	{"password reset tokens", false, `DELETE FROM password_reset_tokens WHERE user_id = $1`},
+	{"email verification tokens", false, `DELETE FROM email_verification_tokens WHERE user_id = $1`},
+	{"audit log request meta", false, `
+		UPDATE audit_logs
+		SET ip_address = '', user_agent = ''
+		WHERE actor_user_id = $1 OR target_user_id = $1
+	`},
+	{"login attempts", true, `DELETE FROM auth_login_attempts WHERE scope = 'email' AND identifier = LOWER($1)`},
+	{"audit log emails", true, `
+		UPDATE audit_logs
+		SET details = (details::JSONB - 'email')::TEXT
+		WHERE LOWER(details::JSONB ->> 'email') = LOWER($1)
+	`},
+	{"audit log lockout identifiers", true, `
+		UPDATE audit_logs
+		SET details = (details::JSONB - 'identifier')::TEXT
+		WHERE details::JSONB ->> 'scope' = 'email' AND details::JSONB ->> 'identifier' = LOWER($1)
+	`},
+}
+
+func (r *erasureRepository) EraseUser(ctx context.Context, params domainuser.EraseUserParams) (domainuser.EraseUserResult, error) {
+	var result domainuser.EraseUserResult
+	err := r.db.Tx().DoTxContext(ctx, nil, func(ctx context.Context, tx sqlx.RDBMS) error {
+		var email string
+		err := tx.QueryRowContext(ctx, `
+			SELECT email FROM users
+			WHERE id = $1 AND erased_at IS NULL
+			FOR UPDATE
+		`, params.UserID).Scan(&email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
+
+		// the job runs long after it was requested, the other admins may
+		// have been deactivated or deleted since
+		lastAdmin, err := lockLastActiveAdmin(ctx, tx, params.UserID)
+		if err != nil {
+			return err
+		}
+		if lastAdmin {
+			result.LastActiveAdmin = true
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE users
+			SET email = $1, name = $2, password_hash = $3, phone = NULL, gender = NULL,
+				status = 'inactive', password_change_required = FALSE,
+				deleted_at = COALESCE(deleted_at, $4), erased_at = $4, updated_at = $4
+			WHERE id = $5
+		`, params.Email, params.Name, params.PasswordHash, params.ErasedAt, params.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to anonymise user: %w", err)
+		}
+
+		for _, v := range erasedUserQueries {
+			arg := params.UserID
+			if v.byEmail {
+				arg = email
+			}
+			_, err = tx.ExecContext(ctx, v.query, arg)
+			if err != nil {
+				return fmt.Errorf("failed to erase %s: %w", v.name, err)
+			}
+		}
+
+		result.Success = true
+		return nil
+	})
+	if err != nil {
+		return domainuser.EraseUserResult{}, err
+	}
+
+	return result, nil
+}
+
+type erasureJobScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanErasureJob(row erasureJobScanner) (domainuser.ErasureJob, error) {
+	var (
+		job         domainuser.ErasureJob
+		requestedBy sql.NullString
+	)
+	err := row.Scan(
+		&job.ID,
+		&job.UserID,
+		&requestedBy,
+		&job.Status,
+		&job.Error,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.CompletedAt,
+	)
+	if err != nil {
+		return domainuser.ErasureJob{}, err
+	}
+	job.RequestedBy = requestedBy.String
+
+	return job, nil
+}
@@ -0,0 +1,54 @@
+package userrepository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainuser "go-bootstrap/internal/domain/user"
+	"go-bootstrap/internal/infrastructure"
+)
+
+func (r *notifierRepository) SendPasswordReset(ctx context.Context, params domainuser.SendPasswordResetParams) (domainuser.SendPasswordResetResult, error) {
+	body := fmt.Sprintf(
+		"Hi %s,\n\nUse the link below to reset your password. It expires at %s.\n\n%s\n\nIf you did not ask for a password reset you can ignore this message.",
+		params.Name,
+		params.ExpiresAt.UTC().Format(time.RFC1123),
+		params.ResetLink,
+	)
+
+	err := r.notifier.Notify(ctx, infrastructure.Notification{
+		Kind:      "password_reset",
+		To:        params.Email,
+		Subject:   "Reset your password",
+		Body:      body,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return domainuser.SendPasswordResetResult{}, fmt.Errorf("failed to send password reset: %w", err)
+	}
+
+	return domainuser.SendPasswordResetResult{}, nil
+}
+
+func (r *notifierRepository) SendEmailVerification(ctx context.Context, params domainuser.SendEmailVerificationParams) (domainuser.SendEmailVerificationResult, error) {
+	body := fmt.Sprintf(
+		"Hi %s,\n\nConfirm your email address with the link below. It expires at %s.\n\n%s\n\nIf you did not create an account you can ignore this message.",
+		params.Name,
+		params.ExpiresAt.UTC().Format(time.RFC1123),
+		params.VerificationLink,
+	)
+
+	err := r.notifier.Notify(ctx, infrastructure.Notification{
+		Kind:      "email_verification",
+		To:        params.Email,
+		Subject:   "Verify your email address",
+		Body:      body,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return domainuser.SendEmailVerificationResult{}, fmt.Errorf("failed to send email verification: %w", err)
+	}
+
+	return domainuser.SendEmailVerificationResult{}, nil
+}
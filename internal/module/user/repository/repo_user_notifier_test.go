@@ -0,0 +1,62 @@
+package userrepository_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-bootstrap/internal/config"
+	domainuser "go-bootstrap/internal/domain/user"
+	"go-bootstrap/internal/infrastructure"
+	userrepository "go-bootstrap/internal/module/user/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifierRepository_SendPasswordReset_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	notifier, err := infrastructure.NewNotifierFromConfig(config.Notifier{
+		Driver:   "file",
+		FilePath: path,
+	})
+	require.NoError(t, err)
+
+	repo := userrepository.NewNotifierRepository(notifier)
+	for range 2 {
+		_, err = repo.SendPasswordReset(context.Background(), domainuser.SendPasswordResetParams{
+			Email:     "user@example.com",
+			Name:      "User",
+			ResetLink: "https://app.example.com/reset-password?token=abc",
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n"))
+	require.Len(t, lines, 2, "every notification is appended as one line")
+
+	var notification infrastructure.Notification
+	require.NoError(t, json.Unmarshal(lines[0], &notification))
+	assert.Equal(t, "password_reset", notification.Kind)
+	assert.Equal(t, "user@example.com", notification.To)
+	assert.Contains(t, notification.Body, "https://app.example.com/reset-password?token=abc")
+}
+
+func TestNewNotifierFromConfig(t *testing.T) {
+	_, err := infrastructure.NewNotifierFromConfig(config.Notifier{})
+	assert.NoError(t, err, "log is the default driver")
+
+	_, err = infrastructure.NewNotifierFromConfig(config.Notifier{Driver: "file"})
+	assert.Error(t, err, "file driver needs a path")
+
+	_, err = infrastructure.NewNotifierFromConfig(config.Notifier{Driver: "smtp"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package userrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	domainuser "go-bootstrap/internal/domain/user"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+func (r *passwordResetRepository) CreatePasswordResetToken(ctx context.Context, params domainuser.CreatePasswordResetTokenParams) (domainuser.CreatePasswordResetTokenResult, error) {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	tokenHash, err := r.tokenHasher.Hash(params.Token)
+	if err != nil {
+		return domainuser.CreatePasswordResetTokenResult{}, fmt.Errorf("failed to hash password reset token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.UserID,
+		tokenHash,
+		params.ExpiresAt,
+		now,
+	)
+	if err != nil {
+		return domainuser.CreatePasswordResetTokenResult{}, fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return domainuser.CreatePasswordResetTokenResult{}, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return domainuser.CreatePasswordResetTokenResult{
+		ID:        fmt.Sprintf("%d", id),
+		CreatedAt: now,
+	}, nil
+}
+
+func (r *passwordResetRepository) GetDetailPasswordResetToken(ctx context.Context, filters domainuser.GetDetailPasswordResetTokenFilters) (domainuser.GetDetailPasswordResetTokenResult, error) {
+	query := `
+		SELECT user_id, expires_at, used_at
+		FROM password_reset_tokens
+		WHERE token_hash = ?
+	`
+
+	tokenHash, err := r.tokenHasher.Hash(filters.Token)
+	if err != nil {
+		return domainuser.GetDetailPasswordResetTokenResult{}, fmt.Errorf("failed to hash password reset token: %w", err)
+	}
+
+	var result domainuser.GetDetailPasswordResetTokenResult
+	var usedAt sql.NullTime
+	err = r.db.RDBMS().QueryRowContext(ctx, query, tokenHash).Scan(
+		&result.UserID,
+		&result.ExpiresAt,
+		&usedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainuser.GetDetailPasswordResetTokenResult{}, databases.ErrNoRowFound
+		}
+		return domainuser.GetDetailPasswordResetTokenResult{}, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+	if usedAt.Valid {
+		result.UsedAt = &usedAt.Time
+	}
+
+	return result, nil
+}
+
+func (r *passwordResetRepository) UsePasswordResetToken(ctx context.Context, params domainuser.UsePasswordResetTokenParams) (domainuser.UsePasswordResetTokenResult, error) {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = ?
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+	`
+
+	tokenHash, err := r.tokenHasher.Hash(params.Token)
+	if err != nil {
+		return domainuser.UsePasswordResetTokenResult{}, fmt.Errorf("failed to hash password reset token: %w", err)
+	}
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query,
+		params.UsedAt,
+		tokenHash,
+		params.UsedAt,
+	)
+	if err != nil {
+		return domainuser.UsePasswordResetTokenResult{}, fmt.Errorf("failed to use password reset token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainuser.UsePasswordResetTokenResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domainuser.UsePasswordResetTokenResult{
+			Success: false,
+		}, nil
+	}
+
+	var userID string
+	err = r.db.RDBMS().QueryRowContext(ctx,
+		`SELECT user_id FROM password_reset_tokens WHERE token_hash = ?`,
+		tokenHash,
+	).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainuser.UsePasswordResetTokenResult{
+				Success: false,
+			}, nil
+		}
+		return domainuser.UsePasswordResetTokenResult{}, fmt.Errorf("failed to get password reset token owner: %w", err)
+	}
+
+	return domainuser.UsePasswordResetTokenResult{
+		Success: true,
+		UserID:  userID,
+	}, nil
+}
+
+func (r *passwordResetRepository) DeletePasswordResetTokens(ctx context.Context, params domainuser.DeletePasswordResetTokensParams) (domainuser.DeletePasswordResetTokensResult, error) {
+	query := `
+		DELETE FROM password_reset_tokens
+		WHERE user_id = ? AND used_at IS NULL
+	`
+
+	result, err := r.db.RDBMS().ExecContext(ctx, query, params.UserID)
+	if err != nil {
+		return domainuser.DeletePasswordResetTokensResult{}, fmt.Errorf("failed to delete password reset tokens: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domainuser.DeletePasswordResetTokensResult{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return domainuser.DeletePasswordResetTokensResult{
+		DeletedCount: rowsAffected,
+	}, nil
+}
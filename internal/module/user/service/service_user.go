@@ -2,24 +2,58 @@ package userservice
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
+	"log/slog"
+	"net/url"
 
+	sharedkernel "go-bootstrap/internal/domain/shared"
 	domainuser "go-bootstrap/internal/domain/user"
 
 	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
 	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type service struct {
-	userRepo domainuser.UserRepositoryDatastore
+	userRepo                domainuser.UserRepositoryDatastore
+	passwordResetRepo       domainuser.PasswordResetRepositoryDatastore
+	emailVerificationRepo   domainuser.EmailVerificationRepositoryDatastore
+	authTokenRepo           domainuser.AuthTokenRepositoryDatastore
+	notifierRepo            domainuser.UserRepositoryNotifier
+	auditLogRepo            domainuser.AuditLogRepositoryDatastore
+	erasureRepo             domainuser.ErasureRepositoryDatastore
+	passwordHasher          sharedkernel.PasswordHasher
+	passwordResetPolicy     domainuser.PasswordResetPolicy
+	emailVerificationPolicy domainuser.EmailVerificationPolicy
+	passwordPolicy          domainuser.PasswordPolicy
 }
 
 func NewService(
 	userRepo domainuser.UserRepositoryDatastore,
+	passwordResetRepo domainuser.PasswordResetRepositoryDatastore,
+	emailVerificationRepo domainuser.EmailVerificationRepositoryDatastore,
+	authTokenRepo domainuser.AuthTokenRepositoryDatastore,
+	notifierRepo domainuser.UserRepositoryNotifier,
+	auditLogRepo domainuser.AuditLogRepositoryDatastore,
+	erasureRepo domainuser.ErasureRepositoryDatastore,
+	passwordHasher sharedkernel.PasswordHasher,
+	passwordResetPolicy domainuser.PasswordResetPolicy,
+	emailVerificationPolicy domainuser.EmailVerificationPolicy,
+	passwordPolicy domainuser.PasswordPolicy,
 ) *service {
 	return &service{
-		userRepo: userRepo,
+		userRepo:                userRepo,
+		passwordResetRepo:       passwordResetRepo,
+		emailVerificationRepo:   emailVerificationRepo,
+		authTokenRepo:           authTokenRepo,
+		notifierRepo:            notifierRepo,
+		auditLogRepo:            auditLogRepo,
+		erasureRepo:             erasureRepo,
+		passwordHasher:          passwordHasher,
+		passwordResetPolicy:     passwordResetPolicy,
+		emailVerificationPolicy: emailVerificationPolicy,
+		passwordPolicy:          passwordPolicy,
 	}
 }
 
@@ -31,16 +65,22 @@ func (s *service) Register(ctx context.Context, input domainuser.RegisterInput)
 		return domainuser.RegisterOutput{}, apperror.BadRequest("email already registered")
 	}
 
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	err := s.checkPassword("password", input.Password, input.Email, input.Name)
+	if err != nil {
+		return domainuser.RegisterOutput{}, err
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(input.Password)
 	if err != nil {
 		return domainuser.RegisterOutput{}, apperror.StdUnknown(err)
 	}
 
 	result, err := s.userRepo.CreateUser(ctx, domainuser.CreateUserParams{
 		Email:        input.Email,
-		PasswordHash: string(passwordHash),
+		PasswordHash: passwordHash,
 		Name:         input.Name,
 		Role:         domainuser.UserRoleUser,
+		Status:       sharedkernel.UserStatusPendingVerification,
 		Phone:        input.Phone,
 		Gender:       input.Gender,
 	})
@@ -48,10 +88,18 @@ func (s *service) Register(ctx context.Context, input domainuser.RegisterInput)
 		return domainuser.RegisterOutput{}, apperror.StdUnknown(err)
 	}
 
+	// the account exists at this point, a failed delivery is recovered
+	// through ResendVerification instead of failing the registration
+	err = s.sendEmailVerification(ctx, result.ID, result.Email, result.Name)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to send email verification", "user_id", result.ID, "error", err)
+	}
+
 	return domainuser.RegisterOutput{
 		UserID:    result.ID,
 		Email:     result.Email,
 		Name:      result.Name,
+		Status:    result.Status,
 		CreatedAt: result.CreatedAt,
 	}, nil
 }
@@ -69,15 +117,16 @@ func (s *service) GetProfile(ctx context.Context, input domainuser.GetProfileInp
 
 	return domainuser.GetProfileOutput{
 		User: domainuser.User{
-			ID:        user.ID,
-			Email:     user.Email,
-			Name:      user.Name,
-			Role:      user.Role,
-			Status:    user.Status,
-			Gender:    user.Gender,
-			Phone:     user.Phone,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
+			ID:                     user.ID,
+			Email:                  user.Email,
+			Name:                   user.Name,
+			Role:                   user.Role,
+			Status:                 user.Status,
+			Gender:                 user.Gender,
+			Phone:                  user.Phone,
+			CreatedAt:              user.CreatedAt,
+			UpdatedAt:              user.UpdatedAt,
+			PasswordChangeRequired: user.PasswordChangeRequired,
 		},
 	}, nil
 }
@@ -98,15 +147,16 @@ func (s *service) GetList(ctx context.Context, input domainuser.GetListInput) (d
 	users := make([]domainuser.User, 0, len(result.Users))
 	for _, u := range result.Users {
 		users = append(users, domainuser.User{
-			ID:        u.ID,
-			Email:     u.Email,
-			Name:      u.Name,
-			Role:      u.Role,
-			Status:    u.Status,
-			Gender:    u.Gender,
-			Phone:     u.Phone,
-			CreatedAt: u.CreatedAt,
-			UpdatedAt: u.UpdatedAt,
+			ID:                     u.ID,
+			Email:                  u.Email,
+			Name:                   u.Name,
+			Role:                   u.Role,
+			Status:                 u.Status,
+			Gender:                 u.Gender,
+			Phone:                  u.Phone,
+			CreatedAt:              u.CreatedAt,
+			UpdatedAt:              u.UpdatedAt,
+			PasswordChangeRequired: u.PasswordChangeRequired,
 		})
 	}
 
@@ -141,21 +191,26 @@ func (s *service) UpdateProfile(ctx context.Context, input domainuser.UpdateProf
 
 	return domainuser.UpdateProfileOutput{
 		User: domainuser.User{
-			ID:        updatedUser.ID,
-			Email:     updatedUser.Email,
-			Name:      updatedUser.Name,
-			Role:      updatedUser.Role,
-			Status:    updatedUser.Status,
-			Gender:    updatedUser.Gender,
-			Phone:     updatedUser.Phone,
-			CreatedAt: updatedUser.CreatedAt,
-			UpdatedAt: updatedUser.UpdatedAt,
+			ID:                     updatedUser.ID,
+			Email:                  updatedUser.Email,
+			Name:                   updatedUser.Name,
+			Role:                   updatedUser.Role,
+			Status:                 updatedUser.Status,
+			Gender:                 updatedUser.Gender,
+			Phone:                  updatedUser.Phone,
+			CreatedAt:              updatedUser.CreatedAt,
+			UpdatedAt:              updatedUser.UpdatedAt,
+			PasswordChangeRequired: updatedUser.PasswordChangeRequired,
 		},
 		UpdatedAt: result.UpdatedAt,
 	}, nil
 }
 
 func (s *service) ChangePassword(ctx context.Context, input domainuser.ChangePasswordInput) (domainuser.ChangePasswordOutput, error) {
+	if err := sharedkernel.DenyImpersonation(ctx); err != nil {
+		return domainuser.ChangePasswordOutput{}, err
+	}
+
 	user, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
 		UserID: &input.UserID,
 	})
@@ -166,24 +221,54 @@ func (s *service) ChangePassword(ctx context.Context, input domainuser.ChangePas
 		return domainuser.ChangePasswordOutput{}, apperror.StdUnknown(err)
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.OldPassword))
+	match, _, err := s.passwordHasher.Verify(input.OldPassword, user.PasswordHash)
 	if err != nil {
+		slog.WarnContext(ctx, "failed to verify password hash", "user_id", user.ID, "error", err)
+	}
+	if !match {
+		s.audit(ctx, sharedkernel.AuditEntry{
+			Action:       sharedkernel.AuditActionPasswordChange,
+			Outcome:      sharedkernel.AuditOutcomeFailure,
+			Reason:       "invalid_old_password",
+			ActorUserID:  input.UserID,
+			TargetUserID: input.UserID,
+		})
 		return domainuser.ChangePasswordOutput{}, apperror.BadRequest("invalid old password")
 	}
 
-	newPasswordHash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	err = s.checkPassword("new_password", input.NewPassword, user.Email, user.Name)
+	if err != nil {
+		return domainuser.ChangePasswordOutput{}, err
+	}
+
+	newPasswordHash, err := s.passwordHasher.Hash(input.NewPassword)
 	if err != nil {
 		return domainuser.ChangePasswordOutput{}, apperror.StdUnknown(err)
 	}
 
 	result, err := s.userRepo.UpdatePassword(ctx, domainuser.UpdatePasswordParams{
 		UserID:          input.UserID,
-		NewPasswordHash: string(newPasswordHash),
+		NewPasswordHash: newPasswordHash,
 	})
 	if err != nil {
 		return domainuser.ChangePasswordOutput{}, apperror.StdUnknown(err)
 	}
 
+	// sessions opened with the old password must log in again
+	_, err = s.authTokenRepo.RevokeUserTokens(ctx, domainuser.RevokeUserTokensParams{
+		UserID: input.UserID,
+	})
+	if err != nil {
+		return domainuser.ChangePasswordOutput{}, apperror.StdUnknown(err)
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionPasswordChange,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  input.UserID,
+		TargetUserID: input.UserID,
+	})
+
 	return domainuser.ChangePasswordOutput{
 		Success:   true,
 		UpdatedAt: result.UpdatedAt,
@@ -191,7 +276,17 @@ func (s *service) ChangePassword(ctx context.Context, input domainuser.ChangePas
 }
 
 func (s *service) UpdateStatus(ctx context.Context, input domainuser.UpdateStatusInput) (domainuser.UpdateStatusOutput, error) {
-	_, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+	switch input.Status {
+	case sharedkernel.UserStatusActive, sharedkernel.UserStatusInactive, sharedkernel.UserStatusSuspended:
+	default:
+		return domainuser.UpdateStatusOutput{}, apperror.BadRequest("invalid user status")
+	}
+
+	if input.ActorUserID == input.UserID {
+		return domainuser.UpdateStatusOutput{}, apperror.Forbidden("you cannot change your own status")
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
 		UserID: &input.UserID,
 	})
 	if err != nil {
@@ -201,13 +296,78 @@ func (s *service) UpdateStatus(ctx context.Context, input domainuser.UpdateStatu
 		return domainuser.UpdateStatusOutput{}, apperror.StdUnknown(err)
 	}
 
-	result, err := s.userRepo.UpdateStatus(ctx, domainuser.UpdateStatusParams(input))
+	errLastAdmin := apperror.Conflict("cannot deactivate the last active admin")
+	deactivate := !input.Status.IsActive()
+	if deactivate {
+		lastAdmin, err := s.isLastActiveAdmin(ctx, user)
+		if err != nil {
+			return domainuser.UpdateStatusOutput{}, apperror.StdUnknown(err)
+		}
+		if lastAdmin {
+			return domainuser.UpdateStatusOutput{}, errLastAdmin
+		}
+	}
+
+	// the repository checks again under a lock, another admin may have been
+	// deactivated since
+	result, err := s.userRepo.UpdateStatus(ctx, domainuser.UpdateStatusParams{
+		UserID:          input.UserID,
+		Status:          input.Status,
+		KeepActiveAdmin: deactivate,
+	})
 	if err != nil {
 		return domainuser.UpdateStatusOutput{}, apperror.StdUnknown(err)
 	}
+	if result.LastActiveAdmin {
+		return domainuser.UpdateStatusOutput{}, errLastAdmin
+	}
+
+	// a user that can no longer log in loses its outstanding tokens right away
+	if input.Status.CanLogin() != nil {
+		_, err = s.authTokenRepo.RevokeUserTokens(ctx, domainuser.RevokeUserTokensParams{
+			UserID: input.UserID,
+		})
+		if err != nil {
+			return domainuser.UpdateStatusOutput{}, apperror.StdUnknown(err)
+		}
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionUserStatusChange,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  input.ActorUserID,
+		TargetUserID: input.UserID,
+		Details: map[string]string{
+			"from": string(user.Status),
+			"to":   string(input.Status),
+		},
+	})
 
 	return domainuser.UpdateStatusOutput{
 		Success:   true,
 		UpdatedAt: result.UpdatedAt,
 	}, nil
 }
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(b), nil
+}
+
+// tokenLink adds token as the "token" query parameter of linkURL.
+func tokenLink(linkURL string, token string) (string, error) {
+	link, err := url.Parse(linkURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
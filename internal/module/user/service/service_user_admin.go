@@ -0,0 +1,249 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+var errUserNotFound = apperror.NotFound("user not found")
+
+func (s *service) CreateUser(ctx context.Context, input domainuser.CreateUserInput) (domainuser.CreateUserOutput, error) {
+	if !input.Role.IsValid() {
+		return domainuser.CreateUserOutput{}, apperror.BadRequest("invalid user role")
+	}
+
+	_, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+		Email: &input.Email,
+	})
+	if err == nil {
+		return domainuser.CreateUserOutput{}, apperror.Conflict("email already registered")
+	}
+	if !errors.Is(err, databases.ErrNoRowFound) {
+		return domainuser.CreateUserOutput{}, apperror.StdUnknown(err)
+	}
+
+	// without a temporary password the random one only fills the column
+	// until the user sets one through the reset link
+	var password string
+	if input.TemporaryPassword != nil {
+		password = *input.TemporaryPassword
+		err = s.checkPassword("temporary_password", password, input.Email, input.Name)
+		if err != nil {
+			return domainuser.CreateUserOutput{}, err
+		}
+	} else {
+		password, err = generateToken()
+		if err != nil {
+			return domainuser.CreateUserOutput{}, apperror.StdUnknown(err)
+		}
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return domainuser.CreateUserOutput{}, apperror.StdUnknown(err)
+	}
+
+	// the admin vouches for the email, the user starts active
+	result, err := s.userRepo.CreateUser(ctx, domainuser.CreateUserParams{
+		Email:                  input.Email,
+		PasswordHash:           passwordHash,
+		Name:                   input.Name,
+		Role:                   input.Role,
+		Status:                 sharedkernel.UserStatusActive,
+		Phone:                  input.Phone,
+		Gender:                 input.Gender,
+		PasswordChangeRequired: input.TemporaryPassword != nil,
+	})
+	if err != nil {
+		return domainuser.CreateUserOutput{}, apperror.StdUnknown(err)
+	}
+
+	if input.TemporaryPassword == nil {
+		_, err = s.RequestPasswordReset(ctx, domainuser.RequestPasswordResetInput{
+			Email: result.Email,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to send password reset for created user", "user_id", result.ID, "error", err)
+		}
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionUserCreate,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  input.ActorUserID,
+		TargetUserID: result.ID,
+		Details: map[string]string{
+			"role":               string(result.Role),
+			"temporary_password": strconv.FormatBool(input.TemporaryPassword != nil),
+		},
+	})
+
+	return domainuser.CreateUserOutput{
+		User: domainuser.User{
+			ID:                     result.ID,
+			Email:                  result.Email,
+			Name:                   result.Name,
+			Role:                   result.Role,
+			Status:                 result.Status,
+			Gender:                 input.Gender,
+			Phone:                  input.Phone,
+			PasswordChangeRequired: input.TemporaryPassword != nil,
+			CreatedAt:              result.CreatedAt,
+			UpdatedAt:              result.CreatedAt,
+		},
+	}, nil
+}
+
+func (s *service) DeleteUser(ctx context.Context, input domainuser.DeleteUserInput) (domainuser.DeleteUserOutput, error) {
+	if input.ActorUserID == input.UserID {
+		return domainuser.DeleteUserOutput{}, apperror.Forbidden("you cannot delete yourself")
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+		UserID: &input.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainuser.DeleteUserOutput{}, errUserNotFound
+		}
+		return domainuser.DeleteUserOutput{}, apperror.StdUnknown(err)
+	}
+
+	errLastAdmin := apperror.Conflict("cannot delete the last active admin")
+	lastAdmin, err := s.isLastActiveAdmin(ctx, user)
+	if err != nil {
+		return domainuser.DeleteUserOutput{}, apperror.StdUnknown(err)
+	}
+	if lastAdmin {
+		return domainuser.DeleteUserOutput{}, errLastAdmin
+	}
+
+	// the repository checks again under a lock, another admin may have been
+	// deactivated or deleted since
+	deletedAt := time.Now().UTC()
+	result, err := s.userRepo.SoftDeleteUser(ctx, domainuser.SoftDeleteUserParams{
+		UserID:          input.UserID,
+		DeletedAt:       deletedAt,
+		KeepActiveAdmin: true,
+	})
+	if err != nil {
+		return domainuser.DeleteUserOutput{}, apperror.StdUnknown(err)
+	}
+	if result.LastActiveAdmin {
+		return domainuser.DeleteUserOutput{}, errLastAdmin
+	}
+	if !result.Success {
+		return domainuser.DeleteUserOutput{}, errUserNotFound
+	}
+
+	// validation already skips a deleted user, revoking ends its sessions
+	// for good even if it is restored later
+	_, err = s.authTokenRepo.RevokeUserTokens(ctx, domainuser.RevokeUserTokensParams{
+		UserID: input.UserID,
+	})
+	if err != nil {
+		return domainuser.DeleteUserOutput{}, apperror.StdUnknown(err)
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionUserDelete,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  input.ActorUserID,
+		TargetUserID: input.UserID,
+	})
+
+	return domainuser.DeleteUserOutput{
+		Success:   true,
+		DeletedAt: deletedAt,
+	}, nil
+}
+
+func (s *service) RestoreUser(ctx context.Context, input domainuser.RestoreUserInput) (domainuser.RestoreUserOutput, error) {
+	deleted, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+		UserID:  &input.UserID,
+		Deleted: true,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainuser.RestoreUserOutput{}, apperror.NotFound("deleted user not found")
+		}
+		return domainuser.RestoreUserOutput{}, apperror.StdUnknown(err)
+	}
+	if deleted.ErasedAt != nil {
+		return domainuser.RestoreUserOutput{}, apperror.Conflict("an erased user cannot be restored")
+	}
+
+	// the email only has to be unique among users that are not deleted
+	_, err = s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+		Email: &deleted.Email,
+	})
+	if err == nil {
+		return domainuser.RestoreUserOutput{}, apperror.Conflict("email is used by another user")
+	}
+	if !errors.Is(err, databases.ErrNoRowFound) {
+		return domainuser.RestoreUserOutput{}, apperror.StdUnknown(err)
+	}
+
+	result, err := s.userRepo.RestoreUser(ctx, domainuser.RestoreUserParams{
+		UserID: input.UserID,
+	})
+	if err != nil {
+		return domainuser.RestoreUserOutput{}, apperror.StdUnknown(err)
+	}
+	if !result.Success {
+		return domainuser.RestoreUserOutput{}, apperror.NotFound("deleted user not found")
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionUserRestore,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  input.ActorUserID,
+		TargetUserID: input.UserID,
+	})
+
+	return domainuser.RestoreUserOutput{
+		User: domainuser.User{
+			ID:                     deleted.ID,
+			Email:                  deleted.Email,
+			Name:                   deleted.Name,
+			Role:                   deleted.Role,
+			Status:                 deleted.Status,
+			Gender:                 deleted.Gender,
+			Phone:                  deleted.Phone,
+			PasswordChangeRequired: deleted.PasswordChangeRequired,
+			CreatedAt:              deleted.CreatedAt,
+			UpdatedAt:              result.UpdatedAt,
+		},
+	}, nil
+}
+
+// isLastActiveAdmin tells whether user is an active admin that is not
+// deleted and no other one is left. It only refuses early with a clear
+// answer, the update itself has to pass KeepActiveAdmin to the repository
+// to be safe against concurrent requests.
+func (s *service) isLastActiveAdmin(ctx context.Context, user domainuser.GetDetailUserResult) (bool, error) {
+	if user.Role != domainuser.UserRoleAdmin || !user.Status.IsActive() || user.DeletedAt != nil {
+		return false, nil
+	}
+
+	activeStatus := sharedkernel.UserStatusActive
+	adminRole := domainuser.UserRoleAdmin
+	count, err := s.userRepo.CountUser(ctx, domainuser.CountUserFilters{
+		Status: &activeStatus,
+		Role:   &adminRole,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return count.Count <= 1, nil
+}
@@ -0,0 +1,188 @@
+package userservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestService_CreateUser(t *testing.T) {
+	ctx := context.Background()
+	temporaryPassword := "temporary-password"
+
+	t.Run("temporary password must be changed", func(t *testing.T) {
+		svc, deps := newTestService(&fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{}})
+		output, err := svc.CreateUser(ctx, domainuser.CreateUserInput{
+			ActorUserID:       "9",
+			Email:             "new@example.com",
+			Name:              "New Admin",
+			Role:              domainuser.UserRoleAdmin,
+			TemporaryPassword: &temporaryPassword,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, domainuser.UserRoleAdmin, output.User.Role)
+		assert.Equal(t, sharedkernel.UserStatusActive, output.User.Status)
+		assert.True(t, output.User.PasswordChangeRequired)
+
+		stored := deps.userRepo.users[output.User.ID]
+		assert.True(t, stored.PasswordChangeRequired)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte(temporaryPassword)))
+		assert.Empty(t, deps.notifierRepo.passwordResets)
+
+		require.Len(t, deps.auditLogRepo.entries, 1)
+		assert.Equal(t, sharedkernel.AuditActionUserCreate, deps.auditLogRepo.entries[0].Action)
+		assert.Equal(t, "9", deps.auditLogRepo.entries[0].ActorUserID)
+		assert.Equal(t, output.User.ID, deps.auditLogRepo.entries[0].TargetUserID)
+
+		// changing the password clears the flag
+		_, err = svc.ChangePassword(ctx, domainuser.ChangePasswordInput{
+			UserID:      output.User.ID,
+			OldPassword: temporaryPassword,
+			NewPassword: "new-password",
+		})
+		require.NoError(t, err)
+		assert.False(t, deps.userRepo.users[output.User.ID].PasswordChangeRequired)
+	})
+
+	t.Run("without a password a reset link is sent", func(t *testing.T) {
+		svc, deps := newTestService(&fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{}})
+		output, err := svc.CreateUser(ctx, domainuser.CreateUserInput{
+			ActorUserID: "9",
+			Email:       "new@example.com",
+			Name:        "New User",
+			Role:        domainuser.UserRoleUser,
+		})
+		require.NoError(t, err)
+		assert.False(t, output.User.PasswordChangeRequired)
+		require.Len(t, deps.notifierRepo.passwordResets, 1)
+		assert.Equal(t, "new@example.com", deps.notifierRepo.passwordResets[0].Email)
+	})
+
+	t.Run("refuses a taken email", func(t *testing.T) {
+		svc, _ := newTestService(&fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{
+			"1": {ID: "1", Email: "user@example.com", Status: sharedkernel.UserStatusActive},
+		}})
+		_, err := svc.CreateUser(ctx, domainuser.CreateUserInput{
+			Email: "user@example.com",
+			Name:  "Someone",
+			Role:  domainuser.UserRoleUser,
+		})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+	})
+
+	t.Run("refuses an unknown role", func(t *testing.T) {
+		svc, _ := newTestService(&fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{}})
+		_, err := svc.CreateUser(ctx, domainuser.CreateUserInput{
+			Email: "new@example.com",
+			Name:  "New User",
+			Role:  domainuser.UserRole("owner"),
+		})
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+	})
+}
+
+func TestService_DeleteUser(t *testing.T) {
+	ctx := context.Background()
+	newRepo := func() *fakeUserRepo {
+		return &fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{
+			"1": {ID: "1", Email: "admin@example.com", Role: domainuser.UserRoleAdmin, Status: sharedkernel.UserStatusActive},
+			"2": {ID: "2", Email: "user@example.com", Role: domainuser.UserRoleUser, Status: sharedkernel.UserStatusActive},
+		}}
+	}
+
+	t.Run("soft deletes and revokes tokens", func(t *testing.T) {
+		repo := newRepo()
+		svc, deps := newTestService(repo)
+		output, err := svc.DeleteUser(ctx, domainuser.DeleteUserInput{ActorUserID: "1", UserID: "2"})
+		require.NoError(t, err)
+		assert.True(t, output.Success)
+		assert.NotNil(t, repo.users["2"].DeletedAt)
+		assert.Equal(t, []string{"2"}, deps.authTokenRepo.revokedUserIDs)
+		require.Len(t, deps.auditLogRepo.entries, 1)
+		assert.Equal(t, sharedkernel.AuditActionUserDelete, deps.auditLogRepo.entries[0].Action)
+
+		_, err = svc.GetProfile(ctx, domainuser.GetProfileInput{UserID: "2"})
+		assert.Error(t, err)
+
+		_, err = svc.DeleteUser(ctx, domainuser.DeleteUserInput{ActorUserID: "1", UserID: "2"})
+		assert.True(t, apperror.IsNotFound(err), "expected not found, got %v", err)
+	})
+
+	t.Run("refuses to delete yourself", func(t *testing.T) {
+		repo := newRepo()
+		svc, _ := newTestService(repo)
+		_, err := svc.DeleteUser(ctx, domainuser.DeleteUserInput{ActorUserID: "2", UserID: "2"})
+		assert.True(t, apperror.IsForbidden(err), "expected forbidden, got %v", err)
+		assert.Nil(t, repo.users["2"].DeletedAt)
+	})
+
+	t.Run("refuses to delete the last active admin", func(t *testing.T) {
+		repo := newRepo()
+		svc, _ := newTestService(repo)
+		_, err := svc.DeleteUser(ctx, domainuser.DeleteUserInput{ActorUserID: "2", UserID: "1"})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+		assert.Nil(t, repo.users["1"].DeletedAt)
+	})
+
+	t.Run("admins cannot delete each other concurrently", func(t *testing.T) {
+		repo := newRepo()
+		repo.users["3"] = domainuser.GetDetailUserResult{ID: "3", Email: "other@example.com", Role: domainuser.UserRoleAdmin, Status: sharedkernel.UserStatusActive}
+		repo.beforeAdminGuard = func(f *fakeUserRepo) {
+			deletedAt := time.Now().UTC()
+			other := f.users["3"]
+			other.DeletedAt = &deletedAt
+			f.users["3"] = other
+		}
+		svc, deps := newTestService(repo)
+		_, err := svc.DeleteUser(ctx, domainuser.DeleteUserInput{ActorUserID: "3", UserID: "1"})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+		assert.Nil(t, repo.users["1"].DeletedAt)
+		assert.Empty(t, deps.authTokenRepo.revokedUserIDs)
+	})
+}
+
+func TestService_RestoreUser(t *testing.T) {
+	ctx := context.Background()
+	deletedAt := time.Now().UTC()
+
+	t.Run("restores a deleted user", func(t *testing.T) {
+		repo := &fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{
+			"2": {ID: "2", Email: "user@example.com", Status: sharedkernel.UserStatusActive, DeletedAt: &deletedAt},
+		}}
+		svc, deps := newTestService(repo)
+		output, err := svc.RestoreUser(ctx, domainuser.RestoreUserInput{ActorUserID: "1", UserID: "2"})
+		require.NoError(t, err)
+		assert.Equal(t, "user@example.com", output.User.Email)
+		assert.Nil(t, repo.users["2"].DeletedAt)
+		require.Len(t, deps.auditLogRepo.entries, 1)
+		assert.Equal(t, sharedkernel.AuditActionUserRestore, deps.auditLogRepo.entries[0].Action)
+	})
+
+	t.Run("refuses a user that is not deleted", func(t *testing.T) {
+		repo := &fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{
+			"2": {ID: "2", Email: "user@example.com", Status: sharedkernel.UserStatusActive},
+		}}
+		svc, _ := newTestService(repo)
+		_, err := svc.RestoreUser(ctx, domainuser.RestoreUserInput{ActorUserID: "1", UserID: "2"})
+		assert.True(t, apperror.IsNotFound(err), "expected not found, got %v", err)
+	})
+
+	t.Run("refuses while another user holds the email", func(t *testing.T) {
+		repo := &fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{
+			"2": {ID: "2", Email: "user@example.com", Status: sharedkernel.UserStatusActive, DeletedAt: &deletedAt},
+			"3": {ID: "3", Email: "user@example.com", Status: sharedkernel.UserStatusActive},
+		}}
+		svc, _ := newTestService(repo)
+		_, err := svc.RestoreUser(ctx, domainuser.RestoreUserInput{ActorUserID: "1", UserID: "2"})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+		assert.NotNil(t, repo.users["2"].DeletedAt)
+	})
+}
@@ -0,0 +1,40 @@
+package userservice
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"time"
+
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+)
+
+// audit writes entry to the audit log with the request meta of ctx. In an
+// impersonated request the actor is always the admin. A failed write is
+// logged and never fails the audited action.
+func (s *service) audit(ctx context.Context, entry sharedkernel.AuditEntry) {
+	if impersonation, ok := sharedkernel.ImpersonationFromContext(ctx); ok {
+		entry.Details = maps.Clone(entry.Details)
+		if entry.Details == nil {
+			entry.Details = map[string]string{}
+		}
+		entry.ActorUserID = impersonation.ActorUserID
+		entry.Details["impersonated_user_id"] = impersonation.UserID
+	}
+	entry.RequestMeta = sharedkernel.RequestMetaFromContext(ctx)
+	entry.CreatedAt = time.Now().UTC()
+
+	_, err := s.auditLogRepo.CreateAuditLog(ctx, domainuser.CreateAuditLogParams{
+		Entry: entry,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to write audit log",
+			"error", err,
+			"action", entry.Action,
+			"outcome", entry.Outcome,
+			"actor_user_id", entry.ActorUserID,
+			"target_user_id", entry.TargetUserID,
+		)
+	}
+}
@@ -0,0 +1,131 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+const defaultEmailVerificationTokenTTL = 24 * time.Hour
+
+func (s *service) VerifyEmail(ctx context.Context, input domainuser.VerifyEmailInput) (domainuser.VerifyEmailOutput, error) {
+	used, err := s.emailVerificationRepo.UseEmailVerificationToken(ctx, domainuser.UseEmailVerificationTokenParams{
+		Token:  input.Token,
+		UsedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return domainuser.VerifyEmailOutput{}, apperror.StdUnknown(err)
+	}
+	if !used.Success {
+		return domainuser.VerifyEmailOutput{}, apperror.BadRequest("invalid or expired verification token")
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+		UserID: &used.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainuser.VerifyEmailOutput{}, apperror.BadRequest("invalid or expired verification token")
+		}
+		return domainuser.VerifyEmailOutput{}, apperror.StdUnknown(err)
+	}
+
+	// an admin may have changed the status in the meantime, only a pending
+	// user is activated
+	status := user.Status
+	if status.IsPendingVerification() {
+		status = sharedkernel.UserStatusActive
+		_, err = s.userRepo.UpdateStatus(ctx, domainuser.UpdateStatusParams{
+			UserID: user.ID,
+			Status: status,
+		})
+		if err != nil {
+			return domainuser.VerifyEmailOutput{}, apperror.StdUnknown(err)
+		}
+	}
+
+	_, err = s.emailVerificationRepo.DeleteEmailVerificationTokens(ctx, domainuser.DeleteEmailVerificationTokensParams{
+		UserID: user.ID,
+	})
+	if err != nil {
+		return domainuser.VerifyEmailOutput{}, apperror.StdUnknown(err)
+	}
+
+	return domainuser.VerifyEmailOutput{
+		UserID: user.ID,
+		Status: status,
+	}, nil
+}
+
+func (s *service) ResendVerification(ctx context.Context, input domainuser.ResendVerificationInput) (domainuser.ResendVerificationOutput, error) {
+	user, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+		Email: &input.Email,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainuser.ResendVerificationOutput{}, nil
+		}
+		return domainuser.ResendVerificationOutput{}, apperror.StdUnknown(err)
+	}
+
+	if !user.Status.IsPendingVerification() {
+		return domainuser.ResendVerificationOutput{}, nil
+	}
+
+	err = s.sendEmailVerification(ctx, user.ID, user.Email, user.Name)
+	if err != nil {
+		return domainuser.ResendVerificationOutput{}, apperror.StdUnknown(err)
+	}
+
+	return domainuser.ResendVerificationOutput{}, nil
+}
+
+// sendEmailVerification replaces any outstanding verification token of the
+// user with a new one and sends its link.
+func (s *service) sendEmailVerification(ctx context.Context, userID, email, name string) error {
+	_, err := s.emailVerificationRepo.DeleteEmailVerificationTokens(ctx, domainuser.DeleteEmailVerificationTokensParams{
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	verificationLink, err := tokenLink(s.emailVerificationPolicy.LinkURL, token)
+	if err != nil {
+		return err
+	}
+
+	ttl := s.emailVerificationPolicy.TokenTTL
+	if ttl <= 0 {
+		ttl = defaultEmailVerificationTokenTTL
+	}
+	expiresAt := time.Now().UTC().Add(ttl)
+
+	_, err = s.emailVerificationRepo.CreateEmailVerificationToken(ctx, domainuser.CreateEmailVerificationTokenParams{
+		UserID:    userID,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.notifierRepo.SendEmailVerification(ctx, domainuser.SendEmailVerificationParams{
+		Email:            email,
+		Name:             name,
+		VerificationLink: verificationLink,
+		ExpiresAt:        expiresAt,
+	})
+	return err
+}
@@ -0,0 +1,250 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+const (
+	erasedUserName        = "Erased User"
+	erasedUserEmailDomain = "@erased.invalid"
+)
+
+var (
+	errErasureJobNotFound = apperror.NotFound("erasure job not found")
+	errUserAlreadyErased  = errors.New("user not found or already erased")
+	errEraseLastAdmin     = errors.New("cannot erase the last active admin")
+)
+
+func (s *service) RequestErasure(ctx context.Context, input domainuser.RequestErasureInput) (domainuser.RequestErasureOutput, error) {
+	if input.ActorUserID == input.UserID {
+		return domainuser.RequestErasureOutput{}, apperror.Forbidden("you cannot erase yourself")
+	}
+
+	// a deleted user can still be erased
+	user, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+		UserID: &input.UserID,
+	})
+	if errors.Is(err, databases.ErrNoRowFound) {
+		user, err = s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+			UserID:  &input.UserID,
+			Deleted: true,
+		})
+	}
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainuser.RequestErasureOutput{}, errUserNotFound
+		}
+		return domainuser.RequestErasureOutput{}, apperror.StdUnknown(err)
+	}
+	if user.ErasedAt != nil {
+		return domainuser.RequestErasureOutput{}, apperror.Conflict("user is already erased")
+	}
+
+	lastAdmin, err := s.isLastActiveAdmin(ctx, user)
+	if err != nil {
+		return domainuser.RequestErasureOutput{}, apperror.StdUnknown(err)
+	}
+	if lastAdmin {
+		return domainuser.RequestErasureOutput{}, apperror.Conflict("cannot erase the last active admin")
+	}
+
+	_, err = s.erasureRepo.GetDetailErasureJob(ctx, domainuser.GetDetailErasureJobFilters{
+		UserID:     &input.UserID,
+		Unfinished: true,
+	})
+	if err == nil {
+		return domainuser.RequestErasureOutput{}, apperror.Conflict("an erasure of the user is already pending")
+	}
+	if !errors.Is(err, databases.ErrNoRowFound) {
+		return domainuser.RequestErasureOutput{}, apperror.StdUnknown(err)
+	}
+
+	createdAt := time.Now().UTC()
+	result, err := s.erasureRepo.CreateErasureJob(ctx, domainuser.CreateErasureJobParams{
+		UserID:      input.UserID,
+		RequestedBy: input.ActorUserID,
+		CreatedAt:   createdAt,
+	})
+	if err != nil {
+		return domainuser.RequestErasureOutput{}, apperror.StdUnknown(err)
+	}
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionUserErasureRequest,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  input.ActorUserID,
+		TargetUserID: input.UserID,
+		Details: map[string]string{
+			"job_id": result.ID,
+		},
+	})
+
+	return domainuser.RequestErasureOutput{
+		Job: domainuser.ErasureJob{
+			ID:          result.ID,
+			UserID:      input.UserID,
+			RequestedBy: input.ActorUserID,
+			Status:      domainuser.ErasureJobStatusPending,
+			CreatedAt:   createdAt,
+		},
+	}, nil
+}
+
+func (s *service) GetErasureJob(ctx context.Context, input domainuser.GetErasureJobInput) (domainuser.GetErasureJobOutput, error) {
+	result, err := s.erasureRepo.GetDetailErasureJob(ctx, domainuser.GetDetailErasureJobFilters{
+		JobID: &input.JobID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainuser.GetErasureJobOutput{}, errErasureJobNotFound
+		}
+		return domainuser.GetErasureJobOutput{}, apperror.StdUnknown(err)
+	}
+
+	return domainuser.GetErasureJobOutput{
+		Job: result.Job,
+	}, nil
+}
+
+func (s *service) RunErasureJob(ctx context.Context, input domainuser.RunErasureJobInput) (domainuser.RunErasureJobOutput, error) {
+	claimed, err := s.erasureRepo.ClaimErasureJob(ctx, domainuser.ClaimErasureJobParams{
+		JobID:     &input.JobID,
+		StartedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return domainuser.RunErasureJobOutput{}, apperror.StdUnknown(err)
+	}
+	if !claimed.Success {
+		_, err = s.GetErasureJob(ctx, domainuser.GetErasureJobInput{
+			JobID: input.JobID,
+		})
+		if err != nil {
+			return domainuser.RunErasureJobOutput{}, err
+		}
+		return domainuser.RunErasureJobOutput{}, apperror.Conflict("erasure job is not pending")
+	}
+
+	return domainuser.RunErasureJobOutput{
+		Job: s.runErasureJob(ctx, claimed.Job),
+	}, nil
+}
+
+func (s *service) WorkerRunErasureJobs(ctx context.Context) {
+	var completed, failed int
+	for ctx.Err() == nil {
+		claimed, err := s.erasureRepo.ClaimErasureJob(ctx, domainuser.ClaimErasureJobParams{
+			StartedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to claim erasure job", "error", err)
+			break
+		}
+		if !claimed.Success {
+			break
+		}
+
+		job := s.runErasureJob(ctx, claimed.Job)
+		if job.Status == domainuser.ErasureJobStatusCompleted {
+			completed++
+		} else {
+			failed++
+		}
+	}
+
+	slog.Info("Erasure jobs processed",
+		"completed_count", completed,
+		"failed_count", failed,
+	)
+}
+
+// runErasureJob erases the user of a claimed job and records the outcome on
+// the job and in the audit log. It is not cancelled with ctx, a running job
+// that was never finished would block further requests for the user.
+func (s *service) runErasureJob(ctx context.Context, job domainuser.ErasureJob) domainuser.ErasureJob {
+	ctx = context.WithoutCancel(ctx)
+
+	err := s.eraseUser(ctx, job.UserID)
+	completedAt := time.Now().UTC()
+	job.CompletedAt = &completedAt
+	job.Status = domainuser.ErasureJobStatusCompleted
+	if err != nil {
+		job.Status = domainuser.ErasureJobStatusFailed
+		job.Error = err.Error()
+		slog.ErrorContext(ctx, "failed to erase user", "job_id", job.ID, "user_id", job.UserID, "error", err)
+	}
+
+	_, err = s.erasureRepo.FinishErasureJob(ctx, domainuser.FinishErasureJobParams{
+		JobID:       job.ID,
+		Status:      job.Status,
+		Error:       job.Error,
+		CompletedAt: completedAt,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to finish erasure job", "job_id", job.ID, "error", err)
+	}
+
+	entry := sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionUserErasure,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  job.RequestedBy,
+		TargetUserID: job.UserID,
+		Details: map[string]string{
+			"job_id": job.ID,
+		},
+	}
+	if job.Status == domainuser.ErasureJobStatusFailed {
+		entry.Outcome = sharedkernel.AuditOutcomeFailure
+		entry.Reason = "erasure_failed"
+	}
+	s.audit(ctx, entry)
+
+	return job
+}
+
+// eraseUser replaces the personal data of the user with values that are
+// random or the same for everyone, so nothing of it can be recovered, and
+// leaves a password nobody knows.
+func (s *service) eraseUser(ctx context.Context, userID string) error {
+	emailToken, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	password, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.erasureRepo.EraseUser(ctx, domainuser.EraseUserParams{
+		UserID:       userID,
+		Email:        "erased-" + emailToken + erasedUserEmailDomain,
+		Name:         erasedUserName,
+		PasswordHash: passwordHash,
+		ErasedAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if result.LastActiveAdmin {
+		return errEraseLastAdmin
+	}
+	if !result.Success {
+		return errUserAlreadyErased
+	}
+
+	return nil
+}
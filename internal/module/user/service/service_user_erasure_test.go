@@ -0,0 +1,198 @@
+package userservice_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RequestErasure(t *testing.T) {
+	ctx := context.Background()
+	phone := "+62811111111"
+	newRepo := func() *fakeUserRepo {
+		return &fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{
+			"1": {ID: "1", Email: "admin@example.com", Role: domainuser.UserRoleAdmin, Status: sharedkernel.UserStatusActive},
+			"2": {ID: "2", Email: "user@example.com", Name: "Jane Doe", Phone: &phone, Role: domainuser.UserRoleUser, Status: sharedkernel.UserStatusActive},
+		}}
+	}
+
+	t.Run("queues a pending job", func(t *testing.T) {
+		svc, deps := newTestService(newRepo())
+		output, err := svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "1", UserID: "2"})
+		require.NoError(t, err)
+		assert.Equal(t, domainuser.ErasureJobStatusPending, output.Job.Status)
+		assert.Equal(t, "2", output.Job.UserID)
+		assert.Equal(t, "1", output.Job.RequestedBy)
+
+		// nothing is erased until the job runs
+		assert.Equal(t, "user@example.com", deps.userRepo.users["2"].Email)
+
+		require.Len(t, deps.auditLogRepo.entries, 1)
+		assert.Equal(t, sharedkernel.AuditActionUserErasureRequest, deps.auditLogRepo.entries[0].Action)
+		assert.Equal(t, output.Job.ID, deps.auditLogRepo.entries[0].Details["job_id"])
+
+		_, err = svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "1", UserID: "2"})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+	})
+
+	t.Run("accepts a deleted user", func(t *testing.T) {
+		repo := newRepo()
+		deletedAt := time.Now().UTC()
+		user := repo.users["2"]
+		user.DeletedAt = &deletedAt
+		repo.users["2"] = user
+		svc, _ := newTestService(repo)
+		_, err := svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "1", UserID: "2"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("refuses to erase yourself", func(t *testing.T) {
+		svc, _ := newTestService(newRepo())
+		_, err := svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "2", UserID: "2"})
+		assert.True(t, apperror.IsForbidden(err), "expected forbidden, got %v", err)
+	})
+
+	t.Run("refuses the last active admin", func(t *testing.T) {
+		svc, _ := newTestService(newRepo())
+		_, err := svc.RequestErasure(ctx, domainuser.RequestErasureInput{UserID: "1"})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+	})
+
+	t.Run("refuses an unknown user", func(t *testing.T) {
+		svc, _ := newTestService(newRepo())
+		_, err := svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "1", UserID: "9"})
+		assert.True(t, apperror.IsNotFound(err), "expected not found, got %v", err)
+	})
+}
+
+func TestService_RunErasureJob(t *testing.T) {
+	ctx := context.Background()
+	phone := "+62811111111"
+	gender := domainuser.GenderFemale
+	newRepo := func() *fakeUserRepo {
+		return &fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{
+			"1": {ID: "1", Email: "admin@example.com", Role: domainuser.UserRoleAdmin, Status: sharedkernel.UserStatusActive},
+			"2": {ID: "2", Email: "user@example.com", Name: "Jane Doe", Phone: &phone, Gender: &gender, PasswordHash: "hash", Role: domainuser.UserRoleUser, Status: sharedkernel.UserStatusActive},
+		}}
+	}
+
+	t.Run("anonymises the user", func(t *testing.T) {
+		svc, deps := newTestService(newRepo())
+		requested, err := svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "1", UserID: "2"})
+		require.NoError(t, err)
+
+		output, err := svc.RunErasureJob(ctx, domainuser.RunErasureJobInput{JobID: requested.Job.ID})
+		require.NoError(t, err)
+		assert.Equal(t, domainuser.ErasureJobStatusCompleted, output.Job.Status)
+		assert.NotNil(t, output.Job.CompletedAt)
+
+		erased := deps.userRepo.users["2"]
+		assert.NotContains(t, erased.Email, "user@example.com")
+		assert.True(t, strings.HasSuffix(erased.Email, "@erased.invalid"), erased.Email)
+		assert.NotEqual(t, "Jane Doe", erased.Name)
+		assert.NotEqual(t, "hash", erased.PasswordHash)
+		assert.Nil(t, erased.Phone)
+		assert.Nil(t, erased.Gender)
+		assert.NotNil(t, erased.DeletedAt)
+		assert.NotNil(t, erased.ErasedAt)
+
+		job, err := svc.GetErasureJob(ctx, domainuser.GetErasureJobInput{JobID: requested.Job.ID})
+		require.NoError(t, err)
+		assert.Equal(t, domainuser.ErasureJobStatusCompleted, job.Job.Status)
+
+		require.Len(t, deps.auditLogRepo.entries, 2)
+		assert.Equal(t, sharedkernel.AuditActionUserErasure, deps.auditLogRepo.entries[1].Action)
+		assert.Equal(t, sharedkernel.AuditOutcomeSuccess, deps.auditLogRepo.entries[1].Outcome)
+		assert.Equal(t, "1", deps.auditLogRepo.entries[1].ActorUserID)
+		assert.Equal(t, "2", deps.auditLogRepo.entries[1].TargetUserID)
+
+		// an erased user cannot be restored nor erased again
+		_, err = svc.RestoreUser(ctx, domainuser.RestoreUserInput{ActorUserID: "1", UserID: "2"})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+		_, err = svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "1", UserID: "2"})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+
+		_, err = svc.RunErasureJob(ctx, domainuser.RunErasureJobInput{JobID: requested.Job.ID})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+	})
+
+	t.Run("records a failure on the job", func(t *testing.T) {
+		svc, deps := newTestService(newRepo())
+		deps.erasureRepo.eraseErr = errors.New("database is down")
+		requested, err := svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "1", UserID: "2"})
+		require.NoError(t, err)
+
+		output, err := svc.RunErasureJob(ctx, domainuser.RunErasureJobInput{JobID: requested.Job.ID})
+		require.NoError(t, err)
+		assert.Equal(t, domainuser.ErasureJobStatusFailed, output.Job.Status)
+		assert.Equal(t, "database is down", output.Job.Error)
+		assert.Equal(t, domainuser.ErasureJobStatusFailed, deps.erasureRepo.jobs[0].Status)
+		assert.Equal(t, "user@example.com", deps.userRepo.users["2"].Email)
+
+		require.Len(t, deps.auditLogRepo.entries, 2)
+		assert.Equal(t, sharedkernel.AuditOutcomeFailure, deps.auditLogRepo.entries[1].Outcome)
+
+		// a failed job does not block a new request
+		_, err = svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "1", UserID: "2"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("fails when the user became the last active admin", func(t *testing.T) {
+		repo := newRepo()
+		other := repo.users["2"]
+		other.Role = domainuser.UserRoleAdmin
+		repo.users["2"] = other
+		svc, deps := newTestService(repo)
+		requested, err := svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "1", UserID: "2"})
+		require.NoError(t, err)
+
+		// the requesting admin is suspended before the job runs
+		actor := repo.users["1"]
+		actor.Status = sharedkernel.UserStatusSuspended
+		repo.users["1"] = actor
+
+		output, err := svc.RunErasureJob(ctx, domainuser.RunErasureJobInput{JobID: requested.Job.ID})
+		require.NoError(t, err)
+		assert.Equal(t, domainuser.ErasureJobStatusFailed, output.Job.Status)
+		assert.Equal(t, "cannot erase the last active admin", output.Job.Error)
+		assert.Equal(t, "user@example.com", deps.userRepo.users["2"].Email)
+		assert.Nil(t, deps.userRepo.users["2"].ErasedAt)
+	})
+
+	t.Run("refuses an unknown job", func(t *testing.T) {
+		svc, _ := newTestService(newRepo())
+		_, err := svc.RunErasureJob(ctx, domainuser.RunErasureJobInput{JobID: "9"})
+		assert.True(t, apperror.IsNotFound(err), "expected not found, got %v", err)
+	})
+}
+
+func TestService_WorkerRunErasureJobs(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestService(&fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{
+		"1": {ID: "1", Email: "admin@example.com", Role: domainuser.UserRoleAdmin, Status: sharedkernel.UserStatusActive},
+		"2": {ID: "2", Email: "first@example.com", Role: domainuser.UserRoleUser, Status: sharedkernel.UserStatusActive},
+		"3": {ID: "3", Email: "second@example.com", Role: domainuser.UserRoleUser, Status: sharedkernel.UserStatusActive},
+	}})
+	for _, userID := range []string{"2", "3"} {
+		_, err := svc.RequestErasure(ctx, domainuser.RequestErasureInput{ActorUserID: "1", UserID: userID})
+		require.NoError(t, err)
+	}
+
+	svc.WorkerRunErasureJobs(ctx)
+
+	require.Len(t, deps.erasureRepo.jobs, 2)
+	for _, job := range deps.erasureRepo.jobs {
+		assert.Equal(t, domainuser.ErasureJobStatusCompleted, job.Status)
+		assert.NotNil(t, deps.userRepo.users[job.UserID].ErasedAt)
+	}
+	assert.Nil(t, deps.userRepo.users["1"].ErasedAt)
+}
@@ -0,0 +1,122 @@
+package userservice
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+
+	"github.com/ccojocar/zxcvbn-go"
+)
+
+const defaultPasswordMinLength = 8
+
+// minPersonalInfoLength skips email and name parts too short to matter, e.g.
+// a password is not refused for containing the initial of the user.
+const minPersonalInfoLength = 3
+
+// checkPassword returns a *sharedkernel.ValidationError for field listing
+// every rule of the password policy that password breaks. email and name
+// belong to the user the password is for.
+func (s *service) checkPassword(field, password, email, name string) error {
+	policy := s.passwordPolicy
+	var messages []string
+
+	minLength := policy.MinLength
+	if minLength <= 0 {
+		minLength = defaultPasswordMinLength
+	}
+	length := utf8.RuneCountInString(password)
+	if length < minLength {
+		messages = append(messages, fmt.Sprintf("must be at least %d characters", minLength))
+	}
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		messages = append(messages, fmt.Sprintf("must be at most %d characters", policy.MaxLength))
+	}
+	if len(password) > domainuser.MaxPasswordBytes {
+		messages = append(messages, fmt.Sprintf("must be at most %d bytes", domainuser.MaxPasswordBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r), unicode.IsSymbol(r), unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUpper && !hasUpper {
+		messages = append(messages, "must contain an uppercase letter")
+	}
+	if policy.RequireLower && !hasLower {
+		messages = append(messages, "must contain a lowercase letter")
+	}
+	if policy.RequireDigit && !hasDigit {
+		messages = append(messages, "must contain a digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		messages = append(messages, "must contain a symbol")
+	}
+
+	personalInfo := personalInfoParts(email, name)
+	if policy.RejectPersonalInfo {
+		lower := strings.ToLower(password)
+		for _, v := range personalInfo {
+			if strings.Contains(lower, v) {
+				messages = append(messages, "must not contain your email or name")
+				break
+			}
+		}
+	}
+
+	common := false
+	for _, v := range policy.RejectList {
+		if strings.EqualFold(password, v) {
+			common = true
+			messages = append(messages, "is too common")
+			break
+		}
+	}
+
+	// zxcvbn gets slow on long input, a password over the byte limit is
+	// refused anyway.
+	if !common && policy.MinStrengthScore > 0 && len(password) <= domainuser.MaxPasswordBytes {
+		if zxcvbn.PasswordStrength(password, personalInfo).Score < policy.MinStrengthScore {
+			messages = append(messages, "is too weak, use a longer password or add unrelated words")
+		}
+	}
+
+	if len(messages) > 0 {
+		return &sharedkernel.ValidationError{
+			Fields: []sharedkernel.FieldError{{Field: field, Messages: messages}},
+		}
+	}
+	return nil
+}
+
+// personalInfoParts returns the lower cased email, its local part and the
+// words of name that are long enough to be checked.
+func personalInfoParts(email, name string) []string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	candidates := []string{email}
+	if local, _, ok := strings.Cut(email, "@"); ok {
+		candidates = append(candidates, local)
+	}
+	candidates = append(candidates, strings.Fields(strings.ToLower(name))...)
+
+	parts := make([]string, 0, len(candidates))
+	for _, v := range candidates {
+		if utf8.RuneCountInString(v) >= minPersonalInfoLength {
+			parts = append(parts, v)
+		}
+	}
+	return parts
+}
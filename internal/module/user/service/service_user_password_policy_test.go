@@ -0,0 +1,124 @@
+package userservice_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+	userservice "go-bootstrap/internal/module/user/service"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestService_PasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := domainuser.PasswordPolicy{
+		MinLength:          10,
+		MaxLength:          64,
+		RequireUpper:       true,
+		RequireDigit:       true,
+		MinStrengthScore:   3,
+		RejectPersonalInfo: true,
+		RejectList:         []string{"Password123!"},
+	}
+
+	newService := func(users map[string]domainuser.GetDetailUserResult) (domainuser.UserService, *fakeTokenRepo) {
+		passwordResetRepo := newFakeTokenRepo()
+		return userservice.NewService(
+			&fakeUserRepo{users: users},
+			passwordResetRepo,
+			newFakeTokenRepo(),
+			&fakeAuthTokenRepo{},
+			&fakeNotifierRepo{},
+			&fakeAuditLogRepo{},
+			&fakeErasureRepo{},
+			newPasswordHasher(),
+			domainuser.PasswordResetPolicy{LinkURL: "https://app.example.com/reset-password", TokenTTL: time.Hour},
+			domainuser.EmailVerificationPolicy{LinkURL: "https://app.example.com/verify-email", TokenTTL: time.Hour},
+			policy,
+		), passwordResetRepo
+	}
+
+	fieldMessages := func(t *testing.T, err error, field string) []string {
+		t.Helper()
+		require.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+		var validationErr *sharedkernel.ValidationError
+		require.True(t, errors.As(err, &validationErr), "expected validation error, got %v", err)
+		require.Len(t, validationErr.Fields, 1)
+		assert.Equal(t, field, validationErr.Fields[0].Field)
+		return validationErr.Fields[0].Messages
+	}
+
+	register := func(password string) error {
+		svc, _ := newService(map[string]domainuser.GetDetailUserResult{})
+		_, err := svc.Register(ctx, domainuser.RegisterInput{
+			Email:    "jane.doe@example.com",
+			Password: password,
+			Name:     "Jane Doe",
+		})
+		return err
+	}
+
+	t.Run("register reports every violated rule", func(t *testing.T) {
+		messages := fieldMessages(t, register("short"), "password")
+		assert.Contains(t, messages, "must be at least 10 characters")
+		assert.Contains(t, messages, "must contain an uppercase letter")
+		assert.Contains(t, messages, "must contain a digit")
+		assert.Contains(t, messages, "is too weak, use a longer password or add unrelated words")
+	})
+
+	for name, tc := range map[string]struct {
+		password string
+		message  string
+	}{
+		"over bcrypt's limit": {strings.Repeat("Ab1", 25), "must be at most 72 bytes"},
+		"over max length":     {strings.Repeat("Ab1", 22), "must be at most 64 characters"},
+		"contains the email":  {"Jane.Doe@Example.com9", "must not contain your email or name"},
+		"contains the name":   {"Quartz7 Doe Lantern Fig", "must not contain your email or name"},
+		"common password":     {"password123!", "is too common"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Contains(t, fieldMessages(t, register(tc.password), "password"), tc.message)
+		})
+	}
+
+	t.Run("strong password is accepted", func(t *testing.T) {
+		assert.NoError(t, register("Quartz7 lantern orbit fig"))
+	})
+
+	t.Run("change password checks the new password", func(t *testing.T) {
+		oldHash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+		require.NoError(t, err)
+		svc, _ := newService(map[string]domainuser.GetDetailUserResult{
+			"1": {ID: "1", Email: "jane.doe@example.com", Name: "Jane Doe", PasswordHash: string(oldHash), Status: sharedkernel.UserStatusActive},
+		})
+
+		_, err = svc.ChangePassword(ctx, domainuser.ChangePasswordInput{
+			UserID:      "1",
+			OldPassword: "old-password",
+			NewPassword: "JaneDoe2024 rocks",
+		})
+		assert.Contains(t, fieldMessages(t, err, "new_password"), "must not contain your email or name")
+	})
+
+	t.Run("rejected reset password keeps the token usable", func(t *testing.T) {
+		svc, passwordResetRepo := newService(map[string]domainuser.GetDetailUserResult{
+			"1": {ID: "1", Email: "jane.doe@example.com", Name: "Jane Doe", Status: sharedkernel.UserStatusActive},
+		})
+		passwordResetRepo.create("1", "reset-token", time.Now().Add(time.Hour))
+
+		_, err := svc.ResetPassword(ctx, domainuser.ResetPasswordInput{Token: "reset-token", NewPassword: "weak"})
+		fieldMessages(t, err, "new_password")
+
+		output, err := svc.ResetPassword(ctx, domainuser.ResetPasswordInput{Token: "reset-token", NewPassword: "Quartz7 lantern orbit fig"})
+		require.NoError(t, err)
+		assert.True(t, output.Success)
+	})
+}
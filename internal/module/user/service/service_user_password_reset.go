@@ -0,0 +1,165 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
+)
+
+const defaultPasswordResetTokenTTL = time.Hour
+
+func (s *service) RequestPasswordReset(ctx context.Context, input domainuser.RequestPasswordResetInput) (domainuser.RequestPasswordResetOutput, error) {
+	user, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+		Email: &input.Email,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainuser.RequestPasswordResetOutput{}, nil
+		}
+		return domainuser.RequestPasswordResetOutput{}, apperror.StdUnknown(err)
+	}
+
+	if user.Status != sharedkernel.UserStatusActive {
+		slog.InfoContext(ctx, "password reset requested for inactive user", "user_id", user.ID)
+		return domainuser.RequestPasswordResetOutput{}, nil
+	}
+
+	// only the latest link stays usable
+	_, err = s.passwordResetRepo.DeletePasswordResetTokens(ctx, domainuser.DeletePasswordResetTokensParams{
+		UserID: user.ID,
+	})
+	if err != nil {
+		return domainuser.RequestPasswordResetOutput{}, apperror.StdUnknown(err)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return domainuser.RequestPasswordResetOutput{}, apperror.StdUnknown(err)
+	}
+
+	resetLink, err := tokenLink(s.passwordResetPolicy.LinkURL, token)
+	if err != nil {
+		return domainuser.RequestPasswordResetOutput{}, apperror.StdUnknown(err)
+	}
+
+	ttl := s.passwordResetPolicy.TokenTTL
+	if ttl <= 0 {
+		ttl = defaultPasswordResetTokenTTL
+	}
+	expiresAt := time.Now().UTC().Add(ttl)
+
+	_, err = s.passwordResetRepo.CreatePasswordResetToken(ctx, domainuser.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return domainuser.RequestPasswordResetOutput{}, apperror.StdUnknown(err)
+	}
+
+	_, err = s.notifierRepo.SendPasswordReset(ctx, domainuser.SendPasswordResetParams{
+		Email:     user.Email,
+		Name:      user.Name,
+		ResetLink: resetLink,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return domainuser.RequestPasswordResetOutput{}, apperror.StdUnknown(err)
+	}
+
+	return domainuser.RequestPasswordResetOutput{}, nil
+}
+
+func (s *service) ResetPassword(ctx context.Context, input domainuser.ResetPasswordInput) (domainuser.ResetPasswordOutput, error) {
+	// the password is checked before the token is used, a rejected password
+	// leaves the link usable for another attempt
+	token, err := s.passwordResetRepo.GetDetailPasswordResetToken(ctx, domainuser.GetDetailPasswordResetTokenFilters{
+		Token: input.Token,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainuser.ResetPasswordOutput{}, apperror.BadRequest("invalid or expired reset token")
+		}
+		return domainuser.ResetPasswordOutput{}, apperror.StdUnknown(err)
+	}
+	if token.UsedAt != nil || !token.ExpiresAt.After(time.Now().UTC()) {
+		return domainuser.ResetPasswordOutput{}, apperror.BadRequest("invalid or expired reset token")
+	}
+
+	user, err := s.userRepo.GetDetailUser(ctx, domainuser.GetDetailUserFilters{
+		UserID: &token.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, databases.ErrNoRowFound) {
+			return domainuser.ResetPasswordOutput{}, apperror.BadRequest("invalid or expired reset token")
+		}
+		return domainuser.ResetPasswordOutput{}, apperror.StdUnknown(err)
+	}
+
+	err = s.checkPassword("new_password", input.NewPassword, user.Email, user.Name)
+	if err != nil {
+		return domainuser.ResetPasswordOutput{}, err
+	}
+
+	used, err := s.passwordResetRepo.UsePasswordResetToken(ctx, domainuser.UsePasswordResetTokenParams{
+		Token:  input.Token,
+		UsedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return domainuser.ResetPasswordOutput{}, apperror.StdUnknown(err)
+	}
+	if !used.Success {
+		return domainuser.ResetPasswordOutput{}, apperror.BadRequest("invalid or expired reset token")
+	}
+
+	newPasswordHash, err := s.passwordHasher.Hash(input.NewPassword)
+	if err != nil {
+		return domainuser.ResetPasswordOutput{}, apperror.StdUnknown(err)
+	}
+
+	result, err := s.userRepo.UpdatePassword(ctx, domainuser.UpdatePasswordParams{
+		UserID:          used.UserID,
+		NewPasswordHash: newPasswordHash,
+	})
+	if err != nil {
+		return domainuser.ResetPasswordOutput{}, apperror.StdUnknown(err)
+	}
+
+	revoked, err := s.authTokenRepo.RevokeUserTokens(ctx, domainuser.RevokeUserTokensParams{
+		UserID: used.UserID,
+	})
+	if err != nil {
+		return domainuser.ResetPasswordOutput{}, apperror.StdUnknown(err)
+	}
+
+	_, err = s.passwordResetRepo.DeletePasswordResetTokens(ctx, domainuser.DeletePasswordResetTokensParams{
+		UserID: used.UserID,
+	})
+	if err != nil {
+		return domainuser.ResetPasswordOutput{}, apperror.StdUnknown(err)
+	}
+
+	slog.InfoContext(ctx, "password reset",
+		"user_id", used.UserID,
+		"revoked_count", revoked.RevokedCount,
+	)
+
+	s.audit(ctx, sharedkernel.AuditEntry{
+		Action:       sharedkernel.AuditActionPasswordReset,
+		Outcome:      sharedkernel.AuditOutcomeSuccess,
+		ActorUserID:  used.UserID,
+		TargetUserID: used.UserID,
+	})
+
+	return domainuser.ResetPasswordOutput{
+		Success:   true,
+		UpdatedAt: result.UpdatedAt,
+	}, nil
+}
@@ -1,16 +1,25 @@
 package userservice_test
 
 import (
+	"context"
+	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
+	"go-bootstrap/internal/config"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+	"go-bootstrap/internal/infrastructure"
+	userservice "go-bootstrap/internal/module/user/service"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/databases"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
-func TestService_Register(t *testing.T) {
-	// TODO: Implement test with mocks
-	t.Skip("Implement with repository mock")
-}
-
 func TestService_GetProfile(t *testing.T) {
 	// TODO: Implement test with mocks
 	t.Skip("Implement with repository mock")
@@ -26,13 +35,698 @@ func TestService_UpdateProfile(t *testing.T) {
 }
 
 func TestService_ChangePassword(t *testing.T) {
-	// TODO: Implement test with mocks
-	t.Skip("Implement with repository mock")
+	ctx := context.Background()
+	oldHash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	setup := func() (domainuser.UserService, testDeps) {
+		return newTestService(&fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{
+			"1": {ID: "1", Email: "user@example.com", PasswordHash: string(oldHash), Status: sharedkernel.UserStatusActive},
+		}})
+	}
+
+	t.Run("changes the password and revokes tokens", func(t *testing.T) {
+		svc, deps := setup()
+		output, err := svc.ChangePassword(ctx, domainuser.ChangePasswordInput{
+			UserID:      "1",
+			OldPassword: "old-password",
+			NewPassword: "new-password",
+		})
+		require.NoError(t, err)
+		assert.True(t, output.Success)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(deps.userRepo.users["1"].PasswordHash), []byte("new-password")))
+		assert.Equal(t, []string{"1"}, deps.authTokenRepo.revokedUserIDs)
+		require.Len(t, deps.auditLogRepo.entries, 1)
+		assert.Equal(t, sharedkernel.AuditActionPasswordChange, deps.auditLogRepo.entries[0].Action)
+		assert.Equal(t, sharedkernel.AuditOutcomeSuccess, deps.auditLogRepo.entries[0].Outcome)
+	})
+
+	t.Run("wrong old password keeps tokens", func(t *testing.T) {
+		svc, deps := setup()
+		_, err := svc.ChangePassword(ctx, domainuser.ChangePasswordInput{
+			UserID:      "1",
+			OldPassword: "wrong-password",
+			NewPassword: "new-password",
+		})
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+		assert.Empty(t, deps.authTokenRepo.revokedUserIDs)
+		require.Len(t, deps.auditLogRepo.entries, 1)
+		assert.Equal(t, sharedkernel.AuditOutcomeFailure, deps.auditLogRepo.entries[0].Outcome)
+		assert.Equal(t, "invalid_old_password", deps.auditLogRepo.entries[0].Reason)
+	})
+
+	t.Run("refused while impersonating", func(t *testing.T) {
+		svc, deps := setup()
+		impersonating := sharedkernel.WithImpersonation(ctx, sharedkernel.Impersonation{ActorUserID: "9", UserID: "1"})
+		_, err := svc.ChangePassword(impersonating, domainuser.ChangePasswordInput{
+			UserID:      "1",
+			OldPassword: "old-password",
+			NewPassword: "new-password",
+		})
+		assert.True(t, apperror.IsForbidden(err), "expected forbidden, got %v", err)
+		assert.Equal(t, string(oldHash), deps.userRepo.users["1"].PasswordHash)
+		assert.Empty(t, deps.authTokenRepo.revokedUserIDs)
+	})
+}
+
+type fakeUserRepo struct {
+	domainuser.UserRepositoryDatastore
+	users         map[string]domainuser.GetDetailUserResult
+	statusUpdates int
+	// beforeAdminGuard runs between the service's own check and the locked
+	// one of the repository, like a concurrent request would
+	beforeAdminGuard func(f *fakeUserRepo)
+}
+
+func (f *fakeUserRepo) GetDetailUser(ctx context.Context, filters domainuser.GetDetailUserFilters) (domainuser.GetDetailUserResult, error) {
+	for _, v := range f.users {
+		if (v.DeletedAt != nil) != filters.Deleted {
+			continue
+		}
+		if filters.UserID != nil && v.ID != *filters.UserID {
+			continue
+		}
+		if filters.Email != nil && v.Email != *filters.Email {
+			continue
+		}
+		return v, nil
+	}
+	return domainuser.GetDetailUserResult{}, databases.ErrNoRowFound
+}
+
+func (f *fakeUserRepo) CreateUser(ctx context.Context, params domainuser.CreateUserParams) (domainuser.CreateUserResult, error) {
+	id := strconv.Itoa(len(f.users) + 1)
+	now := time.Now().UTC()
+	f.users[id] = domainuser.GetDetailUserResult{
+		ID:                     id,
+		Email:                  params.Email,
+		PasswordHash:           params.PasswordHash,
+		Name:                   params.Name,
+		Role:                   params.Role,
+		Status:                 params.Status,
+		PasswordChangeRequired: params.PasswordChangeRequired,
+		CreatedAt:              now,
+		UpdatedAt:              now,
+	}
+	return domainuser.CreateUserResult{
+		ID:        id,
+		Email:     params.Email,
+		Name:      params.Name,
+		Role:      params.Role,
+		Status:    params.Status,
+		CreatedAt: now,
+	}, nil
+}
+
+func (f *fakeUserRepo) UpdatePassword(ctx context.Context, params domainuser.UpdatePasswordParams) (domainuser.UpdatePasswordResult, error) {
+	user := f.users[params.UserID]
+	user.PasswordHash = params.NewPasswordHash
+	user.PasswordChangeRequired = false
+	f.users[params.UserID] = user
+	return domainuser.UpdatePasswordResult{UpdatedAt: time.Now().UTC()}, nil
+}
+
+func (f *fakeUserRepo) CountUser(ctx context.Context, filters domainuser.CountUserFilters) (domainuser.CountUserResult, error) {
+	var count int64
+	for _, v := range f.users {
+		if v.DeletedAt != nil {
+			continue
+		}
+		if filters.Role != nil && v.Role != *filters.Role {
+			continue
+		}
+		if filters.Status != nil && v.Status != *filters.Status {
+			continue
+		}
+		count++
+	}
+	return domainuser.CountUserResult{Count: count}, nil
+}
+
+// lastActiveAdmin mirrors the locked check of the repository, after
+// beforeAdminGuard had the chance to change the users concurrently.
+func (f *fakeUserRepo) lastActiveAdmin(userID string) bool {
+	if f.beforeAdminGuard != nil {
+		f.beforeAdminGuard(f)
+	}
+	var count int
+	self := false
+	for _, v := range f.users {
+		if v.Role == domainuser.UserRoleAdmin && v.Status.IsActive() && v.DeletedAt == nil {
+			count++
+			self = self || v.ID == userID
+		}
+	}
+	return self && count <= 1
+}
+
+func (f *fakeUserRepo) UpdateStatus(ctx context.Context, params domainuser.UpdateStatusParams) (domainuser.UpdateStatusResult, error) {
+	if params.KeepActiveAdmin && f.lastActiveAdmin(params.UserID) {
+		return domainuser.UpdateStatusResult{LastActiveAdmin: true}, nil
+	}
+	user := f.users[params.UserID]
+	user.Status = params.Status
+	f.users[params.UserID] = user
+	f.statusUpdates++
+	return domainuser.UpdateStatusResult{UpdatedAt: time.Now().UTC()}, nil
+}
+
+func (f *fakeUserRepo) SoftDeleteUser(ctx context.Context, params domainuser.SoftDeleteUserParams) (domainuser.SoftDeleteUserResult, error) {
+	if params.KeepActiveAdmin && f.lastActiveAdmin(params.UserID) {
+		return domainuser.SoftDeleteUserResult{LastActiveAdmin: true}, nil
+	}
+	user, ok := f.users[params.UserID]
+	if !ok || user.DeletedAt != nil {
+		return domainuser.SoftDeleteUserResult{}, nil
+	}
+	user.DeletedAt = &params.DeletedAt
+	f.users[params.UserID] = user
+	return domainuser.SoftDeleteUserResult{Success: true}, nil
+}
+
+func (f *fakeUserRepo) RestoreUser(ctx context.Context, params domainuser.RestoreUserParams) (domainuser.RestoreUserResult, error) {
+	user, ok := f.users[params.UserID]
+	if !ok || user.DeletedAt == nil || user.ErasedAt != nil {
+		return domainuser.RestoreUserResult{}, nil
+	}
+	user.DeletedAt = nil
+	f.users[params.UserID] = user
+	return domainuser.RestoreUserResult{Success: true, UpdatedAt: time.Now().UTC()}, nil
 }
 
 func TestService_UpdateStatus(t *testing.T) {
-	// TODO: Implement test with mocks
-	t.Skip("Implement with repository mock")
+	newRepo := func(users ...domainuser.GetDetailUserResult) *fakeUserRepo {
+		repo := &fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{}}
+		for _, v := range users {
+			repo.users[v.ID] = v
+		}
+		return repo
+	}
+	admin := func(id string) domainuser.GetDetailUserResult {
+		return domainuser.GetDetailUserResult{ID: id, Role: domainuser.UserRoleAdmin, Status: sharedkernel.UserStatusActive}
+	}
+	user := func(id string) domainuser.GetDetailUserResult {
+		return domainuser.GetDetailUserResult{ID: id, Role: domainuser.UserRoleUser, Status: sharedkernel.UserStatusActive}
+	}
+
+	t.Run("suspends a regular user", func(t *testing.T) {
+		repo := newRepo(admin("1"), user("2"))
+		svc, deps := newTestService(repo)
+		output, err := svc.UpdateStatus(context.Background(), domainuser.UpdateStatusInput{
+			ActorUserID: "1",
+			UserID:      "2",
+			Status:      sharedkernel.UserStatusSuspended,
+		})
+		require.NoError(t, err)
+		assert.True(t, output.Success)
+		assert.Equal(t, sharedkernel.UserStatusSuspended, repo.users["2"].Status)
+		assert.Equal(t, []string{"2"}, deps.authTokenRepo.revokedUserIDs)
+	})
+
+	t.Run("activating a user keeps its tokens", func(t *testing.T) {
+		inactiveUser := user("2")
+		inactiveUser.Status = sharedkernel.UserStatusInactive
+		repo := newRepo(admin("1"), inactiveUser)
+		svc, deps := newTestService(repo)
+		_, err := svc.UpdateStatus(context.Background(), domainuser.UpdateStatusInput{
+			ActorUserID: "1",
+			UserID:      "2",
+			Status:      sharedkernel.UserStatusActive,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, sharedkernel.UserStatusActive, repo.users["2"].Status)
+		assert.Empty(t, deps.authTokenRepo.revokedUserIDs)
+	})
+
+	t.Run("admin cannot change own status", func(t *testing.T) {
+		repo := newRepo(admin("1"), admin("2"))
+		svc, _ := newTestService(repo)
+		_, err := svc.UpdateStatus(context.Background(), domainuser.UpdateStatusInput{
+			ActorUserID: "1",
+			UserID:      "1",
+			Status:      sharedkernel.UserStatusSuspended,
+		})
+		assert.True(t, apperror.IsForbidden(err), "expected forbidden, got %v", err)
+		assert.Zero(t, repo.statusUpdates)
+	})
+
+	t.Run("last active admin cannot be suspended", func(t *testing.T) {
+		inactiveAdmin := admin("3")
+		inactiveAdmin.Status = sharedkernel.UserStatusInactive
+		repo := newRepo(admin("2"), inactiveAdmin)
+		svc, _ := newTestService(repo)
+		_, err := svc.UpdateStatus(context.Background(), domainuser.UpdateStatusInput{
+			ActorUserID: "1",
+			UserID:      "2",
+			Status:      sharedkernel.UserStatusSuspended,
+		})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+		assert.Zero(t, repo.statusUpdates)
+	})
+
+	t.Run("admins cannot deactivate each other concurrently", func(t *testing.T) {
+		repo := newRepo(admin("1"), admin("2"))
+		repo.beforeAdminGuard = func(f *fakeUserRepo) {
+			other := f.users["1"]
+			other.Status = sharedkernel.UserStatusSuspended
+			f.users["1"] = other
+		}
+		svc, _ := newTestService(repo)
+		_, err := svc.UpdateStatus(context.Background(), domainuser.UpdateStatusInput{
+			ActorUserID: "1",
+			UserID:      "2",
+			Status:      sharedkernel.UserStatusSuspended,
+		})
+		assert.True(t, apperror.IsConflict(err), "expected conflict, got %v", err)
+		assert.Equal(t, sharedkernel.UserStatusActive, repo.users["2"].Status)
+		assert.Zero(t, repo.statusUpdates)
+	})
+
+	t.Run("admin can be suspended while another admin remains", func(t *testing.T) {
+		repo := newRepo(admin("1"), admin("2"))
+		svc, deps := newTestService(repo)
+		ctx := sharedkernel.WithRequestMeta(context.Background(), sharedkernel.RequestMeta{RequestID: "req-1"})
+		_, err := svc.UpdateStatus(ctx, domainuser.UpdateStatusInput{
+			ActorUserID: "1",
+			UserID:      "2",
+			Status:      sharedkernel.UserStatusSuspended,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, repo.statusUpdates)
+
+		require.Len(t, deps.auditLogRepo.entries, 1)
+		entry := deps.auditLogRepo.entries[0]
+		assert.Equal(t, sharedkernel.AuditActionUserStatusChange, entry.Action)
+		assert.Equal(t, "1", entry.ActorUserID)
+		assert.Equal(t, "2", entry.TargetUserID)
+		assert.Equal(t, map[string]string{"from": "active", "to": "suspended"}, entry.Details)
+		assert.Equal(t, "req-1", entry.RequestID)
+	})
+}
+
+// fakeTokenRepo backs both the password reset and the email verification
+// token repositories.
+type fakeTokenRepo struct {
+	domainuser.PasswordResetRepositoryDatastore
+	domainuser.EmailVerificationRepositoryDatastore
+	tokens map[string]*fakeToken
+}
+
+type fakeToken struct {
+	userID    string
+	expiresAt time.Time
+	used      bool
+}
+
+func newFakeTokenRepo() *fakeTokenRepo {
+	return &fakeTokenRepo{tokens: map[string]*fakeToken{}}
+}
+
+func (f *fakeTokenRepo) create(userID, token string, expiresAt time.Time) {
+	f.tokens[token] = &fakeToken{userID: userID, expiresAt: expiresAt}
+}
+
+func (f *fakeTokenRepo) use(token string, usedAt time.Time) (string, bool) {
+	v, ok := f.tokens[token]
+	if !ok || v.used || !v.expiresAt.After(usedAt) {
+		return "", false
+	}
+	v.used = true
+	return v.userID, true
+}
+
+func (f *fakeTokenRepo) delete(userID string) int64 {
+	var deleted int64
+	for k, v := range f.tokens {
+		if v.userID == userID && !v.used {
+			delete(f.tokens, k)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func (f *fakeTokenRepo) CreatePasswordResetToken(ctx context.Context, params domainuser.CreatePasswordResetTokenParams) (domainuser.CreatePasswordResetTokenResult, error) {
+	f.create(params.UserID, params.Token, params.ExpiresAt)
+	return domainuser.CreatePasswordResetTokenResult{ID: params.Token, CreatedAt: time.Now().UTC()}, nil
+}
+
+func (f *fakeTokenRepo) GetDetailPasswordResetToken(ctx context.Context, filters domainuser.GetDetailPasswordResetTokenFilters) (domainuser.GetDetailPasswordResetTokenResult, error) {
+	v, ok := f.tokens[filters.Token]
+	if !ok {
+		return domainuser.GetDetailPasswordResetTokenResult{}, databases.ErrNoRowFound
+	}
+	result := domainuser.GetDetailPasswordResetTokenResult{UserID: v.userID, ExpiresAt: v.expiresAt}
+	if v.used {
+		result.UsedAt = &v.expiresAt
+	}
+	return result, nil
+}
+
+func (f *fakeTokenRepo) UsePasswordResetToken(ctx context.Context, params domainuser.UsePasswordResetTokenParams) (domainuser.UsePasswordResetTokenResult, error) {
+	userID, ok := f.use(params.Token, params.UsedAt)
+	return domainuser.UsePasswordResetTokenResult{Success: ok, UserID: userID}, nil
+}
+
+func (f *fakeTokenRepo) DeletePasswordResetTokens(ctx context.Context, params domainuser.DeletePasswordResetTokensParams) (domainuser.DeletePasswordResetTokensResult, error) {
+	return domainuser.DeletePasswordResetTokensResult{DeletedCount: f.delete(params.UserID)}, nil
+}
+
+func (f *fakeTokenRepo) CreateEmailVerificationToken(ctx context.Context, params domainuser.CreateEmailVerificationTokenParams) (domainuser.CreateEmailVerificationTokenResult, error) {
+	f.create(params.UserID, params.Token, params.ExpiresAt)
+	return domainuser.CreateEmailVerificationTokenResult{ID: params.Token, CreatedAt: time.Now().UTC()}, nil
+}
+
+func (f *fakeTokenRepo) UseEmailVerificationToken(ctx context.Context, params domainuser.UseEmailVerificationTokenParams) (domainuser.UseEmailVerificationTokenResult, error) {
+	userID, ok := f.use(params.Token, params.UsedAt)
+	return domainuser.UseEmailVerificationTokenResult{Success: ok, UserID: userID}, nil
+}
+
+func (f *fakeTokenRepo) DeleteEmailVerificationTokens(ctx context.Context, params domainuser.DeleteEmailVerificationTokensParams) (domainuser.DeleteEmailVerificationTokensResult, error) {
+	return domainuser.DeleteEmailVerificationTokensResult{DeletedCount: f.delete(params.UserID)}, nil
+}
+
+type fakeAuthTokenRepo struct {
+	domainuser.AuthTokenRepositoryDatastore
+	revokedUserIDs []string
+}
+
+func (f *fakeAuthTokenRepo) RevokeUserTokens(ctx context.Context, params domainuser.RevokeUserTokensParams) (domainuser.RevokeUserTokensResult, error) {
+	f.revokedUserIDs = append(f.revokedUserIDs, params.UserID)
+	return domainuser.RevokeUserTokensResult{RevokedCount: 1, RevokedAt: time.Now().UTC()}, nil
+}
+
+type fakeNotifierRepo struct {
+	domainuser.UserRepositoryNotifier
+	passwordResets []domainuser.SendPasswordResetParams
+	verifications  []domainuser.SendEmailVerificationParams
+}
+
+func (f *fakeNotifierRepo) SendPasswordReset(ctx context.Context, params domainuser.SendPasswordResetParams) (domainuser.SendPasswordResetResult, error) {
+	f.passwordResets = append(f.passwordResets, params)
+	return domainuser.SendPasswordResetResult{}, nil
+}
+
+func (f *fakeNotifierRepo) SendEmailVerification(ctx context.Context, params domainuser.SendEmailVerificationParams) (domainuser.SendEmailVerificationResult, error) {
+	f.verifications = append(f.verifications, params)
+	return domainuser.SendEmailVerificationResult{}, nil
+}
+
+type fakeAuditLogRepo struct {
+	entries []sharedkernel.AuditEntry
+}
+
+func (f *fakeAuditLogRepo) CreateAuditLog(ctx context.Context, params domainuser.CreateAuditLogParams) (domainuser.CreateAuditLogResult, error) {
+	f.entries = append(f.entries, params.Entry)
+	return domainuser.CreateAuditLogResult{ID: strconv.Itoa(len(f.entries))}, nil
+}
+
+// fakeErasureRepo keeps erasure jobs in memory and erases users of userRepo.
+// eraseErr fails every erasure.
+type fakeErasureRepo struct {
+	userRepo *fakeUserRepo
+	jobs     []domainuser.ErasureJob
+	eraseErr error
+}
+
+func (f *fakeErasureRepo) CreateErasureJob(ctx context.Context, params domainuser.CreateErasureJobParams) (domainuser.CreateErasureJobResult, error) {
+	id := strconv.Itoa(len(f.jobs) + 1)
+	f.jobs = append(f.jobs, domainuser.ErasureJob{
+		ID:          id,
+		UserID:      params.UserID,
+		RequestedBy: params.RequestedBy,
+		Status:      domainuser.ErasureJobStatusPending,
+		CreatedAt:   params.CreatedAt,
+	})
+	return domainuser.CreateErasureJobResult{ID: id}, nil
+}
+
+func (f *fakeErasureRepo) GetDetailErasureJob(ctx context.Context, filters domainuser.GetDetailErasureJobFilters) (domainuser.GetDetailErasureJobResult, error) {
+	for i := len(f.jobs) - 1; i >= 0; i-- {
+		v := f.jobs[i]
+		if filters.JobID != nil && v.ID != *filters.JobID {
+			continue
+		}
+		if filters.UserID != nil && v.UserID != *filters.UserID {
+			continue
+		}
+		if filters.Unfinished && v.Status != domainuser.ErasureJobStatusPending && v.Status != domainuser.ErasureJobStatusRunning {
+			continue
+		}
+		return domainuser.GetDetailErasureJobResult{Job: v}, nil
+	}
+	return domainuser.GetDetailErasureJobResult{}, databases.ErrNoRowFound
+}
+
+func (f *fakeErasureRepo) ClaimErasureJob(ctx context.Context, params domainuser.ClaimErasureJobParams) (domainuser.ClaimErasureJobResult, error) {
+	for i, v := range f.jobs {
+		if v.Status != domainuser.ErasureJobStatusPending {
+			continue
+		}
+		if params.JobID != nil && v.ID != *params.JobID {
+			continue
+		}
+		v.Status = domainuser.ErasureJobStatusRunning
+		v.StartedAt = &params.StartedAt
+		f.jobs[i] = v
+		return domainuser.ClaimErasureJobResult{Success: true, Job: v}, nil
+	}
+	return domainuser.ClaimErasureJobResult{}, nil
+}
+
+func (f *fakeErasureRepo) FinishErasureJob(ctx context.Context, params domainuser.FinishErasureJobParams) (domainuser.FinishErasureJobResult, error) {
+	for i, v := range f.jobs {
+		if v.ID == params.JobID && v.Status == domainuser.ErasureJobStatusRunning {
+			v.Status = params.Status
+			v.Error = params.Error
+			v.CompletedAt = &params.CompletedAt
+			f.jobs[i] = v
+		}
+	}
+	return domainuser.FinishErasureJobResult{}, nil
+}
+
+func (f *fakeErasureRepo) EraseUser(ctx context.Context, params domainuser.EraseUserParams) (domainuser.EraseUserResult, error) {
+	if f.eraseErr != nil {
+		return domainuser.EraseUserResult{}, f.eraseErr
+	}
+	user, ok := f.userRepo.users[params.UserID]
+	if !ok || user.ErasedAt != nil {
+		return domainuser.EraseUserResult{}, nil
+	}
+	if f.userRepo.lastActiveAdmin(params.UserID) {
+		return domainuser.EraseUserResult{LastActiveAdmin: true}, nil
+	}
+	user.Email = params.Email
+	user.Name = params.Name
+	user.PasswordHash = params.PasswordHash
+	user.Phone = nil
+	user.Gender = nil
+	user.Status = sharedkernel.UserStatusInactive
+	if user.DeletedAt == nil {
+		user.DeletedAt = &params.ErasedAt
+	}
+	user.ErasedAt = &params.ErasedAt
+	f.userRepo.users[params.UserID] = user
+	return domainuser.EraseUserResult{Success: true}, nil
+}
+
+type testDeps struct {
+	userRepo              *fakeUserRepo
+	passwordResetRepo     *fakeTokenRepo
+	emailVerificationRepo *fakeTokenRepo
+	authTokenRepo         *fakeAuthTokenRepo
+	notifierRepo          *fakeNotifierRepo
+	auditLogRepo          *fakeAuditLogRepo
+	erasureRepo           *fakeErasureRepo
+}
+
+func newTestService(userRepo *fakeUserRepo) (domainuser.UserService, testDeps) {
+	deps := testDeps{
+		userRepo:              userRepo,
+		passwordResetRepo:     newFakeTokenRepo(),
+		emailVerificationRepo: newFakeTokenRepo(),
+		authTokenRepo:         &fakeAuthTokenRepo{},
+		notifierRepo:          &fakeNotifierRepo{},
+		auditLogRepo:          &fakeAuditLogRepo{},
+		erasureRepo:           &fakeErasureRepo{userRepo: userRepo},
+	}
+
+	svc := userservice.NewService(
+		deps.userRepo,
+		deps.passwordResetRepo,
+		deps.emailVerificationRepo,
+		deps.authTokenRepo,
+		deps.notifierRepo,
+		deps.auditLogRepo,
+		deps.erasureRepo,
+		newPasswordHasher(),
+		domainuser.PasswordResetPolicy{LinkURL: "https://app.example.com/reset-password", TokenTTL: time.Hour},
+		domainuser.EmailVerificationPolicy{LinkURL: "https://app.example.com/verify-email", TokenTTL: time.Hour},
+		domainuser.PasswordPolicy{},
+	)
+	return svc, deps
+}
+
+// newPasswordHasher hashes with bcrypt at its minimum cost so the tests can
+// check stored hashes with bcrypt directly.
+func newPasswordHasher() sharedkernel.PasswordHasher {
+	hasher, err := infrastructure.NewPasswordHasherFromConfig(config.PasswordHash{
+		Algorithm:  infrastructure.PasswordHashAlgorithmBcrypt,
+		BcryptCost: bcrypt.MinCost,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return hasher
+}
+
+func tokenFromLink(t *testing.T, link string) string {
+	t.Helper()
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "app.example.com", parsed.Host)
+	token := parsed.Query().Get("token")
+	require.NotEmpty(t, token)
+	return token
+}
+
+func TestService_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(status sharedkernel.UserStatus) (domainuser.UserService, testDeps) {
+		return newTestService(&fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{
+			"1": {ID: "1", Email: "user@example.com", Name: "User", PasswordHash: "old", Status: status},
+		}})
+	}
+
+	t.Run("reset link resets the password once and revokes tokens", func(t *testing.T) {
+		svc, deps := setup(sharedkernel.UserStatusActive)
+
+		_, err := svc.RequestPasswordReset(ctx, domainuser.RequestPasswordResetInput{Email: "user@example.com"})
+		require.NoError(t, err)
+		require.Len(t, deps.notifierRepo.passwordResets, 1)
+		token := tokenFromLink(t, deps.notifierRepo.passwordResets[0].ResetLink)
+
+		output, err := svc.ResetPassword(ctx, domainuser.ResetPasswordInput{Token: token, NewPassword: "new-password"})
+		require.NoError(t, err)
+		assert.True(t, output.Success)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(deps.userRepo.users["1"].PasswordHash), []byte("new-password")))
+		assert.Equal(t, []string{"1"}, deps.authTokenRepo.revokedUserIDs)
+
+		_, err = svc.ResetPassword(ctx, domainuser.ResetPasswordInput{Token: token, NewPassword: "another-password"})
+		assert.True(t, apperror.IsBadRequest(err), "reused token must be rejected, got %v", err)
+	})
+
+	t.Run("a new request invalidates the previous link", func(t *testing.T) {
+		svc, deps := setup(sharedkernel.UserStatusActive)
+
+		for range 2 {
+			_, err := svc.RequestPasswordReset(ctx, domainuser.RequestPasswordResetInput{Email: "user@example.com"})
+			require.NoError(t, err)
+		}
+		sent := deps.notifierRepo.passwordResets
+		require.Len(t, sent, 2)
+
+		_, err := svc.ResetPassword(ctx, domainuser.ResetPasswordInput{Token: tokenFromLink(t, sent[0].ResetLink), NewPassword: "new-password"})
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+
+		_, err = svc.ResetPassword(ctx, domainuser.ResetPasswordInput{Token: tokenFromLink(t, sent[1].ResetLink), NewPassword: "new-password"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired token is rejected", func(t *testing.T) {
+		svc, deps := setup(sharedkernel.UserStatusActive)
+		deps.passwordResetRepo.create("1", "expired", time.Now().Add(-time.Minute))
+
+		_, err := svc.ResetPassword(ctx, domainuser.ResetPasswordInput{Token: "expired", NewPassword: "new-password"})
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+		assert.Equal(t, "old", deps.userRepo.users["1"].PasswordHash)
+		assert.Empty(t, deps.authTokenRepo.revokedUserIDs)
+	})
+
+	t.Run("unknown and inactive emails succeed without sending", func(t *testing.T) {
+		svc, deps := setup(sharedkernel.UserStatusSuspended)
+
+		_, err := svc.RequestPasswordReset(ctx, domainuser.RequestPasswordResetInput{Email: "nobody@example.com"})
+		assert.NoError(t, err)
+		_, err = svc.RequestPasswordReset(ctx, domainuser.RequestPasswordResetInput{Email: "user@example.com"})
+		assert.NoError(t, err)
+		assert.Empty(t, deps.notifierRepo.passwordResets)
+	})
+}
+
+func TestService_EmailVerification(t *testing.T) {
+	ctx := context.Background()
+
+	register := func(t *testing.T) (domainuser.UserService, testDeps, domainuser.RegisterOutput) {
+		t.Helper()
+		svc, deps := newTestService(&fakeUserRepo{users: map[string]domainuser.GetDetailUserResult{}})
+		output, err := svc.Register(ctx, domainuser.RegisterInput{
+			Email:    "new@example.com",
+			Password: "password123",
+			Name:     "New User",
+		})
+		require.NoError(t, err)
+		return svc, deps, output
+	}
+
+	t.Run("registration is pending until the email is verified", func(t *testing.T) {
+		svc, deps, registered := register(t)
+		assert.Equal(t, sharedkernel.UserStatusPendingVerification, registered.Status)
+		assert.ErrorIs(t, deps.userRepo.users[registered.UserID].Status.CanLogin(), sharedkernel.ErrUserPendingVerification)
+
+		require.Len(t, deps.notifierRepo.verifications, 1)
+		token := tokenFromLink(t, deps.notifierRepo.verifications[0].VerificationLink)
+
+		output, err := svc.VerifyEmail(ctx, domainuser.VerifyEmailInput{Token: token})
+		require.NoError(t, err)
+		assert.Equal(t, sharedkernel.UserStatusActive, output.Status)
+		assert.NoError(t, deps.userRepo.users[registered.UserID].Status.CanLogin())
+
+		_, err = svc.VerifyEmail(ctx, domainuser.VerifyEmailInput{Token: token})
+		assert.True(t, apperror.IsBadRequest(err), "reused token must be rejected, got %v", err)
+	})
+
+	t.Run("resend replaces the previous link", func(t *testing.T) {
+		svc, deps, _ := register(t)
+
+		_, err := svc.ResendVerification(ctx, domainuser.ResendVerificationInput{Email: "new@example.com"})
+		require.NoError(t, err)
+		sent := deps.notifierRepo.verifications
+		require.Len(t, sent, 2)
+
+		_, err = svc.VerifyEmail(ctx, domainuser.VerifyEmailInput{Token: tokenFromLink(t, sent[0].VerificationLink)})
+		assert.True(t, apperror.IsBadRequest(err), "expected bad request, got %v", err)
+
+		_, err = svc.VerifyEmail(ctx, domainuser.VerifyEmailInput{Token: tokenFromLink(t, sent[1].VerificationLink)})
+		assert.NoError(t, err)
+	})
+
+	t.Run("resend is silent for unknown and verified emails", func(t *testing.T) {
+		svc, deps, registered := register(t)
+		user := deps.userRepo.users[registered.UserID]
+		user.Status = sharedkernel.UserStatusActive
+		deps.userRepo.users[registered.UserID] = user
+
+		_, err := svc.ResendVerification(ctx, domainuser.ResendVerificationInput{Email: "nobody@example.com"})
+		assert.NoError(t, err)
+		_, err = svc.ResendVerification(ctx, domainuser.ResendVerificationInput{Email: "new@example.com"})
+		assert.NoError(t, err)
+		assert.Len(t, deps.notifierRepo.verifications, 1)
+	})
+
+	t.Run("verification does not reactivate a suspended user", func(t *testing.T) {
+		svc, deps, registered := register(t)
+		user := deps.userRepo.users[registered.UserID]
+		user.Status = sharedkernel.UserStatusSuspended
+		deps.userRepo.users[registered.UserID] = user
+
+		output, err := svc.VerifyEmail(ctx, domainuser.VerifyEmailInput{Token: tokenFromLink(t, deps.notifierRepo.verifications[0].VerificationLink)})
+		require.NoError(t, err)
+		assert.Equal(t, sharedkernel.UserStatusSuspended, output.Status)
+		assert.Equal(t, sharedkernel.UserStatusSuspended, deps.userRepo.users[registered.UserID].Status)
+	})
 }
 
 func TestService_PasswordHashing(t *testing.T) {
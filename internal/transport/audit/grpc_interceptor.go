@@ -0,0 +1,65 @@
+package transportaudit
+
+import (
+	"context"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// RequestMetaUnary is the gRPC counterpart of RequestMeta: the peer address,
+// the "user-agent" metadata and the "x-request-id" metadata, or a random
+// request ID, are stored in the context. The request ID is sent back as
+// header metadata.
+func RequestMetaUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(grpcRequestMeta(ctx), req)
+}
+
+func RequestMetaStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &requestMetaServerStream{ServerStream: ss, ctx: grpcRequestMeta(ss.Context())})
+}
+
+func grpcRequestMeta(ctx context.Context) context.Context {
+	var meta sharedkernel.RequestMeta
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		meta.UserAgent = strings.Join(md.Get("user-agent"), " ")
+		if v := md.Get(strings.ToLower(RequestIDHeader)); len(v) > 0 {
+			meta.RequestID = v[0]
+		}
+	}
+	meta.RequestID = ensureRequestID(meta.RequestID)
+	meta.IPAddress = grpcClientIP(ctx)
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(RequestIDHeader), meta.RequestID))
+
+	return sharedkernel.WithRequestMeta(ctx, meta)
+}
+
+// requestMetaServerStream replaces the context of a stream with the one
+// carrying the request meta.
+type requestMetaServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *requestMetaServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// grpcClientIP returns the address of the peer without the port.
+func grpcClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
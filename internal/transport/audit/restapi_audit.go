@@ -0,0 +1,89 @@
+package transportaudit
+
+import (
+	domainaudit "go-bootstrap/internal/domain/audit"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	"go-bootstrap/internal/gen/restapigen"
+	"net/http"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/http/server/ginx"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditRestAPIHandler struct {
+	auditService domainaudit.AuditService
+	helper       *ginx.GinHelper
+}
+
+func NewRestAPIHandler(auditService domainaudit.AuditService, helper *ginx.GinHelper) *AuditRestAPIHandler {
+	return &AuditRestAPIHandler{
+		auditService: auditService,
+		helper:       helper,
+	}
+}
+
+// List audit logs
+// (GET /api/v1/audit-logs)
+func (h *AuditRestAPIHandler) ApiV1GetAuditLogs(c *gin.Context, params restapigen.ApiV1GetAuditLogsParams) {
+	pagination := primitive.PaginationInput{Page: 1, PageSize: 10}
+	if params.Page != nil && *params.Page > 0 {
+		pagination.Page = int64(*params.Page)
+	}
+	if params.PageSize != nil && *params.PageSize > 0 {
+		pagination.PageSize = int64(*params.PageSize)
+	}
+
+	input := domainaudit.GetListAuditLogInput{
+		Pagination:   pagination,
+		UserID:       params.UserId,
+		ActorUserID:  params.ActorUserId,
+		TargetUserID: params.TargetUserId,
+		IPAddress:    params.IpAddress,
+		RequestID:    params.RequestId,
+		From:         params.From,
+		To:           params.To,
+	}
+	if params.Action != nil {
+		action := sharedkernel.AuditAction(*params.Action)
+		input.Action = &action
+	}
+	if params.Outcome != nil {
+		outcome := sharedkernel.AuditOutcome(*params.Outcome)
+		input.Outcome = &outcome
+	}
+
+	output, err := h.auditService.GetListAuditLog(c.Request.Context(), input)
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	auditLogs := make([]restapigen.ApiV1AuditLog, 0, len(output.AuditLogs))
+	for _, v := range output.AuditLogs {
+		details := v.Details
+		if details == nil {
+			details = map[string]string{}
+		}
+		auditLogs = append(auditLogs, restapigen.ApiV1AuditLog{
+			Id:           v.ID,
+			Action:       string(v.Action),
+			Outcome:      string(v.Outcome),
+			Reason:       v.Reason,
+			ActorUserId:  v.ActorUserID,
+			TargetUserId: v.TargetUserID,
+			Details:      details,
+			IpAddress:    v.IPAddress,
+			UserAgent:    v.UserAgent,
+			RequestId:    v.RequestID,
+			CreatedAt:    v.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1GetAuditLogsResponse{
+		AuditLogs:  auditLogs,
+		TotalCount: output.Pagination.TotalData,
+		Page:       int(output.Pagination.Page),
+		PageSize:   int(output.Pagination.PageSize),
+	})
+}
@@ -0,0 +1,58 @@
+package transportaudit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID, on the request when the caller or
+// a proxy assigned one and always on the response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds a request ID taken from the caller, it is stored
+// with every audit log entry of the request.
+const maxRequestIDLength = 128
+
+// RequestMeta is a restapigen.MiddlewareFunc that stores the client IP,
+// User-Agent and request ID of every request in its context for the audit
+// log. A missing or malformed X-Request-ID is replaced by a random one.
+func RequestMeta(c *gin.Context) {
+	requestID := ensureRequestID(c.GetHeader(RequestIDHeader))
+	c.Header(RequestIDHeader, requestID)
+
+	c.Request = c.Request.WithContext(sharedkernel.WithRequestMeta(c.Request.Context(), sharedkernel.RequestMeta{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: requestID,
+	}))
+}
+
+// ensureRequestID returns requestID when it is a sane identifier, otherwise
+// a new random one.
+func ensureRequestID(requestID string) string {
+	if validRequestID(requestID) {
+		return requestID
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,662 @@
+package transportauth
+
+import (
+	"context"
+	"errors"
+	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/gen/grpcgen/auth"
+	"time"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type AuthGrpcHandler struct {
+	authService domainauth.AuthService
+	auth.UnimplementedAuthServiceServer
+}
+
+func NewGrpcHandler(authService domainauth.AuthService) *AuthGrpcHandler {
+	return &AuthGrpcHandler{
+		authService: authService,
+	}
+}
+
+func (h *AuthGrpcHandler) ApiV1Login(ctx context.Context, req *auth.ApiV1LoginRequest) (*auth.ApiV1LoginResponse, error) {
+	client := grpcClientFromContext(ctx)
+	output, err := h.authService.Login(ctx, domainauth.LoginInput{
+		Email:     req.GetEmail(),
+		Password:  req.GetPassword(),
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Platform:  client.Platform,
+	})
+	if err != nil {
+		return nil, grpcLoginError(err)
+	}
+
+	return toGrpcLoginResponse(output), nil
+}
+
+func (h *AuthGrpcHandler) ApiV1LoginMfa(ctx context.Context, req *auth.ApiV1LoginMfaRequest) (*auth.ApiV1LoginResponse, error) {
+	client := grpcClientFromContext(ctx)
+	output, err := h.authService.LoginMfa(ctx, domainauth.LoginMfaInput{
+		Challenge: req.GetMfaChallenge(),
+		Code:      req.GetCode(),
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Platform:  client.Platform,
+	})
+	if err != nil {
+		return nil, grpcLoginError(err)
+	}
+
+	return toGrpcLoginResponse(output), nil
+}
+
+func (h *AuthGrpcHandler) ApiV1StartOidcLogin(ctx context.Context, req *auth.ApiV1StartOidcLoginRequest) (*auth.ApiV1StartOidcLoginResponse, error) {
+	output, err := h.authService.StartOidcLogin(ctx, domainauth.StartOidcLoginInput{
+		Provider: req.GetProvider(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1StartOidcLoginResponse{
+		AuthorizationUrl: output.AuthorizationUrl,
+		State:            output.State,
+		ExpiresIn:        output.ExpiresIn,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1LoginOidc(ctx context.Context, req *auth.ApiV1LoginOidcRequest) (*auth.ApiV1LoginResponse, error) {
+	if req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	client := grpcClientFromContext(ctx)
+	output, err := h.authService.LoginOidc(ctx, domainauth.LoginOidcInput{
+		Provider:  req.GetProvider(),
+		Code:      req.GetCode(),
+		State:     req.GetState(),
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Platform:  client.Platform,
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return toGrpcLoginResponse(output), nil
+}
+
+func (h *AuthGrpcHandler) ApiV1RequestMagicLink(ctx context.Context, req *auth.ApiV1RequestMagicLinkRequest) (*auth.ApiV1RequestMagicLinkResponse, error) {
+	if req.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	_, err := h.authService.RequestMagicLink(ctx, domainauth.RequestMagicLinkInput{
+		Email:     req.GetEmail(),
+		IPAddress: grpcClientIP(ctx),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1RequestMagicLinkResponse{
+		Message: "if the email is registered, a sign-in link has been sent",
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1LoginMagicLink(ctx context.Context, req *auth.ApiV1LoginMagicLinkRequest) (*auth.ApiV1LoginResponse, error) {
+	client := grpcClientFromContext(ctx)
+	output, err := h.authService.LoginMagicLink(ctx, domainauth.LoginMagicLinkInput{
+		Token:     req.GetToken(),
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Platform:  client.Platform,
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return toGrpcLoginResponse(output), nil
+}
+
+func (h *AuthGrpcHandler) ApiV1RefreshToken(ctx context.Context, req *auth.ApiV1RefreshTokenRequest) (*auth.ApiV1RefreshTokenResponse, error) {
+	output, err := h.authService.RefreshToken(ctx, domainauth.RefreshTokenInput{
+		RefreshToken: req.GetRefreshToken(),
+		IPAddress:    grpcClientIP(ctx),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1RefreshTokenResponse{
+		AccessToken:  output.AccessToken,
+		RefreshToken: output.RefreshToken,
+		ExpiresIn:    output.ExpiresIn,
+		TokenType:    output.TokenType,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1Logout(ctx context.Context, req *auth.ApiV1LogoutRequest) (*auth.ApiV1LogoutResponse, error) {
+	output, err := h.authService.Logout(ctx, domainauth.LogoutInput{
+		AccessToken:  req.GetAccessToken(),
+		RefreshToken: req.GetRefreshToken(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1LogoutResponse{
+		Success: output.Success,
+		Message: output.Message,
+	}, nil
+}
+
+// ApiV1ValidateToken is public: it only answers for the token in the
+// request, and whoever holds that token can already act as its owner, so its
+// claims reveal nothing new.
+func (h *AuthGrpcHandler) ApiV1ValidateToken(ctx context.Context, req *auth.ApiV1ValidateTokenRequest) (*auth.ApiV1ValidateTokenResponse, error) {
+	output, err := h.authService.ValidateToken(ctx, domainauth.ValidateTokenInput{
+		Token:     req.GetToken(),
+		IPAddress: grpcClientIP(ctx),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+	if !output.Valid || output.Payload == nil {
+		return &auth.ApiV1ValidateTokenResponse{Valid: false}, nil
+	}
+
+	return &auth.ApiV1ValidateTokenResponse{
+		Valid:     true,
+		UserId:    output.Payload.UserID,
+		SessionId: output.Payload.SessionID,
+		Email:     output.Payload.Email,
+		Role:      string(output.Payload.Role),
+		TokenType: string(output.Payload.TokenType),
+		ExpiresAt: toGrpcTimestamp(output.Payload.ExpiresAt),
+		ApiKeyId:  output.Payload.ApiKeyID,
+		Scopes:    output.Payload.Scopes,
+
+		ImpersonatorUserId: output.Payload.ImpersonatorID,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1RevokeToken(ctx context.Context, req *auth.ApiV1RevokeTokenRequest) (*auth.ApiV1RevokeTokenResponse, error) {
+	err := grpcOauthCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := h.authService.RevokeToken(ctx, domainauth.RevokeTokenInput{
+		Token: req.GetToken(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1RevokeTokenResponse{
+		Success: output.Success,
+		Message: output.Message,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1GetJwks(ctx context.Context, _ *auth.ApiV1GetJwksRequest) (*auth.ApiV1GetJwksResponse, error) {
+	output, err := h.authService.GetJwks(ctx)
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	keys := make([]*auth.ApiV1Jwk, 0, len(output.Keys))
+	for _, v := range output.Keys {
+		keys = append(keys, &auth.ApiV1Jwk{
+			Kty: v.KeyType,
+			Kid: v.KeyID,
+			Use: v.Use,
+			Alg: v.Algorithm,
+			N:   v.N,
+			E:   v.E,
+			Crv: v.Curve,
+			X:   v.X,
+		})
+	}
+
+	return &auth.ApiV1GetJwksResponse{
+		Keys: keys,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1GetListLoginLockout(ctx context.Context, req *auth.ApiV1GetListLoginLockoutRequest) (*auth.ApiV1GetListLoginLockoutResponse, error) {
+	pagination := primitive.PaginationInput{Page: 1, PageSize: 10}
+	if req.GetPage() > 0 {
+		pagination.Page = req.GetPage()
+	}
+	if req.GetPageSize() > 0 {
+		pagination.PageSize = req.GetPageSize()
+	}
+
+	output, err := h.authService.GetListLoginLockout(ctx, domainauth.GetListLoginLockoutInput{
+		Pagination: pagination,
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	lockouts := make([]*auth.ApiV1LoginLockout, 0, len(output.Lockouts))
+	for _, v := range output.Lockouts {
+		lockouts = append(lockouts, &auth.ApiV1LoginLockout{
+			Scope:        string(v.Scope),
+			Identifier:   v.Identifier,
+			LockoutCount: v.LockoutCount,
+			LastFailedAt: timestamppb.New(v.LastFailedAt),
+			LockedUntil:  timestamppb.New(v.LockedUntil),
+		})
+	}
+
+	return &auth.ApiV1GetListLoginLockoutResponse{
+		Lockouts:   lockouts,
+		TotalCount: output.Pagination.TotalData,
+		Page:       output.Pagination.Page,
+		PageSize:   output.Pagination.PageSize,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1ClearLoginLockout(ctx context.Context, req *auth.ApiV1ClearLoginLockoutRequest) (*auth.ApiV1ClearLoginLockoutResponse, error) {
+	output, err := h.authService.ClearLoginLockout(ctx, domainauth.ClearLoginLockoutInput{
+		Scope:      domainauth.LoginAttemptScope(req.GetScope()),
+		Identifier: req.GetIdentifier(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1ClearLoginLockoutResponse{
+		Success: output.Success,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1Impersonate(ctx context.Context, req *auth.ApiV1ImpersonateRequest) (*auth.ApiV1ImpersonateResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	client := grpcClientFromContext(ctx)
+	output, err := h.authService.Impersonate(ctx, domainauth.ImpersonateInput{
+		ActorUserID:  payload.UserID,
+		TargetUserID: req.GetUserId(),
+		Reason:       req.GetReason(),
+		IPAddress:    client.IPAddress,
+		UserAgent:    client.UserAgent,
+		Platform:     client.Platform,
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1ImpersonateResponse{
+		AccessToken: output.AccessToken,
+		ExpiresIn:   output.ExpiresIn,
+		TokenType:   output.TokenType,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1EnrollTotp(ctx context.Context, _ *auth.ApiV1EnrollTotpRequest) (*auth.ApiV1EnrollTotpResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.authService.EnrollTotp(ctx, domainauth.EnrollTotpInput{
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1EnrollTotpResponse{
+		Secret:     output.Secret,
+		OtpauthUri: output.URI,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1ConfirmTotp(ctx context.Context, req *auth.ApiV1ConfirmTotpRequest) (*auth.ApiV1ConfirmTotpResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.authService.ConfirmTotp(ctx, domainauth.ConfirmTotpInput{
+		UserID: payload.UserID,
+		Code:   req.GetCode(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1ConfirmTotpResponse{
+		RecoveryCodes: output.RecoveryCodes,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1DisableTotp(ctx context.Context, req *auth.ApiV1DisableTotpRequest) (*auth.ApiV1DisableTotpResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.authService.DisableTotp(ctx, domainauth.DisableTotpInput{
+		UserID: payload.UserID,
+		Code:   req.GetCode(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1DisableTotpResponse{
+		Success: output.Success,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1RegenerateRecoveryCodes(ctx context.Context, req *auth.ApiV1RegenerateRecoveryCodesRequest) (*auth.ApiV1RegenerateRecoveryCodesResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.authService.RegenerateRecoveryCodes(ctx, domainauth.RegenerateRecoveryCodesInput{
+		UserID: payload.UserID,
+		Code:   req.GetCode(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1RegenerateRecoveryCodesResponse{
+		RecoveryCodes: output.RecoveryCodes,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1GetListSession(ctx context.Context, _ *auth.ApiV1GetListSessionRequest) (*auth.ApiV1GetListSessionResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.authService.GetListSession(ctx, domainauth.GetListSessionInput{
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	sessions := make([]*auth.ApiV1Session, 0, len(output.Sessions))
+	for _, v := range output.Sessions {
+		sessions = append(sessions, &auth.ApiV1Session{
+			Id:         v.ID,
+			UserAgent:  v.UserAgent,
+			IpAddress:  v.IPAddress,
+			Platform:   v.Platform,
+			CreatedAt:  timestamppb.New(v.CreatedAt),
+			LastUsedAt: timestamppb.New(v.LastUsedAt),
+			ExpiresAt:  timestamppb.New(v.ExpiresAt),
+			Current:    v.ID == payload.SessionID,
+		})
+	}
+
+	return &auth.ApiV1GetListSessionResponse{
+		Sessions: sessions,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1RevokeSession(ctx context.Context, req *auth.ApiV1RevokeSessionRequest) (*auth.ApiV1RevokeSessionResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.authService.RevokeSession(ctx, domainauth.RevokeSessionInput{
+		UserID:    payload.UserID,
+		SessionID: req.GetSessionId(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1RevokeSessionResponse{
+		Success: output.Success,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1RevokeOtherSessions(ctx context.Context, _ *auth.ApiV1RevokeOtherSessionsRequest) (*auth.ApiV1RevokeOtherSessionsResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.authService.RevokeOtherSessions(ctx, domainauth.RevokeOtherSessionsInput{
+		UserID:           payload.UserID,
+		CurrentSessionID: payload.SessionID,
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1RevokeOtherSessionsResponse{
+		RevokedCount: output.RevokedCount,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1RevokeAllSessions(ctx context.Context, req *auth.ApiV1RevokeAllSessionsRequest) (*auth.ApiV1RevokeAllSessionsResponse, error) {
+	output, err := h.authService.RevokeAllSessions(ctx, domainauth.RevokeAllSessionsInput{
+		UserID: req.GetUserId(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1RevokeAllSessionsResponse{
+		RevokedCount: output.RevokedCount,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1CreateApiKey(ctx context.Context, req *auth.ApiV1CreateApiKeyRequest) (*auth.ApiV1CreateApiKeyResponse, error) {
+	payload, err := grpcApiKeyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt *time.Time
+	if req.GetExpiresAt() != nil {
+		v := req.GetExpiresAt().AsTime()
+		expiresAt = &v
+	}
+
+	output, err := h.authService.CreateApiKey(ctx, domainauth.CreateApiKeyInput{
+		UserID:     payload.UserID,
+		Name:       req.GetName(),
+		Scopes:     req.GetScopes(),
+		AllowedIPs: req.GetAllowedIps(),
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1CreateApiKeyResponse{
+		Key:    output.Key,
+		ApiKey: toGrpcApiKey(output.ApiKey),
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1GetListApiKey(ctx context.Context, _ *auth.ApiV1GetListApiKeyRequest) (*auth.ApiV1GetListApiKeyResponse, error) {
+	payload, err := grpcApiKeyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := h.authService.GetListApiKey(ctx, domainauth.GetListApiKeyInput{
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	apiKeys := make([]*auth.ApiV1ApiKey, 0, len(output.ApiKeys))
+	for _, v := range output.ApiKeys {
+		apiKeys = append(apiKeys, toGrpcApiKey(v))
+	}
+
+	return &auth.ApiV1GetListApiKeyResponse{
+		ApiKeys: apiKeys,
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1RotateApiKey(ctx context.Context, req *auth.ApiV1RotateApiKeyRequest) (*auth.ApiV1RotateApiKeyResponse, error) {
+	payload, err := grpcApiKeyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := h.authService.RotateApiKey(ctx, domainauth.RotateApiKeyInput{
+		UserID:   payload.UserID,
+		ApiKeyID: req.GetApiKeyId(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1RotateApiKeyResponse{
+		Key:    output.Key,
+		ApiKey: toGrpcApiKey(output.ApiKey),
+	}, nil
+}
+
+func (h *AuthGrpcHandler) ApiV1RevokeApiKey(ctx context.Context, req *auth.ApiV1RevokeApiKeyRequest) (*auth.ApiV1RevokeApiKeyResponse, error) {
+	payload, err := grpcApiKeyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := h.authService.RevokeApiKey(ctx, domainauth.RevokeApiKeyInput{
+		UserID:   payload.UserID,
+		ApiKeyID: req.GetApiKeyId(),
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+
+	return &auth.ApiV1RevokeApiKeyResponse{
+		Success: output.Success,
+	}, nil
+}
+
+// grpcClient describes the caller the same way the REST API does with the
+// client IP, User-Agent and X-Platform headers.
+type grpcClient struct {
+	IPAddress string
+	UserAgent string
+	Platform  string
+}
+
+func grpcClientFromContext(ctx context.Context) grpcClient {
+	client := grpcClient{
+		IPAddress: grpcClientIP(ctx),
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return client
+	}
+	if v := md.Get("user-agent"); len(v) > 0 {
+		client.UserAgent = v[0]
+	}
+	if v := md.Get("x-platform"); len(v) > 0 {
+		client.Platform = v[0]
+	}
+	return client
+}
+
+// grpcApiKeyOwner is apiKeyOwner of the REST handler: API keys are managed
+// with a user's access token only.
+func grpcApiKeyOwner(ctx context.Context) (domainauth.TokenPayload, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return domainauth.TokenPayload{}, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	if payload.TokenType == domainauth.TokenTypeApiKey {
+		return domainauth.TokenPayload{}, GrpcError(apperror.Forbidden("api keys cannot manage api keys"))
+	}
+	return payload, nil
+}
+
+// grpcOauthCaller is oauthCaller of the REST handler: only an API key may act
+// on tokens of other users, the access token of a user is refused even when
+// its role has the permission. gRPC has no OAuth client credentials.
+func grpcOauthCaller(ctx context.Context) error {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	if payload.TokenType != domainauth.TokenTypeApiKey {
+		return GrpcError(apperror.Forbidden("use an api key"))
+	}
+	return nil
+}
+
+// grpcLoginError answers a lockout with ResourceExhausted, any other error as
+// usual.
+func grpcLoginError(err error) error {
+	var lockedErr *domainauth.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		return status.Error(codes.ResourceExhausted, "too many failed login attempts, try again later")
+	}
+	return GrpcError(err)
+}
+
+func toGrpcLoginResponse(output domainauth.LoginOutput) *auth.ApiV1LoginResponse {
+	return &auth.ApiV1LoginResponse{
+		AccessToken:           output.AccessToken,
+		RefreshToken:          output.RefreshToken,
+		ExpiresIn:             output.ExpiresIn,
+		TokenType:             output.TokenType,
+		MfaRequired:           output.MfaRequired,
+		MfaChallenge:          output.MfaChallenge,
+		MfaChallengeExpiresIn: output.MfaChallengeExpiresIn,
+
+		PasswordChangeRequired: output.PasswordChangeRequired,
+	}
+}
+
+func toGrpcApiKey(apiKey domainauth.ApiKey) *auth.ApiV1ApiKey {
+	resp := &auth.ApiV1ApiKey{
+		Id:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		AllowedIps: apiKey.AllowedIPs,
+		CreatedAt:  timestamppb.New(apiKey.CreatedAt),
+	}
+	if apiKey.ExpiresAt != nil {
+		resp.ExpiresAt = timestamppb.New(*apiKey.ExpiresAt)
+	}
+	if apiKey.LastUsedAt != nil {
+		resp.LastUsedAt = timestamppb.New(*apiKey.LastUsedAt)
+	}
+	return resp
+}
+
+// toGrpcTimestamp leaves a zero time unset.
+func toGrpcTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,53 @@
+package transportauth_test
+
+import (
+	"context"
+	"testing"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/gen/grpcgen/auth"
+	transportauth "go-bootstrap/internal/transport/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeRevokeAuthService struct {
+	fakeAuthService
+	revoked []string
+}
+
+func (f *fakeRevokeAuthService) RevokeToken(ctx context.Context, input domainauth.RevokeTokenInput) (domainauth.RevokeTokenOutput, error) {
+	f.revoked = append(f.revoked, input.Token)
+	return domainauth.RevokeTokenOutput{Success: true}, nil
+}
+
+func TestAuthGrpcHandler_ApiV1RevokeToken(t *testing.T) {
+	svc := &fakeRevokeAuthService{}
+	handler := transportauth.NewGrpcHandler(svc)
+	req := &auth.ApiV1RevokeTokenRequest{Token: "token-of-someone-else"}
+
+	t.Run("access token of a user is refused", func(t *testing.T) {
+		ctx := domainauth.WithTokenPayload(context.Background(), domainauth.TokenPayload{UserID: "1", Role: "admin", TokenType: domainauth.TokenTypeAccess})
+		_, err := handler.ApiV1RevokeToken(ctx, req)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Empty(t, svc.revoked)
+	})
+
+	t.Run("api key may revoke", func(t *testing.T) {
+		ctx := domainauth.WithTokenPayload(context.Background(), apiKeyPayload("tokens:revoke"))
+		output, err := handler.ApiV1RevokeToken(ctx, req)
+		require.NoError(t, err)
+		assert.True(t, output.GetSuccess())
+		assert.Equal(t, []string{"token-of-someone-else"}, svc.revoked)
+	})
+}
+
+func TestAuthGrpcHandler_ApiV1Login_PasswordChangeRequired(t *testing.T) {
+	svc := &fakeLoginAuthService{output: domainauth.LoginOutput{AccessToken: "access", PasswordChangeRequired: true}}
+	output, err := transportauth.NewGrpcHandler(svc).ApiV1Login(context.Background(), &auth.ApiV1LoginRequest{Email: "user@example.com", Password: "secret-password"})
+	require.NoError(t, err)
+	assert.True(t, output.GetPasswordChangeRequired())
+}
@@ -0,0 +1,204 @@
+package transportauth
+
+import (
+	"context"
+	"errors"
+	domainauth "go-bootstrap/internal/domain/auth"
+	domainpolicy "go-bootstrap/internal/domain/policy"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	"go-bootstrap/internal/gen/grpcgen/auth"
+	"go-bootstrap/internal/gen/grpcgen/user"
+	"net"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type AuthGrpcInterceptor struct {
+	authService   domainauth.AuthService
+	policyService domainpolicy.PolicyService
+	methods       map[string][]domainpolicy.Permission
+}
+
+// NewGrpcInterceptor guards the gRPC methods listed in methods, keyed by full
+// method name (e.g. "/user.UserService/ApiV1GetListUsers"). A listed method
+// needs a valid bearer token in the "authorization" metadata and every
+// permission mapped to it; methods that are not listed are public. An API key
+// works as bearer token too, limited to its scopes; a method mapped to no
+// permission needs a key with the * scope. Handlers read the caller
+// with domainauth.TokenPayloadFromContext.
+func NewGrpcInterceptor(
+	authService domainauth.AuthService,
+	policyService domainpolicy.PolicyService,
+	methods map[string][]domainpolicy.Permission,
+) *AuthGrpcInterceptor {
+	return &AuthGrpcInterceptor{
+		authService:   authService,
+		policyService: policyService,
+		methods:       methods,
+	}
+}
+
+func (i *AuthGrpcInterceptor) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := i.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (i *AuthGrpcInterceptor) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := i.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authenticatedServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticate returns ctx carrying the token payload of the caller, or ctx
+// unchanged for a public method.
+func (i *AuthGrpcInterceptor) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	permissions, ok := i.methods[fullMethod]
+	if !ok {
+		return ctx, nil
+	}
+
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if token, ok = bearerToken(v); ok {
+				break
+			}
+		}
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	output, err := i.authService.ValidateToken(ctx, domainauth.ValidateTokenInput{
+		Token:     token,
+		IPAddress: grpcClientIP(ctx),
+		Operation: fullMethod,
+	})
+	if err != nil {
+		return nil, GrpcError(err)
+	}
+	if !output.Valid || output.Payload == nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	if output.Payload.PasswordChangeRequired && !passwordChangeOperations[fullMethod] {
+		return nil, GrpcError(errPasswordChangeRequired)
+	}
+
+	if len(permissions) > 0 {
+		_, err = i.policyService.Authorize(ctx, domainpolicy.AuthorizeInput{
+			Role:        string(output.Payload.Role),
+			Permissions: permissions,
+		})
+		if err != nil {
+			return nil, GrpcError(err)
+		}
+	}
+
+	scopes := make([]string, 0, len(permissions))
+	for _, v := range permissions {
+		scopes = append(scopes, string(v))
+	}
+	if !output.Payload.HasScopes(scopes...) {
+		return nil, GrpcError(errApiKeyScope)
+	}
+
+	return domainauth.WithTokenPayload(ctx, *output.Payload), nil
+}
+
+// authenticatedServerStream replaces the context of a stream with the one
+// carrying the token payload.
+type authenticatedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// grpcClientIP returns the address of the peer without the port.
+func grpcClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// errApiKeyScope is returned when the role of the key owner has a permission
+// but the key was not given it.
+var errApiKeyScope = apperror.Forbidden("api key is missing a required scope")
+
+// errPasswordChangeRequired is returned for any operation but the ones in
+// passwordChangeOperations while the user has to replace a temporary
+// password.
+var errPasswordChangeRequired = apperror.Forbidden("password change required")
+
+// passwordChangeOperations are the REST routes and gRPC methods, named like
+// ValidateTokenInput.Operation, that stay usable until the user replaced its
+// temporary password.
+var passwordChangeOperations = map[string]bool{
+	"POST /api/v1/users/change-password":                true,
+	"GET /api/v1/users/profile":                         true,
+	"POST /api/v1/auth/logout":                          true,
+	user.UserService_ApiV1ChangePassword_FullMethodName: true,
+	user.UserService_ApiV1GetProfile_FullMethodName:     true,
+	auth.AuthService_ApiV1Logout_FullMethodName:         true,
+}
+
+// GrpcError converts an apperror into a gRPC status. Unknown and internal
+// errors are answered without their message. A *sharedkernel.ValidationError
+// is answered with InvalidArgument carrying a BadRequest detail per violation.
+func GrpcError(err error) error {
+	var validationErr *sharedkernel.ValidationError
+	if errors.As(err, &validationErr) {
+		return grpcValidationError(validationErr)
+	}
+
+	apperr, ok := apperror.As(err)
+	if !ok {
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	code := apperr.Code.ToGRPCCode()
+	if code == codes.Internal {
+		return status.Error(code, "internal server error")
+	}
+	return status.Error(code, apperr.PublicMessage)
+}
+
+func grpcValidationError(err *sharedkernel.ValidationError) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range err.Fields {
+		for _, message := range field.Messages {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: message,
+			})
+		}
+	}
+
+	st, detailErr := status.New(codes.InvalidArgument, "Validation error").WithDetails(badRequest)
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return st.Err()
+}
@@ -0,0 +1,43 @@
+package transportauth_test
+
+import (
+	"context"
+	"testing"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	domainpolicy "go-bootstrap/internal/domain/policy"
+	"go-bootstrap/internal/gen/grpcgen/auth"
+	"go-bootstrap/internal/gen/grpcgen/user"
+	transportauth "go-bootstrap/internal/transport/auth"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthGrpcInterceptor_ApiKeyScopes(t *testing.T) {
+	methods := map[string][]domainpolicy.Permission{
+		auth.AuthService_ApiV1EnrollTotp_FullMethodName:   {},
+		user.UserService_ApiV1GetListUsers_FullMethodName: {domainpolicy.PermissionUsersList},
+	}
+	call := func(payload domainauth.TokenPayload, method string) codes.Code {
+		interceptor := transportauth.NewGrpcInterceptor(&fakeAuthService{payload: payload}, fakePolicyService{}, methods)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer key"))
+		_, err := interceptor.Unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+		return status.Code(err)
+	}
+
+	t.Run("narrow key is refused an unscoped method", func(t *testing.T) {
+		payload := apiKeyPayload("users:list")
+		assert.Equal(t, codes.PermissionDenied, call(payload, auth.AuthService_ApiV1EnrollTotp_FullMethodName))
+		assert.Equal(t, codes.OK, call(payload, user.UserService_ApiV1GetListUsers_FullMethodName))
+	})
+
+	t.Run("key with every scope may use an unscoped method", func(t *testing.T) {
+		assert.Equal(t, codes.OK, call(apiKeyPayload(domainauth.ApiKeyScopeAll), auth.AuthService_ApiV1EnrollTotp_FullMethodName))
+	})
+}
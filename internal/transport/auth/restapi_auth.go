@@ -1,9 +1,19 @@
 package transportauth
 
 import (
+	"errors"
 	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/gen/restapigen"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
 	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/http/server/ginx"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/generic"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,17 +32,821 @@ func NewRestAPIHandler(authService domainauth.AuthService, helper *ginx.GinHelpe
 // User login
 // (POST /api/v1/auth/login)
 func (h *AuthRestAPIHandler) ApiV1PostAuthLogin(c *gin.Context) {
-	// TODO: Implement login handler
+	var req restapigen.ApiV1PostAuthLoginRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+	proof, ok := dpopProof(c)
+	if !ok {
+		h.helper.ErrorResponse(c, errMultipleDpopProofs)
+		return
+	}
+
+	output, err := h.authService.Login(c.Request.Context(), domainauth.LoginInput{
+		Email:     string(req.Email),
+		Password:  req.Password,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Platform:  c.GetHeader("X-Platform"),
+		Dpop:      proof,
+	})
+	if err != nil {
+		h.loginErrorResponse(c, err)
+		return
+	}
+
+	h.loginResponse(c, output)
+}
+
+// Complete login with a second factor
+// (POST /api/v1/auth/login/mfa)
+func (h *AuthRestAPIHandler) ApiV1PostAuthLoginMfa(c *gin.Context) {
+	var req restapigen.ApiV1PostAuthLoginMfaRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+	proof, ok := dpopProof(c)
+	if !ok {
+		h.helper.ErrorResponse(c, errMultipleDpopProofs)
+		return
+	}
+
+	output, err := h.authService.LoginMfa(c.Request.Context(), domainauth.LoginMfaInput{
+		Challenge: req.MfaChallenge,
+		Code:      req.Code,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Platform:  c.GetHeader("X-Platform"),
+		Dpop:      proof,
+	})
+	if err != nil {
+		h.loginErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostAuthLoginResponse{
+		AccessToken:            output.AccessToken,
+		RefreshToken:           output.RefreshToken,
+		ExpiresIn:              output.ExpiresIn,
+		TokenType:              output.TokenType,
+		PasswordChangeRequired: &output.PasswordChangeRequired,
+	})
+}
+
+// Start OpenID Connect login
+// (GET /api/v1/auth/oidc/{provider}/authorize)
+func (h *AuthRestAPIHandler) ApiV1GetAuthOidcAuthorize(c *gin.Context, provider string) {
+	output, err := h.authService.StartOidcLogin(c.Request.Context(), domainauth.StartOidcLoginInput{
+		Provider: provider,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1GetAuthOidcAuthorizeResponse{
+		AuthorizationUrl: output.AuthorizationUrl,
+		State:            output.State,
+		ExpiresIn:        output.ExpiresIn,
+	})
+}
+
+// Finish OpenID Connect login
+// (GET /api/v1/auth/oidc/{provider}/callback)
+func (h *AuthRestAPIHandler) ApiV1GetAuthOidcCallback(c *gin.Context, provider string, params restapigen.ApiV1GetAuthOidcCallbackParams) {
+	if params.Error != nil {
+		h.helper.ErrorResponse(c, apperror.BadRequest("oidc login failed: "+*params.Error))
+		return
+	}
+	if params.Code == nil || *params.Code == "" {
+		h.helper.ErrorResponse(c, apperror.BadRequest("code is required"))
+		return
+	}
+
+	output, err := h.authService.LoginOidc(c.Request.Context(), domainauth.LoginOidcInput{
+		Provider:  provider,
+		Code:      *params.Code,
+		State:     params.State,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Platform:  c.GetHeader("X-Platform"),
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	h.loginResponse(c, output)
+}
+
+// Request a sign-in link
+// (POST /api/v1/auth/magic-link)
+func (h *AuthRestAPIHandler) ApiV1PostAuthMagicLink(c *gin.Context) {
+	var req restapigen.ApiV1PostAuthMagicLinkRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	_, err := h.authService.RequestMagicLink(c.Request.Context(), domainauth.RequestMagicLinkInput{
+		Email:     string(req.Email),
+		IPAddress: c.ClientIP(),
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, restapigen.ApiV1PostAuthMagicLinkResponse{
+		Message: "if the email is registered, a sign-in link has been sent",
+	})
+}
+
+// Log in with a sign-in link
+// (POST /api/v1/auth/magic-link/login)
+func (h *AuthRestAPIHandler) ApiV1PostAuthMagicLinkLogin(c *gin.Context) {
+	var req restapigen.ApiV1PostAuthMagicLinkLoginRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+	proof, ok := dpopProof(c)
+	if !ok {
+		h.helper.ErrorResponse(c, errMultipleDpopProofs)
+		return
+	}
+
+	output, err := h.authService.LoginMagicLink(c.Request.Context(), domainauth.LoginMagicLinkInput{
+		Token:     req.Token,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Platform:  c.GetHeader("X-Platform"),
+		Dpop:      proof,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	h.loginResponse(c, output)
+}
+
+// loginResponse answers with the tokens, or with 202 and the challenge when
+// a second factor is required.
+func (h *AuthRestAPIHandler) loginResponse(c *gin.Context, output domainauth.LoginOutput) {
+	if output.MfaRequired {
+		c.JSON(http.StatusAccepted, restapigen.ApiV1PostAuthLoginMfaRequiredResponse{
+			MfaRequired:  true,
+			MfaChallenge: output.MfaChallenge,
+			ExpiresIn:    output.MfaChallengeExpiresIn,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostAuthLoginResponse{
+		AccessToken:            output.AccessToken,
+		RefreshToken:           output.RefreshToken,
+		ExpiresIn:              output.ExpiresIn,
+		TokenType:              output.TokenType,
+		PasswordChangeRequired: &output.PasswordChangeRequired,
+	})
+}
+
+// loginErrorResponse answers a lockout with 429 and Retry-After, any other
+// error as usual.
+func (h *AuthRestAPIHandler) loginErrorResponse(c *gin.Context, err error) {
+	var lockedErr *domainauth.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		retryAfter := int64(math.Ceil(time.Until(lockedErr.LockedUntil).Seconds()))
+		c.Header("Retry-After", strconv.FormatInt(max(retryAfter, 1), 10))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"message": "too many failed login attempts, try again later",
+		})
+		return
+	}
+	h.helper.ErrorResponse(c, err)
+}
+
+// Enroll TOTP
+// (POST /api/v1/auth/mfa/totp)
+func (h *AuthRestAPIHandler) ApiV1PostAuthMfaTotp(c *gin.Context) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	output, err := h.authService.EnrollTotp(c.Request.Context(), domainauth.EnrollTotpInput{
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostAuthMfaTotpResponse{
+		Secret:     output.Secret,
+		OtpauthUri: output.URI,
+	})
+}
+
+// Confirm TOTP enrollment
+// (POST /api/v1/auth/mfa/totp/confirm)
+func (h *AuthRestAPIHandler) ApiV1PostAuthMfaTotpConfirm(c *gin.Context) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var req restapigen.ApiV1PostAuthMfaTotpConfirmRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	output, err := h.authService.ConfirmTotp(c.Request.Context(), domainauth.ConfirmTotpInput{
+		UserID: payload.UserID,
+		Code:   req.Code,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostAuthMfaTotpConfirmResponse{
+		RecoveryCodes: output.RecoveryCodes,
+	})
+}
+
+// Disable TOTP
+// (POST /api/v1/auth/mfa/totp/disable)
+func (h *AuthRestAPIHandler) ApiV1PostAuthMfaTotpDisable(c *gin.Context) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var req restapigen.ApiV1PostAuthMfaTotpDisableRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	output, err := h.authService.DisableTotp(c.Request.Context(), domainauth.DisableTotpInput{
+		UserID: payload.UserID,
+		Code:   generic.FromPtr(req.Code),
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostAuthMfaTotpDisableResponse{
+		Success: output.Success,
+	})
+}
+
+// Regenerate recovery codes
+// (POST /api/v1/auth/mfa/recovery-codes)
+func (h *AuthRestAPIHandler) ApiV1PostAuthMfaRecoveryCodes(c *gin.Context) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var req restapigen.ApiV1PostAuthMfaRecoveryCodesRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	output, err := h.authService.RegenerateRecoveryCodes(c.Request.Context(), domainauth.RegenerateRecoveryCodesInput{
+		UserID: payload.UserID,
+		Code:   req.Code,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostAuthMfaRecoveryCodesResponse{
+		RecoveryCodes: output.RecoveryCodes,
+	})
+}
+
+// List login lockouts
+// (GET /api/v1/auth/lockouts)
+func (h *AuthRestAPIHandler) ApiV1GetAuthLockouts(c *gin.Context, params restapigen.ApiV1GetAuthLockoutsParams) {
+	pagination := primitive.PaginationInput{Page: 1, PageSize: 10}
+	if params.Page != nil && *params.Page > 0 {
+		pagination.Page = int64(*params.Page)
+	}
+	if params.PageSize != nil && *params.PageSize > 0 {
+		pagination.PageSize = int64(*params.PageSize)
+	}
+
+	output, err := h.authService.GetListLoginLockout(c.Request.Context(), domainauth.GetListLoginLockoutInput{
+		Pagination: pagination,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	lockouts := make([]restapigen.ApiV1AuthLockout, 0, len(output.Lockouts))
+	for _, v := range output.Lockouts {
+		lockouts = append(lockouts, restapigen.ApiV1AuthLockout{
+			Scope:        string(v.Scope),
+			Identifier:   v.Identifier,
+			LockoutCount: v.LockoutCount,
+			LastFailedAt: v.LastFailedAt,
+			LockedUntil:  v.LockedUntil,
+		})
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1GetAuthLockoutsResponse{
+		Lockouts:   lockouts,
+		TotalCount: output.Pagination.TotalData,
+		Page:       int(output.Pagination.Page),
+		PageSize:   int(output.Pagination.PageSize),
+	})
+}
+
+// Clear login lockout
+// (DELETE /api/v1/auth/lockouts/{scope}/{identifier})
+func (h *AuthRestAPIHandler) ApiV1DeleteAuthLockout(c *gin.Context, scope string, identifier string) {
+	output, err := h.authService.ClearLoginLockout(c.Request.Context(), domainauth.ClearLoginLockoutInput{
+		Scope:      domainauth.LoginAttemptScope(scope),
+		Identifier: identifier,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1DeleteAuthLockoutResponse{
+		Success: output.Success,
+	})
+}
+
+// Impersonate a user
+// (POST /api/v1/auth/impersonate)
+func (h *AuthRestAPIHandler) ApiV1PostAuthImpersonate(c *gin.Context) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var req restapigen.ApiV1PostAuthImpersonateRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	output, err := h.authService.Impersonate(c.Request.Context(), domainauth.ImpersonateInput{
+		ActorUserID:  payload.UserID,
+		TargetUserID: req.UserId,
+		Reason:       req.Reason,
+		IPAddress:    c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+		Platform:     c.GetHeader("X-Platform"),
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostAuthImpersonateResponse{
+		AccessToken: output.AccessToken,
+		ExpiresIn:   output.ExpiresIn,
+		TokenType:   output.TokenType,
+	})
 }
 
 // User logout
 // (POST /api/v1/auth/logout)
 func (h *AuthRestAPIHandler) ApiV1PostAuthLogout(c *gin.Context) {
-	// TODO: Implement logout handler
+	var req restapigen.ApiV1PostAuthLogoutRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	output, err := h.authService.Logout(c.Request.Context(), domainauth.LogoutInput{
+		AccessToken:  req.AccessToken,
+		RefreshToken: generic.FromPtr(req.RefreshToken),
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostAuthLogoutResponse{
+		Success: output.Success,
+		Message: output.Message,
+	})
 }
 
 // Refresh access token
 // (POST /api/v1/auth/refresh)
 func (h *AuthRestAPIHandler) ApiV1PostAuthRefresh(c *gin.Context) {
-	// TODO: Implement refresh token handler
+	var req restapigen.ApiV1PostAuthRefreshRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+	proof, ok := dpopProof(c)
+	if !ok {
+		h.helper.ErrorResponse(c, errMultipleDpopProofs)
+		return
+	}
+
+	output, err := h.authService.RefreshToken(c.Request.Context(), domainauth.RefreshTokenInput{
+		RefreshToken: req.RefreshToken,
+		IPAddress:    c.ClientIP(),
+		Dpop:         proof,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostAuthRefreshResponse{
+		AccessToken:  output.AccessToken,
+		RefreshToken: output.RefreshToken,
+		ExpiresIn:    output.ExpiresIn,
+		TokenType:    output.TokenType,
+	})
+}
+
+// List sessions
+// (GET /api/v1/auth/sessions)
+func (h *AuthRestAPIHandler) ApiV1GetAuthSessions(c *gin.Context) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	output, err := h.authService.GetListSession(c.Request.Context(), domainauth.GetListSessionInput{
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	sessions := make([]restapigen.ApiV1AuthSession, 0, len(output.Sessions))
+	for _, v := range output.Sessions {
+		sessions = append(sessions, restapigen.ApiV1AuthSession{
+			Id:         v.ID,
+			UserAgent:  v.UserAgent,
+			IpAddress:  v.IPAddress,
+			Platform:   v.Platform,
+			CreatedAt:  v.CreatedAt,
+			LastUsedAt: v.LastUsedAt,
+			ExpiresAt:  v.ExpiresAt,
+			Current:    v.ID == payload.SessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1GetAuthSessionsResponse{
+		Sessions: sessions,
+	})
+}
+
+// Revoke other sessions
+// (DELETE /api/v1/auth/sessions)
+func (h *AuthRestAPIHandler) ApiV1DeleteAuthSessions(c *gin.Context) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	output, err := h.authService.RevokeOtherSessions(c.Request.Context(), domainauth.RevokeOtherSessionsInput{
+		UserID:           payload.UserID,
+		CurrentSessionID: payload.SessionID,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1DeleteAuthSessionsResponse{
+		RevokedCount: output.RevokedCount,
+	})
+}
+
+// Revoke session
+// (DELETE /api/v1/auth/sessions/{session_id})
+func (h *AuthRestAPIHandler) ApiV1DeleteAuthSession(c *gin.Context, sessionId string) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	output, err := h.authService.RevokeSession(c.Request.Context(), domainauth.RevokeSessionInput{
+		UserID:    payload.UserID,
+		SessionID: sessionId,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1DeleteAuthSessionResponse{
+		Success: output.Success,
+	})
+}
+
+// Log a user out everywhere
+// (DELETE /api/v1/auth/users/{user_id}/sessions)
+func (h *AuthRestAPIHandler) ApiV1DeleteAuthUserSessions(c *gin.Context, userId string) {
+	output, err := h.authService.RevokeAllSessions(c.Request.Context(), domainauth.RevokeAllSessionsInput{
+		UserID: userId,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1DeleteAuthUserSessionsResponse{
+		RevokedCount: output.RevokedCount,
+	})
+}
+
+// List API keys
+// (GET /api/v1/auth/api-keys)
+func (h *AuthRestAPIHandler) ApiV1GetAuthApiKeys(c *gin.Context) {
+	payload, ok := h.apiKeyOwner(c)
+	if !ok {
+		return
+	}
+
+	output, err := h.authService.GetListApiKey(c.Request.Context(), domainauth.GetListApiKeyInput{
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	apiKeys := make([]restapigen.ApiV1AuthApiKey, 0, len(output.ApiKeys))
+	for _, v := range output.ApiKeys {
+		apiKeys = append(apiKeys, apiKeyResponse(v))
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1GetAuthApiKeysResponse{
+		ApiKeys: apiKeys,
+	})
+}
+
+// Create API key
+// (POST /api/v1/auth/api-keys)
+func (h *AuthRestAPIHandler) ApiV1PostAuthApiKeys(c *gin.Context) {
+	payload, ok := h.apiKeyOwner(c)
+	if !ok {
+		return
+	}
+
+	var req restapigen.ApiV1PostAuthApiKeysRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	output, err := h.authService.CreateApiKey(c.Request.Context(), domainauth.CreateApiKeyInput{
+		UserID:     payload.UserID,
+		Name:       req.Name,
+		Scopes:     generic.FromPtr(req.Scopes),
+		AllowedIPs: generic.FromPtr(req.AllowedIps),
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, restapigen.ApiV1PostAuthApiKeysResponse{
+		Key:    output.Key,
+		ApiKey: apiKeyResponse(output.ApiKey),
+	})
+}
+
+// Revoke API key
+// (DELETE /api/v1/auth/api-keys/{api_key_id})
+func (h *AuthRestAPIHandler) ApiV1DeleteAuthApiKey(c *gin.Context, apiKeyId string) {
+	payload, ok := h.apiKeyOwner(c)
+	if !ok {
+		return
+	}
+
+	output, err := h.authService.RevokeApiKey(c.Request.Context(), domainauth.RevokeApiKeyInput{
+		UserID:   payload.UserID,
+		ApiKeyID: apiKeyId,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1DeleteAuthApiKeyResponse{
+		Success: output.Success,
+	})
+}
+
+// Rotate API key
+// (POST /api/v1/auth/api-keys/{api_key_id}/rotate)
+func (h *AuthRestAPIHandler) ApiV1PostAuthApiKeyRotate(c *gin.Context, apiKeyId string) {
+	payload, ok := h.apiKeyOwner(c)
+	if !ok {
+		return
+	}
+
+	output, err := h.authService.RotateApiKey(c.Request.Context(), domainauth.RotateApiKeyInput{
+		UserID:   payload.UserID,
+		ApiKeyID: apiKeyId,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostAuthApiKeyRotateResponse{
+		Key:    output.Key,
+		ApiKey: apiKeyResponse(output.ApiKey),
+	})
+}
+
+// apiKeyOwner returns the caller of an API key endpoint. Keys are managed
+// with a user's access token only, so a key cannot mint keys wider than itself.
+func (h *AuthRestAPIHandler) apiKeyOwner(c *gin.Context) (domainauth.TokenPayload, bool) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return domainauth.TokenPayload{}, false
+	}
+	if payload.TokenType == domainauth.TokenTypeApiKey {
+		h.helper.ErrorResponse(c, apperror.Forbidden("api keys cannot manage api keys"))
+		return domainauth.TokenPayload{}, false
+	}
+	return payload, true
+}
+
+func apiKeyResponse(apiKey domainauth.ApiKey) restapigen.ApiV1AuthApiKey {
+	return restapigen.ApiV1AuthApiKey{
+		Id:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		AllowedIps: apiKey.AllowedIPs,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+// JSON Web Key Set
+// (GET /.well-known/jwks.json)
+func (h *AuthRestAPIHandler) GetWellKnownJwks(c *gin.Context) {
+	output, err := h.authService.GetJwks(c.Request.Context())
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	keys := make([]restapigen.Jwk, 0, len(output.Keys))
+	for _, v := range output.Keys {
+		keys = append(keys, restapigen.Jwk{
+			Kty: v.KeyType,
+			Kid: v.KeyID,
+			Use: v.Use,
+			Alg: v.Algorithm,
+			N:   generic.Ternary(v.N != "", &v.N, nil),
+			E:   generic.Ternary(v.E != "", &v.E, nil),
+			Crv: generic.Ternary(v.Curve != "", &v.Curve, nil),
+			X:   generic.Ternary(v.X != "", &v.X, nil),
+		})
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, restapigen.GetWellKnownJwksResponse{
+		Keys: keys,
+	})
+}
+
+// Token introspection
+// (POST /oauth/introspect)
+func (h *AuthRestAPIHandler) PostOauthIntrospect(c *gin.Context) {
+	if !h.oauthCaller(c) {
+		return
+	}
+
+	token := c.PostForm("token")
+	if token == "" {
+		oauthInvalidRequest(c, "token is required")
+		return
+	}
+
+	output, err := h.authService.IntrospectToken(c.Request.Context(), domainauth.IntrospectTokenInput{
+		Token:         token,
+		TokenTypeHint: c.PostForm("token_type_hint"),
+		IPAddress:     c.PostForm("client_ip"),
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	if !output.Active || output.Payload == nil {
+		c.JSON(http.StatusOK, restapigen.PostOauthIntrospectResponse{Active: false})
+		return
+	}
+
+	payload := output.Payload
+	tokenUse := restapigen.PostOauthIntrospectResponseTokenUse(payload.TokenType)
+	resp := restapigen.PostOauthIntrospectResponse{
+		Active:    true,
+		Username:  &payload.Email,
+		TokenType: generic.ToPtr(generic.Ternary(payload.DpopJkt != "", "DPoP", "Bearer")),
+		Sub:       &payload.UserID,
+		Role:      generic.ToPtr(string(payload.Role)),
+		TokenUse:  &tokenUse,
+		Sid:       generic.Ternary(payload.SessionID != "", &payload.SessionID, nil),
+		ApiKeyId:  generic.Ternary(payload.ApiKeyID != "", &payload.ApiKeyID, nil),
+	}
+	if payload.Impersonated() {
+		resp.Act = &struct {
+			Sub string `json:"sub"`
+		}{Sub: payload.ImpersonatorID}
+	}
+	if payload.DpopJkt != "" {
+		resp.Cnf = &struct {
+			Jkt string `json:"jkt"`
+		}{Jkt: payload.DpopJkt}
+	}
+	if !payload.IssuedAt.IsZero() {
+		resp.Iat = generic.ToPtr(payload.IssuedAt.Unix())
+	}
+	if !payload.ExpiresAt.IsZero() {
+		resp.Exp = generic.ToPtr(payload.ExpiresAt.Unix())
+	}
+	if payload.TokenType == domainauth.TokenTypeApiKey {
+		resp.Scope = generic.ToPtr(strings.Join(payload.Scopes, " "))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Token revocation
+// (POST /oauth/revoke)
+func (h *AuthRestAPIHandler) PostOauthRevoke(c *gin.Context) {
+	if !h.oauthCaller(c) {
+		return
+	}
+
+	token := c.PostForm("token")
+	if token == "" {
+		oauthInvalidRequest(c, "token is required")
+		return
+	}
+
+	// an unknown or already revoked token is answered with 200 as well
+	// (RFC 7009 section 2.2), only a failure to revoke is an error
+	_, err := h.authService.RevokeToken(c.Request.Context(), domainauth.RevokeTokenInput{
+		Token:         token,
+		TokenTypeHint: c.PostForm("token_type_hint"),
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// oauthCaller allows OAuth clients and API keys on the introspection and
+// revocation endpoints; the access token of a user is refused even when its
+// role has the permission.
+func (h *AuthRestAPIHandler) oauthCaller(c *gin.Context) bool {
+	if _, ok := domainauth.OauthClientFromContext(c.Request.Context()); ok {
+		return true
+	}
+
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return false
+	}
+	if payload.TokenType != domainauth.TokenTypeApiKey {
+		h.helper.ErrorResponse(c, apperror.Forbidden("use client credentials or an api key"))
+		return false
+	}
+	return true
+}
+
+// oauthInvalidRequest answers with the error format of RFC 6749 section 5.2.
+func oauthInvalidRequest(c *gin.Context, description string) {
+	c.JSON(http.StatusBadRequest, restapigen.OauthError{
+		Error:            "invalid_request",
+		ErrorDescription: &description,
+	})
 }
@@ -0,0 +1,80 @@
+package transportauth_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	"go-bootstrap/internal/gen/restapigen"
+	transportauth "go-bootstrap/internal/transport/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/http/server/ginx"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/validatorx"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLoginAuthService records the login inputs and answers with output.
+type fakeLoginAuthService struct {
+	domainauth.AuthService
+	output domainauth.LoginOutput
+	inputs []domainauth.LoginInput
+}
+
+func (f *fakeLoginAuthService) Login(ctx context.Context, input domainauth.LoginInput) (domainauth.LoginOutput, error) {
+	f.inputs = append(f.inputs, input)
+	return f.output, nil
+}
+
+func newLoginEngine(t *testing.T, svc domainauth.AuthService, trustedProxies []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	validatorx.InitValidator()
+	handler := transportauth.NewRestAPIHandler(svc, ginx.NewGinHelper("message", "errors"))
+	engine := gin.New()
+	require.NoError(t, engine.SetTrustedProxies(trustedProxies))
+	engine.POST("/api/v1/auth/login", handler.ApiV1PostAuthLogin)
+	return engine
+}
+
+func postLogin(engine *gin.Engine, peer, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"user@example.com","password":"secret-password"}`))
+	req.RemoteAddr = peer + ":40000"
+	req.Header.Set("Content-Type", "application/json")
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthRestAPIHandler_Login_ClientIP(t *testing.T) {
+	svc := &fakeLoginAuthService{output: domainauth.LoginOutput{AccessToken: "access"}}
+	engine := newLoginEngine(t, svc, nil)
+
+	// a new forged address on every attempt still counts towards the peer
+	for _, forged := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		require.Equal(t, http.StatusOK, postLogin(engine, "203.0.113.9", forged).Code)
+	}
+	require.Len(t, svc.inputs, 3)
+	for _, input := range svc.inputs {
+		assert.Equal(t, "203.0.113.9", input.IPAddress)
+	}
+}
+
+func TestAuthRestAPIHandler_Login_PasswordChangeRequired(t *testing.T) {
+	svc := &fakeLoginAuthService{output: domainauth.LoginOutput{AccessToken: "access", PasswordChangeRequired: true}}
+	engine := newLoginEngine(t, svc, nil)
+
+	rec := postLogin(engine, "203.0.113.9", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body restapigen.ApiV1PostAuthLoginResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.NotNil(t, body.PasswordChangeRequired)
+	assert.True(t, *body.PasswordChangeRequired)
+}
@@ -0,0 +1,226 @@
+package transportauth
+
+import (
+	domainauth "go-bootstrap/internal/domain/auth"
+	domainpolicy "go-bootstrap/internal/domain/policy"
+	"go-bootstrap/internal/gen/restapigen"
+	"strings"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/http/server/ginx"
+	"github.com/gin-gonic/gin"
+)
+
+type AuthRestAPIMiddleware struct {
+	authService   domainauth.AuthService
+	policyService domainpolicy.PolicyService
+	helper        *ginx.GinHelper
+}
+
+func NewRestAPIMiddleware(
+	authService domainauth.AuthService,
+	policyService domainpolicy.PolicyService,
+	helper *ginx.GinHelper,
+) *AuthRestAPIMiddleware {
+	return &AuthRestAPIMiddleware{
+		authService:   authService,
+		policyService: policyService,
+		helper:        helper,
+	}
+}
+
+// BearerAuth is a restapigen.MiddlewareFunc. The generated wrapper sets
+// restapigen.BearerAuthScopes only for operations that require bearerAuth in
+// the OpenAPI spec, so operations declared with `security: []` pass through.
+// The bearer token is an access token or an API key. A DPoP-bound access
+// token is sent with the DPoP scheme and a proof in the DPoP header (RFC 9449).
+// Basic credentials on an operation that also accepts oauthClient are left to
+// OauthClientAuth. A user that has to replace a temporary password is refused
+// everything but passwordChangeOperations.
+func (m *AuthRestAPIMiddleware) BearerAuth(c *gin.Context) {
+	if _, ok := c.Get(restapigen.BearerAuthScopes); !ok {
+		return
+	}
+	if _, ok := c.Get(restapigen.OauthClientScopes); ok {
+		if _, _, basic := c.Request.BasicAuth(); basic {
+			return
+		}
+	}
+
+	token, ok := bearerToken(c.GetHeader("Authorization"))
+	var proof *domainauth.DpopProof
+	if !ok {
+		token, ok = dpopToken(c.GetHeader("Authorization"))
+		if !ok {
+			m.unauthorized(c, "missing bearer token")
+			return
+		}
+		proof, ok = dpopProof(c)
+		if !ok || proof == nil {
+			m.unauthorized(c, "missing or invalid DPoP proof")
+			return
+		}
+	}
+
+	operation := c.Request.Method + " " + c.FullPath()
+	output, err := m.authService.ValidateToken(c.Request.Context(), domainauth.ValidateTokenInput{
+		Token:     token,
+		IPAddress: c.ClientIP(),
+		Operation: operation,
+		Dpop:      proof,
+	})
+	if err != nil {
+		m.helper.ErrorResponse(c, err)
+		c.Abort()
+		return
+	}
+	if !output.Valid || output.Payload == nil {
+		m.unauthorized(c, "invalid or expired token")
+		return
+	}
+	if output.Payload.PasswordChangeRequired && !passwordChangeOperations[operation] {
+		m.helper.ErrorResponse(c, errPasswordChangeRequired)
+		c.Abort()
+		return
+	}
+
+	c.Request = c.Request.WithContext(domainauth.WithTokenPayload(c.Request.Context(), *output.Payload))
+}
+
+// OauthClientAuth is a restapigen.MiddlewareFunc for operations that accept
+// oauthClient in the OpenAPI spec: HTTP Basic with the client_id and
+// client_secret of a client in the oauth config. When the operation also
+// accepts bearerAuth, a request without Basic credentials is left to
+// BearerAuth.
+func (m *AuthRestAPIMiddleware) OauthClientAuth(c *gin.Context) {
+	if _, ok := c.Get(restapigen.OauthClientScopes); !ok {
+		return
+	}
+
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		if _, bearer := c.Get(restapigen.BearerAuthScopes); bearer {
+			return
+		}
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		m.helper.ErrorResponse(c, apperror.Unauthorized("missing client credentials"))
+		c.Abort()
+		return
+	}
+
+	output, err := m.authService.AuthenticateOauthClient(c.Request.Context(), domainauth.AuthenticateOauthClientInput{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	})
+	if err != nil {
+		if apperror.IsUnauthorized(err) {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		m.helper.ErrorResponse(c, err)
+		c.Abort()
+		return
+	}
+
+	c.Request = c.Request.WithContext(domainauth.WithOauthClient(c.Request.Context(), output.ClientID))
+}
+
+// RequirePermissions is a restapigen.MiddlewareFunc that must run after
+// BearerAuth and OauthClientAuth. An authenticated OAuth client needs no
+// permissions. The bearerAuth scopes of an operation in the OpenAPI spec are
+// the permissions the caller's role needs, e.g. `bearerAuth: [users:list]`;
+// an API key must also have them in its scopes. An operation declared with
+// `bearerAuth: []` is refused to an API key without the * scope.
+func (m *AuthRestAPIMiddleware) RequirePermissions(c *gin.Context) {
+	if _, ok := domainauth.OauthClientFromContext(c.Request.Context()); ok {
+		return
+	}
+
+	scopes := c.GetStringSlice(restapigen.BearerAuthScopes)
+	if len(scopes) == 0 {
+		payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+		if ok && !payload.HasScopes() {
+			m.helper.ErrorResponse(c, errApiKeyScope)
+			c.Abort()
+		}
+		return
+	}
+
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		m.unauthorized(c, "missing bearer token")
+		return
+	}
+
+	permissions := make([]domainpolicy.Permission, 0, len(scopes))
+	for _, v := range scopes {
+		permissions = append(permissions, domainpolicy.Permission(v))
+	}
+
+	_, err := m.policyService.Authorize(c.Request.Context(), domainpolicy.AuthorizeInput{
+		Role:        string(payload.Role),
+		Permissions: permissions,
+	})
+	if err != nil {
+		m.helper.ErrorResponse(c, err)
+		c.Abort()
+		return
+	}
+
+	if !payload.HasScopes(scopes...) {
+		m.helper.ErrorResponse(c, errApiKeyScope)
+		c.Abort()
+		return
+	}
+}
+
+func (m *AuthRestAPIMiddleware) unauthorized(c *gin.Context, msg string) {
+	c.Header("WWW-Authenticate", "Bearer, DPoP")
+	m.helper.ErrorResponse(c, apperror.Unauthorized(msg))
+	c.Abort()
+}
+
+func bearerToken(header string) (string, bool) {
+	return schemeToken(header, "Bearer")
+}
+
+func dpopToken(header string) (string, bool) {
+	return schemeToken(header, "DPoP")
+}
+
+func schemeToken(header, want string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, want) {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+var errMultipleDpopProofs = apperror.BadRequest("only one DPoP header is allowed")
+
+// dpopProof returns the DPoP proof of the request with the method and URL it
+// was received on, nil when there is none. More than one DPoP header is
+// refused. The URL is rebuilt from the Host header, the service corrects it
+// with the configured base URL behind a proxy.
+func dpopProof(c *gin.Context) (*domainauth.DpopProof, bool) {
+	values := c.Request.Header.Values("DPoP")
+	switch len(values) {
+	case 0:
+		return nil, true
+	case 1:
+	default:
+		return nil, false
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+
+	return &domainauth.DpopProof{
+		Proof:  values[0],
+		Method: c.Request.Method,
+		Url:    scheme + "://" + c.Request.Host + c.Request.URL.Path,
+	}, true
+}
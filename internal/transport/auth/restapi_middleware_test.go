@@ -0,0 +1,132 @@
+package transportauth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domainauth "go-bootstrap/internal/domain/auth"
+	domainpolicy "go-bootstrap/internal/domain/policy"
+	"go-bootstrap/internal/gen/restapigen"
+	transportauth "go-bootstrap/internal/transport/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/http/server/ginx"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthService validates every token to payload, only from allowedIP
+// when it is set.
+type fakeAuthService struct {
+	domainauth.AuthService
+	payload   domainauth.TokenPayload
+	allowedIP string
+}
+
+func (f *fakeAuthService) ValidateToken(ctx context.Context, input domainauth.ValidateTokenInput) (domainauth.ValidateTokenOutput, error) {
+	if f.allowedIP != "" && input.IPAddress != f.allowedIP {
+		return domainauth.ValidateTokenOutput{Valid: false}, nil
+	}
+	payload := f.payload
+	return domainauth.ValidateTokenOutput{Valid: true, Payload: &payload}, nil
+}
+
+// fakePolicyService grants every permission.
+type fakePolicyService struct{}
+
+func (fakePolicyService) Authorize(ctx context.Context, input domainpolicy.AuthorizeInput) (domainpolicy.AuthorizeOutput, error) {
+	return domainpolicy.AuthorizeOutput{}, nil
+}
+
+type fakeRestAPIServer struct {
+	restapigen.ServerInterface
+}
+
+func (fakeRestAPIServer) ApiV1PostAuthMfaTotp(c *gin.Context) {
+	c.Status(http.StatusOK)
+}
+
+func (fakeRestAPIServer) ApiV1GetUsers(c *gin.Context, params restapigen.ApiV1GetUsersParams) {
+	c.Status(http.StatusOK)
+}
+
+func apiKeyPayload(scopes ...string) domainauth.TokenPayload {
+	return domainauth.TokenPayload{
+		UserID:    "1",
+		Role:      "admin",
+		TokenType: domainauth.TokenTypeApiKey,
+		Scopes:    scopes,
+	}
+}
+
+func TestAuthRestAPIMiddleware_ApiKeyScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newEngine := func(payload domainauth.TokenPayload) *gin.Engine {
+		m := transportauth.NewRestAPIMiddleware(&fakeAuthService{payload: payload}, fakePolicyService{}, ginx.NewGinHelper("message", "errors"))
+		engine := gin.New()
+		restapigen.RegisterHandlersWithOptions(engine, fakeRestAPIServer{}, restapigen.GinServerOptions{
+			Middlewares: []restapigen.MiddlewareFunc{m.OauthClientAuth, m.BearerAuth, m.RequirePermissions},
+		})
+		return engine
+	}
+	serve := func(engine *gin.Engine, method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer key")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("narrow key is refused an unscoped operation", func(t *testing.T) {
+		engine := newEngine(apiKeyPayload("users:list"))
+		assert.Equal(t, http.StatusForbidden, serve(engine, http.MethodPost, "/api/v1/auth/mfa/totp"))
+		assert.Equal(t, http.StatusOK, serve(engine, http.MethodGet, "/api/v1/users"))
+	})
+
+	t.Run("key with every scope may use an unscoped operation", func(t *testing.T) {
+		engine := newEngine(apiKeyPayload(domainauth.ApiKeyScopeAll))
+		assert.Equal(t, http.StatusOK, serve(engine, http.MethodPost, "/api/v1/auth/mfa/totp"))
+	})
+
+	t.Run("access token is not limited by scopes", func(t *testing.T) {
+		engine := newEngine(domainauth.TokenPayload{UserID: "1", Role: "admin", TokenType: domainauth.TokenTypeAccess})
+		assert.Equal(t, http.StatusOK, serve(engine, http.MethodPost, "/api/v1/auth/mfa/totp"))
+	})
+}
+
+func TestAuthRestAPIMiddleware_ApiKeyAllowedIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newEngine := func(trustedProxies []string) *gin.Engine {
+		svc := &fakeAuthService{payload: apiKeyPayload("users:list"), allowedIP: "10.0.0.7"}
+		m := transportauth.NewRestAPIMiddleware(svc, fakePolicyService{}, ginx.NewGinHelper("message", "errors"))
+		engine := gin.New()
+		require.NoError(t, engine.SetTrustedProxies(trustedProxies))
+		restapigen.RegisterHandlersWithOptions(engine, fakeRestAPIServer{}, restapigen.GinServerOptions{
+			Middlewares: []restapigen.MiddlewareFunc{m.OauthClientAuth, m.BearerAuth, m.RequirePermissions},
+		})
+		return engine
+	}
+	serve := func(engine *gin.Engine, peer, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		req.RemoteAddr = peer + ":40000"
+		req.Header.Set("Authorization", "Bearer key")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("forged header from an untrusted peer is ignored", func(t *testing.T) {
+		engine := newEngine(nil)
+		assert.Equal(t, http.StatusUnauthorized, serve(engine, "203.0.113.9", "10.0.0.7"))
+		assert.Equal(t, http.StatusOK, serve(engine, "10.0.0.7", ""))
+	})
+
+	t.Run("header from a trusted proxy names the client", func(t *testing.T) {
+		engine := newEngine([]string{"192.0.2.1"})
+		assert.Equal(t, http.StatusOK, serve(engine, "192.0.2.1", "10.0.0.7"))
+		assert.Equal(t, http.StatusUnauthorized, serve(engine, "203.0.113.9", "10.0.0.7"))
+	})
+}
@@ -0,0 +1,245 @@
+package transportuser
+
+import (
+	"context"
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
+	domainuser "go-bootstrap/internal/domain/user"
+	"go-bootstrap/internal/gen/grpcgen/user"
+	transportauth "go-bootstrap/internal/transport/auth"
+
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/utils/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type UserGrpcHandler struct {
+	userService domainuser.UserService
+	user.UnimplementedUserServiceServer
+}
+
+func NewGrpcHandler(userService domainuser.UserService) *UserGrpcHandler {
+	return &UserGrpcHandler{
+		userService: userService,
+	}
+}
+
+func (h *UserGrpcHandler) ApiV1Register(ctx context.Context, req *user.ApiV1RegisterRequest) (*user.ApiV1RegisterResponse, error) {
+	output, err := h.userService.Register(ctx, domainuser.RegisterInput{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Name:     req.GetName(),
+		Phone:    req.Phone,
+		Gender:   toDomainGender(req.Gender),
+	})
+	if err != nil {
+		return nil, transportauth.GrpcError(err)
+	}
+
+	return &user.ApiV1RegisterResponse{
+		UserId:    output.UserID,
+		Email:     output.Email,
+		Name:      output.Name,
+		Status:    string(output.Status),
+		CreatedAt: timestamppb.New(output.CreatedAt),
+	}, nil
+}
+
+func (h *UserGrpcHandler) ApiV1GetProfile(ctx context.Context, _ *user.ApiV1GetProfileRequest) (*user.ApiV1GetProfileResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.userService.GetProfile(ctx, domainuser.GetProfileInput{
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		return nil, transportauth.GrpcError(err)
+	}
+
+	return &user.ApiV1GetProfileResponse{
+		User: toGrpcUser(output.User),
+	}, nil
+}
+
+func (h *UserGrpcHandler) ApiV1GetListUsers(ctx context.Context, req *user.ApiV1GetListUsersRequest) (*user.ApiV1GetListUsersResponse, error) {
+	input := domainuser.GetListInput{
+		Pagination: primitive.PaginationInput{
+			Page:     req.GetPage(),
+			PageSize: req.GetPageSize(),
+		},
+		Search: req.Search,
+	}
+	if req.Status != nil {
+		v := sharedkernel.UserStatus(req.GetStatus())
+		input.Status = &v
+	}
+	if req.Role != nil {
+		v := domainuser.UserRole(req.GetRole())
+		input.Role = &v
+	}
+
+	output, err := h.userService.GetList(ctx, input)
+	if err != nil {
+		return nil, transportauth.GrpcError(err)
+	}
+
+	users := make([]*user.ApiV1User, 0, len(output.Users))
+	for _, v := range output.Users {
+		users = append(users, toGrpcUser(v))
+	}
+
+	return &user.ApiV1GetListUsersResponse{
+		Users:      users,
+		TotalCount: output.Pagination.TotalData,
+		Page:       output.Pagination.Page,
+		PageSize:   output.Pagination.PageSize,
+	}, nil
+}
+
+func (h *UserGrpcHandler) ApiV1UpdateProfile(ctx context.Context, req *user.ApiV1UpdateProfileRequest) (*user.ApiV1UpdateProfileResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.userService.UpdateProfile(ctx, domainuser.UpdateProfileInput{
+		UserID: payload.UserID,
+		Name:   req.Name,
+		Phone:  req.Phone,
+		Gender: toDomainGender(req.Gender),
+	})
+	if err != nil {
+		return nil, transportauth.GrpcError(err)
+	}
+
+	return &user.ApiV1UpdateProfileResponse{
+		User:      toGrpcUser(output.User),
+		UpdatedAt: timestamppb.New(output.UpdatedAt),
+	}, nil
+}
+
+func (h *UserGrpcHandler) ApiV1ChangePassword(ctx context.Context, req *user.ApiV1ChangePasswordRequest) (*user.ApiV1ChangePasswordResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.userService.ChangePassword(ctx, domainuser.ChangePasswordInput{
+		UserID:      payload.UserID,
+		OldPassword: req.GetOldPassword(),
+		NewPassword: req.GetNewPassword(),
+	})
+	if err != nil {
+		return nil, transportauth.GrpcError(err)
+	}
+
+	return &user.ApiV1ChangePasswordResponse{
+		Success:   output.Success,
+		UpdatedAt: timestamppb.New(output.UpdatedAt),
+	}, nil
+}
+
+func (h *UserGrpcHandler) ApiV1UpdateStatus(ctx context.Context, req *user.ApiV1UpdateStatusRequest) (*user.ApiV1UpdateStatusResponse, error) {
+	payload, ok := domainauth.TokenPayloadFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	output, err := h.userService.UpdateStatus(ctx, domainuser.UpdateStatusInput{
+		ActorUserID: payload.UserID,
+		UserID:      req.GetUserId(),
+		Status:      sharedkernel.UserStatus(req.GetStatus()),
+	})
+	if err != nil {
+		return nil, transportauth.GrpcError(err)
+	}
+
+	return &user.ApiV1UpdateStatusResponse{
+		Success:   output.Success,
+		UpdatedAt: timestamppb.New(output.UpdatedAt),
+	}, nil
+}
+
+func (h *UserGrpcHandler) ApiV1VerifyEmail(ctx context.Context, req *user.ApiV1VerifyEmailRequest) (*user.ApiV1VerifyEmailResponse, error) {
+	output, err := h.userService.VerifyEmail(ctx, domainuser.VerifyEmailInput{
+		Token: req.GetToken(),
+	})
+	if err != nil {
+		return nil, transportauth.GrpcError(err)
+	}
+
+	return &user.ApiV1VerifyEmailResponse{
+		UserId: output.UserID,
+		Status: string(output.Status),
+	}, nil
+}
+
+func (h *UserGrpcHandler) ApiV1ResendVerification(ctx context.Context, req *user.ApiV1ResendVerificationRequest) (*user.ApiV1ResendVerificationResponse, error) {
+	_, err := h.userService.ResendVerification(ctx, domainuser.ResendVerificationInput{
+		Email: req.GetEmail(),
+	})
+	if err != nil {
+		return nil, transportauth.GrpcError(err)
+	}
+
+	return &user.ApiV1ResendVerificationResponse{
+		Message: "if the email is pending verification, a new link has been sent",
+	}, nil
+}
+
+func (h *UserGrpcHandler) ApiV1RequestPasswordReset(ctx context.Context, req *user.ApiV1RequestPasswordResetRequest) (*user.ApiV1RequestPasswordResetResponse, error) {
+	_, err := h.userService.RequestPasswordReset(ctx, domainuser.RequestPasswordResetInput{
+		Email: req.GetEmail(),
+	})
+	if err != nil {
+		return nil, transportauth.GrpcError(err)
+	}
+
+	return &user.ApiV1RequestPasswordResetResponse{
+		Message: "if the email is registered, a reset link has been sent",
+	}, nil
+}
+
+func (h *UserGrpcHandler) ApiV1ResetPassword(ctx context.Context, req *user.ApiV1ResetPasswordRequest) (*user.ApiV1ResetPasswordResponse, error) {
+	output, err := h.userService.ResetPassword(ctx, domainuser.ResetPasswordInput{
+		Token:       req.GetToken(),
+		NewPassword: req.GetNewPassword(),
+	})
+	if err != nil {
+		return nil, transportauth.GrpcError(err)
+	}
+
+	return &user.ApiV1ResetPasswordResponse{
+		Success:   output.Success,
+		UpdatedAt: timestamppb.New(output.UpdatedAt),
+	}, nil
+}
+
+func toGrpcUser(u domainuser.User) *user.ApiV1User {
+	resp := &user.ApiV1User{
+		Id:        u.ID,
+		Email:     u.Email,
+		Name:      u.Name,
+		Role:      string(u.Role),
+		Status:    string(u.Status),
+		Phone:     u.Phone,
+		CreatedAt: timestamppb.New(u.CreatedAt),
+		UpdatedAt: timestamppb.New(u.UpdatedAt),
+	}
+	if u.Gender != nil {
+		v := string(*u.Gender)
+		resp.Gender = &v
+	}
+	return resp
+}
+
+func toDomainGender(gender *string) *domainuser.Gender {
+	if gender == nil {
+		return nil
+	}
+	v := domainuser.Gender(*gender)
+	return &v
+}
@@ -1,11 +1,18 @@
 package transportuser
 
 import (
+	"errors"
+	domainauth "go-bootstrap/internal/domain/auth"
+	sharedkernel "go-bootstrap/internal/domain/shared"
 	domainuser "go-bootstrap/internal/domain/user"
 	"go-bootstrap/internal/gen/restapigen"
+	"net/http"
 
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/apperror"
 	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/http/server/ginx"
+	"github.com/SyaibanAhmadRamadhan/go-foundation-kit/validatorx"
 	"github.com/gin-gonic/gin"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 type UserRestAPIHandler struct {
@@ -29,13 +36,53 @@ func (h *UserRestAPIHandler) ApiV1GetUsers(c *gin.Context, params restapigen.Api
 // Change password
 // (POST /api/v1/users/change-password)
 func (h *UserRestAPIHandler) ApiV1PostUsersChangePassword(c *gin.Context) {
-	// TODO: Implement change password handler
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var req restapigen.ApiV1PostUsersChangePasswordRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	output, err := h.userService.ChangePassword(c.Request.Context(), domainuser.ChangePasswordInput{
+		UserID:      payload.UserID,
+		OldPassword: req.OldPassword,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostUsersChangePasswordResponse{
+		Success:   output.Success,
+		UpdatedAt: output.UpdatedAt,
+	})
 }
 
 // Get user profile
 // (GET /api/v1/users/profile)
 func (h *UserRestAPIHandler) ApiV1GetUsersProfile(c *gin.Context) {
-	// TODO: Implement get user profile handler
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	output, err := h.userService.GetProfile(c.Request.Context(), domainuser.GetProfileInput{
+		UserID: payload.UserID,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1GetUsersProfileResponse{
+		User: toRestAPIUser(output.User),
+	})
 }
 
 // Update user profile
@@ -47,11 +94,336 @@ func (h *UserRestAPIHandler) ApiV1PutUsersProfile(c *gin.Context) {
 // Register new user
 // (POST /api/v1/users/register)
 func (h *UserRestAPIHandler) ApiV1PostUsersRegister(c *gin.Context) {
-	// TODO: Implement user registration handler
+	var req restapigen.ApiV1PostUsersRegisterRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	var gender *domainuser.Gender
+	if req.Gender != nil {
+		v := domainuser.Gender(*req.Gender)
+		gender = &v
+	}
+
+	output, err := h.userService.Register(c.Request.Context(), domainuser.RegisterInput{
+		Email:    string(req.Email),
+		Password: req.Password,
+		Name:     req.Name,
+		Phone:    req.Phone,
+		Gender:   gender,
+	})
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, restapigen.ApiV1PostUsersRegisterResponse{
+		UserId:    output.UserID,
+		Email:     output.Email,
+		Name:      output.Name,
+		Status:    string(output.Status),
+		CreatedAt: output.CreatedAt,
+	})
+}
+
+// Verify email address
+// (POST /api/v1/users/verify-email)
+func (h *UserRestAPIHandler) ApiV1PostUsersVerifyEmail(c *gin.Context) {
+	var req restapigen.ApiV1PostUsersVerifyEmailRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	output, err := h.userService.VerifyEmail(c.Request.Context(), domainuser.VerifyEmailInput{
+		Token: req.Token,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostUsersVerifyEmailResponse{
+		UserId: output.UserID,
+		Status: string(output.Status),
+	})
+}
+
+// Resend verification link
+// (POST /api/v1/users/resend-verification)
+func (h *UserRestAPIHandler) ApiV1PostUsersResendVerification(c *gin.Context) {
+	var req restapigen.ApiV1PostUsersResendVerificationRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	_, err := h.userService.ResendVerification(c.Request.Context(), domainuser.ResendVerificationInput{
+		Email: string(req.Email),
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, restapigen.ApiV1PostUsersResendVerificationResponse{
+		Message: "if the email is pending verification, a new link has been sent",
+	})
+}
+
+// Request password reset
+// (POST /api/v1/users/forgot-password)
+func (h *UserRestAPIHandler) ApiV1PostUsersForgotPassword(c *gin.Context) {
+	var req restapigen.ApiV1PostUsersForgotPasswordRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	_, err := h.userService.RequestPasswordReset(c.Request.Context(), domainuser.RequestPasswordResetInput{
+		Email: string(req.Email),
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, restapigen.ApiV1PostUsersForgotPasswordResponse{
+		Message: "if the email is registered, a reset link has been sent",
+	})
+}
+
+// Reset password
+// (POST /api/v1/users/reset-password)
+func (h *UserRestAPIHandler) ApiV1PostUsersResetPassword(c *gin.Context) {
+	var req restapigen.ApiV1PostUsersResetPasswordRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	output, err := h.userService.ResetPassword(c.Request.Context(), domainuser.ResetPasswordInput{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostUsersResetPasswordResponse{
+		Success:   output.Success,
+		UpdatedAt: output.UpdatedAt,
+	})
 }
 
 // Update user status
 // (PUT /api/v1/users/{user_id}/status)
 func (h *UserRestAPIHandler) ApiV1PutUsersStatus(c *gin.Context, userId string) {
-	// TODO: Implement update user status handler
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var req restapigen.ApiV1PutUsersStatusRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	output, err := h.userService.UpdateStatus(c.Request.Context(), domainuser.UpdateStatusInput{
+		ActorUserID: payload.UserID,
+		UserID:      userId,
+		Status:      sharedkernel.UserStatus(req.Status),
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PutUsersStatusResponse{
+		Success:   output.Success,
+		UpdatedAt: output.UpdatedAt,
+	})
+}
+
+// Create user
+// (POST /api/v1/users)
+func (h *UserRestAPIHandler) ApiV1PostUsers(c *gin.Context) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var req restapigen.ApiV1PostUsersRequest
+	if !h.helper.MustShouldBind(c, &req) {
+		return
+	}
+
+	var gender *domainuser.Gender
+	if req.Gender != nil {
+		v := domainuser.Gender(*req.Gender)
+		gender = &v
+	}
+
+	output, err := h.userService.CreateUser(c.Request.Context(), domainuser.CreateUserInput{
+		ActorUserID:       payload.UserID,
+		Email:             string(req.Email),
+		Name:              req.Name,
+		Role:              domainuser.UserRole(req.Role),
+		Phone:             req.Phone,
+		Gender:            gender,
+		TemporaryPassword: req.TemporaryPassword,
+	})
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, restapigen.ApiV1PostUsersResponse{
+		User: toRestAPIUser(output.User),
+	})
+}
+
+// Delete user
+// (DELETE /api/v1/users/{user_id})
+func (h *UserRestAPIHandler) ApiV1DeleteUsers(c *gin.Context, userId string) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	output, err := h.userService.DeleteUser(c.Request.Context(), domainuser.DeleteUserInput{
+		ActorUserID: payload.UserID,
+		UserID:      userId,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1DeleteUsersResponse{
+		Success:   output.Success,
+		DeletedAt: output.DeletedAt,
+	})
+}
+
+// Restore user
+// (POST /api/v1/users/{user_id}/restore)
+func (h *UserRestAPIHandler) ApiV1PostUsersRestore(c *gin.Context, userId string) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	output, err := h.userService.RestoreUser(c.Request.Context(), domainuser.RestoreUserInput{
+		ActorUserID: payload.UserID,
+		UserID:      userId,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1PostUsersRestoreResponse{
+		User: toRestAPIUser(output.User),
+	})
+}
+
+func (h *UserRestAPIHandler) ApiV1PostUsersErasure(c *gin.Context, userId string) {
+	payload, ok := domainauth.TokenPayloadFromContext(c.Request.Context())
+	if !ok {
+		h.helper.ErrorResponse(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	output, err := h.userService.RequestErasure(c.Request.Context(), domainuser.RequestErasureInput{
+		ActorUserID: payload.UserID,
+		UserID:      userId,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, restapigen.ApiV1PostUsersErasureResponse{
+		Job: toRestAPIErasureJob(output.Job),
+	})
+}
+
+func (h *UserRestAPIHandler) ApiV1GetUsersErasureJobs(c *gin.Context, jobId string) {
+	output, err := h.userService.GetErasureJob(c.Request.Context(), domainuser.GetErasureJobInput{
+		JobID: jobId,
+	})
+	if err != nil {
+		h.helper.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restapigen.ApiV1GetUsersErasureJobsResponse{
+		Job: toRestAPIErasureJob(output.Job),
+	})
+}
+
+// errorResponse answers a *sharedkernel.ValidationError like a failed
+// request binding, one entry per violated rule, and any other error through
+// the helper.
+func (h *UserRestAPIHandler) errorResponse(c *gin.Context, err error) {
+	var validationErr *sharedkernel.ValidationError
+	if errors.As(err, &validationErr) {
+		violations := make([]validatorx.ValidationError, 0, len(validationErr.Fields))
+		for _, field := range validationErr.Fields {
+			for _, message := range field.Messages {
+				violations = append(violations, validatorx.ValidationError{
+					Field:   field.Field,
+					Message: message,
+				})
+			}
+		}
+		_ = c.Error(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Validation error",
+			"errors":  violations,
+		})
+		return
+	}
+	h.helper.ErrorResponse(c, err)
+}
+
+func toRestAPIUser(user domainuser.User) restapigen.ApiV1User {
+	var gender *restapigen.ApiV1UserGender
+	if user.Gender != nil {
+		v := restapigen.ApiV1UserGender(*user.Gender)
+		gender = &v
+	}
+
+	return restapigen.ApiV1User{
+		Id:                     user.ID,
+		Email:                  openapi_types.Email(user.Email),
+		Name:                   user.Name,
+		Phone:                  user.Phone,
+		Gender:                 gender,
+		Role:                   restapigen.ApiV1UserRole(user.Role),
+		Status:                 restapigen.ApiV1UserStatus(user.Status),
+		PasswordChangeRequired: user.PasswordChangeRequired,
+		CreatedAt:              user.CreatedAt,
+		UpdatedAt:              user.UpdatedAt,
+	}
+}
+
+func toRestAPIErasureJob(job domainuser.ErasureJob) restapigen.ApiV1ErasureJob {
+	var requestedBy *string
+	if job.RequestedBy != "" {
+		requestedBy = &job.RequestedBy
+	}
+
+	return restapigen.ApiV1ErasureJob{
+		Id:          job.ID,
+		UserId:      job.UserID,
+		RequestedBy: requestedBy,
+		Status:      restapigen.ApiV1ErasureJobStatus(job.Status),
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
+	}
 }
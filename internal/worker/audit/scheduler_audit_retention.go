@@ -0,0 +1,29 @@
+package workeraudit
+
+import (
+	"context"
+	domainaudit "go-bootstrap/internal/domain/audit"
+	"log/slog"
+	"time"
+)
+
+type SchedulerAuditRetention struct {
+	auditService domainaudit.AuditService
+}
+
+func NewSchedulerAuditRetention(
+	auditService domainaudit.AuditService,
+) *SchedulerAuditRetention {
+	return &SchedulerAuditRetention{
+		auditService: auditService,
+	}
+}
+
+func (w *SchedulerAuditRetention) DeleteExpiredAuditLogs() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	slog.Info("Starting expired audit logs deletion...")
+
+	w.auditService.WorkerDeleteExpiredAuditLogs(ctx)
+}
@@ -0,0 +1,29 @@
+package workeruser
+
+import (
+	"context"
+	domainuser "go-bootstrap/internal/domain/user"
+	"log/slog"
+	"time"
+)
+
+type SchedulerUserErasure struct {
+	userService domainuser.UserService
+}
+
+func NewSchedulerUserErasure(
+	userService domainuser.UserService,
+) *SchedulerUserErasure {
+	return &SchedulerUserErasure{
+		userService: userService,
+	}
+}
+
+func (w *SchedulerUserErasure) RunErasureJobs() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	slog.Info("Starting pending user erasure jobs...")
+
+	w.userService.WorkerRunErasureJobs(ctx)
+}
@@ -0,0 +1,25 @@
+-- Migration: Track refresh token lineage in auth_tokens
+-- Created: 2026-10-16
+--
+-- family_id groups every access/refresh token issued from one login.
+-- parent_id points a rotated refresh token at the one it replaced.
+-- A refresh token presented again after rotation revokes its whole family.
+
+ALTER TABLE auth_tokens ADD COLUMN family_id VARCHAR(64) NULL;
+ALTER TABLE auth_tokens ADD COLUMN parent_id BIGINT NULL;
+
+-- existing tokens have no known lineage, give each its own family
+UPDATE auth_tokens SET family_id = 'legacy-' || id WHERE family_id IS NULL;
+
+ALTER TABLE auth_tokens ALTER COLUMN family_id SET NOT NULL;
+ALTER TABLE auth_tokens
+    ADD CONSTRAINT fk_auth_tokens_parent_id
+    FOREIGN KEY (parent_id) REFERENCES auth_tokens(id) ON DELETE SET NULL;
+
+ALTER TABLE auth_tokens DROP CONSTRAINT IF EXISTS auth_tokens_status_check;
+ALTER TABLE auth_tokens
+    ADD CONSTRAINT auth_tokens_status_check
+    CHECK (status IN ('active', 'revoked', 'expired', 'rotated'));
+
+CREATE INDEX idx_auth_tokens_family_id ON auth_tokens(family_id);
+CREATE INDEX idx_auth_tokens_parent_id ON auth_tokens(parent_id);
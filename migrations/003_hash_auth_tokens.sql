@@ -0,0 +1,34 @@
+-- Migration: Store only keyed hashes of tokens in auth_tokens
+-- Created: 2026-10-16
+--
+-- token_hash / refresh_token_hash hold hex(HMAC-SHA256(token, pepper)) where
+-- pepper is token_hash.pepper in env.json. The plaintext columns are dropped.
+--
+-- By default every existing session is invalidated (users log in again).
+-- To keep live sessions instead, uncomment the rehash block below (it must run
+-- after the ADD COLUMN statements and before the DELETE) and pass the same
+-- pepper the application is configured with:
+--
+--   psql -v pepper="'<token_hash.pepper>'" -f migrations/003_hash_auth_tokens.sql
+--
+
+ALTER TABLE auth_tokens ADD COLUMN token_hash CHAR(64) NULL;
+ALTER TABLE auth_tokens ADD COLUMN refresh_token_hash CHAR(64) NULL;
+
+-- CREATE EXTENSION IF NOT EXISTS pgcrypto;
+-- UPDATE auth_tokens SET
+--     token_hash = encode(hmac(token, :pepper, 'sha256'), 'hex'),
+--     refresh_token_hash = CASE
+--         WHEN refresh_token IS NULL THEN NULL
+--         ELSE encode(hmac(refresh_token, :pepper, 'sha256'), 'hex')
+--     END;
+
+-- rows that were not rehashed can never be looked up again
+DELETE FROM auth_tokens WHERE token_hash IS NULL;
+
+ALTER TABLE auth_tokens ALTER COLUMN token_hash SET NOT NULL;
+ALTER TABLE auth_tokens DROP COLUMN token;
+ALTER TABLE auth_tokens DROP COLUMN refresh_token;
+
+CREATE UNIQUE INDEX idx_auth_tokens_token_hash ON auth_tokens(token_hash);
+CREATE INDEX idx_auth_tokens_refresh_token_hash ON auth_tokens(refresh_token_hash);
@@ -0,0 +1,20 @@
+-- Migration: Create auth_login_attempts table for brute-force lockout
+-- Created: 2026-10-16
+--
+-- One row per (scope, identifier): scope is 'email' or 'ip'. Only used when
+-- login_lockout.store is "sql".
+
+CREATE TABLE IF NOT EXISTS auth_login_attempts (
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('email', 'ip')),
+    identifier VARCHAR(255) NOT NULL,
+    failed_count INT NOT NULL DEFAULT 0,
+    lockout_count INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (scope, identifier)
+);
+
+CREATE INDEX idx_auth_login_attempts_locked_until ON auth_login_attempts(locked_until);
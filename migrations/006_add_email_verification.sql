@@ -0,0 +1,25 @@
+-- Migration: Email verification on registration
+-- Created: 2026-10-16
+--
+-- New users start as 'pending_verification' and become 'active' once the
+-- link from email_verification_tokens is used. Existing users keep their
+-- status. token_hash is HMAC-SHA256(token, token_hash.pepper).
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_status_check
+    CHECK (status IN ('active', 'inactive', 'suspended', 'pending_verification'));
+
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_email_verification_tokens_user_id ON email_verification_tokens(user_id);
+CREATE INDEX idx_email_verification_tokens_expires_at ON email_verification_tokens(expires_at);
@@ -0,0 +1,45 @@
+-- Migration: Create tables for TOTP two-factor authentication
+-- Created: 2026-10-16
+--
+-- secret_encrypted is AES-256-GCM with secret_encryption.key, the secret has
+-- to be read back to verify codes. last_used_step is the last accepted RFC
+-- 6238 time step; a code of that step or an earlier one is refused.
+-- TOTP is only enforced once confirmed_at is set.
+-- code_hash and challenge_hash are HMAC-SHA256 with token_hash.pepper.
+
+CREATE TABLE IF NOT EXISTS auth_mfa_totp (
+    user_id BIGINT PRIMARY KEY,
+    secret_encrypted TEXT NOT NULL,
+    confirmed_at TIMESTAMP NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS auth_mfa_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS auth_mfa_challenges (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    challenge_hash CHAR(64) NOT NULL UNIQUE,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_auth_mfa_challenges_user_id ON auth_mfa_challenges(user_id);
+CREATE INDEX idx_auth_mfa_challenges_expires_at ON auth_mfa_challenges(expires_at);
@@ -0,0 +1,36 @@
+-- Migration: Create auth_sessions table for session management
+-- Created: 2026-10-16
+--
+-- A session is one login: the token family (auth_tokens.family_id) of an
+-- access/refresh pair plus the client it was issued to. platform is the
+-- x-platform request header. last_used_at and expires_at move on every
+-- refresh. Revoking a session revokes the tokens of its family in the same
+-- transaction; access tokens carry the session id in the sid claim.
+--
+-- Live token families get a session row so their refresh tokens keep
+-- working; access tokens issued before this migration have no sid claim and
+-- are rejected, clients refresh them.
+
+CREATE TABLE IF NOT EXISTS auth_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    family_id VARCHAR(64) NOT NULL UNIQUE,
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    platform VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_auth_sessions_user_id ON auth_sessions(user_id);
+CREATE INDEX idx_auth_sessions_expires_at ON auth_sessions(expires_at);
+
+INSERT INTO auth_sessions (user_id, family_id, created_at, last_used_at, expires_at)
+SELECT user_id, family_id, MIN(created_at), MAX(created_at), MAX(expires_at)
+FROM auth_tokens
+WHERE status = 'active' AND token_type = 'refresh'
+GROUP BY user_id, family_id;
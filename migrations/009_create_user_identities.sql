@@ -0,0 +1,36 @@
+-- Migration: Create tables for OpenID Connect login
+-- Created: 2026-10-16
+--
+-- user_identities links an external account to a user. subject is the "sub"
+-- claim of the provider's ID token, email the address it reported when the
+-- identity was linked.
+-- auth_oidc_states holds logins that were started but not finished yet and
+-- is deleted on use. state_hash is HMAC-SHA256 with token_hash.pepper,
+-- code_verifier_encrypted is the PKCE verifier encrypted with
+-- secret_encryption.key.
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (provider, subject),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+
+CREATE TABLE IF NOT EXISTS auth_oidc_states (
+    id BIGSERIAL PRIMARY KEY,
+    state_hash CHAR(64) NOT NULL UNIQUE,
+    provider VARCHAR(50) NOT NULL,
+    nonce VARCHAR(255) NOT NULL,
+    code_verifier_encrypted TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_auth_oidc_states_expires_at ON auth_oidc_states(expires_at);
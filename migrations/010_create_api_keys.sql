@@ -0,0 +1,28 @@
+-- Migration: Create api_keys table for machine clients
+-- Created: 2026-10-16
+--
+-- An API key acts as its owner (user_id), limited to scopes. The key is
+-- shown once at creation or rotation; key_hash is HMAC-SHA256 with
+-- token_hash.pepper and prefix is the first characters of the key, kept to
+-- tell keys apart. scopes and allowed_ips are space separated, an empty
+-- allowed_ips accepts any client IP. Rotation replaces prefix and key_hash in
+-- place, revoked keys are kept for the record.
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT NOT NULL DEFAULT '',
+    allowed_ips TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
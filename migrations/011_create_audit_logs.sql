@@ -0,0 +1,28 @@
+-- Migration: Create audit_logs table for the security audit trail
+-- Created: 2026-10-16
+--
+-- One row per security relevant event: logins, logouts, refreshes,
+-- revocations, password and status changes. actor_user_id performed the
+-- action on target_user_id, either is NULL when unknown (e.g. a login with an
+-- unknown email). There is no foreign key to users so the trail outlives the
+-- account. details is a JSON object of action specific strings. Rows older
+-- than app_scheduler.audit_log.retention are deleted by the scheduler.
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    reason VARCHAR(100) NOT NULL DEFAULT '',
+    actor_user_id BIGINT NULL,
+    target_user_id BIGINT NULL,
+    details TEXT NOT NULL DEFAULT '{}',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at);
+CREATE INDEX idx_audit_logs_actor_user_id ON audit_logs(actor_user_id, created_at);
+CREATE INDEX idx_audit_logs_target_user_id ON audit_logs(target_user_id, created_at);
+CREATE INDEX idx_audit_logs_action ON audit_logs(action, created_at);
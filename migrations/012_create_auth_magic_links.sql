@@ -0,0 +1,23 @@
+-- Migration: Create auth_magic_links table for passwordless login
+-- Created: 2026-10-16
+--
+-- One row per sign-in link sent by email. token_hash is HMAC-SHA256(token,
+-- token_hash.pepper), same as auth_tokens. A link is usable while used_at IS
+-- NULL and expires_at is in the future; rows are kept after use so the
+-- requests of a user can be counted for rate limiting, and are deleted by the
+-- token cleanup worker once expired.
+
+CREATE TABLE IF NOT EXISTS auth_magic_links (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_auth_magic_links_user_id ON auth_magic_links(user_id, created_at);
+CREATE INDEX idx_auth_magic_links_expires_at ON auth_magic_links(expires_at);
@@ -0,0 +1,22 @@
+-- Migration: Bind tokens to DPoP proof keys and remember used proofs
+-- Created: 2026-10-17
+--
+-- dpop_jkt holds the RFC 7638 SHA-256 thumbprint (base64url) of the key a
+-- client proved possession of when the token was issued (RFC 9449). A bound
+-- access token is only accepted with a DPoP proof signed by that key, a bound
+-- refresh token only rotates with one. NULL marks a plain bearer token.
+--
+-- auth_dpop_jtis is the replay cache of proofs: jti_hash is
+-- HMAC-SHA256(jkt || "." || jti, token_hash.pepper). A row expires when its
+-- proof would be too old to be accepted anyway, and is deleted by the token
+-- cleanup worker.
+
+ALTER TABLE auth_tokens ADD COLUMN dpop_jkt VARCHAR(64) NULL;
+
+CREATE TABLE IF NOT EXISTS auth_dpop_jtis (
+    jti_hash CHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_auth_dpop_jtis_expires_at ON auth_dpop_jtis(expires_at);
@@ -0,0 +1,20 @@
+-- Migration: Soft-delete users and temporary passwords set by an admin
+-- Created: 2026-10-17
+--
+-- A user with deleted_at set is deleted: every repository query skips it, so
+-- it cannot log in and its tokens stop validating, until an admin restores it
+-- by clearing deleted_at. Its rows in other tables are kept for the restore.
+-- The email stays unique among users that are not deleted only, a deleted
+-- user cannot be restored while another user holds its email.
+--
+-- password_change_required is set when an admin creates a user with a
+-- temporary password. Until a new password is set the tokens of the user are
+-- only accepted to change the password, read the profile and log out.
+
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN password_change_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users(email) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_deleted_at ON users(deleted_at);
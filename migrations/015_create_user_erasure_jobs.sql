@@ -0,0 +1,34 @@
+-- Migration: Erasure of user accounts for right-to-be-forgotten requests
+-- Created: 2026-10-17
+--
+-- An erasure is requested as a row in user_erasure_jobs and carried out by
+-- the scheduler or the erase-user command: status goes from pending to
+-- running to completed or failed, error tells why a job failed.
+--
+-- Erasing keeps the users row so audit_logs and user_erasure_jobs still
+-- point at it, but overwrites email, name, phone, gender and password_hash
+-- with random or empty values and sets erased_at and deleted_at. An erased
+-- user cannot be restored. The rows of the user in the token, session, MFA,
+-- identity, API key and link tables are deleted, as is the email lockout
+-- counter, and the audit log loses the IP address, user agent and email of
+-- the user but keeps the events.
+
+ALTER TABLE users ADD COLUMN erased_at TIMESTAMP NULL;
+
+CREATE TABLE IF NOT EXISTS user_erasure_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    requested_by BIGINT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP NULL,
+    completed_at TIMESTAMP NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (requested_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_user_erasure_jobs_user_id ON user_erasure_jobs(user_id);
+CREATE INDEX idx_user_erasure_jobs_status ON user_erasure_jobs(status, created_at);